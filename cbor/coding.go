@@ -18,15 +18,35 @@ func EncodeBlock(block blocks.Block, key crypto.EncryptionKey) (format.Node, err
 }
 
 // DecodeBlock returns a node by decrypting the block's raw bytes with key.
+// The default decode limits are applied.
 func DecodeBlock(block blocks.Block, key crypto.DecryptionKey) (format.Node, error) {
+	return decodeBlock(block, key, DecodeLimits{})
+}
+
+func decodeBlock(block blocks.Block, key crypto.DecryptionKey, limits DecodeLimits) (format.Node, error) {
 	var raw []byte
-	err := cbornode.DecodeInto(block.RawData(), &raw)
-	if err != nil {
+	if err := decodeInto(block.RawData(), &raw, limits); err != nil {
 		return nil, err
 	}
 	decoded, err := key.Decrypt(raw)
 	if err != nil {
 		return nil, err
 	}
-	return cbornode.Decode(decoded, mh.SHA2_256, -1)
+	return decode(decoded, limits)
+}
+
+// decode returns a node from raw data after checking it against the decode limits.
+func decode(data []byte, limits DecodeLimits) (format.Node, error) {
+	if err := CheckLimits(data, limits); err != nil {
+		return nil, err
+	}
+	return cbornode.Decode(data, mh.SHA2_256, -1)
+}
+
+// decodeInto decodes raw data into obj after checking it against the decode limits.
+func decodeInto(data []byte, obj interface{}, limits DecodeLimits) error {
+	if err := CheckLimits(data, limits); err != nil {
+		return err
+	}
+	return cbornode.DecodeInto(data, obj)
 }
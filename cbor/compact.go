@@ -0,0 +1,57 @@
+package cbor
+
+import (
+	"bytes"
+	"fmt"
+
+	cbornode "github.com/ipfs/go-ipld-cbor"
+	format "github.com/ipfs/go-ipld-format"
+	mh "github.com/multiformats/go-multihash"
+	pb "github.com/textileio/go-threads/net/pb"
+)
+
+// CompactRecordProto returns a copy of a proto record without its event node,
+// if the event node can be rebuilt from the header and body nodes. Otherwise,
+// the record is returned as is.
+func CompactRecordProto(rec *pb.Log_Record) *pb.Log_Record {
+	if len(rec.EventNode) == 0 || len(rec.HeaderNode) == 0 || len(rec.BodyNode) == 0 {
+		return rec
+	}
+	event, err := rebuildEvent(rec.HeaderNode, rec.BodyNode, DecodeLimits{})
+	if err != nil || !bytes.Equal(event.RawData(), rec.EventNode) {
+		return rec
+	}
+	return &pb.Log_Record{
+		RecordNode: rec.RecordNode,
+		HeaderNode: rec.HeaderNode,
+		BodyNode:   rec.BodyNode,
+	}
+}
+
+// rebuildEvent returns the event node linking the given header and body nodes.
+func rebuildEvent(headerNode, bodyNode []byte, limits DecodeLimits) (format.Node, error) {
+	header, err := decode(headerNode, limits)
+	if err != nil {
+		return nil, err
+	}
+	body, err := decode(bodyNode, limits)
+	if err != nil {
+		return nil, err
+	}
+	return cbornode.WrapObject(&event{
+		Body:   body.Cid(),
+		Header: header.Cid(),
+	}, mh.SHA2_256, -1)
+}
+
+// expandEventNode returns the event node of a proto record, rebuilding it if
+// the record is in the compact format.
+func expandEventNode(rec *pb.Log_Record, limits DecodeLimits) (format.Node, error) {
+	if len(rec.EventNode) != 0 {
+		return decode(rec.EventNode, limits)
+	}
+	if len(rec.HeaderNode) == 0 || len(rec.BodyNode) == 0 {
+		return nil, fmt.Errorf("compact record is missing its header or body")
+	}
+	return rebuildEvent(rec.HeaderNode, rec.BodyNode, limits)
+}
@@ -26,11 +26,23 @@ type event struct {
 
 // eventHeader defines the node structure of an event header.
 type eventHeader struct {
-	Key []byte `refmt:",omitempty"`
+	Key     []byte `refmt:",omitempty"`
+	Receipt bool   `refmt:",omitempty"`
 }
 
 // CreateEvent create a new event by wrapping the body node.
 func CreateEvent(ctx context.Context, dag format.DAGService, body format.Node, rkey crypto.EncryptionKey) (net.Event, error) {
+	return createEvent(ctx, dag, body, rkey, false)
+}
+
+// CreateReceiptEvent creates a new event by wrapping the body node, with its
+// header marking it as a receipt. Peers that predate receipt headers can't
+// decode the header, so they can't read the body.
+func CreateReceiptEvent(ctx context.Context, dag format.DAGService, body format.Node, rkey crypto.EncryptionKey) (net.Event, error) {
+	return createEvent(ctx, dag, body, rkey, true)
+}
+
+func createEvent(ctx context.Context, dag format.DAGService, body format.Node, rkey crypto.EncryptionKey, receipt bool) (net.Event, error) {
 	key, err := sym.NewRandom()
 	if err != nil {
 		return nil, err
@@ -44,7 +56,8 @@ func CreateEvent(ctx context.Context, dag format.DAGService, body format.Node, r
 		return nil, err
 	}
 	eventHeader := &eventHeader{
-		Key: keyb,
+		Key:     keyb,
+		Receipt: receipt,
 	}
 	header, err := cbornode.WrapObject(eventHeader, mh.SHA2_256, -1)
 	if err != nil {
@@ -91,13 +104,18 @@ func GetEvent(ctx context.Context, dag format.DAGService, id cid.Cid) (net.Event
 
 // EventFromNode decodes the given node into an event.
 func EventFromNode(node format.Node) (*Event, error) {
+	return eventFromNode(node, DecodeLimits{})
+}
+
+func eventFromNode(node format.Node, limits DecodeLimits) (*Event, error) {
 	obj := new(event)
-	if err := cbornode.DecodeInto(node.RawData(), obj); err != nil {
+	if err := decodeInto(node.RawData(), obj, limits); err != nil {
 		return nil, err
 	}
 	return &Event{
-		Node: node,
-		obj:  obj,
+		Node:   node,
+		obj:    obj,
+		limits: limits,
 	}, nil
 }
 
@@ -110,7 +128,18 @@ func EventFromRecord(ctx context.Context, dag format.DAGService, rec net.Record)
 
 	event, ok := block.(*Event)
 	if !ok {
-		return EventFromNode(block)
+		r, isRecord := rec.(*Record)
+		var limits DecodeLimits
+		if isRecord {
+			limits = r.limits
+		}
+		if event, err = eventFromNode(block, limits); err != nil {
+			return nil, err
+		}
+		// Keep the decoded event so that its header and body are loaded once
+		if isRecord {
+			r.block = event
+		}
 	}
 	return event, nil
 }
@@ -127,6 +156,18 @@ type Event struct {
 	obj    *event
 	header *EventHeader
 	body   format.Node
+	// limits are applied when decrypting the header and body.
+	limits DecodeLimits
+}
+
+// BodySize loads the encrypted body and returns its byte length. The size is
+// taken from the body itself, so it's available without the read key.
+func (e *Event) BodySize(ctx context.Context, dag format.DAGService) (int, error) {
+	body, err := e.GetBody(ctx, dag, nil)
+	if err != nil {
+		return 0, err
+	}
+	return len(body.RawData()), nil
 }
 
 func (e *Event) HeaderID() cid.Cid {
@@ -150,11 +191,11 @@ func (e *Event) GetHeader(ctx context.Context, dag format.DAGService, key crypto
 
 	header := new(eventHeader)
 	if key != nil {
-		node, err := DecodeBlock(e.header, key)
+		node, err := decodeBlock(e.header, key, e.limits)
 		if err != nil {
 			return nil, err
 		}
-		if err = cbornode.DecodeInto(node.RawData(), header); err != nil {
+		if err = decodeInto(node.RawData(), header, e.limits); err != nil {
 			return nil, err
 		}
 		e.header.obj = header
@@ -190,7 +231,7 @@ func (e *Event) GetBody(ctx context.Context, dag format.DAGService, key crypto.D
 	if k == nil {
 		return e.body, nil
 	} else {
-		return DecodeBlock(e.body, k)
+		return decodeBlock(e.body, k, e.limits)
 	}
 }
 
@@ -207,3 +248,10 @@ func (h *EventHeader) Key() (crypto.DecryptionKey, error) {
 	}
 	return crypto.DecryptionKeyFromBytes(h.obj.Key)
 }
+
+func (h *EventHeader) Receipt() (bool, error) {
+	if h.obj == nil {
+		return false, fmt.Errorf("obj not loaded")
+	}
+	return h.obj.Receipt, nil
+}
@@ -0,0 +1,219 @@
+package cbor
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// ErrDecodeLimitExceeded indicates that a node exceeded the configured decode limits.
+var ErrDecodeLimitExceeded = fmt.Errorf("cbor decode limit exceeded")
+
+// DecodeLimits bounds the resources used when decoding untrusted CBOR nodes.
+// A zero or negative value for any field is replaced by the default limit, so
+// a check cannot be disabled by leaving it unset.
+type DecodeLimits struct {
+	// MaxDepth is the maximum nesting depth of arrays, maps, and tags.
+	MaxDepth int
+	// MaxAlloc is the maximum number of bytes a node may cause to be allocated.
+	MaxAlloc int
+	// MaxLength is the maximum number of elements in a single array or map.
+	MaxLength int
+}
+
+// DefaultDecodeLimits returns the decode limits used where none are configured.
+func DefaultDecodeLimits() DecodeLimits {
+	return DecodeLimits{
+		MaxDepth:  64,
+		MaxAlloc:  1 << 26, // 64 MiB
+		MaxLength: 1 << 16,
+	}
+}
+
+// OrDefault returns the limits with unset fields replaced by the defaults.
+func (l DecodeLimits) OrDefault() DecodeLimits {
+	def := DefaultDecodeLimits()
+	if l.MaxDepth <= 0 {
+		l.MaxDepth = def.MaxDepth
+	}
+	if l.MaxAlloc <= 0 {
+		l.MaxAlloc = def.MaxAlloc
+	}
+	if l.MaxLength <= 0 {
+		l.MaxLength = def.MaxLength
+	}
+	return l
+}
+
+// CheckLimits walks the raw CBOR data without decoding it and returns
+// ErrDecodeLimitExceeded if any of the limits are exceeded.
+func CheckLimits(data []byte, limits DecodeLimits) error {
+	s := &limitScanner{data: data, limits: limits.OrDefault()}
+	for s.pos < len(s.data) {
+		if err := s.item(0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type limitScanner struct {
+	data   []byte
+	pos    int
+	alloc  int
+	limits DecodeLimits
+}
+
+const (
+	majorUint   = 0
+	majorNegInt = 1
+	majorBytes  = 2
+	majorText   = 3
+	majorArray  = 4
+	majorMap    = 5
+	majorTag    = 6
+	majorSimple = 7
+
+	infoIndefinite = 31
+	breakCode      = 0xff
+)
+
+var errTruncated = fmt.Errorf("cbor: unexpected end of data")
+
+// head reads an item header, returning the major type, the additional info,
+// and the decoded argument.
+func (s *limitScanner) head() (major byte, info byte, arg uint64, err error) {
+	if s.pos >= len(s.data) {
+		return 0, 0, 0, errTruncated
+	}
+	b := s.data[s.pos]
+	s.pos++
+	major, info = b>>5, b&0x1f
+	var n int
+	switch {
+	case info < 24:
+		return major, info, uint64(info), nil
+	case info == 24:
+		n = 1
+	case info == 25:
+		n = 2
+	case info == 26:
+		n = 4
+	case info == 27:
+		n = 8
+	case info == infoIndefinite:
+		return major, info, 0, nil
+	default:
+		return 0, 0, 0, fmt.Errorf("cbor: invalid additional info %d", info)
+	}
+	if s.pos+n > len(s.data) {
+		return 0, 0, 0, errTruncated
+	}
+	raw := s.data[s.pos : s.pos+n]
+	s.pos += n
+	switch n {
+	case 1:
+		arg = uint64(raw[0])
+	case 2:
+		arg = uint64(binary.BigEndian.Uint16(raw))
+	case 4:
+		arg = uint64(binary.BigEndian.Uint32(raw))
+	case 8:
+		arg = binary.BigEndian.Uint64(raw)
+	}
+	return major, info, arg, nil
+}
+
+func (s *limitScanner) allocate(n uint64) error {
+	if n > uint64(s.limits.MaxAlloc-s.alloc) {
+		return fmt.Errorf("%w: allocation exceeds %d bytes", ErrDecodeLimitExceeded, s.limits.MaxAlloc)
+	}
+	s.alloc += int(n)
+	return nil
+}
+
+func (s *limitScanner) length(n uint64) error {
+	if n > uint64(s.limits.MaxLength) {
+		return fmt.Errorf("%w: length exceeds %d elements", ErrDecodeLimitExceeded, s.limits.MaxLength)
+	}
+	return nil
+}
+
+func (s *limitScanner) isBreak() bool {
+	if s.pos < len(s.data) && s.data[s.pos] == breakCode {
+		s.pos++
+		return true
+	}
+	return false
+}
+
+// item scans a single data item at the given depth.
+func (s *limitScanner) item(depth int) error {
+	if depth > s.limits.MaxDepth {
+		return fmt.Errorf("%w: depth exceeds %d", ErrDecodeLimitExceeded, s.limits.MaxDepth)
+	}
+	major, info, arg, err := s.head()
+	if err != nil {
+		return err
+	}
+	switch major {
+	case majorUint, majorNegInt, majorSimple:
+		return nil
+	case majorBytes, majorText:
+		if info == infoIndefinite {
+			for !s.isBreak() {
+				if s.pos >= len(s.data) {
+					return errTruncated
+				}
+				if err := s.item(depth + 1); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		if err := s.allocate(arg); err != nil {
+			return err
+		}
+		if arg > uint64(len(s.data)-s.pos) {
+			return errTruncated
+		}
+		s.pos += int(arg)
+		return nil
+	case majorArray, majorMap:
+		per := uint64(1)
+		if major == majorMap {
+			per = 2
+		}
+		if info == infoIndefinite {
+			var count uint64
+			for !s.isBreak() {
+				if s.pos >= len(s.data) {
+					return errTruncated
+				}
+				count++
+				if err := s.length(count / per); err != nil {
+					return err
+				}
+				if err := s.item(depth + 1); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		if err := s.length(arg); err != nil {
+			return err
+		}
+		// Decoders preallocate containers, so account for the element slots.
+		if err := s.allocate(arg * per); err != nil {
+			return err
+		}
+		for i := uint64(0); i < arg*per; i++ {
+			if err := s.item(depth + 1); err != nil {
+				return err
+			}
+		}
+		return nil
+	case majorTag:
+		return s.item(depth + 1)
+	}
+	return fmt.Errorf("cbor: invalid major type %d", major)
+}
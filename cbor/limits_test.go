@@ -0,0 +1,57 @@
+package cbor
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	cbornode "github.com/ipfs/go-ipld-cbor"
+	mh "github.com/multiformats/go-multihash"
+)
+
+func TestCheckLimits(t *testing.T) {
+	node, err := cbornode.WrapObject(map[string]interface{}{
+		"foo": "bar",
+		"baz": []interface{}{1, 2, []byte("howdy")},
+	}, mh.SHA2_256, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = CheckLimits(node.RawData(), DefaultDecodeLimits()); err != nil {
+		t.Fatalf("expected valid node to pass, got %v", err)
+	}
+
+	t.Run("depth", func(t *testing.T) {
+		data := append(bytes.Repeat([]byte{0x81}, 100), 0x01) // [[[...1...]]]
+		if err := CheckLimits(data, DecodeLimits{MaxDepth: 10}); !errors.Is(err, ErrDecodeLimitExceeded) {
+			t.Fatalf("expected decode limit error, got %v", err)
+		}
+		if err := CheckLimits(data, DecodeLimits{MaxDepth: 100}); err != nil {
+			t.Fatalf("expected nesting within limit to pass, got %v", err)
+		}
+	})
+
+	t.Run("defaults", func(t *testing.T) {
+		data := append(bytes.Repeat([]byte{0x81}, 100), 0x01)
+		if err := CheckLimits(data, DecodeLimits{}); !errors.Is(err, ErrDecodeLimitExceeded) {
+			t.Fatalf("expected unset limits to use the defaults, got %v", err)
+		}
+		if err := CheckLimits(data, DecodeLimits{MaxDepth: -1}); !errors.Is(err, ErrDecodeLimitExceeded) {
+			t.Fatalf("expected negative limits to use the defaults, got %v", err)
+		}
+	})
+
+	t.Run("length", func(t *testing.T) {
+		data := []byte{0x9a, 0xff, 0xff, 0xff, 0xff} // array header with 2^32-1 elements
+		if err := CheckLimits(data, DecodeLimits{MaxLength: 1024}); !errors.Is(err, ErrDecodeLimitExceeded) {
+			t.Fatalf("expected decode limit error, got %v", err)
+		}
+	})
+
+	t.Run("alloc", func(t *testing.T) {
+		data := []byte{0x5a, 0x00, 0x10, 0x00, 0x00} // byte string header claiming 1 MiB
+		if err := CheckLimits(data, DecodeLimits{MaxAlloc: 1024}); !errors.Is(err, ErrDecodeLimitExceeded) {
+			t.Fatalf("expected decode limit error, got %v", err)
+		}
+	})
+}
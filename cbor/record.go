@@ -37,18 +37,23 @@ type CreateRecordConfig struct {
 	ServiceKey crypto.EncryptionKey
 }
 
+// RecordPayload returns the payload signed by the log key of a record.
+// The first record in a log signs the author's public key instead of its
+// block and previous record.
+func RecordPayload(block, prev cid.Cid, pubKey []byte) []byte {
+	if prev.Defined() {
+		return append(block.Bytes(), prev.Bytes()...)
+	}
+	return pubKey
+}
+
 // CreateRecord returns a new record from the given block and log private key.
 func CreateRecord(ctx context.Context, dag format.DAGService, config CreateRecordConfig) (net.Record, error) {
 	pkb, err := config.PubKey.MarshalBinary()
 	if err != nil {
 		return nil, err
 	}
-	var payload []byte
-	if config.Prev.Defined() {
-		payload = append(config.Block.Cid().Bytes(), config.Prev.Bytes()...)
-	} else {
-		payload = pkb
-	}
+	payload := RecordPayload(config.Block.Cid(), config.Prev, pkb)
 	sig, err := config.Key.Sign(payload)
 	if err != nil {
 		return nil, err
@@ -97,7 +102,7 @@ func RecordFromNode(coded format.Node, key crypto.DecryptionKey) (net.Record, er
 	if err != nil {
 		return nil, err
 	}
-	if err = cbornode.DecodeInto(node.RawData(), obj); err != nil {
+	if err = decodeInto(node.RawData(), obj, DecodeLimits{}); err != nil {
 		return nil, err
 	}
 	return &Record{
@@ -143,36 +148,48 @@ func RecordToProto(ctx context.Context, dag format.DAGService, rec net.Record) (
 }
 
 // Unmarshal returns a node from a serialized version that contains link data.
+// Records in the compact format have their event node rebuilt. The default
+// decode limits are applied.
 func RecordFromProto(rec *pb.Log_Record, key crypto.DecryptionKey) (net.Record, error) {
+	return RecordFromProtoWithLimits(rec, key, DecodeLimits{})
+}
+
+// RecordFromProtoWithLimits is like RecordFromProto, but applies the given
+// decode limits. The limits also apply when the record's event header and
+// body are later decrypted.
+func RecordFromProtoWithLimits(rec *pb.Log_Record, key crypto.DecryptionKey, limits DecodeLimits) (net.Record, error) {
 	if key == nil {
 		return nil, fmt.Errorf("decryption key is required")
 	}
 
-	rnode, err := cbornode.Decode(rec.RecordNode, mh.SHA2_256, -1)
+	rnode, err := decode(rec.RecordNode, limits)
 	if err != nil {
 		return nil, err
 	}
-	enode, err := cbornode.Decode(rec.EventNode, mh.SHA2_256, -1)
+	enode, err := expandEventNode(rec, limits)
 	if err != nil {
 		return nil, err
 	}
-	hnode, err := cbornode.Decode(rec.HeaderNode, mh.SHA2_256, -1)
+	hnode, err := decode(rec.HeaderNode, limits)
 	if err != nil {
 		return nil, err
 	}
-	body, err := cbornode.Decode(rec.BodyNode, mh.SHA2_256, -1)
+	body, err := decode(rec.BodyNode, limits)
 	if err != nil {
 		return nil, err
 	}
 
-	decoded, err := DecodeBlock(rnode, key)
+	decoded, err := decodeBlock(rnode, key, limits)
 	if err != nil {
 		return nil, err
 	}
 	robj := new(record)
-	if err = cbornode.DecodeInto(decoded.RawData(), robj); err != nil {
+	if err = decodeInto(decoded.RawData(), robj, limits); err != nil {
 		return nil, err
 	}
+	if !robj.Block.Equals(enode.Cid()) {
+		return nil, fmt.Errorf("record block %s does not match event %s", robj.Block, enode.Cid())
+	}
 
 	eobj := new(event)
 	if err = cbornode.DecodeInto(enode.RawData(), eobj); err != nil {
@@ -184,12 +201,14 @@ func RecordFromProto(rec *pb.Log_Record, key crypto.DecryptionKey) (net.Record,
 		header: &EventHeader{
 			Node: hnode,
 		},
-		body: body,
+		body:   body,
+		limits: limits,
 	}
 	return &Record{
-		Node:  rnode,
-		obj:   robj,
-		block: event,
+		Node:   rnode,
+		obj:    robj,
+		block:  event,
+		limits: limits,
 	}, nil
 }
 
@@ -199,6 +218,8 @@ type Record struct {
 
 	obj   *record
 	block format.Node
+	// limits are applied when decoding the record's event.
+	limits DecodeLimits
 }
 
 func (r *Record) BlockID() cid.Cid {
@@ -234,12 +255,7 @@ func (r *Record) Verify(key ic.PubKey) error {
 	if r.block == nil {
 		return fmt.Errorf("block not loaded")
 	}
-	var payload []byte
-	if r.PrevID().Defined() {
-		payload = append(r.block.Cid().Bytes(), r.PrevID().Bytes()...)
-	} else {
-		payload = r.PubKey()
-	}
+	payload := RecordPayload(r.block.Cid(), r.PrevID(), r.PubKey())
 	ok, err := key.Verify(payload, r.Sig())
 	if !ok || err != nil {
 		return fmt.Errorf("bad signature")
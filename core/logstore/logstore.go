@@ -72,6 +72,9 @@ type ThreadMetadata interface {
 
 	// PutBytes stores a byte value under key.
 	PutBytes(t thread.ID, key string, val []byte) error
+
+	// ClearMetadata deletes all metadata under a thread.
+	ClearMetadata(t thread.ID) error
 }
 
 // KeyBook stores log keys.
@@ -100,6 +103,12 @@ type KeyBook interface {
 	// AddServiceKey adds a service key under a thread.
 	AddServiceKey(thread.ID, *sym.Key) error
 
+	// RetiredKeys retrieves the keys previously used by a thread, newest first.
+	RetiredKeys(thread.ID) ([]thread.Key, error)
+
+	// SetRetiredKeys replaces the retired keys of a thread.
+	SetRetiredKeys(thread.ID, []thread.Key) error
+
 	// ClearKeys deletes all keys under a thread.
 	ClearKeys(thread.ID) error
 
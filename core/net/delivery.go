@@ -0,0 +1,70 @@
+package net
+
+import (
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+// PushOutcome is the result of pushing a record to a single peer.
+type PushOutcome int
+
+const (
+	// PushAccepted means the peer accepted the record.
+	PushAccepted PushOutcome = iota
+	// PushFailed means the peer could not be reached or did not accept the record.
+	PushFailed
+	// PushTimedOut means the peer did not reply in time.
+	PushTimedOut
+	// PushDeferred means the peer was known to be unreachable, so the record
+	// was queued for a later attempt without being sent.
+	PushDeferred
+	// PushHeld means the peer received the record, but holds it without
+	// storing it until its ancestors arrive.
+	PushHeld
+)
+
+func (o PushOutcome) String() string {
+	switch o {
+	case PushAccepted:
+		return "accepted"
+	case PushFailed:
+		return "failed"
+	case PushTimedOut:
+		return "timed out"
+	case PushDeferred:
+		return "deferred"
+	case PushHeld:
+		return "held"
+	default:
+		return "unknown"
+	}
+}
+
+// Delivery describes an attempt to push a record to a single peer.
+type Delivery struct {
+	// Addr is the log address the record was pushed to. It is nil for
+	// retries of queued records.
+	Addr ma.Multiaddr
+	// Peer is the ID of the peer at Addr.
+	Peer peer.ID
+	// Outcome is the result of the push.
+	Outcome PushOutcome
+	// Err is the reason the record was not accepted, if any.
+	Err error
+	// Time is when the attempt completed.
+	Time time.Time
+}
+
+// PushResult summarizes the direct pushes of a record to a thread's members.
+type PushResult struct {
+	// Succeeded is the number of peers that accepted the record at any of
+	// their addresses.
+	Succeeded int
+	// Failed is the number of peers that did not, including peers that were
+	// skipped as unreachable.
+	Failed int
+	// Deliveries describes the push to each address.
+	Deliveries []Delivery
+}
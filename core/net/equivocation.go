@@ -0,0 +1,36 @@
+package net
+
+import (
+	"time"
+
+	"github.com/ipfs/go-cid"
+	"github.com/libp2p/go-libp2p-core/peer"
+)
+
+// Equivocation is evidence that a log's owner signed two different records
+// with the same previous record, i.e., forked the log.
+type Equivocation struct {
+	// Log is the ID of the forked log.
+	Log peer.ID
+	// Prev is the previous record of both records. It is undefined if both
+	// records are the first in the log.
+	Prev cid.Cid
+	// Records are the conflicting records. The first is the one accepted by
+	// the host, the second was rejected.
+	Records [2]SignedRecord
+	// Time is when the equivocation was detected.
+	Time time.Time
+}
+
+// SignedRecord holds the signed fields of a record, which are enough to
+// check its signature against the log's public key.
+type SignedRecord struct {
+	// Cid is the record's cid.
+	Cid cid.Cid
+	// Block is the cid of the record's event.
+	Block cid.Cid
+	// PubKey is the key of the record's author.
+	PubKey []byte
+	// Sig is the record's signature by the log key.
+	Sig []byte
+}
@@ -24,6 +24,10 @@ type Event interface {
 
 	// GetBody loads and optionally decrypts the event body.
 	GetBody(context.Context, format.DAGService, crypto.DecryptionKey) (format.Node, error)
+
+	// BodySize loads the event body and returns its encrypted size in bytes,
+	// which doesn't require the read key.
+	BodySize(context.Context, format.DAGService) (int, error)
 }
 
 // EventHeader is the format of the event's header object
@@ -32,4 +36,7 @@ type EventHeader interface {
 
 	// Key returns a single-use decryption key for the event body.
 	Key() (crypto.DecryptionKey, error)
+
+	// Receipt returns whether or not the event is a receipt.
+	Receipt() (bool, error)
 }
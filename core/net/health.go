@@ -0,0 +1,42 @@
+package net
+
+import "time"
+
+// BreakerState is the state of the circuit breaker that guards requests to a
+// peer.
+type BreakerState int
+
+const (
+	// BreakerClosed means requests to the peer are made.
+	BreakerClosed BreakerState = iota
+	// BreakerOpen means the peer failed too many requests in a row, and
+	// requests to it are skipped until a cooldown ends.
+	BreakerOpen
+	// BreakerHalfOpen means the cooldown ended, and a single request is let
+	// through as a probe. The breaker closes if the probe succeeds, and opens
+	// again if it fails.
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// PeerBreaker describes the circuit breaker of a peer with failed requests.
+type PeerBreaker struct {
+	// State is the state of the breaker.
+	State BreakerState
+	// Failures is the number of consecutive failed requests.
+	Failures int
+	// LastFailure is when the last request failed.
+	LastFailure time.Time
+}
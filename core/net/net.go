@@ -3,11 +3,13 @@ package net
 import (
 	"context"
 	"io"
+	"time"
 
 	"github.com/ipfs/go-cid"
 	format "github.com/ipfs/go-ipld-format"
 	"github.com/libp2p/go-libp2p-core/host"
 	"github.com/libp2p/go-libp2p-core/peer"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
 	ma "github.com/multiformats/go-multiaddr"
 	"github.com/textileio/go-threads/core/thread"
 )
@@ -21,6 +23,130 @@ type Net interface {
 
 	// Host provides a network identity.
 	Host() host.Host
+
+	// PubSub provides the pubsub instance used for thread topics, which may be
+	// shared with application messaging on other topics.
+	PubSub() *pubsub.PubSub
+
+	// RekeyThread replaces the thread's service and read keys with new random keys.
+	// The new keys are pushed to thread members, and the old keys are retained
+	// so that existing records remain readable.
+	RekeyThread(ctx context.Context, id thread.ID) (thread.Key, error)
+
+	// RotateReadKey replaces the thread's read key with a new random key, which
+	// is pushed to thread members other than the revoked peers.
+	RotateReadKey(ctx context.Context, id thread.ID, revoked ...peer.ID) (thread.Key, error)
+
+	// TopicPeers returns the peers subscribed to the thread's pubsub topic.
+	TopicPeers(id thread.ID) ([]peer.ID, error)
+
+	// ConnectMembers connects to known thread members that are missing from
+	// the thread's pubsub topic.
+	ConnectMembers(ctx context.Context, id thread.ID) error
+
+	// Follow opens a stream with a peer over which new records in threads are
+	// sent as the peer stores them. Unlike the pubsub topic, the stream fails
+	// rather than dropping records. It blocks until ctx is done or the stream ends.
+	Follow(ctx context.Context, pid peer.ID, ids ...thread.ID) error
+
+	// PullByHeight returns the records in a log with heights in the range [start, end).
+	// The first record in a log has height zero. If the log is only held in
+	// part, the records held at the top of the range are returned with an error.
+	PullByHeight(ctx context.Context, id thread.ID, lid peer.ID, start, end int) ([]Record, error)
+
+	// Ancestry returns the records in a log after from, up to and including to, oldest first.
+	Ancestry(ctx context.Context, id thread.ID, lid peer.ID, from, to cid.Cid) ([]Record, error)
+
+	// VerifyThread compares the local log heads of a thread with the heads held
+	// by its members and reports any discrepancies.
+	VerifyThread(ctx context.Context, id thread.ID) (VerifyReport, error)
+
+	// IsReplicatedTo returns whether or not a peer holds every record of a
+	// thread that is held locally.
+	IsReplicatedTo(ctx context.Context, id thread.ID, pid peer.ID) (bool, error)
+
+	// DroppedEvents returns the number of records that were not delivered to
+	// subscribers because they didn't keep up.
+	DroppedEvents() uint64
+
+	// Deliveries returns the attempts to push a record to its thread's
+	// members, in the order they completed.
+	Deliveries(rid cid.Cid) []Delivery
+
+	// OutboundQueueStats returns stats about records waiting to be pushed to
+	// peers that could not be reached.
+	OutboundQueueStats() OutboundQueueStats
+
+	// PeerBreakers returns the circuit breakers of peers with failed
+	// requests. Peers that are not listed are not failing.
+	PeerBreakers() map[peer.ID]PeerBreaker
+
+	// ThreadStats returns stats about the records of a thread held locally.
+	// The network is not used.
+	ThreadStats(id thread.ID) (ThreadStats, error)
+
+	// Flush applies buffered records that can be applied and delivers queued
+	// pushes, returning once nothing is pending or ctx is done.
+	Flush(ctx context.Context) error
+
+	// LeaveThread announces to the other members that the host is leaving a
+	// thread, and then deletes the thread locally.
+	LeaveThread(ctx context.Context, id thread.ID, opts ...ThreadOption) error
+
+	// DeleteLog removes another peer's log from a thread, along with its
+	// records. The host's own log can't be deleted.
+	DeleteLog(ctx context.Context, id thread.ID, lid peer.ID, opts ...ThreadOption) error
+
+	// SetRequestTimeout sets the max time to wait for a request to a peer.
+	SetRequestTimeout(timeout time.Duration)
+
+	// SetMaxThreadGoroutines sets the max number of background goroutines per thread.
+	SetMaxThreadGoroutines(max int)
+
+	// SetMaxTopicPeers sets the number of thread topic peers above which records
+	// are only pushed directly.
+	SetMaxTopicPeers(max int)
+
+	// SetLogRateLimit sets the max number of new records a log may receive by
+	// push per interval.
+	SetLogRateLimit(records int, interval time.Duration)
+
+	// SetAutoPull enables or disables the periodic pulling of all threads.
+	SetAutoPull(enabled bool)
+
+	// SendReceipt acknowledges a record with a receipt record in the host's log.
+	SendReceipt(ctx context.Context, id thread.ID, rid cid.Cid, opts ...ThreadOption) (ThreadRecord, error)
+
+	// Receipts returns the logs that have acknowledged a record, mapped to
+	// their receipt record.
+	Receipts(id thread.ID, rid cid.Cid) (map[peer.ID]cid.Cid, error)
+
+	// Equivocations returns the evidence of log owners that forked their
+	// logs in a thread.
+	Equivocations(id thread.ID) ([]Equivocation, error)
+
+	// LogForks returns the evidence of the forks of a single log in a
+	// thread. See Equivocations.
+	LogForks(id thread.ID, lid peer.ID) ([]Equivocation, error)
+
+	// ExportCAR writes all of a thread's records to w as a CAR file.
+	ExportCAR(ctx context.Context, id thread.ID, w io.Writer) error
+
+	// ImportCAR loads a thread from a CAR file written by ExportCAR.
+	ImportCAR(ctx context.Context, r io.Reader, opts ...NewThreadOption) (thread.Info, error)
+
+	// ExportThread writes a thread's logs, heads, and encrypted records to w as
+	// a versioned bundle. The thread key is included if the host owns a log in
+	// the thread.
+	ExportThread(ctx context.Context, id thread.ID, w io.Writer, opts ...ThreadOption) error
+
+	// ImportThread loads a thread from a bundle written by ExportThread. The
+	// host writes to the thread with a log of its own, never one in the bundle.
+	ImportThread(ctx context.Context, r io.Reader, opts ...NewThreadOption) (thread.Info, error)
+
+	// ThreadGoroutines returns the number of background goroutines currently
+	// running on behalf of each thread.
+	ThreadGoroutines() map[thread.ID]int
 }
 
 // API is the network interface for thread orchestration.
@@ -41,7 +41,9 @@ func WithNewThreadToken(t thread.Token) NewThreadOption {
 
 // ThreadOptions defines options for interacting with a thread.
 type ThreadOptions struct {
-	Token thread.Token
+	Token       thread.Token
+	MinReplicas int
+	PushResult  *PushResult
 }
 
 // ThreadOption specifies thread options.
@@ -54,10 +56,29 @@ func WithThreadToken(t thread.Token) ThreadOption {
 	}
 }
 
+// WithMinReplicas makes record creation wait until at least n peers have
+// acknowledged the new record. An error is returned if fewer peers acknowledge
+// the record in time. The record is stored locally either way.
+func WithMinReplicas(n int) ThreadOption {
+	return func(args *ThreadOptions) {
+		args.MinReplicas = n
+	}
+}
+
+// WithPushResult makes record creation wait until the new record has been
+// pushed to each thread member, and sets r to the outcome. Records are
+// published to the thread topic regardless.
+func WithPushResult(r *PushResult) ThreadOption {
+	return func(args *ThreadOptions) {
+		args.PushResult = r
+	}
+}
+
 // SubOptions defines options for a thread subscription.
 type SubOptions struct {
 	ThreadIDs thread.IDSlice
 	Token     thread.Token
+	Deletions bool
 }
 
 // SubOption is a thread subscription option.
@@ -71,6 +92,14 @@ func WithSubFilter(id thread.ID) SubOption {
 	}
 }
 
+// WithSubDeletions makes the subscription also receive a thread record
+// with a nil value when a thread is deleted.
+func WithSubDeletions() SubOption {
+	return func(args *SubOptions) {
+		args.Deletions = true
+	}
+}
+
 // WithSubToken provides authorization for a subscription.
 func WithSubToken(t thread.Token) SubOption {
 	return func(args *SubOptions) {
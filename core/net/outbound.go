@@ -0,0 +1,23 @@
+package net
+
+import (
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+)
+
+// OutboundQueueStats describes records waiting to be pushed to peers.
+type OutboundQueueStats struct {
+	// Peers maps each peer with undelivered records to its queue stats.
+	Peers map[peer.ID]PeerQueueStats
+}
+
+// PeerQueueStats describes the records waiting to be pushed to a single peer.
+type PeerQueueStats struct {
+	// Pending is the number of undelivered records.
+	Pending int
+	// OldestAge is the age of the oldest undelivered record.
+	OldestAge time.Duration
+	// Attempts is the total number of failed delivery attempts.
+	Attempts int
+}
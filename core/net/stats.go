@@ -0,0 +1,28 @@
+package net
+
+import (
+	"github.com/ipfs/go-cid"
+	"github.com/libp2p/go-libp2p-core/peer"
+)
+
+// ThreadStats describes the records of a thread that are held locally.
+type ThreadStats struct {
+	// Logs maps each log in the thread to its stats.
+	Logs map[peer.ID]LogStats
+	// Records is the number of records held in all logs.
+	Records int
+	// Bytes is the size of the blocks of all held records.
+	Bytes int64
+}
+
+// LogStats describes the records of a log that are held locally.
+type LogStats struct {
+	// Heads are the current heads of the log.
+	Heads []cid.Cid
+	// Records is the number of records held, walking back from the heads
+	// until a record is not held.
+	Records int
+	// Bytes is the size of the record, event, header, and body blocks of
+	// the held records.
+	Bytes int64
+}
@@ -0,0 +1,49 @@
+package net
+
+import (
+	"github.com/ipfs/go-cid"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/textileio/go-threads/core/thread"
+)
+
+// VerifyReport compares the local view of a thread with the views of its members.
+type VerifyReport struct {
+	// ThreadID is the verified thread.
+	ThreadID thread.ID
+	// Logs contains a comparison for every log known locally or by a member,
+	// in order of log ID.
+	Logs []LogReport
+	// Unreachable lists the members that could not be queried.
+	Unreachable []peer.ID
+}
+
+// Consistent returns whether or not all reachable members agree with the local view.
+func (r VerifyReport) Consistent() bool {
+	for _, l := range r.Logs {
+		if !l.Consistent() {
+			return false
+		}
+	}
+	return true
+}
+
+// LogReport compares the local head of a log with the heads held by members.
+type LogReport struct {
+	// LogID is the compared log.
+	LogID peer.ID
+	// LocalHead is the local head, or cid.Undef if the log is unknown locally.
+	LocalHead cid.Cid
+	// Heads maps each member that reported the log to its head.
+	Heads map[peer.ID]cid.Cid
+	// Behind lists members that are missing records held locally.
+	Behind []peer.ID
+	// Ahead lists members that hold records missing locally.
+	Ahead []peer.ID
+	// Divergent is true when members report different heads.
+	Divergent bool
+}
+
+// Consistent returns whether or not all members that reported the log agree with the local head.
+func (l LogReport) Consistent() bool {
+	return len(l.Behind) == 0 && len(l.Ahead) == 0 && !l.Divergent
+}
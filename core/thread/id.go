@@ -2,6 +2,7 @@ package thread
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/binary"
 	"fmt"
 
@@ -59,7 +60,73 @@ func NewIDV1(variant Variant, size uint8) ID {
 	if err != nil {
 		panic("random read failed")
 	}
+	return newIDV1(variant, num)
+}
+
+// NewIDV1FromName returns a deterministic ID using the given variant,
+// where the number is derived by hashing name.
+// The same variant, size, and name always produce the same ID.
+func NewIDV1FromName(variant Variant, size uint8, name string) ID {
+	num := make([]byte, 0, int(size)+sha256.Size)
+	for i := uint64(0); len(num) < int(size); i++ {
+		h := sha256.New()
+		var ctr [binary.MaxVarintLen64]byte
+		h.Write(ctr[:binary.PutUvarint(ctr[:], i)])
+		h.Write([]byte(name))
+		num = h.Sum(num)
+	}
+	return newIDV1(variant, num[:size])
+}
+
+// IDOptions defines options for generating a new ID.
+type IDOptions struct {
+	Variant Variant
+	Size    uint8
+	Name    string
+}
+
+// IDOption specifies ID generation options.
+type IDOption func(*IDOptions)
+
+// WithIDVariant sets the ID variant. Defaults to Raw.
+func WithIDVariant(v Variant) IDOption {
+	return func(args *IDOptions) {
+		args.Variant = v
+	}
+}
+
+// WithIDSize sets the byte length of the ID number. Defaults to 32.
+func WithIDSize(size uint8) IDOption {
+	return func(args *IDOptions) {
+		args.Size = size
+	}
+}
+
+// WithIDName derives the ID number from a hash of name instead of
+// random bytes, which makes the resulting ID deterministic.
+func WithIDName(name string) IDOption {
+	return func(args *IDOptions) {
+		args.Name = name
+	}
+}
+
+// NewID returns a new V1 ID. By default, the ID is random with the Raw
+// variant and a 32 byte number.
+func NewID(opts ...IDOption) ID {
+	args := &IDOptions{
+		Variant: Raw,
+		Size:    32,
+	}
+	for _, opt := range opts {
+		opt(args)
+	}
+	if args.Name != "" {
+		return NewIDV1FromName(args.Variant, args.Size, args.Name)
+	}
+	return NewIDV1(args.Variant, args.Size)
+}
 
+func newIDV1(variant Variant, num []byte) ID {
 	numlen := len(num)
 	// two 8 bytes (max) numbers plus num
 	buf := make([]byte, 2*binary.MaxVarintLen64+numlen)
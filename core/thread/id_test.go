@@ -18,6 +18,31 @@ func TestDecode(t *testing.T) {
 	t.Logf("Decoded ID: %s", j.String())
 }
 
+func TestNewID(t *testing.T) {
+	i := NewID()
+	if i.Variant() != Raw {
+		t.Errorf("got wrong variant from %s: %d", i.String(), i.Variant())
+	}
+	if NewID().Equals(i) {
+		t.Errorf("expected random IDs to differ")
+	}
+
+	opts := []IDOption{WithIDVariant(AccessControlled), WithIDSize(48), WithIDName("foo")}
+	j := NewID(opts...)
+	if j.Variant() != AccessControlled {
+		t.Errorf("got wrong variant from %s: %d", j.String(), j.Variant())
+	}
+	if !NewID(opts...).Equals(j) {
+		t.Errorf("expected named IDs to be deterministic")
+	}
+	if NewID(WithIDVariant(AccessControlled), WithIDSize(48), WithIDName("bar")).Equals(j) {
+		t.Errorf("expected IDs with different names to differ")
+	}
+	if _, err := Cast(j.Bytes()); err != nil {
+		t.Errorf("named ID %s is not valid: %s", j.String(), err)
+	}
+}
+
 func TestExtractEncoding(t *testing.T) {
 	i := NewIDV1(Raw, 16)
 
@@ -14,11 +14,15 @@ const (
 	// Code is the protocol code.
 	Code = 406
 	// Version is the current protocol version.
-	Version = "0.0.1"
+	Version = "0.0.2"
 	// Protocol is the threads protocol tag.
 	Protocol protocol.ID = "/" + Name + "/" + Version
 )
 
+// Protocols are the tags of the supported protocol versions, newest first.
+// Peers talk with the newest version both of them support.
+var Protocols = []protocol.ID{Protocol, "/" + Name + "/0.0.1"}
+
 var addrProtocol = ma.Protocol{
 	Name:       Name,
 	Code:       Code,
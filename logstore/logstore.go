@@ -165,6 +165,9 @@ func (ls *logstore) DeleteThread(id thread.ID) error {
 	if err := ls.ClearKeys(id); err != nil {
 		return err
 	}
+	if err := ls.ClearMetadata(id); err != nil {
+		return err
+	}
 
 	set, err := ls.getLogIDs(id)
 	if err != nil {
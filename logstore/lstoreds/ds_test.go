@@ -1,7 +1,10 @@
 package lstoreds
 
 import (
+	"bytes"
 	"context"
+	"crypto/rand"
+	"errors"
 	"io/ioutil"
 	"os"
 	"testing"
@@ -9,7 +12,11 @@ import (
 
 	ds "github.com/ipfs/go-datastore"
 	badger "github.com/ipfs/go-ds-badger"
+	"github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/libp2p/go-libp2p-core/peer"
 	core "github.com/textileio/go-threads/core/logstore"
+	"github.com/textileio/go-threads/core/thread"
+	sym "github.com/textileio/go-threads/crypto/symmetric"
 	pt "github.com/textileio/go-threads/test"
 )
 
@@ -44,10 +51,15 @@ func TestDatastoreAddrBook(t *testing.T) {
 
 func TestDatastoreKeyBook(t *testing.T) {
 	for name, dsFactory := range dstores {
+		dsFactory := dsFactory
 		t.Run(name, func(t *testing.T) {
 			t.Parallel()
 			pt.KeyBookTest(t, keyBookFactory(t, dsFactory))
 		})
+		t.Run(name+" Encrypted", func(t *testing.T) {
+			t.Parallel()
+			pt.KeyBookTest(t, encryptedKeyBookFactory(t, dsFactory))
+		})
 	}
 }
 
@@ -69,6 +81,114 @@ func TestDatastoreMetadataBook(t *testing.T) {
 	}
 }
 
+func TestEncryptedKeyBookAtRest(t *testing.T) {
+	store := ds.NewMapDatastore()
+	kb, err := NewEncryptedKeyBook(store, sym.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tid := thread.NewIDV1(thread.Raw, 24)
+	sk, _, err := crypto.GenerateEd25519Key(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lid, err := peer.IDFromPrivateKey(sk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = kb.AddPrivKey(tid, lid, sk); err != nil {
+		t.Fatal(err)
+	}
+	key := thread.NewRandomKey()
+	if err = kb.AddServiceKey(tid, key.Service()); err != nil {
+		t.Fatal(err)
+	}
+	if err = kb.AddReadKey(tid, key.Read()); err != nil {
+		t.Fatal(err)
+	}
+	retired := thread.NewRandomKey()
+	if err = kb.SetRetiredKeys(tid, []thread.Key{retired}); err != nil {
+		t.Fatal(err)
+	}
+
+	skb, err := sk.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	checks := []struct {
+		name  string
+		key   ds.Key
+		plain [][]byte
+	}{
+		{"priv", dsLogKey(tid, lid, kbBase).Child(privSuffix), [][]byte{skb}},
+		{"service", dsThreadKey(tid, kbBase).Child(serviceSuffix), [][]byte{key.Service().Bytes()}},
+		{"read", dsThreadKey(tid, kbBase).Child(readSuffix), [][]byte{key.Read().Bytes()}},
+		{"retired", dsThreadKey(tid, kbBase).Child(retiredSuffix), [][]byte{
+			retired.Service().Bytes(),
+			retired.Read().Bytes(),
+			[]byte(retired.String()),
+		}},
+	}
+	for _, c := range checks {
+		raw, err := store.Get(c.key)
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		for _, p := range c.plain {
+			if bytes.Contains(raw, p) {
+				t.Errorf("%s key is stored in plaintext", c.name)
+			}
+		}
+	}
+
+	if res, err := kb.PrivKey(tid, lid); err != nil || !res.Equals(sk) {
+		t.Error("retrieved private key did not match stored private key")
+	}
+	if res, err := kb.RetiredKeys(tid); err != nil || len(res) != 1 || res[0].String() != retired.String() {
+		t.Error("retrieved retired keys did not match stored retired keys")
+	}
+}
+
+func TestEncryptedKeyBookOpen(t *testing.T) {
+	tid := thread.NewIDV1(thread.Raw, 24)
+
+	t.Run("plaintext store", func(t *testing.T) {
+		store := ds.NewMapDatastore()
+		kb, err := NewKeyBook(store)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = kb.AddServiceKey(tid, sym.New()); err != nil {
+			t.Fatal(err)
+		}
+		if _, err = NewEncryptedKeyBook(store, sym.New()); !errors.Is(err, ErrKeysNotSealed) {
+			t.Fatalf("expected ErrKeysNotSealed, got %v", err)
+		}
+	})
+
+	t.Run("encrypted store", func(t *testing.T) {
+		store := ds.NewMapDatastore()
+		mk := sym.New()
+		kb, err := NewEncryptedKeyBook(store, mk)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = kb.AddServiceKey(tid, sym.New()); err != nil {
+			t.Fatal(err)
+		}
+		if _, err = NewKeyBook(store); !errors.Is(err, ErrKeysSealed) {
+			t.Fatalf("expected ErrKeysSealed without a master key, got %v", err)
+		}
+		if _, err = NewEncryptedKeyBook(store, sym.New()); !errors.Is(err, ErrKeysSealed) {
+			t.Fatalf("expected ErrKeysSealed with another master key, got %v", err)
+		}
+		if _, err = NewEncryptedKeyBook(store, mk); err != nil {
+			t.Fatalf("expected reopening with the master key to succeed, got %v", err)
+		}
+	})
+}
+
 func addressBookFactory(tb testing.TB, storeFactory datastoreFactory, opts Options) pt.AddrBookFactory {
 	return func() (core.AddrBook, func()) {
 		store, closeFunc := storeFactory(tb)
@@ -98,6 +218,17 @@ func keyBookFactory(tb testing.TB, storeFactory datastoreFactory) pt.KeyBookFact
 	}
 }
 
+func encryptedKeyBookFactory(tb testing.TB, storeFactory datastoreFactory) pt.KeyBookFactory {
+	return func() (core.KeyBook, func()) {
+		store, closeFunc := storeFactory(tb)
+		kb, err := NewEncryptedKeyBook(store, sym.New())
+		if err != nil {
+			tb.Fatal(err)
+		}
+		return kb, closeFunc
+	}
+}
+
 func headBookFactory(tb testing.TB, storeFactory datastoreFactory) pt.HeadBookFactory {
 	return func() (core.HeadBook, func()) {
 		store, closeFunc := storeFactory(tb)
@@ -1,6 +1,7 @@
 package lstoreds
 
 import (
+	"encoding/json"
 	"fmt"
 
 	ds "github.com/ipfs/go-datastore"
@@ -15,28 +16,73 @@ import (
 
 type dsKeyBook struct {
 	ds ds.Datastore
+
+	// masterKey optionally encrypts private, read, and service keys at rest.
+	masterKey *sym.Key
 }
 
 // Public and private keys are stored under the following db key pattern:
 // /threads/keys/<b32 thread id no padding>/<b32 log id no padding>/(pub|priv)
-// Follow and read keys are stored under the following db key pattern:
-// /threads/keys/<b32 thread id no padding>/(service|read)
+// Follow, read and retired keys are stored under the following db key pattern:
+// /threads/keys/<b32 thread id no padding>/(service|read|retired)
 var (
 	kbBase        = ds.NewKey("/thread/keys")
 	pubSuffix     = ds.NewKey("/pub")
 	privSuffix    = ds.NewKey("/priv")
 	readSuffix    = ds.NewKey("/read")
 	serviceSuffix = ds.NewKey("/service")
+	retiredSuffix = ds.NewKey("/retired")
+)
+
+// sealedKey marks a store whose secret keys are encrypted with a master key.
+// Its value is sealedCheck encrypted with that key. It lives outside kbBase,
+// so that it is not picked up by queries for keys.
+var (
+	sealedKey   = ds.NewKey("/thread/sealed")
+	sealedCheck = []byte("threads/keybook")
+)
+
+var (
+	// ErrKeysNotSealed indicates that a master key was given for a store
+	// that already holds keys that are not encrypted.
+	ErrKeysNotSealed = fmt.Errorf("key book holds keys that are not encrypted with a master key")
+
+	// ErrKeysSealed indicates that no master key, or the wrong one, was
+	// given for a store whose keys are encrypted.
+	ErrKeysSealed = fmt.Errorf("key book keys are encrypted with another master key")
 )
 
 var _ core.KeyBook = (*dsKeyBook)(nil)
 
 // NewKeyBook returns a new key book for storing public and private keys
 // of (thread.ID, peer.ID) pairs with durable guarantees by store.
+// ErrKeysSealed is returned if store was written by an encrypted key book.
 func NewKeyBook(store ds.Datastore) (core.KeyBook, error) {
+	if sealed, err := store.Has(sealedKey); err != nil {
+		return nil, fmt.Errorf("error when checking key book encryption: %w", err)
+	} else if sealed {
+		return nil, ErrKeysSealed
+	}
 	return &dsKeyBook{ds: store}, nil
 }
 
+// NewEncryptedKeyBook returns a new key book that encrypts private, read,
+// and service keys with masterKey before writing them to store.
+// Keys are only decrypted in memory when they are retrieved.
+// Existing keys are not migrated: ErrKeysNotSealed is returned if store
+// already holds keys written without a master key, and ErrKeysSealed if
+// they were written with a different one.
+func NewEncryptedKeyBook(store ds.Datastore, masterKey *sym.Key) (core.KeyBook, error) {
+	if masterKey == nil {
+		return nil, fmt.Errorf("master key is nil")
+	}
+	kb := &dsKeyBook{ds: store, masterKey: masterKey}
+	if err := kb.checkSealed(); err != nil {
+		return nil, err
+	}
+	return kb, nil
+}
+
 // PubKey returns the public key of (thread.ID, peer.ID). The implementation
 // assumes the key is in the store with the exception that peer.ID is an
 // Identity multihash. If the public key can't be resolved, nil is returned.
@@ -89,6 +135,9 @@ func (kb *dsKeyBook) PrivKey(t thread.ID, p peer.ID) (crypto.PrivKey, error) {
 	if err != nil {
 		return nil, fmt.Errorf("error when getting private key for %s", key)
 	}
+	if v, err = kb.open(v); err != nil {
+		return nil, fmt.Errorf("error when decrypting private key of %v: %w", key, err)
+	}
 	sk, err := crypto.UnmarshalPrivateKey(v)
 	if err != nil {
 		return nil, fmt.Errorf("error when unmarshaling private key of %v", key)
@@ -108,6 +157,9 @@ func (kb *dsKeyBook) AddPrivKey(t thread.ID, p peer.ID, sk crypto.PrivKey) error
 	if err != nil {
 		return fmt.Errorf("error when getting private key bytes: %w", err)
 	}
+	if skb, err = kb.seal(skb); err != nil {
+		return fmt.Errorf("error when encrypting private key: %w", err)
+	}
 	key := dsLogKey(t, p, kbBase).Child(privSuffix)
 	if err = kb.ds.Put(key, skb); err != nil {
 		return fmt.Errorf("error when putting key %v in datastore: %w", key, err)
@@ -126,6 +178,9 @@ func (kb *dsKeyBook) ReadKey(t thread.ID) (*sym.Key, error) {
 	if err != nil {
 		return nil, fmt.Errorf("error when getting read-key from datastore: %v", err)
 	}
+	if v, err = kb.open(v); err != nil {
+		return nil, fmt.Errorf("error when decrypting read-key: %w", err)
+	}
 	return sym.FromBytes(v)
 }
 
@@ -134,8 +189,12 @@ func (kb *dsKeyBook) AddReadKey(t thread.ID, rk *sym.Key) error {
 	if rk == nil {
 		return fmt.Errorf("read-key is nil")
 	}
+	val, err := kb.seal(rk.Bytes())
+	if err != nil {
+		return fmt.Errorf("error when encrypting read-key: %w", err)
+	}
 	key := dsThreadKey(t, kbBase).Child(readSuffix)
-	if err := kb.ds.Put(key, rk.Bytes()); err != nil {
+	if err := kb.ds.Put(key, val); err != nil {
 		return fmt.Errorf("error when adding read-key to datastore: %w", err)
 	}
 	return nil
@@ -153,6 +212,9 @@ func (kb *dsKeyBook) ServiceKey(t thread.ID) (*sym.Key, error) {
 	if err != nil {
 		return nil, fmt.Errorf("error when getting service-key from datastore: %v", err)
 	}
+	if v, err = kb.open(v); err != nil {
+		return nil, fmt.Errorf("error when decrypting service-key: %w", err)
+	}
 	return sym.FromBytes(v)
 }
 
@@ -161,13 +223,70 @@ func (kb *dsKeyBook) AddServiceKey(t thread.ID, fk *sym.Key) error {
 	if fk == nil {
 		return fmt.Errorf("service-key is nil")
 	}
+	val, err := kb.seal(fk.Bytes())
+	if err != nil {
+		return fmt.Errorf("error when encrypting service-key: %w", err)
+	}
 	key := dsThreadKey(t, kbBase).Child(serviceSuffix)
-	if err := kb.ds.Put(key, fk.Bytes()); err != nil {
+	if err := kb.ds.Put(key, val); err != nil {
 		return fmt.Errorf("error when adding service-key to datastore: %w", err)
 	}
 	return nil
 }
 
+// RetiredKeys returns the keys previously used by thread.ID, newest first.
+// In case there are none, it will return nil.
+func (kb *dsKeyBook) RetiredKeys(t thread.ID) ([]thread.Key, error) {
+	key := dsThreadKey(t, kbBase).Child(retiredSuffix)
+	v, err := kb.ds.Get(key)
+	if err == ds.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error when getting retired keys from datastore: %v", err)
+	}
+	if v, err = kb.open(v); err != nil {
+		return nil, fmt.Errorf("error when decrypting retired keys: %w", err)
+	}
+	var strs []string
+	if err = json.Unmarshal(v, &strs); err != nil {
+		return nil, fmt.Errorf("error when unmarshaling retired keys: %w", err)
+	}
+	keys := make([]thread.Key, len(strs))
+	for i, s := range strs {
+		if keys[i], err = thread.KeyFromString(s); err != nil {
+			return nil, fmt.Errorf("error when decoding retired key: %w", err)
+		}
+	}
+	return keys, nil
+}
+
+// SetRetiredKeys replaces the retired keys of thread.ID.
+func (kb *dsKeyBook) SetRetiredKeys(t thread.ID, keys []thread.Key) error {
+	key := dsThreadKey(t, kbBase).Child(retiredSuffix)
+	if len(keys) == 0 {
+		if err := kb.ds.Delete(key); err != nil {
+			return fmt.Errorf("error when clearing retired keys: %w", err)
+		}
+		return nil
+	}
+	strs := make([]string, len(keys))
+	for i, k := range keys {
+		strs[i] = k.String()
+	}
+	v, err := json.Marshal(strs)
+	if err != nil {
+		return fmt.Errorf("error when marshaling retired keys: %w", err)
+	}
+	if v, err = kb.seal(v); err != nil {
+		return fmt.Errorf("error when encrypting retired keys: %w", err)
+	}
+	if err = kb.ds.Put(key, v); err != nil {
+		return fmt.Errorf("error when adding retired keys to datastore: %w", err)
+	}
+	return nil
+}
+
 // ClearKeys deletes all keys under a thread.
 func (kb *dsKeyBook) ClearKeys(t thread.ID) error {
 	return kb.clearKeys(dsThreadKey(t, kbBase))
@@ -184,6 +303,57 @@ func (kb *dsKeyBook) ClearLogKeys(t thread.ID, p peer.ID) error {
 	return nil
 }
 
+// seal encrypts a secret key value with the master key, if any.
+func (kb *dsKeyBook) seal(v []byte) ([]byte, error) {
+	if kb.masterKey == nil {
+		return v, nil
+	}
+	return kb.masterKey.Encrypt(v)
+}
+
+// checkSealed verifies that the store's keys are encrypted with the master
+// key, marking an empty store as such.
+func (kb *dsKeyBook) checkSealed() error {
+	v, err := kb.ds.Get(sealedKey)
+	if err == nil {
+		if _, err = kb.open(v); err != nil {
+			return ErrKeysSealed
+		}
+		return nil
+	}
+	if err != ds.ErrNotFound {
+		return fmt.Errorf("error when checking key book encryption: %w", err)
+	}
+	results, err := kb.ds.Query(query.Query{Prefix: kbBase.String(), KeysOnly: true})
+	if err != nil {
+		return fmt.Errorf("error when checking key book encryption: %w", err)
+	}
+	defer results.Close()
+	for result := range results.Next() {
+		if result.Error != nil {
+			return fmt.Errorf("error when checking key book encryption: %w", result.Error)
+		}
+		if ds.RawKey(result.Key).Name() != pubSuffix.Name() {
+			return ErrKeysNotSealed
+		}
+	}
+	if v, err = kb.seal(sealedCheck); err != nil {
+		return fmt.Errorf("error when encrypting key book check: %w", err)
+	}
+	if err = kb.ds.Put(sealedKey, v); err != nil {
+		return fmt.Errorf("error when marking key book encrypted: %w", err)
+	}
+	return nil
+}
+
+// open decrypts a secret key value with the master key, if any.
+func (kb *dsKeyBook) open(v []byte) ([]byte, error) {
+	if kb.masterKey == nil {
+		return v, nil
+	}
+	return kb.masterKey.Decrypt(v)
+}
+
 func (kb *dsKeyBook) clearKeys(prefix ds.Key) error {
 	q := query.Query{Prefix: prefix.String(), KeysOnly: true}
 	results, err := kb.ds.Query(q)
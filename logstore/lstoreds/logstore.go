@@ -9,6 +9,7 @@ import (
 	"github.com/libp2p/go-libp2p-core/peer"
 	core "github.com/textileio/go-threads/core/logstore"
 	"github.com/textileio/go-threads/core/thread"
+	sym "github.com/textileio/go-threads/crypto/symmetric"
 	lstore "github.com/textileio/go-threads/logstore"
 	"github.com/whyrusleeping/base32"
 )
@@ -25,6 +26,10 @@ type Options struct {
 	// Initial delay before GC processes start. Intended to give the system breathing room to fully boot
 	// before starting GC.
 	GCInitialDelay time.Duration
+
+	// MasterKey, if set, encrypts log private keys and thread read, service and retired keys at rest.
+	// A store written without a master key can't be opened with one, and vice versa.
+	MasterKey *sym.Key
 }
 
 // DefaultOpts returns the default options for a persistent peerstore, with the full-purge GC algorithm:
@@ -47,7 +52,12 @@ func NewLogstore(ctx context.Context, store ds.Batching, opts Options) (core.Log
 		return nil, err
 	}
 
-	keyBook, err := NewKeyBook(store)
+	var keyBook core.KeyBook
+	if opts.MasterKey != nil {
+		keyBook, err = NewEncryptedKeyBook(store, opts.MasterKey)
+	} else {
+		keyBook, err = NewKeyBook(store)
+	}
 	if err != nil {
 		return nil, err
 	}
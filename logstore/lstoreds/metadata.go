@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	ds "github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/query"
 	core "github.com/textileio/go-threads/core/logstore"
 	"github.com/textileio/go-threads/core/thread"
 	"github.com/whyrusleeping/base32"
@@ -76,6 +77,24 @@ func (m *dsThreadMetadata) PutBytes(t thread.ID, key string, val []byte) error {
 	return m.setValue(t, key, val)
 }
 
+func (m *dsThreadMetadata) ClearMetadata(t thread.ID) error {
+	q := query.Query{
+		Prefix:   tmetaBase.ChildString(base32.RawStdEncoding.EncodeToString(t.Bytes())).String(),
+		KeysOnly: true,
+	}
+	results, err := m.ds.Query(q)
+	if err != nil {
+		return err
+	}
+	defer results.Close()
+	for result := range results.Next() {
+		if err := m.ds.Delete(ds.NewKey(result.Key)); err != nil {
+			return fmt.Errorf("error when clearing metadata: %w", err)
+		}
+	}
+	return nil
+}
+
 func keyMeta(t thread.ID, k string) ds.Key {
 	key := tmetaBase.ChildString(base32.RawStdEncoding.EncodeToString(t.Bytes()))
 	key = key.ChildString(k)
@@ -18,6 +18,7 @@ type memoryKeyBook struct {
 	sks map[thread.ID]map[peer.ID]crypto.PrivKey
 	rks map[thread.ID][]byte
 	fks map[thread.ID][]byte
+	rts map[thread.ID][]thread.Key
 }
 
 func (mkb *memoryKeyBook) getPubKey(t thread.ID, p peer.ID) (crypto.PubKey, bool) {
@@ -46,6 +47,7 @@ func NewKeyBook() core.KeyBook {
 		sks: map[thread.ID]map[peer.ID]crypto.PrivKey{},
 		rks: map[thread.ID][]byte{},
 		fks: map[thread.ID][]byte{},
+		rts: map[thread.ID][]thread.Key{},
 	}
 }
 
@@ -139,20 +141,49 @@ func (mkb *memoryKeyBook) AddServiceKey(t thread.ID, key *sym.Key) error {
 	return nil
 }
 
+func (mkb *memoryKeyBook) RetiredKeys(t thread.ID) ([]thread.Key, error) {
+	mkb.RLock()
+	keys := append([]thread.Key(nil), mkb.rts[t]...)
+	mkb.RUnlock()
+	return keys, nil
+}
+
+func (mkb *memoryKeyBook) SetRetiredKeys(t thread.ID, keys []thread.Key) error {
+	mkb.Lock()
+	if len(keys) == 0 {
+		delete(mkb.rts, t)
+	} else {
+		mkb.rts[t] = append([]thread.Key(nil), keys...)
+	}
+	mkb.Unlock()
+	return nil
+}
+
 func (mkb *memoryKeyBook) ClearKeys(t thread.ID) error {
 	mkb.Lock()
 	delete(mkb.pks, t)
 	delete(mkb.sks, t)
 	delete(mkb.rks, t)
 	delete(mkb.fks, t)
+	delete(mkb.rts, t)
 	mkb.Unlock()
 	return nil
 }
 
 func (mkb *memoryKeyBook) ClearLogKeys(t thread.ID, p peer.ID) error {
 	mkb.Lock()
-	delete(mkb.pks, t)
-	delete(mkb.sks, t)
+	if mkb.pks[t] != nil {
+		delete(mkb.pks[t], p)
+		if len(mkb.pks[t]) == 0 {
+			delete(mkb.pks, t)
+		}
+	}
+	if mkb.sks[t] != nil {
+		delete(mkb.sks[t], p)
+		if len(mkb.sks[t]) == 0 {
+			delete(mkb.sks, t)
+		}
+	}
 	mkb.Unlock()
 	return nil
 }
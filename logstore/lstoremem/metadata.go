@@ -72,6 +72,17 @@ func (m *memoryThreadMetadata) GetBytes(t thread.ID, key string) (*[]byte, error
 	return &val, nil
 }
 
+func (m *memoryThreadMetadata) ClearMetadata(t thread.ID) error {
+	m.dslock.Lock()
+	defer m.dslock.Unlock()
+	for k := range m.ds {
+		if k.id.Equals(t) {
+			delete(m.ds, k)
+		}
+	}
+	return nil
+}
+
 func (m *memoryThreadMetadata) putValue(t thread.ID, key string, val interface{}) {
 	m.dslock.Lock()
 	defer m.dslock.Unlock()
@@ -0,0 +1,136 @@
+package net
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	"github.com/libp2p/go-libp2p-core/peer"
+	pstore "github.com/libp2p/go-libp2p-core/peerstore"
+	lstore "github.com/textileio/go-threads/core/logstore"
+	"github.com/textileio/go-threads/core/thread"
+)
+
+// AddrSource identifies how a log address was learned.
+type AddrSource int
+
+const (
+	// AddrSourceDirect is an address given to the host directly, e.g., with AddReplicator.
+	AddrSourceDirect AddrSource = iota
+	// AddrSourcePulled is an address received in a log fetched from a peer.
+	AddrSourcePulled
+	// AddrSourcePushed is an address received in a log pushed by a peer.
+	AddrSourcePushed
+)
+
+// AddrTTLPolicy returns the TTL for addresses learned from a source.
+type AddrTTLPolicy func(src AddrSource) time.Duration
+
+// addrTTL returns the configured TTL for addresses from src.
+func (n *net) addrTTL(src AddrSource) time.Duration {
+	if n.conf.AddrTTLPolicy == nil {
+		return pstore.PermanentAddrTTL
+	}
+	return n.conf.AddrTTLPolicy(src)
+}
+
+// addLog adds or updates a log learned from src, applying the address TTL policy
+// to its addresses. from is the peer the log was received from, or empty if
+// the log was given to the host directly. The update is merged into any
+// existing log info so that a stale update can't lose current state:
+//   - the log's public key must match its ID
+//   - the host's own logs are only updated directly
+//   - addresses are added to the existing ones, keeping the longer of their
+//     TTLs, but only by the log's owner, i.e., a peer among its addresses,
+//     once it has any
+//   - transport parts of addresses go to the peerstore, see learnPeerAddrs
+//   - keys are never removed or replaced
+//   - the head only moves to a record that is held locally and is higher in
+//     the log than the current head
+//   - addresses of logs whose owner left the thread are dropped
+//   - tombstoned logs, see tombstone, are only added back directly
+func (n *net) addLog(id thread.ID, lg thread.LogInfo, src AddrSource, from peer.ID) error {
+	if lg.PubKey != nil && !lg.ID.MatchesPublicKey(lg.PubKey) {
+		return fmt.Errorf("public key of log %s does not match its ID", lg.ID)
+	}
+	if from != "" {
+		if removed, err := n.isTombstoned(id, lg.ID); err != nil || removed {
+			return err
+		}
+	}
+	cur, err := n.store.GetLog(id, lg.ID)
+	if err != nil && !errors.Is(err, lstore.ErrLogNotFound) {
+		return err
+	}
+	if cur.PrivKey != nil && from != "" {
+		n.log.Debugf("ignoring update of own log %s from %s", lg.ID, from)
+		return nil
+	}
+	if cur.PubKey != nil {
+		lg.PubKey = cur.PubKey
+	}
+	if cur.PrivKey != nil {
+		lg.PrivKey = nil
+	}
+	ahead, err := n.isAhead(id, lg.Head, cur.Head)
+	if err != nil {
+		return err
+	}
+	if !ahead {
+		lg.Head = cid.Undef
+	}
+
+	addrs := lg.Addrs
+	lg.Addrs = nil
+	left, err := n.hasLeft(id, lg.ID)
+	if err != nil {
+		return err
+	}
+	if left || !isLogOwner(cur, from) {
+		addrs = nil
+	}
+	if err := n.store.AddLog(id, lg); err != nil {
+		return err
+	}
+	if len(addrs) == 0 {
+		return nil
+	}
+	ttl := n.addrTTL(src)
+	return n.store.AddAddrs(id, lg.ID, n.learnPeerAddrs(addrs, ttl, from), ttl)
+}
+
+// isLogOwner returns whether or not a peer may update the addresses of a log,
+// i.e., the log has no addresses yet, or the peer is among them. Updates
+// given to the host directly, from an empty peer ID, are always allowed.
+func isLogOwner(lg thread.LogInfo, from peer.ID) bool {
+	if from == "" || len(lg.Addrs) == 0 {
+		return true
+	}
+	for _, a := range lg.Addrs {
+		if _, pid := splitPeerAddr(a); pid == from {
+			return true
+		}
+	}
+	return false
+}
+
+// isAhead returns whether or not head is a locally indexed record that is
+// higher in its log than cur.
+func (n *net) isAhead(id thread.ID, head, cur cid.Cid) (bool, error) {
+	if !head.Defined() || head.Equals(cur) {
+		return false, nil
+	}
+	h, err := n.store.GetInt64(id, recordHeightKey(head))
+	if err != nil || h == nil {
+		return false, err
+	}
+	if !cur.Defined() {
+		return true, nil
+	}
+	c, err := n.store.GetInt64(id, recordHeightKey(cur))
+	if err != nil || c == nil {
+		return false, err
+	}
+	return *h > *c, nil
+}
@@ -0,0 +1,121 @@
+package net
+
+import (
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	ma "github.com/multiformats/go-multiaddr"
+	manet "github.com/multiformats/go-multiaddr-net"
+	"github.com/textileio/go-threads/core/thread"
+)
+
+// hostAddrs returns a snapshot of the host's current advertised addresses.
+// The host may learn or lose addresses at any time, so callers should take a
+// single snapshot and use it for the duration of an operation.
+func (n *net) hostAddrs() []ma.Multiaddr {
+	addrs := n.host.Addrs()
+	res := make([]ma.Multiaddr, 0, len(addrs))
+	for _, a := range addrs {
+		if a == nil {
+			continue
+		}
+		res = append(res, a)
+	}
+	return res
+}
+
+// dialableAddrs returns the host addresses that peers may dial, including
+// relay addresses. Loopback and private addresses are only included if
+// Config.AllowPrivateAddrs is set.
+func (n *net) dialableAddrs() []ma.Multiaddr {
+	addrs := n.hostAddrs()
+	res := addrs[:0]
+	for _, a := range addrs {
+		if manet.IsIPUnspecified(a) {
+			continue
+		}
+		if !n.conf.AllowPrivateAddrs && (manet.IsIPLoopback(a) || manet.IsPrivateAddr(a) || manet.IsIP6LinkLocal(a)) {
+			continue
+		}
+		res = append(res, a)
+	}
+	return res
+}
+
+// advertisedLog returns a log as it's sent to peers. The host's own address
+// in the log, which is only its peer ID, is replaced with the host's dialable
+// addresses, so that peers that don't know the host yet can reach it.
+func (n *net) advertisedLog(lg thread.LogInfo) thread.LogInfo {
+	var dialable []ma.Multiaddr
+	addrs := make([]ma.Multiaddr, 0, len(lg.Addrs))
+	for _, a := range lg.Addrs {
+		transport, pid := splitPeerAddr(a)
+		if transport != nil || pid != n.host.ID() {
+			addrs = append(addrs, a)
+			continue
+		}
+		if dialable == nil {
+			dialable = n.dialableAddrs()
+		}
+		p2p, err := ma.NewComponent(ma.ProtocolWithCode(ma.P_P2P).Name, pid.String())
+		if err != nil || len(dialable) == 0 {
+			addrs = append(addrs, a)
+			continue
+		}
+		for _, d := range dialable {
+			addrs = append(addrs, d.Encapsulate(p2p))
+		}
+	}
+	lg.Addrs = addrs
+	return lg
+}
+
+// splitPeerAddr splits an address into the transport part, which is nil for
+// an address that is only a peer ID, and the ID of the peer it points to.
+// The peer ID is empty if the address doesn't end with one.
+func splitPeerAddr(addr ma.Multiaddr) (ma.Multiaddr, peer.ID) {
+	transport, last := ma.SplitLast(addr)
+	if last == nil || last.Protocol().Code != ma.P_P2P {
+		return addr, ""
+	}
+	pid, err := peer.Decode(last.Value())
+	if err != nil {
+		return addr, ""
+	}
+	return transport, pid
+}
+
+// learnPeerAddrs adds the transport parts of log addresses received from
+// peers to the peerstore, where they are used to dial the peers, and returns
+// the addresses reduced to the peer IDs by which logs are addressed.
+// Only the sender's own transport addresses are learned, so that a peer
+// can't redirect dials to other peers. Addresses given to the host directly,
+// from an empty peer ID, are all learned.
+func (n *net) learnPeerAddrs(addrs []ma.Multiaddr, ttl time.Duration, from peer.ID) []ma.Multiaddr {
+	res := make([]ma.Multiaddr, 0, len(addrs))
+	seen := make(map[peer.ID]struct{})
+	for _, a := range addrs {
+		transport, pid := splitPeerAddr(a)
+		if pid == "" {
+			res = append(res, a)
+			continue
+		}
+		if transport != nil && pid != n.host.ID() && (from == "" || pid == from) {
+			n.host.Peerstore().AddAddr(pid, transport, ttl)
+		}
+		if _, ok := seen[pid]; ok {
+			continue
+		}
+		seen[pid] = struct{}{}
+		if transport != nil {
+			p2p, err := ma.NewComponent(ma.ProtocolWithCode(ma.P_P2P).Name, pid.String())
+			if err != nil {
+				res = append(res, a)
+				continue
+			}
+			a = p2p
+		}
+		res = append(res, a)
+	}
+	return res
+}
@@ -0,0 +1,73 @@
+package net
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ipfs/go-cid"
+	"github.com/libp2p/go-libp2p-core/peer"
+	core "github.com/textileio/go-threads/core/net"
+	"github.com/textileio/go-threads/core/thread"
+)
+
+// ErrNotAncestor indicates that a record is not an ancestor of another record.
+var ErrNotAncestor = fmt.Errorf("record is not an ancestor")
+
+// Ancestry returns the records in a log after from, up to and including to,
+// oldest first. An undefined from returns all records up to to. Walking stops
+// with ErrNotAncestor if from is not reached within MaxPullLimit records.
+func (n *net) Ancestry(ctx context.Context, id thread.ID, lid peer.ID, from, to cid.Cid) ([]core.Record, error) {
+	if _, err := n.store.GetLog(id, lid); err != nil {
+		return nil, err
+	}
+	if err := n.checkInLog(id, lid, to); err != nil {
+		return nil, err
+	}
+
+	var recs []core.Record
+	seen := make(map[cid.Cid]struct{})
+	cursor := to
+	for !cursor.Equals(from) {
+		if !cursor.Defined() {
+			return nil, fmt.Errorf("%w: %s is not before %s", ErrNotAncestor, from, to)
+		}
+		if _, ok := seen[cursor]; ok {
+			return nil, fmt.Errorf("cycle detected at record %s", cursor)
+		}
+		if len(recs) >= MaxPullLimit {
+			return nil, fmt.Errorf("%w: %s not found within %d records of %s", ErrNotAncestor, from, MaxPullLimit, to)
+		}
+		seen[cursor] = struct{}{}
+		r, err := n.getRecordWithKeys(ctx, id, cursor)
+		if err != nil {
+			return nil, err
+		}
+		recs = append(recs, r)
+		cursor = r.PrevID()
+	}
+
+	for i, j := 0, len(recs)-1; i < j; i, j = i+1, j-1 {
+		recs[i], recs[j] = recs[j], recs[i]
+	}
+	return recs, nil
+}
+
+// checkInLog returns an error if the height index shows that rid is not in a log.
+// Records that aren't indexed are assumed to be in the log.
+func (n *net) checkInLog(id thread.ID, lid peer.ID, rid cid.Cid) error {
+	if !rid.Defined() {
+		return nil
+	}
+	h, err := n.store.GetInt64(id, recordHeightKey(rid))
+	if err != nil || h == nil {
+		return err
+	}
+	v, err := n.store.GetString(id, heightKey(lid, *h))
+	if err != nil {
+		return err
+	}
+	if v == nil || *v != rid.String() {
+		return fmt.Errorf("record %s is not in log %s", rid, lid)
+	}
+	return nil
+}
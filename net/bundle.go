@@ -0,0 +1,265 @@
+package net
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/ipfs/go-cid"
+	cbornode "github.com/ipfs/go-ipld-cbor"
+	"github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/libp2p/go-libp2p-core/peer"
+	ma "github.com/multiformats/go-multiaddr"
+	mh "github.com/multiformats/go-multihash"
+	core "github.com/textileio/go-threads/core/net"
+	"github.com/textileio/go-threads/core/thread"
+)
+
+// BundleVersion is the version of the bundles written by ExportThread.
+const BundleVersion = 1
+
+// ErrInvalidBundle indicates that a CAR stream is not a supported thread bundle.
+var ErrInvalidBundle = fmt.Errorf("invalid thread bundle")
+
+func init() {
+	cbornode.RegisterCborType(bundleManifest{})
+	cbornode.RegisterCborType(bundleLog{})
+}
+
+// bundleManifest is the root node of a thread bundle. Keys are only present
+// if the exporting host owned a log in the thread.
+type bundleManifest struct {
+	Version uint64
+	Thread  string
+	Key     string   `refmt:",omitempty"`
+	Retired []string `refmt:",omitempty"`
+	Logs    []bundleLog
+}
+
+// bundleLog describes a log in a thread bundle.
+type bundleLog struct {
+	ID     string
+	PubKey []byte
+	Addrs  [][]byte
+	Heads  []cid.Cid
+}
+
+// ExportThread writes a thread to w as a bundle, which is a CAR (v1) stream
+// rooted at a versioned manifest of the thread's logs, with their addresses
+// and heads, followed by all of their records as they are stored, i.e.,
+// encrypted. If the host owns a log in the thread, the thread key is included,
+// and the bundle must be kept as securely as the key itself. Private log keys
+// are never included, so that a log is only ever written by one host.
+func (n *net) ExportThread(ctx context.Context, id thread.ID, w io.Writer, opts ...core.ThreadOption) error {
+	args := &core.ThreadOptions{}
+	for _, opt := range opts {
+		opt(args)
+	}
+	if _, err := args.Token.Validate(n.getPrivKey()); err != nil {
+		return err
+	}
+
+	info, err := n.store.GetThread(id)
+	if err != nil {
+		return err
+	}
+	own, err := n.getOwnLog(id)
+	if err != nil {
+		return err
+	}
+	m := bundleManifest{Version: BundleVersion, Thread: id.String()}
+	if own.PubKey != nil {
+		m.Key = info.Key.String()
+		retired, err := n.retiredKeys(id)
+		if err != nil {
+			return err
+		}
+		m.Retired = keysToStrings(retired)
+	}
+	for _, lg := range info.Logs {
+		if lg.PubKey == nil {
+			continue
+		}
+		l, err := n.bundleLog(id, lg)
+		if err != nil {
+			return err
+		}
+		m.Logs = append(m.Logs, l)
+	}
+	root, err := cbornode.WrapObject(m, mh.SHA2_256, -1)
+	if err != nil {
+		return err
+	}
+	header, err := cbornode.DumpObject(carHeader{Roots: []cid.Cid{root.Cid()}, Version: 1})
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	if err = writeCARSection(bw, header); err != nil {
+		return err
+	}
+	if err = writeCARBlock(bw, root); err != nil {
+		return err
+	}
+	ds := n.threadDAG(id)
+	written := make(map[cid.Cid]struct{})
+	for _, lg := range m.Logs {
+		for _, head := range lg.Heads {
+			for cursor := head; cursor.Defined(); {
+				if _, ok := written[cursor]; ok {
+					break
+				}
+				r, err := n.getRecordWithKeys(ctx, id, cursor)
+				if err != nil {
+					return err
+				}
+				nodes, err := recordNodes(ctx, ds, r)
+				if err != nil {
+					return fmt.Errorf("exporting record %s: %w", cursor, err)
+				}
+				for _, nd := range nodes {
+					if err = writeCARBlock(bw, nd); err != nil {
+						return err
+					}
+				}
+				written[cursor] = struct{}{}
+				cursor = r.PrevID()
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+// bundleLog returns the manifest entry of a log.
+func (n *net) bundleLog(id thread.ID, lg thread.LogInfo) (l bundleLog, err error) {
+	l.ID = lg.ID.String()
+	if l.PubKey, err = crypto.MarshalPublicKey(lg.PubKey); err != nil {
+		return
+	}
+	for _, addr := range lg.Addrs {
+		l.Addrs = append(l.Addrs, addr.Bytes())
+	}
+	l.Heads, err = n.store.Heads(id, lg.ID)
+	return
+}
+
+// ImportThread loads a thread bundle written by ExportThread. If the thread
+// does not exist, it is added with the thread key given in opts, or else with
+// the key in the bundle, and the host gets a new log with the log key given in
+// opts. The logs in the bundle are added to the thread, and their records are
+// verified and applied in order. Records that are already held locally are
+// skipped. A stream with blocks that don't match their cid is rejected with
+// ErrInvalidCAR, and a bundle of an unsupported version with ErrInvalidBundle.
+func (n *net) ImportThread(ctx context.Context, r io.Reader, opts ...core.NewThreadOption) (info thread.Info, err error) {
+	args := &core.NewThreadOptions{}
+	for _, opt := range opts {
+		opt(args)
+	}
+	if _, err = args.Token.Validate(n.getPrivKey()); err != nil {
+		return
+	}
+
+	tmp, root, err := readCAR(ctx, r, n.conf.DecodeLimits)
+	if err != nil {
+		return
+	}
+	node, err := tmp.Get(ctx, root)
+	if err != nil {
+		return info, fmt.Errorf("%w: missing root: %v", ErrInvalidBundle, err)
+	}
+	var m bundleManifest
+	if err = cbornode.DecodeInto(node.RawData(), &m); err != nil {
+		return info, fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+	}
+	if m.Version != BundleVersion {
+		return info, fmt.Errorf("%w: unsupported version %d", ErrInvalidBundle, m.Version)
+	}
+	id, err := thread.Decode(m.Thread)
+	if err != nil {
+		return info, fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+	}
+	logs := make([]thread.LogInfo, len(m.Logs))
+	for i, l := range m.Logs {
+		if logs[i], err = bundleLogInfo(l); err != nil {
+			return
+		}
+	}
+	if !args.ThreadKey.Defined() && m.Key != "" {
+		if args.ThreadKey, err = thread.KeyFromString(m.Key); err != nil {
+			return info, fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+		}
+	}
+	if err = n.ensureImportThread(id, args); err != nil {
+		return
+	}
+	if err = n.importRetiredKeys(id, m.Retired); err != nil {
+		return
+	}
+
+	tsph := n.getThreadSemaphore(id)
+	tsph <- struct{}{}
+	defer func() { <-tsph }()
+
+	own, err := n.getOwnLog(id)
+	if err != nil {
+		return
+	}
+	for i, lg := range logs {
+		if lg.ID == own.ID {
+			lg.Addrs = nil
+		}
+		if err = n.addLog(id, lg, AddrSourceDirect, ""); err != nil {
+			return
+		}
+		for _, head := range m.Logs[i].Heads {
+			if err = n.importLog(ctx, tmp, id, lg, head); err != nil {
+				return
+			}
+		}
+	}
+	return n.getThreadWithAddrs(id)
+}
+
+// importRetiredKeys stores the retired keys of an imported thread, unless the
+// thread already has retired keys of its own.
+func (n *net) importRetiredKeys(id thread.ID, retired []string) error {
+	if len(retired) == 0 {
+		return nil
+	}
+	cur, err := n.retiredKeys(id)
+	if err != nil || len(cur) > 0 {
+		return err
+	}
+	keys := make([]thread.Key, len(retired))
+	for i, s := range retired {
+		if keys[i], err = thread.KeyFromString(s); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+		}
+	}
+	return n.store.SetRetiredKeys(id, keys)
+}
+
+// bundleLogInfo returns the log described by a manifest entry.
+func bundleLogInfo(l bundleLog) (lg thread.LogInfo, err error) {
+	lg.ID, err = peer.Decode(l.ID)
+	if err != nil {
+		return lg, fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+	}
+	lg.PubKey, err = crypto.UnmarshalPublicKey(l.PubKey)
+	if err != nil {
+		return lg, fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+	}
+	if !lg.ID.MatchesPublicKey(lg.PubKey) {
+		return lg, fmt.Errorf("%w: log %s does not match its key", ErrInvalidBundle, lg.ID)
+	}
+	for _, b := range l.Addrs {
+		addr, err := ma.NewMultiaddrBytes(b)
+		if err != nil {
+			return lg, fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+		}
+		lg.Addrs = append(lg.Addrs, addr)
+	}
+	return lg, nil
+}
@@ -0,0 +1,364 @@
+package net
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	blocks "github.com/ipfs/go-block-format"
+	bserv "github.com/ipfs/go-blockservice"
+	"github.com/ipfs/go-cid"
+	datastore "github.com/ipfs/go-datastore"
+	syncds "github.com/ipfs/go-datastore/sync"
+	bs "github.com/ipfs/go-ipfs-blockstore"
+	offline "github.com/ipfs/go-ipfs-exchange-offline"
+	cbornode "github.com/ipfs/go-ipld-cbor"
+	format "github.com/ipfs/go-ipld-format"
+	dag "github.com/ipfs/go-merkledag"
+	"github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/libp2p/go-libp2p-core/peer"
+	mh "github.com/multiformats/go-multihash"
+	"github.com/textileio/go-threads/cbor"
+	lstore "github.com/textileio/go-threads/core/logstore"
+	core "github.com/textileio/go-threads/core/net"
+	"github.com/textileio/go-threads/core/thread"
+)
+
+// ErrInvalidCAR indicates that a CAR stream is malformed or is not a thread export.
+var ErrInvalidCAR = fmt.Errorf("invalid thread CAR")
+
+func init() {
+	cbornode.RegisterCborType(carHeader{})
+	cbornode.RegisterCborType(carManifest{})
+	cbornode.RegisterCborType(carLog{})
+}
+
+// carHeader is the header of a CAR (v1) stream.
+type carHeader struct {
+	Roots   []cid.Cid `refmt:"roots"`
+	Version uint64    `refmt:"version"`
+}
+
+// carManifest is the root node of an exported thread. It links the head of
+// each log, from which all other records are reachable.
+type carManifest struct {
+	Thread string
+	Logs   []carLog
+}
+
+// carLog describes an exported log.
+type carLog struct {
+	ID     string
+	PubKey []byte
+	Head   cid.Cid `refmt:",omitempty"`
+}
+
+// ExportCAR writes all of a thread's records to w as a CAR (v1) stream.
+// The root of the stream is a manifest node linking the head of each log.
+// Thread and log keys are not exported. All blocks of each record must be
+// stored locally.
+func (n *net) ExportCAR(ctx context.Context, id thread.ID, w io.Writer) error {
+	info, err := n.store.GetThread(id)
+	if err != nil {
+		return err
+	}
+	m := carManifest{Thread: id.String()}
+	for _, lg := range info.Logs {
+		if lg.PubKey == nil {
+			continue
+		}
+		pk, err := crypto.MarshalPublicKey(lg.PubKey)
+		if err != nil {
+			return err
+		}
+		m.Logs = append(m.Logs, carLog{ID: lg.ID.String(), PubKey: pk, Head: lg.Head})
+	}
+	root, err := cbornode.WrapObject(m, mh.SHA2_256, -1)
+	if err != nil {
+		return err
+	}
+	header, err := cbornode.DumpObject(carHeader{Roots: []cid.Cid{root.Cid()}, Version: 1})
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	if err = writeCARSection(bw, header); err != nil {
+		return err
+	}
+	if err = writeCARBlock(bw, root); err != nil {
+		return err
+	}
+	ds := n.threadDAG(id)
+	for _, lg := range m.Logs {
+		cursor := lg.Head
+		for cursor.Defined() {
+			r, err := n.getRecordWithKeys(ctx, id, cursor)
+			if err != nil {
+				return err
+			}
+			nodes, err := recordNodes(ctx, ds, r)
+			if err != nil {
+				return fmt.Errorf("exporting record %s: %w", cursor, err)
+			}
+			for _, nd := range nodes {
+				if err = writeCARBlock(bw, nd); err != nil {
+					return err
+				}
+			}
+			cursor = r.PrevID()
+		}
+	}
+	return bw.Flush()
+}
+
+// ImportCAR loads a thread exported with ExportCAR. If the thread does not
+// exist, it is added with the thread key given in opts, which is required.
+// The exported logs are added to the thread, and their records are verified
+// and applied in order, as if they were pulled from a peer.
+func (n *net) ImportCAR(ctx context.Context, r io.Reader, opts ...core.NewThreadOption) (info thread.Info, err error) {
+	args := &core.NewThreadOptions{}
+	for _, opt := range opts {
+		opt(args)
+	}
+	if _, err = args.Token.Validate(n.getPrivKey()); err != nil {
+		return
+	}
+
+	tmp, root, err := readCAR(ctx, r, n.conf.DecodeLimits)
+	if err != nil {
+		return
+	}
+	node, err := tmp.Get(ctx, root)
+	if err != nil {
+		return info, fmt.Errorf("%w: missing root: %v", ErrInvalidCAR, err)
+	}
+	var m carManifest
+	if err = cbornode.DecodeInto(node.RawData(), &m); err != nil {
+		return info, fmt.Errorf("%w: %v", ErrInvalidCAR, err)
+	}
+	id, err := thread.Decode(m.Thread)
+	if err != nil {
+		return info, fmt.Errorf("%w: %v", ErrInvalidCAR, err)
+	}
+	if err = n.ensureImportThread(id, args); err != nil {
+		return
+	}
+
+	tsph := n.getThreadSemaphore(id)
+	tsph <- struct{}{}
+	defer func() { <-tsph }()
+
+	for _, l := range m.Logs {
+		lg, err := carLogInfo(l)
+		if err != nil {
+			return info, err
+		}
+		if err = n.addLog(id, lg, AddrSourceDirect, ""); err != nil {
+			return info, err
+		}
+		if err = n.importLog(ctx, tmp, id, lg, l.Head); err != nil {
+			return info, err
+		}
+	}
+	return n.getThreadWithAddrs(id)
+}
+
+// ensureImportThread adds the thread being imported if it does not exist.
+func (n *net) ensureImportThread(id thread.ID, args *core.NewThreadOptions) error {
+	err := n.ensureUnique(id)
+	if err != nil {
+		if errors.Is(err, lstore.ErrThreadExists) {
+			return nil
+		}
+		return err
+	}
+	if !args.ThreadKey.Defined() {
+		return fmt.Errorf("a thread key is required to import thread %s", id)
+	}
+	if err = n.store.AddThread(thread.Info{ID: id, Key: args.ThreadKey}); err != nil {
+		return err
+	}
+	if args.ThreadKey.CanRead() {
+		linfo, err := createLog(n.host.ID(), args.LogKey)
+		if err != nil {
+			return err
+		}
+		if err = n.store.AddLog(id, linfo); err != nil {
+			return err
+		}
+	}
+	return n.server.ps.Add(id)
+}
+
+// importLog applies the records of an imported log up to head, oldest first.
+func (n *net) importLog(ctx context.Context, tmp format.DAGService, id thread.ID, lg thread.LogInfo, head cid.Cid) error {
+	keys, err := n.serviceKeys(id)
+	if err != nil {
+		return err
+	}
+	var recs []core.Record
+	for cursor := head; cursor.Defined(); {
+		has, err := n.hasBlock(id, cursor)
+		if err != nil {
+			return err
+		}
+		if has {
+			break
+		}
+		var r core.Record
+		for _, sk := range keys {
+			if r, err = cbor.GetRecord(ctx, tmp, cursor, sk); err == nil {
+				break
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("%w: record %s: %v", ErrInvalidCAR, cursor, err)
+		}
+		if _, err = r.GetBlock(ctx, tmp); err != nil {
+			return fmt.Errorf("%w: record %s: %v", ErrInvalidCAR, cursor, err)
+		}
+		if err = r.Verify(lg.PubKey); err != nil {
+			return err
+		}
+		n.preloadRecord(ctx, tmp, r)
+		recs = append(recs, r)
+		cursor = r.PrevID()
+	}
+	for i := len(recs) - 1; i >= 0; i-- {
+		if err = n.putRecord(ctx, id, lg.ID, recs[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// carLogInfo returns the log described by an exported log.
+func carLogInfo(l carLog) (lg thread.LogInfo, err error) {
+	lg.ID, err = peer.Decode(l.ID)
+	if err != nil {
+		return lg, fmt.Errorf("%w: %v", ErrInvalidCAR, err)
+	}
+	lg.PubKey, err = crypto.UnmarshalPublicKey(l.PubKey)
+	if err != nil {
+		return lg, fmt.Errorf("%w: %v", ErrInvalidCAR, err)
+	}
+	if !lg.ID.MatchesPublicKey(lg.PubKey) {
+		return lg, fmt.Errorf("%w: log %s does not match its key", ErrInvalidCAR, lg.ID)
+	}
+	return lg, nil
+}
+
+// recordNodes returns the record, event, header, and body nodes of a record.
+func recordNodes(ctx context.Context, ds format.DAGService, r core.Record) ([]format.Node, error) {
+	event, err := cbor.EventFromRecord(ctx, ds, r)
+	if err != nil {
+		return nil, err
+	}
+	header, err := event.GetHeader(ctx, ds, nil)
+	if err != nil {
+		return nil, err
+	}
+	body, err := event.GetBody(ctx, ds, nil)
+	if err != nil {
+		return nil, err
+	}
+	return []format.Node{r, event, header, body}, nil
+}
+
+// readCAR loads the blocks of a CAR (v1) stream into a temporary DAG service
+// and returns it with the stream's single root. Sections larger than the max
+// allocation of limits are refused.
+func readCAR(ctx context.Context, r io.Reader, limits cbor.DecodeLimits) (format.DAGService, cid.Cid, error) {
+	max := limits.OrDefault().MaxAlloc
+	br := bufio.NewReader(r)
+	data, err := readCARSection(br, max)
+	if err != nil {
+		return nil, cid.Undef, err
+	}
+	var header carHeader
+	if err = cbornode.DecodeInto(data, &header); err != nil {
+		return nil, cid.Undef, fmt.Errorf("%w: %v", ErrInvalidCAR, err)
+	}
+	if header.Version != 1 || len(header.Roots) != 1 {
+		return nil, cid.Undef, fmt.Errorf("%w: expected version 1 with a single root", ErrInvalidCAR)
+	}
+
+	b := bs.NewBlockstore(syncds.MutexWrap(datastore.NewMapDatastore()))
+	tmp := dag.NewDAGService(bserv.New(b, offline.Exchange(b)))
+	for {
+		data, err = readCARSection(br, max)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, cid.Undef, err
+		}
+		l, c, err := cid.CidFromBytes(data)
+		if err != nil {
+			return nil, cid.Undef, fmt.Errorf("%w: %v", ErrInvalidCAR, err)
+		}
+		// Blocks must hash to their cid, and records are decoded from cbor
+		sum, err := c.Prefix().Sum(data[l:])
+		if err != nil || !sum.Equals(c) {
+			return nil, cid.Undef, fmt.Errorf("%w: block %s does not match its cid", ErrInvalidCAR, c)
+		}
+		blk, err := blocks.NewBlockWithCid(data[l:], c)
+		if err != nil {
+			return nil, cid.Undef, err
+		}
+		nd, err := cbornode.DecodeBlock(blk)
+		if err != nil {
+			return nil, cid.Undef, fmt.Errorf("%w: %v", ErrInvalidCAR, err)
+		}
+		if err = tmp.Add(ctx, nd); err != nil {
+			return nil, cid.Undef, err
+		}
+	}
+	return tmp, header.Roots[0], nil
+}
+
+func writeCARBlock(w io.Writer, nd format.Node) error {
+	return writeCARSection(w, nd.Cid().Bytes(), nd.RawData())
+}
+
+// writeCARSection writes the parts of a section prefixed with their total length.
+func writeCARSection(w io.Writer, parts ...[]byte) error {
+	var size int
+	for _, p := range parts {
+		size += len(p)
+	}
+	buf := make([]byte, binary.MaxVarintLen64)
+	if _, err := w.Write(buf[:binary.PutUvarint(buf, uint64(size))]); err != nil {
+		return err
+	}
+	for _, p := range parts {
+		if _, err := w.Write(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readCARSection reads a length-prefixed section of at most max bytes,
+// returning io.EOF at the end of the stream.
+func readCARSection(r *bufio.Reader, max int) ([]byte, error) {
+	size, err := binary.ReadUvarint(r)
+	if err != nil {
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCAR, err)
+	}
+	if size > uint64(max) {
+		return nil, fmt.Errorf("%w: section exceeds %d bytes", cbor.ErrDecodeLimitExceeded, max)
+	}
+	data := make([]byte, size)
+	if _, err = io.ReadFull(r, data); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCAR, err)
+	}
+	return data, nil
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	nnet "net"
+	"sort"
 	"sync"
 	"time"
 
@@ -12,8 +14,9 @@ import (
 	"github.com/gogo/status"
 	"github.com/ipfs/go-cid"
 	"github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/libp2p/go-libp2p-core/network"
 	"github.com/libp2p/go-libp2p-core/peer"
-	gostream "github.com/libp2p/go-libp2p-gostream"
+	"github.com/libp2p/go-libp2p-core/protocol"
 	ma "github.com/multiformats/go-multiaddr"
 	"github.com/textileio/go-threads/cbor"
 	lstore "github.com/textileio/go-threads/core/logstore"
@@ -23,7 +26,6 @@ import (
 	pb "github.com/textileio/go-threads/net/pb"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/connectivity"
 )
 
 const (
@@ -31,6 +33,12 @@ const (
 	DialTimeout = time.Second * 10
 )
 
+// ErrLocalOnly indicates that an operation requires networking, which is disabled.
+var ErrLocalOnly = errors.New("networking is disabled in local-only mode")
+
+// ErrInsufficientReplicas indicates that a record was not acknowledged by enough peers.
+var ErrInsufficientReplicas = errors.New("record not acknowledged by enough peers")
+
 // getLogs in a thread.
 func (s *server) getLogs(ctx context.Context, id thread.ID, pid peer.ID) ([]thread.LogInfo, error) {
 	sk, err := s.net.store.ServiceKey(id)
@@ -57,21 +65,21 @@ func (s *server) getLogs(ctx context.Context, id thread.ID, pid peer.ID) ([]thre
 		Body: body,
 	}
 
-	log.Debugf("getting %s logs from %s...", id, pid)
+	s.log.Debugf("getting %s logs from %s...", id, pid)
 
 	client, err := s.dial(pid)
 	if err != nil {
 		return nil, err
 	}
-	cctx, cancel := context.WithTimeout(ctx, DialTimeout)
+	cctx, cancel := s.requestContext(ctx)
 	defer cancel()
 	reply, err := client.GetLogs(cctx, req)
 	if err != nil {
-		log.Warnf("get logs from %s failed: %s", pid, err)
+		s.log.Warnf("get logs from %s failed: %s", pid, err)
 		return nil, err
 	}
 
-	log.Debugf("received %d logs from %s", len(reply.Logs), pid)
+	s.log.Debugf("received %d logs from %s", len(reply.Logs), pid)
 
 	lgs := make([]thread.LogInfo, len(reply.Logs))
 	for i, l := range reply.Logs {
@@ -81,11 +89,124 @@ func (s *server) getLogs(ctx context.Context, id thread.ID, pid peer.ID) ([]thre
 	return lgs, nil
 }
 
+// getHeads returns the heads of a log from a peer, without the rest of the
+// log info returned by getLogs.
+func (s *server) getHeads(ctx context.Context, id thread.ID, lid peer.ID, pid peer.ID) ([]cid.Cid, error) {
+	sk, err := s.net.store.ServiceKey(id)
+	if err != nil {
+		return nil, err
+	}
+	if sk == nil {
+		return nil, fmt.Errorf("a service-key is required to request heads")
+	}
+
+	body := &pb.GetHeadsRequest_Body{
+		ThreadID:   &pb.ProtoThreadID{ID: id},
+		ServiceKey: &pb.ProtoKey{Key: sk},
+		LogID:      &pb.ProtoPeerID{ID: lid},
+	}
+	sig, key, err := s.signRequestBody(body)
+	if err != nil {
+		return nil, err
+	}
+	req := &pb.GetHeadsRequest{
+		Header: &pb.Header{
+			PubKey:    &pb.ProtoPubKey{PubKey: key},
+			Signature: sig,
+		},
+		Body: body,
+	}
+
+	client, err := s.dial(pid)
+	if err != nil {
+		return nil, err
+	}
+	cctx, cancel := s.requestContext(ctx)
+	defer cancel()
+	reply, err := client.GetHeads(cctx, req)
+	if err != nil {
+		s.log.Warnf("get heads from %s failed: %s", pid, err)
+		return nil, err
+	}
+	heads := make([]cid.Cid, len(reply.Heads))
+	for i, h := range reply.Heads {
+		heads[i] = h.Cid
+	}
+	return heads, nil
+}
+
+// getLogsBatch returns the logs of multiple threads from a peer in one request.
+// Threads without a service key are skipped. Threads that the peer fails to
+// return are logged and left out of the result.
+func (s *server) getLogsBatch(ctx context.Context, ids []thread.ID, pid peer.ID) (map[thread.ID][]thread.LogInfo, error) {
+	body := &pb.GetLogsBatchRequest_Body{}
+	for _, id := range ids {
+		sk, err := s.net.store.ServiceKey(id)
+		if err != nil {
+			return nil, err
+		}
+		if sk == nil {
+			s.log.Warnf("a service-key is required to request logs of thread %s", id)
+			continue
+		}
+		body.Threads = append(body.Threads, &pb.GetLogsRequest_Body{
+			ThreadID:   &pb.ProtoThreadID{ID: id},
+			ServiceKey: &pb.ProtoKey{Key: sk},
+		})
+	}
+	sig, key, err := s.signRequestBody(body)
+	if err != nil {
+		return nil, err
+	}
+	req := &pb.GetLogsBatchRequest{
+		Header: &pb.Header{
+			PubKey:    &pb.ProtoPubKey{PubKey: key},
+			Signature: sig,
+		},
+		Body: body,
+	}
+
+	s.log.Debugf("getting logs of %d threads from %s...", len(body.Threads), pid)
+
+	client, err := s.dial(pid)
+	if err != nil {
+		return nil, err
+	}
+	cctx, cancel := s.requestContext(ctx)
+	defer cancel()
+	reply, err := client.GetLogsBatch(cctx, req)
+	if err != nil {
+		s.log.Warnf("batch get logs from %s failed: %s", pid, err)
+		return nil, err
+	}
+
+	res := make(map[thread.ID][]thread.LogInfo, len(reply.Threads))
+	for _, entry := range reply.Threads {
+		if entry.ThreadID == nil {
+			continue
+		}
+		if entry.Error != "" {
+			s.log.Warnf("get logs of thread %s from %s failed: %s", entry.ThreadID.ID, pid, entry.Error)
+			continue
+		}
+		lgs := make([]thread.LogInfo, len(entry.Logs))
+		for i, l := range entry.Logs {
+			lgs[i] = logFromProto(l)
+		}
+		res[entry.ThreadID.ID] = lgs
+	}
+
+	s.log.Debugf("received logs of %d threads from %s", len(res), pid)
+
+	return res, nil
+}
+
 // pushLog to a peer.
-func (s *server) pushLog(ctx context.Context, id thread.ID, lg thread.LogInfo, pid peer.ID, sk *sym.Key, rk *sym.Key) error {
+func (s *server) pushLog(ctx context.Context, id thread.ID, lg thread.LogInfo, pid peer.ID, sk *sym.Key, rk *sym.Key, kc *pb.PushLogRequest_KeyChange) error {
 	body := &pb.PushLogRequest_Body{
-		ThreadID: &pb.ProtoThreadID{ID: id},
-		Log:      logToProto(lg),
+		ThreadID:  &pb.ProtoThreadID{ID: id},
+		Log:       logToProto(s.net.advertisedLog(lg)),
+		KeyChange: kc,
 	}
 	if sk != nil {
 		body.ServiceKey = &pb.ProtoKey{Key: sk}
@@ -105,13 +226,13 @@ func (s *server) pushLog(ctx context.Context, id thread.ID, lg thread.LogInfo, p
 		Body: body,
 	}
 
-	log.Debugf("pushing log %s to %s...", lg.ID, pid)
+	s.log.Debugf("pushing log %s to %s...", lg.ID, pid)
 
 	client, err := s.dial(pid)
 	if err != nil {
-		return fmt.Errorf("dial %s failed: %s", pid, err)
+		return fmt.Errorf("dial %s failed: %w", pid, err)
 	}
-	cctx, cancel := context.WithTimeout(ctx, DialTimeout)
+	cctx, cancel := s.requestContext(ctx)
 	defer cancel()
 	_, err = client.PushLog(cctx, lreq)
 	if err != nil {
@@ -121,29 +242,162 @@ func (s *server) pushLog(ctx context.Context, id thread.ID, lg thread.LogInfo, p
 }
 
 // records maintains an ordered list of records from multiple sources.
+// Records may be stored in any order, and are listed in causal order.
 type records struct {
 	sync.RWMutex
 	m map[peer.ID]map[cid.Cid]core.Record
 	s map[peer.ID][]core.Record
+	// truncated holds the logs that a peer did not send in full.
+	truncated map[peer.ID]bool
+
+	// out, if set, receives each new record as it is stored. Records are
+	// queued in pending and sent by one caller at a time, without the lock.
+	ctx     context.Context
+	out     chan<- logRecord
+	pending []logRecord
+	sending bool
+	// keep is whether stored records are kept for List. Otherwise, only the
+	// last record of each log is kept, for checking the order of records.
+	keep bool
+}
+
+// logRecord is a record in a log. A logRecord without a record indicates that
+// a peer truncated the log, i.e., it has more records to pull.
+type logRecord struct {
+	lid peer.ID
+	rec core.Record
 }
 
 // newRecords creates an instance of records.
 func newRecords() *records {
 	return &records{
-		m: make(map[peer.ID]map[cid.Cid]core.Record),
-		s: make(map[peer.ID][]core.Record),
+		m:         make(map[peer.ID]map[cid.Cid]core.Record),
+		s:         make(map[peer.ID][]core.Record),
+		truncated: make(map[peer.ID]bool),
+		keep:      true,
 	}
 }
 
-// List all records.
+// newRecordStream creates an instance of records that sends each new record
+// on out, in log order, until ctx is done. Unless keep is set, records are
+// not kept once sent, so that large histories are not held in memory.
+func newRecordStream(ctx context.Context, out chan<- logRecord, keep bool) *records {
+	r := newRecords()
+	r.ctx = ctx
+	r.out = out
+	r.keep = keep
+	return r
+}
+
+// List all records. The records of each log are listed in causal order, see
+// causalOrder. The returned map and slices are copies, so they may be
+// modified by the caller. Only the last record of each log is listed if
+// records are not kept.
 func (r *records) List() map[peer.ID][]core.Record {
 	r.RLock()
 	defer r.RUnlock()
-	return r.s
+	list := make(map[peer.ID][]core.Record, len(r.s))
+	for p, recs := range r.s {
+		list[p] = causalOrder(recs)
+	}
+	return list
+}
+
+// causalOrder returns a copy of the records of a log in which each record
+// comes after the record it builds on, if that is included. Records that
+// build on the same record, e.g., because peers sent different branches of
+// a log, and records that build on none of the others, are taken in order of
+// CID, so that the order does not depend on the order records arrived in.
+// Each branch is listed in full before the next.
+func causalOrder(recs []core.Record) []core.Record {
+	included := make(map[cid.Cid]struct{}, len(recs))
+	for _, rec := range recs {
+		included[rec.Cid()] = struct{}{}
+	}
+	var roots []core.Record
+	children := make(map[cid.Cid][]core.Record)
+	for _, rec := range recs {
+		if _, ok := included[rec.PrevID()]; ok {
+			children[rec.PrevID()] = append(children[rec.PrevID()], rec)
+		} else {
+			roots = append(roots, rec)
+		}
+	}
+
+	ordered := make([]core.Record, 0, len(recs))
+	stack := byCidDesc(roots)
+	for len(stack) > 0 {
+		rec := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		ordered = append(ordered, rec)
+		stack = append(stack, byCidDesc(children[rec.Cid()])...)
+	}
+	return ordered
+}
+
+// byCidDesc sorts records in descending order of CID, so that they're popped
+// off a stack in ascending order.
+func byCidDesc(recs []core.Record) []core.Record {
+	sort.Slice(recs, func(i, j int) bool {
+		return recs[i].Cid().KeyString() > recs[j].Cid().KeyString()
+	})
+	return recs
+}
+
+// Len returns the number of stored records in all logs, including records
+// that are not kept.
+func (r *records) Len() int {
+	r.RLock()
+	defer r.RUnlock()
+	var l int
+	for _, m := range r.m {
+		l += len(m)
+	}
+	return l
 }
 
-// Store a record.
-func (r *records) Store(p peer.ID, key cid.Cid, value core.Record) {
+// Get returns a stored record in a log. Records that are not kept are not
+// returned.
+func (r *records) Get(p peer.ID, key cid.Cid) (core.Record, bool) {
+	r.RLock()
+	defer r.RUnlock()
+	rec, ok := r.m[p][key]
+	return rec, ok && rec != nil
+}
+
+// Truncated returns the logs that a peer did not send in full.
+func (r *records) Truncated() map[peer.ID]bool {
+	r.RLock()
+	defer r.RUnlock()
+	list := make(map[peer.ID]bool, len(r.truncated))
+	for p := range r.truncated {
+		list[p] = true
+	}
+	return list
+}
+
+// Truncate marks a log as not sent in full by a peer.
+func (r *records) Truncate(p peer.ID) {
+	r.Lock()
+	r.truncated[p] = true
+	if r.out != nil {
+		r.pending = append(r.pending, logRecord{lid: p})
+	}
+	r.Unlock()
+	r.flush()
+}
+
+// Store a record. Records may be stored in any order, unless they're sent on
+// out as they're stored. Then, a record that doesn't build on the last record
+// stored in its log is dropped, leaving it to be pulled again, and false is
+// returned.
+func (r *records) Store(p peer.ID, key cid.Cid, value core.Record) bool {
+	ok := r.store(p, key, value)
+	r.flush()
+	return ok
+}
+
+func (r *records) store(p peer.ID, key cid.Cid, value core.Record) bool {
 	r.Lock()
 	defer r.Unlock()
 	if _, ok := r.m[p]; !ok {
@@ -151,20 +405,120 @@ func (r *records) Store(p peer.ID, key cid.Cid, value core.Record) {
 		r.s[p] = make([]core.Record, 0)
 	}
 	if _, ok := r.m[p][key]; ok {
-		return
+		return true
+	}
+	if l := len(r.s[p]); r.out != nil && l > 0 && r.s[p][l-1].Cid() != value.PrevID() {
+		return false
 	}
-	r.m[p][key] = value
 
-	// Sanity check
-	if len(r.s[p]) > 0 && r.s[p][len(r.s[p])-1].Cid() != value.PrevID() {
-		panic("there is a gap in records list")
+	if r.keep {
+		r.m[p][key] = value
+		r.s[p] = append(r.s[p], value)
+	} else {
+		r.m[p][key] = nil
+		r.s[p] = append(r.s[p][:0], value)
 	}
+	if r.out != nil {
+		r.pending = append(r.pending, logRecord{lid: p, rec: value})
+	}
+	return true
+}
 
-	r.s[p] = append(r.s[p], value)
+// flush sends the pending records on out in the order they were stored.
+// If another caller is already sending, it sends these records too.
+func (r *records) flush() {
+	if r.out == nil {
+		return
+	}
+	r.Lock()
+	if r.sending {
+		r.Unlock()
+		return
+	}
+	r.sending = true
+	for len(r.pending) > 0 {
+		lr := r.pending[0]
+		r.pending = r.pending[1:]
+		r.Unlock()
+		select {
+		case r.out <- lr:
+		case <-r.ctx.Done():
+		}
+		r.Lock()
+	}
+	r.sending = false
+	r.Unlock()
 }
 
 // getRecords from log addresses.
-func (s *server) getRecords(ctx context.Context, id thread.ID, lid peer.ID, offsets map[peer.ID]cid.Cid, limit int) (map[peer.ID][]core.Record, error) {
+func (s *server) getRecords(ctx context.Context, id thread.ID, lid peer.ID, offsets map[peer.ID]cid.Cid, limit int) (map[peer.ID][]core.Record, map[peer.ID]bool, error) {
+	lg, err := s.net.store.GetLog(id, lid)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.getRecordsFromAddrs(ctx, id, lg.Addrs, offsets, limit)
+}
+
+// getRecordsFromAddrs requests records in all logs at offsets from each address.
+// The logs that a peer truncated, e.g., due to its pull limit, are returned
+// along with the records.
+func (s *server) getRecordsFromAddrs(ctx context.Context, id thread.ID, addrs []ma.Multiaddr, offsets map[peer.ID]cid.Cid, limit int) (map[peer.ID][]core.Record, map[peer.ID]bool, error) {
+	if s.net.conf.LocalOnly {
+		return nil, nil, nil
+	}
+	req, err := s.newGetRecordsRequest(id, offsets, nil, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+	recs := newRecords()
+	s.fetchRecords(ctx, id, addrs, offsets, req, recs)
+	return recs.List(), recs.Truncated(), nil
+}
+
+// streamRecordsFromAddrs is like getRecordsFromAddrs, but sends the records on
+// the returned channel as they arrive instead of collecting them. Records are
+// requested with GetRecordsStream, so they are received one at a time. Records
+// in each log are sent in order. The channel is closed once all addresses have
+// replied. The caller must either drain the channel or cancel ctx. A log that
+// a peer truncated is sent as a logRecord without a record.
+func (s *server) streamRecordsFromAddrs(ctx context.Context, id thread.ID, addrs []ma.Multiaddr, offsets map[peer.ID]cid.Cid, limit int) (<-chan logRecord, error) {
+	out := make(chan logRecord)
+	if s.net.conf.LocalOnly {
+		close(out)
+		return out, nil
+	}
+	req, err := s.newGetRecordsRequest(id, offsets, nil, limit)
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		defer close(out)
+		// Read-repair compares all received records, so they must be kept
+		s.fetchRecords(ctx, id, addrs, offsets, req, newRecordStream(ctx, out, s.net.conf.ReadRepair))
+	}()
+	return out, nil
+}
+
+// getRecordRange requests the records in a log after from, up to and
+// including to, from each address. If to is undefined, records up to the
+// log head are requested.
+func (s *server) getRecordRange(ctx context.Context, id thread.ID, lid peer.ID, from, to cid.Cid, addrs []ma.Multiaddr) ([]core.Record, error) {
+	if s.net.conf.LocalOnly {
+		return nil, nil
+	}
+	offsets := map[peer.ID]cid.Cid{lid: from}
+	req, err := s.newGetRecordsRequest(id, offsets, map[peer.ID]cid.Cid{lid: to}, s.pullLimit())
+	if err != nil {
+		return nil, err
+	}
+	recs := newRecords()
+	s.fetchRecords(ctx, id, addrs, offsets, req, recs)
+	return recs.List()[lid], nil
+}
+
+// newGetRecordsRequest returns a signed request for records in all logs at
+// offsets. Records in logs with a stop are only requested up to the stop.
+func (s *server) newGetRecordsRequest(id thread.ID, offsets map[peer.ID]cid.Cid, stops map[peer.ID]cid.Cid, limit int) (*pb.GetRecordsRequest, error) {
 	sk, err := s.net.store.ServiceKey(id)
 	if err != nil {
 		return nil, err
@@ -179,6 +533,7 @@ func (s *server) getRecords(ctx context.Context, id thread.ID, lid peer.ID, offs
 			LogID:  &pb.ProtoPeerID{ID: lid},
 			Offset: &pb.ProtoCid{Cid: offset},
 			Limit:  int32(limit),
+			To:     &pb.ProtoCid{Cid: stops[lid]},
 		})
 	}
 
@@ -191,170 +546,437 @@ func (s *server) getRecords(ctx context.Context, id thread.ID, lid peer.ID, offs
 	if err != nil {
 		return nil, err
 	}
-	req := &pb.GetRecordsRequest{
+	return &pb.GetRecordsRequest{
 		Header: &pb.Header{
 			PubKey:    &pb.ProtoPubKey{PubKey: key},
 			Signature: sig,
+			Compact:   s.net.conf.CompactRecords,
 		},
 		Body: body,
-	}
-
-	lg, err := s.net.store.GetLog(id, lid)
-	if err != nil {
-		return nil, err
-	}
+	}, nil
+}
 
-	// Pull from each address
-	recs := newRecords()
+// fetchRecords sends a get records request to each address, storing the
+// replies in recs.
+func (s *server) fetchRecords(ctx context.Context, id thread.ID, addrs []ma.Multiaddr, offsets map[peer.ID]cid.Cid, req *pb.GetRecordsRequest, recs *records) {
+	ctx, span := s.net.tracer.start(ctx, "pullRecords", id, "", cid.Undef)
+	defer s.net.tracer.end(span, nil)
+	var gotLock sync.Mutex
+	got := make(replies)
 	wg := sync.WaitGroup{}
-	for _, addr := range lg.Addrs {
+	for _, addr := range addrs {
 		wg.Add(1)
 		go func(addr ma.Multiaddr) {
 			defer wg.Done()
-			p, err := addr.ValueForProtocol(ma.P_P2P)
+			pid, err := peerIDFromAddr(addr)
 			if err != nil {
-				log.Error(err)
+				s.log.Error(err)
 				return
 			}
-			pid, err := peer.Decode(p)
-			if err != nil {
-				log.Error(err)
+			if pid.String() == s.net.host.ID().String() {
 				return
 			}
-			if pid.String() == s.net.host.ID().String() {
+			if s.net.underPressure() && s.net.host.Network().Connectedness(pid) != network.Connected {
+				s.log.Debugf("skipping pull from %s under connection pressure", pid)
+				return
+			}
+			if s.health.unreachable(pid) {
+				s.log.Debugf("skipping pull from unreachable peer %s", pid)
 				return
 			}
 
-			log.Debugf("getting records from %s...", p)
+			s.log.Debugf("getting records from %s...", pid)
 
 			client, err := s.dial(pid)
 			if err != nil {
-				log.Errorf("dial %s failed: %s", p, err)
+				s.health.failure(pid)
+				s.log.Errorf("dial %s failed: %s", pid, err)
 				return
 			}
-			cctx, cancel := context.WithTimeout(ctx, DialTimeout)
-			defer cancel()
-			reply, err := client.GetRecords(cctx, req)
+			var served map[peer.ID][]core.Record
+			if recs.out != nil {
+				served, err = s.fetchRecordStream(ctx, client, pid, id, req, recs)
+			} else {
+				served, err = s.fetchRecordReply(ctx, client, pid, id, req, recs)
+			}
 			if err != nil {
-				log.Warnf("get records from %s failed: %s", p, err)
+				s.log.Error(err)
 				return
 			}
-			for _, l := range reply.Logs {
-				log.Debugf("received %d records in log %s from %s", len(l.Records), l.LogID.ID, p)
+			gotLock.Lock()
+			got[pid] = served
+			gotLock.Unlock()
+		}(addr)
+	}
+	wg.Wait()
 
-				lg, err := s.net.store.GetLog(id, l.LogID.ID)
-				if err != nil && !errors.Is(err, lstore.ErrLogNotFound) {
-					log.Error(err)
-					return
-				}
-				if lg.PubKey == nil {
-					if l.Log != nil {
-						lg = logFromProto(l.Log)
-						lg.Head = cid.Undef
-						if err = s.net.store.AddLog(id, lg); err != nil {
-							log.Error(err)
-							return
-						}
-					} else {
-						continue
-					}
-				}
+	// Peers are only compared when their logs were requested up to the head
+	if s.net.conf.ReadRepair && !boundedRequest(req) {
+		list := recs.List()
+		s.net.spawn(id, func() {
+			s.readRepair(s.net.ctx, id, offsets, got, list)
+		})
+	}
+}
 
-				for _, r := range l.Records {
-					rec, err := cbor.RecordFromProto(r, sk)
-					if err != nil {
-						log.Error(err)
-						return
-					}
-					if err = rec.Verify(lg.PubKey); err != nil {
-						log.Error(err)
-						return
-					}
-					recs.Store(lg.ID, rec.Cid(), rec)
-				}
+// boundedRequest returns whether a get records request stops before the
+// head of any log.
+func boundedRequest(req *pb.GetRecordsRequest) bool {
+	for _, l := range req.Body.Logs {
+		if l.To != nil && l.To.Cid.Defined() {
+			return true
+		}
+	}
+	return false
+}
+
+// fetchRecordReply requests records from a peer with GetRecords, storing
+// them in recs. The records served in each log are returned.
+func (s *server) fetchRecordReply(ctx context.Context, client pb.ServiceClient, pid peer.ID, id thread.ID, req *pb.GetRecordsRequest, recs *records) (map[peer.ID][]core.Record, error) {
+	cctx, cancel := s.requestContext(ctx)
+	defer cancel()
+	reply, err := client.GetRecords(cctx, req)
+	if err != nil {
+		s.health.failure(pid)
+		s.log.Warnf("get records from %s failed: %s", pid, err)
+		return nil, nil
+	}
+	s.health.success(pid)
+	served := make(map[peer.ID][]core.Record)
+	for _, l := range reply.Logs {
+		s.log.Debugf("received %d records in log %s from %s", len(l.Records), l.LogID.ID, pid)
+
+		lg, err := s.fetchedLog(id, l.LogID.ID, l.Log, pid)
+		if err != nil {
+			return nil, err
+		}
+		if lg.PubKey == nil {
+			continue
+		}
+		lrecs := make([]core.Record, 0, len(l.Records))
+		for _, r := range l.Records {
+			rec, err := s.fetchedRecord(id, lg, r)
+			if err != nil {
+				return nil, err
 			}
-		}(addr)
+			if !recs.Store(lg.ID, rec.Cid(), rec) {
+				s.log.Warnf("dropping record %s received out of order in log %s", rec.Cid(), lg.ID)
+			}
+			lrecs = append(lrecs, rec)
+		}
+		if l.Truncated {
+			// The peer's log may go on, so it can't be compared
+			recs.Truncate(lg.ID)
+			continue
+		}
+		served[lg.ID] = lrecs
 	}
-	wg.Wait()
+	return served, nil
+}
+
+// fetchRecordStream requests records from a peer with GetRecordsStream,
+// storing them in recs as they arrive. The records served in each log are
+// returned if recs keeps records. Peers that don't support streams are sent
+// a GetRecords request instead.
+// Streams of large histories may take longer than the request timeout, so it
+// bounds the wait for each message instead. The whole stream is only bounded
+// by ctx.
+func (s *server) fetchRecordStream(ctx context.Context, client pb.ServiceClient, pid peer.ID, id thread.ID, req *pb.GetRecordsRequest, recs *records) (map[peer.ID][]core.Record, error) {
+	cctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	timeout := s.net.settings.requestTimeout()
+	idle := time.AfterFunc(timeout, cancel)
+	defer idle.Stop()
+	stream, err := client.GetRecordsStream(cctx, req)
+	if err != nil {
+		s.health.failure(pid)
+		s.log.Warnf("get records stream from %s failed: %s", pid, err)
+		return nil, nil
+	}
+	served := make(map[peer.ID][]core.Record)
+	logs := make(map[peer.ID]thread.LogInfo)
+	var count int
+	for {
+		msg, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if status.Convert(err).Code() == codes.Unimplemented {
+				return s.fetchRecordReply(ctx, client, pid, id, req, recs)
+			}
+			s.health.failure(pid)
+			if cctx.Err() != nil && ctx.Err() == nil {
+				s.log.Warnf("get records stream from %s stalled for %s", pid, timeout)
+			} else {
+				s.log.Warnf("get records stream from %s failed: %s", pid, err)
+			}
+			return nil, nil
+		}
+		idle.Reset(timeout)
+		if msg.LogID == nil {
+			continue
+		}
+		lg, ok := logs[msg.LogID.ID]
+		if !ok {
+			if lg, err = s.fetchedLog(id, msg.LogID.ID, msg.Log, pid); err != nil {
+				return nil, err
+			}
+			logs[msg.LogID.ID] = lg
+		}
+		if lg.PubKey == nil {
+			continue
+		}
+		if msg.Truncated {
+			recs.Truncate(lg.ID)
+			delete(served, lg.ID)
+			continue
+		}
+		if msg.Record == nil {
+			continue
+		}
+		rec, err := s.fetchedRecord(id, lg, msg.Record)
+		if err != nil {
+			return nil, err
+		}
+		if !recs.Store(lg.ID, rec.Cid(), rec) {
+			s.log.Warnf("dropping record %s received out of order in log %s", rec.Cid(), lg.ID)
+		}
+		if recs.keep {
+			served[lg.ID] = append(served[lg.ID], rec)
+		}
+		count++
+	}
+	s.health.success(pid)
+	s.log.Debugf("received %d records from %s", count, pid)
+	return served, nil
+}
 
-	return recs.List(), nil
+// fetchedLog returns a log that records were fetched for. Unknown logs are
+// added if their info is given. The returned log has no public key if
+// records in it should be skipped, e.g., because the log was tombstoned.
+func (s *server) fetchedLog(id thread.ID, lid peer.ID, pblg *pb.Log, pid peer.ID) (thread.LogInfo, error) {
+	if removed, err := s.net.isTombstoned(id, lid); err != nil || removed {
+		return thread.LogInfo{}, err
+	}
+	lg, err := s.net.store.GetLog(id, lid)
+	if err != nil && !errors.Is(err, lstore.ErrLogNotFound) {
+		return lg, err
+	}
+	if lg.PubKey == nil && pblg != nil {
+		lg = logFromProto(pblg)
+		lg.Head = cid.Undef
+		if err = s.net.addLog(id, lg, AddrSourcePulled, pid); err != nil {
+			return lg, err
+		}
+	}
+	return lg, nil
 }
 
-// pushRecord to log addresses and thread topic.
-func (s *server) pushRecord(ctx context.Context, id thread.ID, lid peer.ID, rec core.Record) error {
-	// Collect known writers
-	addrs := make([]ma.Multiaddr, 0)
-	info, err := s.net.store.GetThread(id)
+// fetchedRecord decodes and verifies a fetched record.
+func (s *server) fetchedRecord(id thread.ID, lg thread.LogInfo, pbrec *pb.Log_Record) (core.Record, error) {
+	rec, err := s.net.recordFromProto(id, pbrec)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	for _, l := range info.Logs {
-		addrs = append(addrs, l.Addrs...)
+	if err = rec.Verify(lg.PubKey); err != nil {
+		return nil, err
 	}
+	return rec, nil
+}
+
+// pushes tracks the direct pushes of a record to log addresses. It may be
+// waited on by several callers.
+type pushes struct {
+	sync.Mutex
+	total   int
+	done    []core.Delivery
+	changed chan struct{} // Closed and replaced when a push completes
+}
 
-	pbrec, err := cbor.RecordToProto(ctx, s.net, rec)
+func newPushes(total int) *pushes {
+	return &pushes{total: total, changed: make(chan struct{})}
+}
+
+// add records a completed push.
+func (p *pushes) add(d core.Delivery) {
+	p.Lock()
+	defer p.Unlock()
+	p.done = append(p.done, d)
+	close(p.changed)
+	p.changed = make(chan struct{})
+}
+
+// wait blocks until cond holds for the completed pushes, or all pushes have
+// completed, returning the completed pushes.
+func (p *pushes) wait(ctx context.Context, cond func([]core.Delivery) bool) ([]core.Delivery, error) {
+	for {
+		p.Lock()
+		done, changed := p.done, p.changed
+		p.Unlock()
+		if cond(done) || len(done) == p.total {
+			return done, nil
+		}
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return done, ctx.Err()
+		}
+	}
+}
+
+// result waits for all pushes to complete and returns their outcome.
+// Each push is bounded by the request timeout.
+func (p *pushes) result() core.PushResult {
+	done, _ := p.wait(context.Background(), func([]core.Delivery) bool { return false })
+	return newPushResult(done)
+}
+
+// newPushResult counts the peers that accepted a record at any of their
+// addresses, and those that did not.
+func newPushResult(ds []core.Delivery) core.PushResult {
+	res := core.PushResult{Deliveries: ds}
+	accepted := make(map[peer.ID]bool)
+	for _, d := range ds {
+		accepted[d.Peer] = accepted[d.Peer] || d.Outcome == core.PushAccepted
+	}
+	for _, ok := range accepted {
+		if ok {
+			res.Succeeded++
+		} else {
+			res.Failed++
+		}
+	}
+	return res
+}
+
+// pushRecord to log addresses and thread topic, returning the direct pushes
+// to log addresses. Publishing to the topic doesn't depend on their outcome.
+// If minReplicas is greater than zero, pushRecord waits until that many peers
+// have acknowledged the record, returning ErrInsufficientReplicas if fewer do
+// before the context deadline, or the request timeout if ctx has none.
+// Concurrent pushes of the same record share the same network operations.
+func (s *server) pushRecord(ctx context.Context, id thread.ID, lid peer.ID, rec core.Record, minReplicas int) (p *pushes, err error) {
+	ctx, span := s.net.tracer.start(ctx, "pushRecord", id, lid, rec.Cid())
+	defer func() { s.net.tracer.end(span, err) }()
+	if s.net.conf.LocalOnly {
+		if minReplicas > 0 {
+			return newPushes(0), fmt.Errorf("%w: %v", ErrInsufficientReplicas, ErrLocalOnly)
+		}
+		return newPushes(0), nil
+	}
+	p, err = s.inflight.do(rec.Cid(), func() (*pushes, error) {
+		return s.startPushes(ctx, id, lid, rec, minReplicas > 0)
+	})
 	if err != nil {
-		return err
+		return nil, err
 	}
-	body := &pb.PushRecordRequest_Body{
-		ThreadID: &pb.ProtoThreadID{ID: id},
-		LogID:    &pb.ProtoPeerID{ID: lid},
-		Record:   pbrec,
+	if minReplicas > 0 {
+		return p, waitForReplicas(ctx, p, minReplicas, s.net.settings.requestTimeout())
 	}
-	sig, key, err := s.signRequestBody(body)
+	return p, nil
+}
+
+// startPushes pushes a record to log addresses and publishes it to the
+// thread topic, returning the direct pushes without waiting for them.
+// Critical pushes are not shed under connection pressure.
+func (s *server) startPushes(ctx context.Context, id thread.ID, lid peer.ID, rec core.Record, critical bool) (*pushes, error) {
+	// Collect known writers, other than the host
+	addrs := make([]ma.Multiaddr, 0)
+	info, err := s.net.store.GetThread(id)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	req := &pb.PushRecordRequest{
-		Header: &pb.Header{
-			PubKey:    &pb.ProtoPubKey{PubKey: key},
-			Signature: sig,
-		},
-		Body: body,
+	for _, l := range info.Logs {
+		for _, addr := range l.Addrs {
+			if pid, err := peerIDFromAddr(addr); err == nil && pid == s.net.host.ID() {
+				continue
+			}
+			addrs = append(addrs, addr)
+		}
+	}
+
+	req, err := s.newPushRecordRequest(ctx, id, lid, rec)
+	if err != nil {
+		return nil, err
 	}
 
 	// Push to each address
+	p := newPushes(len(addrs))
 	for _, addr := range addrs {
 		go func(addr ma.Multiaddr) {
-			p, err := addr.ValueForProtocol(ma.P_P2P)
-			if err != nil {
-				log.Error(err)
+			var pid peer.ID
+			var perr error
+			deferred := false
+			defer func() {
+				d := newDelivery(perr)
+				d.Addr, d.Peer = addr, pid
+				if deferred {
+					d.Outcome = core.PushDeferred
+				}
+				s.deliveries.add(rec.Cid(), d)
+				p.add(d)
+			}()
+			pid, perr = peerIDFromAddr(addr)
+			if perr != nil {
+				s.log.Error(perr)
 				return
 			}
-			pid, err := peer.Decode(p)
-			if err != nil {
-				log.Error(err)
+			if s.health.unreachable(pid) {
+				s.log.Debugf("skipping push to unreachable peer %s", pid)
+				s.queuePush(pid, rec.Cid(), req)
+				perr, deferred = errUnreachable, true
 				return
 			}
-			if pid.String() == s.net.host.ID().String() {
+			if s.net.shedPush(pid, critical) {
+				s.log.Debugf("deferring push to %s under connection pressure", pid)
+				s.queuePush(pid, rec.Cid(), req)
+				perr, deferred = fmt.Errorf("deferred under connection pressure"), true
+				return
+			}
+			release, err := s.acquirePushSlot(ctx)
+			if err != nil {
+				s.queuePush(pid, rec.Cid(), req)
+				perr = err
 				return
 			}
+			defer release()
 
-			log.Debugf("pushing record to %s...", p)
+			s.log.Debugf("pushing record to %s...", pid)
 
 			client, err := s.dial(pid)
 			if err != nil {
-				log.Errorf("dial %s failed: %s", p, err)
+				s.health.failure(pid)
+				s.queuePush(pid, rec.Cid(), req)
+				s.log.Errorf("dial %s failed: %s", pid, err)
+				perr = err
 				return
 			}
-			cctx, cancel := context.WithTimeout(context.Background(), DialTimeout)
+			cctx, cancel := s.pushContext(ctx)
 			defer cancel()
 			if _, err = client.PushRecord(cctx, req); err != nil {
+				perr = err
+				if isHeld(err) {
+					// The peer fetches the missing ancestors itself
+					s.log.Debugf("record %s is held by %s", rec.Cid(), pid)
+					s.health.success(pid)
+					return
+				}
 				if status.Convert(err).Code() == codes.NotFound { // Send the missing log
-					log.Debugf("pushing log %s to %s...", lid, p)
+					s.log.Debugf("pushing log %s to %s...", lid, pid)
 
 					l, err := s.net.store.GetLog(id, lid)
 					if err != nil {
-						log.Error(err)
+						s.log.Error(err)
 						return
 					}
 					body := &pb.PushLogRequest_Body{
 						ThreadID: &pb.ProtoThreadID{ID: id},
-						Log:      logToProto(l),
+						Log:      logToProto(s.net.advertisedLog(l)),
 					}
 					sig, key, err := s.signRequestBody(body)
 					if err != nil {
-						log.Error(err)
+						s.log.Error(err)
 						return
 					}
 					lreq := &pb.PushLogRequest{
@@ -365,73 +987,181 @@ func (s *server) pushRecord(ctx context.Context, id thread.ID, lid peer.ID, rec
 						Body: body,
 					}
 					if _, err = client.PushLog(cctx, lreq); err != nil {
-						log.Warnf("push log to %s failed: %s", p, err)
+						s.log.Warnf("push log to %s failed: %s", pid, err)
 						return
 					}
 					return
 				}
-				log.Warnf("push record to %s failed: %s", p, err)
+				s.health.failure(pid)
+				s.queuePush(pid, rec.Cid(), req)
+				s.log.Warnf("push record to %s failed: %s", pid, err)
 				return
 			}
+			s.health.success(pid)
 		}(addr)
 	}
 
 	// Finally, publish to the thread's topic
-	if err = s.ps.Publish(ctx, id, req); err != nil {
-		log.Errorf("error publishing record: %s", err)
+	if s.net.topicOversized(id) {
+		s.log.Warnf("thread %s topic is oversized, skipping publish", id)
+	} else if size, max := req.Size(), s.net.maxPubSubMessageSize(); size > max {
+		s.log.Warnf("record %s is too large to publish (%d > %d bytes), skipping publish", rec.Cid(), size, max)
+	} else if err = s.ps.Publish(ctx, id, req); err != nil {
+		s.log.Errorf("error publishing record: %s", err)
+	}
+	return p, nil
+}
+
+// newPushRecordRequest returns a signed request to push a record.
+func (s *server) newPushRecordRequest(ctx context.Context, id thread.ID, lid peer.ID, rec core.Record) (*pb.PushRecordRequest, error) {
+	pbrec, err := cbor.RecordToProto(ctx, s.net.threadDAG(id), rec)
+	if err != nil {
+		return nil, err
+	}
+	body := &pb.PushRecordRequest_Body{
+		ThreadID: &pb.ProtoThreadID{ID: id},
+		LogID:    &pb.ProtoPeerID{ID: lid},
+		Record:   pbrec,
 	}
-	return nil
+	sig, key, err := s.signRequestBody(body)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.PushRecordRequest{
+		Header: &pb.Header{
+			PubKey:    &pb.ProtoPubKey{PubKey: key},
+			Signature: sig,
+		},
+		Body: body,
+	}, nil
+}
+
+// waitForReplicas waits until min distinct peers have accepted pushes.
+// Peers that hold the record until its ancestors arrive have not stored it,
+// so they don't count.
+func waitForReplicas(ctx context.Context, p *pushes, min int, timeout time.Duration) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	var replicas int
+	_, err := p.wait(ctx, func(done []core.Delivery) bool {
+		replicas = newPushResult(done).Succeeded
+		return replicas >= min
+	})
+	switch {
+	case replicas >= min:
+		return nil
+	case err != nil:
+		return fmt.Errorf("%w: %d of %d acknowledged before deadline", ErrInsufficientReplicas, replicas, min)
+	default:
+		return fmt.Errorf("%w: %d of %d acknowledged", ErrInsufficientReplicas, replicas, min)
+	}
+}
+
+// requestContext returns a context for a request to a peer, which ends at
+// the deadline already set on ctx or after the request timeout, whichever
+// comes first. The request timeout is a cap rather than a default: it bounds
+// how long the node waits on a single unresponsive peer, and callers with a
+// longer deadline, such as history pulls, spread it over several requests.
+// Record streams, which may be long, apply the timeout to each message
+// instead, see fetchRecordStream.
+func (s *server) requestContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, s.net.settings.requestTimeout())
 }
 
-// dial attempts to open a gRPC connection over libp2p to a peer.
+// pushContext is like requestContext, but keeps only the deadline and span
+// of ctx, since pushes may outlive the call.
+func (s *server) pushContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	base := s.net.tracer.detach(ctx)
+	dl := time.Now().Add(s.net.settings.requestTimeout())
+	if cdl, ok := ctx.Deadline(); ok && cdl.Before(dl) {
+		dl = cdl
+	}
+	return context.WithDeadline(base, dl)
+}
+
+// dial returns a client for a peer, reusing a cached connection if it is
+// healthy, or else opening a new gRPC connection over libp2p.
 func (s *server) dial(peerID peer.ID) (pb.ServiceClient, error) {
-	s.Lock()
-	defer s.Unlock()
-	conn, ok := s.conns[peerID]
-	if ok {
-		if conn.GetState() == connectivity.Shutdown {
-			if err := conn.Close(); err != nil {
-				log.Errorf("error closing connection: %v", err)
-			}
-		} else {
-			return pb.NewServiceClient(conn), nil
-		}
+	if s.net.conf.LocalOnly {
+		return nil, ErrLocalOnly
+	}
+	if conn := s.conns.get(peerID); conn != nil {
+		return pb.NewServiceClient(conn), nil
 	}
 	ctx, cancel := context.WithTimeout(context.Background(), DialTimeout)
 	defer cancel()
-	conn, err := grpc.DialContext(ctx, peerID.Pretty(), s.getLibp2pDialer(), grpc.WithInsecure())
+	// The first stream is opened up front, so that the connection's options
+	// can depend on the protocol version negotiated with the peer
+	st, err := s.openStream(ctx, peerID, s.net.protocols()...)
 	if err != nil {
 		return nil, err
 	}
-	s.conns[peerID] = conn
-	return pb.NewServiceClient(conn), nil
+	opts := append([]grpc.DialOption{s.getLibp2pDialer(st), s.getSecurityOption()}, s.net.metrics.dialOptions()...)
+	if supportsKeepalive(st.Protocol()) {
+		opts = append(opts, keepaliveDialOption())
+	}
+	opts = append(opts, s.net.tracer.dialOptions()...)
+	conn, err := grpc.DialContext(ctx, peerID.Pretty(), opts...)
+	if err != nil {
+		_ = st.Reset()
+		return nil, err
+	}
+	return pb.NewServiceClient(s.conns.put(peerID, conn)), nil
 }
 
 // getLibp2pDialer returns a WithContextDialer option for libp2p dialing.
-func (s *server) getLibp2pDialer() grpc.DialOption {
+// The first stream is used for the first connection, and reconnects open
+// new streams with the protocol version it negotiated.
+func (s *server) getLibp2pDialer(first network.Stream) grpc.DialOption {
+	firstc := make(chan network.Stream, 1)
+	firstc <- first
 	return grpc.WithContextDialer(func(ctx context.Context, peerIDStr string) (nnet.Conn, error) {
+		select {
+		case st := <-firstc:
+			return &streamConn{Stream: st}, nil
+		default:
+		}
 		id, err := peer.Decode(peerIDStr)
 		if err != nil {
 			return nil, fmt.Errorf("grpc tried to dial non peerID: %s", err)
 		}
-		return gostream.Dial(ctx, s.net.host, id, thread.Protocol)
+		st, err := s.openStream(ctx, id, first.Protocol())
+		if err != nil {
+			return nil, err
+		}
+		return &streamConn{Stream: st}, nil
 	})
 }
 
+// openStream opens a libp2p stream to a peer with the newest of the given
+// thread protocol versions that the peer supports.
+func (s *server) openStream(ctx context.Context, pid peer.ID, protos ...protocol.ID) (network.Stream, error) {
+	s.net.resolvePeer(ctx, pid)
+	return s.net.host.NewStream(ctx, pid, protos...)
+}
+
+// getSecurityOption returns the configured transport credentials option, or
+// an insecure option, relying on the security of the libp2p stream alone.
+func (s *server) getSecurityOption() grpc.DialOption {
+	if s.net.conf.TransportCredentials != nil {
+		return grpc.WithTransportCredentials(s.net.conf.TransportCredentials)
+	}
+	return grpc.WithInsecure()
+}
+
 // signRequestBody signs an outbound request body with the hosts's private key.
 func (s *server) signRequestBody(msg proto.Marshaler) (sig []byte, pk crypto.PubKey, err error) {
-	payload, err := msg.Marshal()
-	if err != nil {
-		return
-	}
 	sk := s.net.getPrivKey()
 	if sk == nil {
 		err = fmt.Errorf("private key for host not found")
 		return
 	}
-	sig, err = sk.Sign(payload)
+	header, err := SignRequest(sk, msg)
 	if err != nil {
 		return
 	}
-	return sig, sk.GetPublic(), nil
+	return header.Signature, header.PubKey.PubKey, nil
 }
@@ -0,0 +1,119 @@
+package net
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+)
+
+// connCache holds client connections to peers, evicting the least recently
+// used connection when full.
+type connCache struct {
+	sync.Mutex
+	max int
+	l   *list.List
+	e   map[peer.ID]*list.Element
+	log Logger
+}
+
+type cachedConn struct {
+	pid  peer.ID
+	conn *grpc.ClientConn
+}
+
+func newConnCache(max int, l Logger) *connCache {
+	return &connCache{
+		max: max,
+		l:   list.New(),
+		e:   make(map[peer.ID]*list.Element),
+		log: l,
+	}
+}
+
+// get returns the connection to a peer, or nil if there is none.
+// Failed connections are closed and evicted.
+func (c *connCache) get(pid peer.ID) *grpc.ClientConn {
+	c.Lock()
+	defer c.Unlock()
+	e, ok := c.e[pid]
+	if !ok {
+		return nil
+	}
+	conn := e.Value.(*cachedConn).conn
+	switch conn.GetState() {
+	case connectivity.Shutdown, connectivity.TransientFailure:
+		c.evict(e)
+		return nil
+	}
+	c.l.MoveToFront(e)
+	return conn
+}
+
+// put caches a connection to a peer and returns the connection to use.
+// If a connection was cached concurrently, it is kept and conn is closed.
+func (c *connCache) put(pid peer.ID, conn *grpc.ClientConn) *grpc.ClientConn {
+	c.Lock()
+	defer c.Unlock()
+	if e, ok := c.e[pid]; ok {
+		c.closeConn(conn)
+		c.l.MoveToFront(e)
+		return e.Value.(*cachedConn).conn
+	}
+	c.e[pid] = c.l.PushFront(&cachedConn{pid: pid, conn: conn})
+	for c.max > 0 && c.l.Len() > c.max {
+		c.evict(c.l.Back())
+	}
+	return conn
+}
+
+// all returns the cached connections.
+func (c *connCache) all() []cachedConn {
+	c.Lock()
+	defer c.Unlock()
+	conns := make([]cachedConn, 0, c.l.Len())
+	for e := c.l.Front(); e != nil; e = e.Next() {
+		conns = append(conns, *e.Value.(*cachedConn))
+	}
+	return conns
+}
+
+// remove closes and evicts the connection to a peer, unless it was replaced
+// by another connection.
+func (c *connCache) remove(pid peer.ID, conn *grpc.ClientConn) {
+	c.Lock()
+	defer c.Unlock()
+	if e, ok := c.e[pid]; ok && e.Value.(*cachedConn).conn == conn {
+		c.evict(e)
+	}
+}
+
+// len returns the number of cached connections.
+func (c *connCache) len() int {
+	c.Lock()
+	defer c.Unlock()
+	return c.l.Len()
+}
+
+// closeAll closes and evicts all connections.
+func (c *connCache) closeAll() {
+	c.Lock()
+	defer c.Unlock()
+	for e := c.l.Front(); e != nil; e = c.l.Front() {
+		c.evict(e)
+	}
+}
+
+func (c *connCache) evict(e *list.Element) {
+	cc := c.l.Remove(e).(*cachedConn)
+	delete(c.e, cc.pid)
+	c.closeConn(cc.conn)
+}
+
+func (c *connCache) closeConn(conn *grpc.ClientConn) {
+	if err := conn.Close(); err != nil {
+		c.log.Errorf("error closing connection: %v", err)
+	}
+}
@@ -0,0 +1,95 @@
+package net
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	core "github.com/textileio/go-threads/core/net"
+	"github.com/textileio/go-threads/core/thread"
+)
+
+// ErrOwnLog indicates an operation is not allowed on the host's own log.
+var ErrOwnLog = fmt.Errorf("operation not allowed on own log")
+
+// tombstoneKey returns the thread metadata key marking a log that was
+// removed from the thread, i.e., deleted or revoked.
+func tombstoneKey(lid peer.ID) string {
+	return "tombstone/" + lid.String()
+}
+
+// DeleteLog removes another peer's log from a thread, along with its keys,
+// addresses, and locally held records. A history pull of the log that is
+// queued or running is cancelled. Records are no longer pushed to the log's
+// addresses. The log is tombstoned, so that it isn't added back when it's
+// pulled or pushed by a peer.
+func (n *net) DeleteLog(ctx context.Context, id thread.ID, lid peer.ID, opts ...core.ThreadOption) error {
+	args := &core.ThreadOptions{}
+	for _, opt := range opts {
+		opt(args)
+	}
+	if _, err := args.Token.Validate(n.getPrivKey()); err != nil {
+		return err
+	}
+
+	ownlg, err := n.getOwnLog(id)
+	if err != nil {
+		return err
+	}
+	if ownlg.ID == lid {
+		return ErrOwnLog
+	}
+	n.logPulls.cancel(id, lid)
+
+	n.log.Debugf("deleting log %s in thread %s...", lid, id)
+	tsph := n.getThreadSemaphore(id)
+	tsph <- struct{}{}
+	defer func() { <-tsph }()
+	if err = n.tombstone(id, lid); err != nil {
+		return err
+	}
+	if err = n.deleteLogRecords(ctx, id, lid); err != nil {
+		return err
+	}
+	return n.store.DeleteLog(id, lid)
+}
+
+// tombstone marks a log as removed from a thread. Peers can no longer add the
+// log, push records to it, or sign key changes with it.
+func (n *net) tombstone(id thread.ID, lid peer.ID) error {
+	return n.store.PutString(id, tombstoneKey(lid), time.Now().Format(time.RFC3339))
+}
+
+// isTombstoned returns whether or not a log was removed from a thread with
+// DeleteLog, or revoked with RotateReadKey.
+func (n *net) isTombstoned(id thread.ID, lid peer.ID) (bool, error) {
+	v, err := n.store.GetString(id, tombstoneKey(lid))
+	if err != nil {
+		return false, err
+	}
+	return v != nil && *v != "", nil
+}
+
+// deleteLogRecords removes the locally held records of a log, walking back
+// from each head until a record is not held.
+func (n *net) deleteLogRecords(ctx context.Context, id thread.ID, lid peer.ID) error {
+	heads, err := n.store.Heads(id, lid)
+	if err != nil {
+		return err
+	}
+	for _, head := range heads {
+		for head.Defined() {
+			// Followed threads may not hold a log's full history
+			if has, err := n.hasBlock(id, head); err != nil {
+				return err
+			} else if !has {
+				break
+			}
+			if head, err = n.deleteRecord(ctx, id, head); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
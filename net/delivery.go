@@ -0,0 +1,71 @@
+package net
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/gogo/status"
+	"github.com/ipfs/go-cid"
+	core "github.com/textileio/go-threads/core/net"
+	"google.golang.org/grpc/codes"
+)
+
+// DeliveryLogSize is the number of most recently pushed records whose
+// deliveries are kept.
+var DeliveryLogSize = 1000
+
+// deliveries records the outcome of each push of the host's records.
+type deliveries struct {
+	sync.Mutex
+	m     map[cid.Cid][]core.Delivery
+	order []cid.Cid
+}
+
+func newDeliveries() *deliveries {
+	return &deliveries{m: make(map[cid.Cid][]core.Delivery)}
+}
+
+// add a delivery of a record, forgetting the oldest records over the max.
+func (d *deliveries) add(rid cid.Cid, dl core.Delivery) {
+	d.Lock()
+	defer d.Unlock()
+	if _, ok := d.m[rid]; !ok {
+		d.order = append(d.order, rid)
+		for len(d.order) > DeliveryLogSize {
+			delete(d.m, d.order[0])
+			d.order = d.order[1:]
+		}
+	}
+	d.m[rid] = append(d.m[rid], dl)
+}
+
+// Deliveries returns the attempts to push a record to its thread's members,
+// in the order they completed, including retries of queued pushes.
+// Records published over pubsub are not included.
+func (n *net) Deliveries(rid cid.Cid) []core.Delivery {
+	d := n.server.deliveries
+	d.Lock()
+	defer d.Unlock()
+	return append([]core.Delivery(nil), d.m[rid]...)
+}
+
+// pushOutcome returns the outcome of a push that failed with err.
+func pushOutcome(err error) core.PushOutcome {
+	if errors.Is(err, context.DeadlineExceeded) || status.Convert(err).Code() == codes.DeadlineExceeded {
+		return core.PushTimedOut
+	}
+	return core.PushFailed
+}
+
+// newDelivery returns a delivery for a push that completed with err.
+func newDelivery(err error) core.Delivery {
+	d := core.Delivery{Outcome: core.PushAccepted, Err: err, Time: time.Now()}
+	if isHeld(err) {
+		d.Outcome = core.PushHeld
+	} else if err != nil {
+		d.Outcome = pushOutcome(err)
+	}
+	return d
+}
@@ -0,0 +1,24 @@
+package net
+
+import (
+	"github.com/ipfs/go-cid"
+	"github.com/textileio/go-threads/cbor"
+	sym "github.com/textileio/go-threads/crypto/symmetric"
+	pb "github.com/textileio/go-threads/net/pb"
+)
+
+// RecordDependencies returns the cids of the records that the record in a push
+// request directly depends on. These must be present for the record to be
+// applied without being held until they arrive. A record links to a single
+// previous record in its log, so at most one cid is returned. The record is
+// decoded with the thread's service key, but not verified.
+func RecordDependencies(req *pb.PushRecordRequest, sk *sym.Key) ([]cid.Cid, error) {
+	rec, err := cbor.RecordFromProto(req.Body.Record, sk)
+	if err != nil {
+		return nil, err
+	}
+	if !rec.PrevID().Defined() {
+		return nil, nil
+	}
+	return []cid.Cid{rec.PrevID()}, nil
+}
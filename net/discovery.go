@@ -0,0 +1,43 @@
+package net
+
+import (
+	"context"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/peerstore"
+	"github.com/libp2p/go-libp2p-core/routing"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+// PeerResolver returns the addresses of a peer, e.g., from a DHT or a
+// directory service. It is consulted when dialing a thread member whose
+// addresses are not known to the host.
+type PeerResolver func(ctx context.Context, pid peer.ID) ([]ma.Multiaddr, error)
+
+// RoutingResolver returns a PeerResolver that finds peers with r, e.g., a
+// libp2p DHT.
+func RoutingResolver(r routing.PeerRouting) PeerResolver {
+	return func(ctx context.Context, pid peer.ID) ([]ma.Multiaddr, error) {
+		info, err := r.FindPeer(ctx, pid)
+		if err != nil {
+			return nil, err
+		}
+		return info.Addrs, nil
+	}
+}
+
+// resolvePeer adds the addresses of a peer to the host's peerstore with the
+// configured PeerResolver, if the host doesn't know any. Resolution errors
+// are logged, leaving the dial to fail as it would without a resolver.
+func (n *net) resolvePeer(ctx context.Context, pid peer.ID) {
+	if n.conf.PeerResolver == nil || len(n.host.Peerstore().Addrs(pid)) > 0 {
+		return
+	}
+	addrs, err := n.conf.PeerResolver(ctx, pid)
+	if err != nil {
+		n.log.Warnf("error resolving peer %s: %s", pid, err)
+		return
+	}
+	n.log.Debugf("resolved %d addresses for peer %s", len(addrs), pid)
+	n.host.Peerstore().AddAddrs(pid, addrs, peerstore.AddressTTL)
+}
@@ -0,0 +1,137 @@
+package net
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	"github.com/libp2p/go-libp2p-core/peer"
+	core "github.com/textileio/go-threads/core/net"
+	"github.com/textileio/go-threads/core/thread"
+)
+
+// ErrEquivocation indicates that a record conflicts with another record
+// signed by the same log key with the same previous record.
+var ErrEquivocation = fmt.Errorf("log owner signed conflicting records")
+
+// maxLogForks is the max number of equivocations stored per log. Once a log's
+// owner is known to fork it, further evidence adds little, so conflicting
+// records beyond this are rejected without being stored.
+const maxLogForks = 16
+
+// forksKey returns the thread metadata key of the equivocations of a log.
+func forksKey(lid peer.ID) string {
+	return "forks/" + lid.String()
+}
+
+// Equivocations returns the evidence of log owners that forked their logs
+// in a thread, in the order it was detected.
+func (n *net) Equivocations(id thread.ID) ([]core.Equivocation, error) {
+	info, err := n.store.GetThread(id)
+	if err != nil {
+		return nil, err
+	}
+	var eqs []core.Equivocation
+	for _, lg := range info.Logs {
+		forks, err := n.LogForks(id, lg.ID)
+		if err != nil {
+			return nil, err
+		}
+		eqs = append(eqs, forks...)
+	}
+	sort.SliceStable(eqs, func(i, j int) bool {
+		return eqs[i].Time.Before(eqs[j].Time)
+	})
+	return eqs, nil
+}
+
+// LogForks returns the evidence of the forks of a log in a thread, in the
+// order it was detected.
+func (n *net) LogForks(id thread.ID, lid peer.ID) ([]core.Equivocation, error) {
+	b, err := n.store.GetBytes(id, forksKey(lid))
+	if err != nil || b == nil {
+		return nil, err
+	}
+	var forks []core.Equivocation
+	if err = json.Unmarshal(*b, &forks); err != nil {
+		return nil, err
+	}
+	return forks, nil
+}
+
+// checkEquivocation returns ErrEquivocation if a log already has a different
+// record at the height of rec, storing the pair as evidence. Records whose
+// height is unknown, i.e., whose log history is incomplete, are not checked.
+func (n *net) checkEquivocation(ctx context.Context, id thread.ID, lid peer.ID, rec core.Record) error {
+	var height int64
+	if rec.PrevID().Defined() {
+		prev, err := n.store.GetInt64(id, recordHeightKey(rec.PrevID()))
+		if err != nil || prev == nil {
+			return err
+		}
+		height = *prev + 1
+	}
+	v, err := n.store.GetString(id, heightKey(lid, height))
+	if err != nil || v == nil {
+		return err
+	}
+	existing, err := cid.Decode(*v)
+	if err != nil {
+		return err
+	}
+	if existing.Equals(rec.Cid()) {
+		return nil
+	}
+	other, err := n.getRecordWithKeys(ctx, id, existing)
+	if err != nil {
+		return err
+	}
+	if !other.PrevID().Equals(rec.PrevID()) {
+		return nil
+	}
+
+	n.log.Warnf("log %s in thread %s has conflicting records %s and %s", lid, id, existing, rec.Cid())
+	if err = n.addEquivocation(id, core.Equivocation{
+		Log:     lid,
+		Prev:    rec.PrevID(),
+		Records: [2]core.SignedRecord{signedRecord(other), signedRecord(rec)},
+		Time:    time.Now(),
+	}); err != nil {
+		return err
+	}
+	return fmt.Errorf("%w: %s conflicts with %s", ErrEquivocation, rec.Cid(), existing)
+}
+
+// addEquivocation stores evidence of an equivocation unless the same pair
+// of records is already stored, or the log has maxLogForks equivocations.
+func (n *net) addEquivocation(id thread.ID, eq core.Equivocation) error {
+	forks, err := n.LogForks(id, eq.Log)
+	if err != nil {
+		return err
+	}
+	if len(forks) >= maxLogForks {
+		return nil
+	}
+	for _, f := range forks {
+		if f.Records[0].Cid.Equals(eq.Records[0].Cid) && f.Records[1].Cid.Equals(eq.Records[1].Cid) {
+			return nil
+		}
+	}
+	b, err := json.Marshal(append(forks, eq))
+	if err != nil {
+		return err
+	}
+	return n.store.PutBytes(id, forksKey(eq.Log), b)
+}
+
+func signedRecord(rec core.Record) core.SignedRecord {
+	return core.SignedRecord{
+		Cid:    rec.Cid(),
+		Block:  rec.BlockID(),
+		PubKey: rec.PubKey(),
+		Sig:    rec.Sig(),
+	}
+}
@@ -0,0 +1,72 @@
+package net
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	core "github.com/textileio/go-threads/core/net"
+)
+
+// EventPolicy determines how records are delivered to subscribers that fall behind.
+type EventPolicy int
+
+const (
+	// EventBlock waits up to the notify timeout for a subscriber to accept a record,
+	// dropping the record if it doesn't. This is the default.
+	EventBlock EventPolicy = iota
+	// EventDropNewest drops incoming records while a subscriber's buffer is full.
+	EventDropNewest
+	// EventDropOldest evicts the oldest buffered record to make room for an incoming one.
+	EventDropOldest
+)
+
+// eventBuffer returns the number of records buffered for each subscriber.
+// The drop policies need room for at least one record, since an unbuffered
+// channel is only ever full.
+func (n *net) eventBuffer() int {
+	if n.conf.EventBuffer < 1 && n.conf.EventPolicy != EventBlock {
+		return 1
+	}
+	return n.conf.EventBuffer
+}
+
+// DroppedEvents returns the number of records that were not delivered to subscribers.
+func (n *net) DroppedEvents() uint64 {
+	return atomic.LoadUint64(&n.dropped)
+}
+
+// deliver sends a record to a subscriber channel according to the configured policy.
+func (n *net) deliver(ctx context.Context, ch chan core.ThreadRecord, rec core.ThreadRecord) {
+	switch n.conf.EventPolicy {
+	case EventDropNewest:
+		select {
+		case ch <- rec:
+		default:
+			atomic.AddUint64(&n.dropped, 1)
+		}
+	case EventDropOldest:
+		for {
+			select {
+			case ch <- rec:
+				return
+			default:
+			}
+			select {
+			case <-ch:
+				atomic.AddUint64(&n.dropped, 1)
+			default:
+			}
+		}
+	default:
+		timer := time.NewTimer(notifyTimeout)
+		defer timer.Stop()
+		select {
+		case ch <- rec:
+		case <-timer.C:
+			atomic.AddUint64(&n.dropped, 1)
+			n.log.Warnf("dropped record %s for slow subscriber", rec.Value().Cid())
+		case <-ctx.Done():
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package net
+
+import (
+	"context"
+	"time"
+)
+
+// FlushRetryInterval is the interval between attempts to deliver queued
+// pushes while flushing.
+var FlushRetryInterval = time.Second
+
+// Flush applies buffered records whose previous records have arrived, and
+// retries queued pushes until all are delivered and no history pulls of new
+// logs are pending. It returns once the network is quiescent, or with the
+// context's error if it is done first. Records still waiting on missing
+// ancestors remain buffered.
+func (n *net) Flush(ctx context.Context) error {
+	for {
+		if err := n.applyOrphans(ctx); err != nil {
+			return err
+		}
+		n.server.retryPushes()
+		if n.logPulls.idle() && n.server.outbound.len() == 0 {
+			return nil
+		}
+		select {
+		case <-time.After(FlushRetryInterval):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// applyOrphans applies buffered records whose previous record is now held,
+// e.g., because it arrived while the record was being buffered.
+func (n *net) applyOrphans(ctx context.Context) error {
+	for _, or := range n.orphans.ready(n.hasBlock) {
+		tsph := n.getThreadSemaphore(or.id)
+		tsph <- struct{}{}
+		err := n.putRecord(ctx, or.id, or.lid, or.rec)
+		<-tsph
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,295 @@
+package net
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/gogo/status"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/textileio/go-threads/cbor"
+	core "github.com/textileio/go-threads/core/net"
+	"github.com/textileio/go-threads/core/thread"
+	pb "github.com/textileio/go-threads/net/pb"
+	"google.golang.org/grpc/codes"
+)
+
+// FollowerBuffer is the number of new records buffered for each peer
+// following threads over a Subscribe stream. The stream of a peer that falls
+// further behind is ended, leaving the missed records to be pulled.
+var FollowerBuffer = 1024
+
+// followers tracks the Subscribe streams open on the host by thread, so that
+// new records can be sent to the peers following each thread.
+type followers struct {
+	sync.Mutex
+	m      map[thread.ID]map[*follower]struct{}
+	open   map[*follower]struct{}
+	closed bool
+}
+
+// follower is a Subscribe stream.
+type follower struct {
+	ids  map[thread.ID]struct{}
+	recs chan core.ThreadRecord
+	// done is closed with err set when the host ends the stream.
+	done chan struct{}
+	err  error
+}
+
+func newFollowers() *followers {
+	return &followers{
+		m:    make(map[thread.ID]map[*follower]struct{}),
+		open: make(map[*follower]struct{}),
+	}
+}
+
+// start registers a new stream, returning false if the host is closing.
+func (fs *followers) start() (*follower, bool) {
+	fs.Lock()
+	defer fs.Unlock()
+	if fs.closed {
+		return nil, false
+	}
+	f := &follower{
+		ids:  make(map[thread.ID]struct{}),
+		recs: make(chan core.ThreadRecord, FollowerBuffer),
+		done: make(chan struct{}),
+	}
+	fs.open[f] = struct{}{}
+	return f, true
+}
+
+// follow adds a thread to a stream.
+func (fs *followers) follow(f *follower, id thread.ID) {
+	fs.Lock()
+	defer fs.Unlock()
+	if _, ok := fs.open[f]; !ok {
+		return
+	}
+	if fs.m[id] == nil {
+		fs.m[id] = make(map[*follower]struct{})
+	}
+	fs.m[id][f] = struct{}{}
+	f.ids[id] = struct{}{}
+}
+
+// stop unregisters a stream.
+func (fs *followers) stop(f *follower) {
+	fs.Lock()
+	defer fs.Unlock()
+	for id := range f.ids {
+		delete(fs.m[id], f)
+		if len(fs.m[id]) == 0 {
+			delete(fs.m, id)
+		}
+	}
+	delete(fs.open, f)
+}
+
+// send queues a record on the streams following its thread. Streams without
+// room for the record are ended.
+func (fs *followers) send(rec core.ThreadRecord) {
+	fs.Lock()
+	defer fs.Unlock()
+	for f := range fs.m[rec.ThreadID()] {
+		select {
+		case f.recs <- rec:
+		default:
+			f.end(status.Error(codes.ResourceExhausted, "follower fell behind"))
+		}
+	}
+}
+
+// closeAll ends all streams, and prevents new ones.
+func (fs *followers) closeAll() {
+	fs.Lock()
+	defer fs.Unlock()
+	fs.closed = true
+	for f := range fs.open {
+		f.end(status.Error(codes.Unavailable, "host is closing"))
+	}
+}
+
+// end ends a stream with err. The stream stays registered until it's stopped.
+// The followers lock must be held.
+func (f *follower) end(err error) {
+	select {
+	case <-f.done:
+	default:
+		f.err = err
+		close(f.done)
+	}
+}
+
+// startFollowers sends new records to the streams following their threads
+// until the network is closed.
+func (s *server) startFollowers() {
+	listener := s.net.bus.Listen()
+	go func() {
+		defer listener.Discard()
+		for i := range listener.Channel() {
+			rec, ok := i.(core.ThreadRecord)
+			if !ok || rec.Value() == nil {
+				continue
+			}
+			s.followers.send(rec)
+		}
+	}()
+}
+
+// Subscribe receives a stream of requests to follow threads, and replies with
+// each new record in the followed threads as it's stored. Requests may be
+// sent at any time until the peer closes its side of the stream.
+func (s *server) Subscribe(stream pb.Service_SubscribeServer) error {
+	f, ok := s.followers.start()
+	if !ok {
+		return status.Error(codes.Unavailable, "host is closing")
+	}
+	defer s.followers.stop(f)
+
+	ctx := stream.Context()
+	reqs := make(chan error, 1)
+	go func() {
+		reqs <- s.receiveSubscriptions(stream, f)
+	}()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-f.done:
+			return f.err
+		case err := <-reqs:
+			if err != nil {
+				return err
+			}
+			reqs = nil
+		case rec := <-f.recs:
+			pbrec, err := cbor.RecordToProto(ctx, s.net.threadDAG(rec.ThreadID()), rec.Value())
+			if err != nil {
+				return status.Error(codes.Internal, err.Error())
+			}
+			if err = stream.Send(&pb.SubscribeReply{
+				ThreadID: &pb.ProtoThreadID{ID: rec.ThreadID()},
+				LogID:    &pb.ProtoPeerID{ID: rec.LogID()},
+				Record:   pbrec,
+			}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// receiveSubscriptions adds the threads of a Subscribe stream's requests to
+// its follower, until the peer closes its side of the stream.
+func (s *server) receiveSubscriptions(stream pb.Service_SubscribeServer, f *follower) error {
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if req.Body == nil || req.Body.ThreadID == nil {
+			return status.Error(codes.InvalidArgument, "a thread is required")
+		}
+		pid, err := verifyRequest(req.Header, req.Body)
+		if err != nil {
+			return err
+		}
+		if err = s.checkServiceKey(req.Body.ThreadID.ID, req.Body.ServiceKey); err != nil {
+			return err
+		}
+		s.followers.follow(f, req.Body.ThreadID.ID)
+		s.log.Debugf("%s is following thread %s", pid, req.Body.ThreadID.ID)
+	}
+}
+
+// Follow opens a Subscribe stream with a peer, and accepts the records it
+// sends in the given threads as if they were pushed. Records that were stored
+// by the peer before the stream was opened are not sent, and should be pulled.
+func (n *net) Follow(ctx context.Context, pid peer.ID, ids ...thread.ID) error {
+	if n.conf.LocalOnly {
+		return ErrLocalOnly
+	}
+	return n.server.follow(ctx, pid, ids)
+}
+
+func (s *server) follow(ctx context.Context, pid peer.ID, ids []thread.ID) error {
+	reqs := make([]*pb.SubscribeRequest, len(ids))
+	followed := make(map[thread.ID]struct{}, len(ids))
+	for i, id := range ids {
+		sk, err := s.net.store.ServiceKey(id)
+		if err != nil {
+			return err
+		}
+		if sk == nil {
+			return fmt.Errorf("a service-key is required to follow threads")
+		}
+		body := &pb.SubscribeRequest_Body{
+			ThreadID:   &pb.ProtoThreadID{ID: id},
+			ServiceKey: &pb.ProtoKey{Key: sk},
+		}
+		sig, key, err := s.signRequestBody(body)
+		if err != nil {
+			return err
+		}
+		reqs[i] = &pb.SubscribeRequest{
+			Header: &pb.Header{
+				PubKey:    &pb.ProtoPubKey{PubKey: key},
+				Signature: sig,
+			},
+			Body: body,
+		}
+		followed[id] = struct{}{}
+	}
+
+	s.log.Debugf("following %d threads on %s...", len(ids), pid)
+
+	client, err := s.dial(pid)
+	if err != nil {
+		return fmt.Errorf("dial %s failed: %w", pid, err)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := client.Subscribe(ctx)
+	if err != nil {
+		return err
+	}
+	for _, req := range reqs {
+		if err = stream.Send(req); err != nil {
+			return err
+		}
+	}
+	if err = stream.CloseSend(); err != nil {
+		return err
+	}
+	for {
+		reply, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+		if reply.ThreadID == nil || reply.LogID == nil || reply.Record == nil {
+			continue
+		}
+		id := reply.ThreadID.ID
+		if _, ok := followed[id]; !ok {
+			continue
+		}
+		errs, err := s.acceptRecords(ctx, pid, id, reply.LogID.ID, []*pb.Log_Record{reply.Record})
+		if err == nil {
+			err = errs[0]
+		}
+		if err != nil {
+			s.log.Warnf("error accepting record of thread %s from %s: %s", id, pid, err)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package net
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/textileio/go-threads/core/thread"
+)
+
+// GapFillTimeout is the max time spent fetching the missing ancestors of a
+// buffered record.
+var GapFillTimeout = time.Second * 30
+
+// gapFills tracks the logs with missing ancestors being fetched, so that a
+// burst of out-of-order records results in one fetch per log at a time.
+// A gap found during a fetch is remembered and fetched next.
+type gapFills struct {
+	sync.Mutex
+	m map[logKey]*gapFill
+}
+
+type gapFill struct {
+	next cid.Cid
+	from peer.ID
+}
+
+func newGapFills() *gapFills {
+	return &gapFills{m: make(map[logKey]*gapFill)}
+}
+
+// start returns true if no fetch is running for the log. Otherwise, prev is
+// fetched from pid after the running fetch.
+func (g *gapFills) start(k logKey, prev cid.Cid, pid peer.ID) bool {
+	g.Lock()
+	defer g.Unlock()
+	if f, ok := g.m[k]; ok {
+		f.next, f.from = prev, pid
+		return false
+	}
+	g.m[k] = &gapFill{}
+	return true
+}
+
+// done returns the next gap to fetch in a log, ending its fetch if there is none.
+func (g *gapFills) done(k logKey) (cid.Cid, peer.ID, bool) {
+	g.Lock()
+	defer g.Unlock()
+	f := g.m[k]
+	if f == nil || !f.next.Defined() {
+		delete(g.m, k)
+		return cid.Undef, "", false
+	}
+	next, from := f.next, f.from
+	f.next, f.from = cid.Undef, ""
+	return next, from, true
+}
+
+// cancel ends the fetch of a log, dropping any remembered gap.
+func (g *gapFills) cancel(k logKey) {
+	g.Lock()
+	defer g.Unlock()
+	delete(g.m, k)
+}
+
+// fillGap fetches the records of a log missing up to prev from the peer that
+// sent a record building on them. The fetch runs in the background, and the
+// records waiting on the fetched ones are applied as they're put.
+func (s *server) fillGap(id thread.ID, lid peer.ID, prev cid.Cid, pid peer.ID) {
+	k := logKey{id: id, lid: lid}
+	if !s.gaps.start(k, prev, pid) {
+		return
+	}
+	spawned := s.net.spawn(id, func() {
+		for ok := true; ok; prev, pid, ok = s.gaps.done(k) {
+			if has, err := s.net.hasBlock(id, prev); err != nil || has {
+				continue
+			}
+			ctx, cancel := context.WithTimeout(s.net.ctx, GapFillTimeout)
+			if err := s.fetchMissingRecords(ctx, id, lid, prev, pid); err != nil {
+				s.log.Warnf("error fetching missing records of log %s from %s: %s", lid, pid, err)
+			}
+			cancel()
+		}
+	})
+	if !spawned {
+		s.gaps.cancel(k)
+	}
+}
@@ -0,0 +1,140 @@
+package net
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	core "github.com/textileio/go-threads/core/net"
+)
+
+var (
+	// DefaultUnreachableAfter is the default number of consecutive request
+	// failures after which a peer is considered unreachable.
+	DefaultUnreachableAfter = 3
+
+	// UnreachableBackoff is how long an unreachable peer is skipped before
+	// another request is attempted.
+	UnreachableBackoff = time.Minute
+)
+
+// errUnreachable indicates that a request was skipped because the peer's
+// breaker is open.
+var errUnreachable = fmt.Errorf("peer is unreachable")
+
+// peerHealth is a circuit breaker per peer. A peer's breaker opens after
+// threshold consecutive request failures, and requests to the peer are
+// skipped for the backoff. A single request is then let through as a probe,
+// which closes the breaker if it succeeds, or opens it again if it fails.
+type peerHealth struct {
+	sync.Mutex
+	threshold int
+	backoff   time.Duration
+	m         map[peer.ID]*failures
+	log       Logger
+}
+
+type failures struct {
+	count int
+	last  time.Time
+	// probed is when the probe in flight was let through, if any. Other
+	// requests are skipped until it completes, or for another backoff if it
+	// never reports back.
+	probed time.Time
+}
+
+// newPeerHealth returns a tracker that marks peers unreachable after threshold
+// consecutive failures, for backoff.
+func newPeerHealth(threshold int, backoff time.Duration, l Logger) *peerHealth {
+	if threshold <= 0 {
+		threshold = DefaultUnreachableAfter
+	}
+	if backoff <= 0 {
+		backoff = UnreachableBackoff
+	}
+	return &peerHealth{
+		threshold: threshold,
+		backoff:   backoff,
+		m:         make(map[peer.ID]*failures),
+		log:       l,
+	}
+}
+
+// failure records a failed request to a peer.
+func (h *peerHealth) failure(pid peer.ID) {
+	h.Lock()
+	defer h.Unlock()
+	f, ok := h.m[pid]
+	if !ok {
+		f = &failures{}
+		h.m[pid] = f
+	}
+	f.count++
+	f.last = time.Now()
+	if !f.probed.IsZero() {
+		f.probed = time.Time{}
+		h.log.Debugf("peer %s is still unreachable after a probe", pid)
+	} else if f.count == h.threshold {
+		h.log.Debugf("peer %s is unreachable after %d failures", pid, f.count)
+	}
+}
+
+// success records a successful request to a peer, resetting its failures.
+func (h *peerHealth) success(pid peer.ID) {
+	h.Lock()
+	defer h.Unlock()
+	delete(h.m, pid)
+}
+
+// unreachable returns whether or not requests to a peer should be skipped.
+// Once the backoff has passed, a single request is let through as a probe.
+func (h *peerHealth) unreachable(pid peer.ID) bool {
+	h.Lock()
+	defer h.Unlock()
+	f, ok := h.m[pid]
+	if !ok || f.count < h.threshold {
+		return false
+	}
+	since := f.last
+	if !f.probed.IsZero() {
+		since = f.probed
+	}
+	if time.Since(since) < h.backoff {
+		return true
+	}
+	f.probed = time.Now()
+	return false
+}
+
+// state returns the breaker state of a peer.
+func (h *peerHealth) state(f *failures) core.BreakerState {
+	switch {
+	case f.count < h.threshold:
+		return core.BreakerClosed
+	case !f.probed.IsZero() || time.Since(f.last) >= h.backoff:
+		return core.BreakerHalfOpen
+	default:
+		return core.BreakerOpen
+	}
+}
+
+// breakers returns the breakers of peers with failed requests.
+func (h *peerHealth) breakers() map[peer.ID]core.PeerBreaker {
+	h.Lock()
+	defer h.Unlock()
+	res := make(map[peer.ID]core.PeerBreaker, len(h.m))
+	for pid, f := range h.m {
+		res[pid] = core.PeerBreaker{
+			State:       h.state(f),
+			Failures:    f.count,
+			LastFailure: f.last,
+		}
+	}
+	return res
+}
+
+// PeerBreakers returns the circuit breakers of peers with failed requests.
+func (n *net) PeerBreakers() map[peer.ID]core.PeerBreaker {
+	return n.server.health.breakers()
+}
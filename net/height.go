@@ -0,0 +1,163 @@
+package net
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ipfs/go-cid"
+	format "github.com/ipfs/go-ipld-format"
+	"github.com/libp2p/go-libp2p-core/peer"
+	core "github.com/textileio/go-threads/core/net"
+	"github.com/textileio/go-threads/core/thread"
+)
+
+// ErrPartialHistory indicates that a log is only held in part locally, so not
+// all of the records in a height range are available.
+var ErrPartialHistory = fmt.Errorf("log history is incomplete")
+
+// heightKey returns the thread metadata key of the record at height in a log.
+func heightKey(lid peer.ID, height int64) string {
+	return fmt.Sprintf("height/%s/%d", lid, height)
+}
+
+// recordHeightKey returns the thread metadata key of a record's height.
+func recordHeightKey(rid cid.Cid) string {
+	return "recordHeight/" + rid.String()
+}
+
+// indexRecord stores the height of a record that was just appended to a log.
+// The first record in a log has height zero.
+func (n *net) indexRecord(id thread.ID, lid peer.ID, rec core.Record) error {
+	var height int64
+	if rec.PrevID().Defined() {
+		prev, err := n.store.GetInt64(id, recordHeightKey(rec.PrevID()))
+		if err != nil {
+			return err
+		}
+		if prev == nil {
+			// The log's history is incomplete, so heights are unknown
+			return nil
+		}
+		height = *prev + 1
+	}
+	if err := n.store.PutInt64(id, recordHeightKey(rec.Cid()), height); err != nil {
+		return err
+	}
+	return n.store.PutString(id, heightKey(lid, height), rec.Cid().String())
+}
+
+// recordAtHeight returns the cid of the record at height in a log.
+func (n *net) recordAtHeight(id thread.ID, lid peer.ID, height int64) (cid.Cid, error) {
+	v, err := n.store.GetString(id, heightKey(lid, height))
+	if err != nil {
+		return cid.Undef, err
+	}
+	if v == nil {
+		return cid.Undef, fmt.Errorf("record at height %d not found", height)
+	}
+	return cid.Decode(*v)
+}
+
+// logHeight returns the height of a record in a log, or -1 if the record is
+// not indexed as part of the log.
+func (n *net) logHeight(id thread.ID, lid peer.ID, rid cid.Cid) (int64, error) {
+	h, err := n.store.GetInt64(id, recordHeightKey(rid))
+	if err != nil || h == nil {
+		return -1, err
+	}
+	v, err := n.store.GetString(id, heightKey(lid, *h))
+	if err != nil || v == nil || *v != rid.String() {
+		return -1, err
+	}
+	return *h, nil
+}
+
+// headHeight returns the height of a log's head. A head that is not indexed,
+// e.g., because it arrived before the records it builds on, is indexed by
+// walking back to a record whose height is known. ErrPartialHistory is
+// returned if a record on the way is not held locally.
+func (n *net) headHeight(ctx context.Context, id thread.ID, lid peer.ID, head cid.Cid) (int64, error) {
+	var (
+		height int64 = -1
+		path   []cid.Cid
+	)
+	for cursor := head; cursor.Defined(); {
+		h, err := n.store.GetInt64(id, recordHeightKey(cursor))
+		if err != nil {
+			return 0, err
+		}
+		if h != nil {
+			height = *h
+			break
+		}
+		r, err := n.getRecord(ctx, id, cursor)
+		if err != nil {
+			if errors.Is(err, format.ErrNotFound) {
+				return 0, fmt.Errorf("%w: record %s not found", ErrPartialHistory, cursor)
+			}
+			return 0, err
+		}
+		path = append(path, cursor)
+		cursor = r.PrevID()
+	}
+	for i := len(path) - 1; i >= 0; i-- {
+		height++
+		if err := n.store.PutInt64(id, recordHeightKey(path[i]), height); err != nil {
+			return 0, err
+		}
+		if err := n.store.PutString(id, heightKey(lid, height), path[i].String()); err != nil {
+			return 0, err
+		}
+	}
+	return height, nil
+}
+
+// PullByHeight returns the records in a log with heights in the range [start, end).
+// The log is first pulled from the network so that the range is as complete as possible.
+// If the log is only held in part, the records that are held at the top of the
+// range are returned along with ErrPartialHistory.
+func (n *net) PullByHeight(ctx context.Context, id thread.ID, lid peer.ID, start, end int) ([]core.Record, error) {
+	if start < 0 || end <= start {
+		return nil, fmt.Errorf("invalid height range [%d, %d)", start, end)
+	}
+	if err := n.pullThread(ctx, id); err != nil {
+		return nil, err
+	}
+
+	lg, err := n.store.GetLog(id, lid)
+	if err != nil {
+		return nil, err
+	}
+	if !lg.Head.Defined() {
+		return nil, nil
+	}
+	top, err := n.headHeight(ctx, id, lid, lg.Head)
+	if err != nil {
+		return nil, err
+	}
+	if int64(end) > top+1 {
+		end = int(top + 1)
+	}
+	if start >= end {
+		return nil, nil
+	}
+
+	cursor, err := n.recordAtHeight(id, lid, int64(end-1))
+	if err != nil {
+		return nil, err
+	}
+	recs := make([]core.Record, end-start)
+	for i := len(recs) - 1; i >= 0; i-- {
+		r, err := n.getRecord(ctx, id, cursor)
+		if err != nil {
+			if errors.Is(err, format.ErrNotFound) {
+				return recs[i+1:], fmt.Errorf("%w: record at height %d not found", ErrPartialHistory, start+i)
+			}
+			return nil, err
+		}
+		recs[i] = r
+		cursor = r.PrevID()
+	}
+	return recs, nil
+}
@@ -0,0 +1,20 @@
+package net
+
+import (
+	"context"
+
+	core "github.com/textileio/go-threads/core/net"
+	"github.com/textileio/go-threads/core/thread"
+)
+
+// ThreadHook is called once a thread is established on the host, e.g., to
+// append initial access-control records with n.CreateRecord.
+type ThreadHook func(ctx context.Context, n core.Net, info thread.Info) error
+
+// runCreatedHook calls the configured thread creation hook, if any.
+func (n *net) runCreatedHook(ctx context.Context, info thread.Info) error {
+	if n.conf.OnThreadCreated == nil {
+		return nil
+	}
+	return n.conf.OnThreadCreated(ctx, n, info)
+}
@@ -0,0 +1,64 @@
+package net
+
+import (
+	"sync"
+
+	"github.com/ipfs/go-cid"
+)
+
+// inflight coalesces concurrent pushes of the same record, e.g., a record
+// added directly and echoed back over pubsub, so that it's encoded, signed,
+// and sent to each address once.
+type inflight struct {
+	sync.Mutex
+	m map[cid.Cid]*inflightPush
+}
+
+type inflightPush struct {
+	ready chan struct{}
+	p     *pushes
+	err   error
+}
+
+func newInflight() *inflight {
+	return &inflight{m: make(map[cid.Cid]*inflightPush)}
+}
+
+// do starts the pushes of a record, or returns the pushes already started
+// for it. A record stays in flight until all its pushes have completed.
+func (f *inflight) do(rid cid.Cid, start func() (*pushes, error)) (*pushes, error) {
+	f.Lock()
+	if c, ok := f.m[rid]; ok {
+		f.Unlock()
+		<-c.ready
+		return c.p, c.err
+	}
+	c := &inflightPush{ready: make(chan struct{})}
+	f.m[rid] = c
+	f.Unlock()
+
+	c.p, c.err = start()
+	close(c.ready)
+	if c.err != nil {
+		f.forget(rid)
+		return nil, c.err
+	}
+	go func() {
+		c.p.result()
+		f.forget(rid)
+	}()
+	return c.p, nil
+}
+
+// len returns the number of records in flight.
+func (f *inflight) len() int {
+	f.Lock()
+	defer f.Unlock()
+	return len(f.m)
+}
+
+func (f *inflight) forget(rid cid.Cid) {
+	f.Lock()
+	defer f.Unlock()
+	delete(f.m, rid)
+}
@@ -0,0 +1,116 @@
+package net
+
+import (
+	"context"
+
+	"github.com/ipfs/go-cid"
+	cbornode "github.com/ipfs/go-ipld-cbor"
+	"github.com/libp2p/go-libp2p-core/peer"
+	mh "github.com/multiformats/go-multihash"
+	"github.com/textileio/go-threads/cbor"
+	core "github.com/textileio/go-threads/core/net"
+	"github.com/textileio/go-threads/core/thread"
+)
+
+func init() {
+	cbornode.RegisterCborType(leaveBody{})
+}
+
+// leaveBody is the body of a leave record, a tombstone event that announces
+// that the owner of the log it's in left the thread.
+type leaveBody struct {
+	ThreadsLeave string
+}
+
+// leftKey returns the thread metadata key marking a log whose owner left the thread.
+func leftKey(lid peer.ID) string {
+	return "left/" + lid.String()
+}
+
+// LeaveThread appends a leave record to the host's log and pushes it to the
+// other members, so they stop dialing the host for the thread. The thread is
+// then deleted locally. If the host can't write to the thread, it is only
+// deleted locally.
+// The leave record is a regular record whose body names the log, so members
+// without the thread's read key, such as replicators, keep the log as is.
+func (n *net) LeaveThread(ctx context.Context, id thread.ID, opts ...core.ThreadOption) error {
+	args := &core.ThreadOptions{}
+	for _, opt := range opts {
+		opt(args)
+	}
+	if _, err := args.Token.Validate(n.getPrivKey()); err != nil {
+		return err
+	}
+
+	info, err := n.store.GetThread(id)
+	if err != nil {
+		return err
+	}
+	ownlg, err := n.getOwnLog(id)
+	if err != nil {
+		return err
+	}
+	if ownlg.PrivKey != nil && info.Key.CanRead() {
+		body, err := cbornode.WrapObject(&leaveBody{ThreadsLeave: ownlg.ID.String()}, mh.SHA2_256, -1)
+		if err != nil {
+			return err
+		}
+		rec, lg, err := n.appendOwnRecord(ctx, id, body, nil, false)
+		if err != nil {
+			return err
+		}
+		if _, err = n.server.pushRecord(ctx, id, lg.ID, rec, 0); err != nil {
+			n.log.Errorf("error pushing leave record to thread %s: %s", id, err)
+		}
+	}
+	return n.DeleteThread(ctx, id, opts...)
+}
+
+// isLeave returns whether or not a record in a log is a leave record. Records
+// that can't be read with the thread's read key are not considered leave
+// records.
+func (n *net) isLeave(ctx context.Context, id thread.ID, lid peer.ID, event *cbor.Event) (bool, error) {
+	rk, err := n.store.ReadKey(id)
+	if err != nil || rk == nil {
+		return false, err
+	}
+	body, err := event.GetBody(ctx, n.threadDAG(id), rk)
+	if err != nil {
+		return false, nil
+	}
+	var lb leaveBody
+	if err = cbornode.DecodeInto(body.RawData(), &lb); err != nil {
+		return false, nil
+	}
+	return lb.ThreadsLeave == lid.String(), nil
+}
+
+// memberLeft handles a leave record by forgetting the addresses of its log.
+// The log's records are kept.
+func (n *net) memberLeft(id thread.ID, lid peer.ID, rid cid.Cid) error {
+	n.log.Debugf("owner of log %s left thread %s", lid, id)
+	if err := n.store.PutString(id, leftKey(lid), rid.String()); err != nil {
+		return err
+	}
+	return n.store.ClearAddrs(id, lid)
+}
+
+// memberRejoined clears the mark of a log whose owner left the thread once
+// the log is written to again, so that its addresses are learned again.
+func (n *net) memberRejoined(id thread.ID, lid peer.ID) error {
+	left, err := n.hasLeft(id, lid)
+	if err != nil || !left {
+		return err
+	}
+	n.log.Debugf("owner of log %s rejoined thread %s", lid, id)
+	return n.store.PutString(id, leftKey(lid), "")
+}
+
+// hasLeft returns whether or not the owner of a log left the thread.
+func (n *net) hasLeft(id thread.ID, lid peer.ID) (bool, error) {
+	v, err := n.store.GetString(id, leftKey(lid))
+	if err != nil {
+		return false, err
+	}
+	return v != nil && *v != "", nil
+}
@@ -0,0 +1,26 @@
+package net
+
+import (
+	logging "github.com/ipfs/go-log"
+)
+
+// Logger is the interface through which a network logs. Loggers from
+// github.com/ipfs/go-log, including the default, satisfy it.
+type Logger interface {
+	Debug(args ...interface{})
+	Debugf(format string, args ...interface{})
+	Infof(format string, args ...interface{})
+	Warn(args ...interface{})
+	Warnf(format string, args ...interface{})
+	Error(args ...interface{})
+	Errorf(format string, args ...interface{})
+}
+
+// netLog is the "net" subsystem logger, registered up front so that its level
+// can be set before a network is created.
+var netLog = logging.Logger("net")
+
+// defaultLogger returns the logger of networks without Config.Logger.
+func defaultLogger() Logger {
+	return netLog
+}
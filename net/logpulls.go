@@ -0,0 +1,121 @@
+package net
+
+import (
+	"context"
+	"sync"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/textileio/go-threads/core/thread"
+)
+
+// logPulls runs the history pulls of new logs on a bounded number of
+// workers. A log that is queued or being pulled is not queued again.
+type logPulls struct {
+	sync.Mutex
+	pull    func(ctx context.Context, id thread.ID, lid peer.ID)
+	pending map[logKey]struct{}
+	running map[logKey]context.CancelFunc
+	queue   []logKey
+	ready   chan struct{}
+}
+
+func newLogPulls(pull func(ctx context.Context, id thread.ID, lid peer.ID)) *logPulls {
+	return &logPulls{
+		pull:    pull,
+		pending: make(map[logKey]struct{}),
+		running: make(map[logKey]context.CancelFunc),
+		ready:   make(chan struct{}, 1),
+	}
+}
+
+// start runs workers until ctx is done, which also cancels running pulls.
+func (p *logPulls) start(ctx context.Context, workers int) {
+	for i := 0; i < workers; i++ {
+		go p.work(ctx)
+	}
+}
+
+// enqueue queues a pull of a log's history, returning false if the log is
+// already queued or being pulled.
+func (p *logPulls) enqueue(id thread.ID, lid peer.ID) bool {
+	p.Lock()
+	defer p.Unlock()
+	k := logKey{id: id, lid: lid}
+	if _, ok := p.pending[k]; ok {
+		return false
+	}
+	p.pending[k] = struct{}{}
+	p.queue = append(p.queue, k)
+	p.signal()
+	return true
+}
+
+// idle returns whether no pulls are queued or running.
+func (p *logPulls) idle() bool {
+	p.Lock()
+	defer p.Unlock()
+	return len(p.pending) == 0
+}
+
+func (p *logPulls) work(ctx context.Context) {
+	for {
+		k, pctx, ok := p.next(ctx)
+		if !ok {
+			select {
+			case <-p.ready:
+				continue
+			case <-ctx.Done():
+				return
+			}
+		}
+		p.pull(pctx, k.id, k.lid)
+		p.Lock()
+		p.running[k]()
+		delete(p.running, k)
+		delete(p.pending, k)
+		p.Unlock()
+	}
+}
+
+// cancel removes a queued pull of a log, or cancels it if it's running.
+func (p *logPulls) cancel(id thread.ID, lid peer.ID) {
+	p.Lock()
+	defer p.Unlock()
+	k := logKey{id: id, lid: lid}
+	if cancel, ok := p.running[k]; ok {
+		cancel()
+		return
+	}
+	for i, q := range p.queue {
+		if q == k {
+			p.queue = append(p.queue[:i], p.queue[i+1:]...)
+			delete(p.pending, k)
+			return
+		}
+	}
+}
+
+// next pops the oldest queued pull and marks it running with a context
+// derived from ctx, waking another worker if more remain.
+func (p *logPulls) next(ctx context.Context) (logKey, context.Context, bool) {
+	p.Lock()
+	defer p.Unlock()
+	if len(p.queue) == 0 {
+		return logKey{}, nil, false
+	}
+	k := p.queue[0]
+	p.queue = p.queue[1:]
+	if len(p.queue) > 0 {
+		p.signal()
+	}
+	pctx, cancel := context.WithCancel(ctx)
+	p.running[k] = cancel
+	return k, pctx, true
+}
+
+func (p *logPulls) signal() {
+	select {
+	case p.ready <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,76 @@
+package net
+
+import (
+	"context"
+	"sync"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/textileio/go-threads/core/thread"
+)
+
+// TopicPeers returns the pubsub peers currently subscribed to the thread's topic.
+func (n *net) TopicPeers(id thread.ID) ([]peer.ID, error) {
+	return n.server.ps.Peers(id)
+}
+
+// ConnectMembers connects to all known thread members that are not already
+// subscribed to the thread's topic, which encourages them into the pubsub mesh.
+// Members that can't be reached are logged and skipped.
+func (n *net) ConnectMembers(ctx context.Context, id thread.ID) error {
+	info, err := n.store.GetThread(id)
+	if err != nil {
+		return err
+	}
+	subscribed, err := n.server.ps.Peers(id)
+	if err != nil {
+		return err
+	}
+	skip := make(map[peer.ID]struct{}, len(subscribed)+1)
+	skip[n.host.ID()] = struct{}{}
+	for _, p := range subscribed {
+		skip[p] = struct{}{}
+	}
+
+	members := make(map[peer.ID]struct{})
+	for _, lg := range info.Logs {
+		for _, addr := range lg.Addrs {
+			pid, err := peerIDFromAddr(addr)
+			if err != nil {
+				n.log.Error(err)
+				continue
+			}
+			if _, ok := skip[pid]; !ok {
+				members[pid] = struct{}{}
+			}
+		}
+	}
+
+	wg := sync.WaitGroup{}
+	for pid := range members {
+		wg.Add(1)
+		go func(pid peer.ID) {
+			defer wg.Done()
+			cctx, cancel := context.WithTimeout(ctx, DialTimeout)
+			defer cancel()
+			if err := n.host.Connect(cctx, n.host.Peerstore().PeerInfo(pid)); err != nil {
+				n.log.Warnf("error connecting to thread %s member %s: %s", id, pid, err)
+			}
+		}(pid)
+	}
+	wg.Wait()
+	return nil
+}
+
+// topicOversized returns whether or not the thread's topic has more peers than
+// the configured maximum, in which case records are only pushed directly.
+func (n *net) topicOversized(id thread.ID) bool {
+	max := n.settings.maxTopicPeers()
+	if max <= 0 {
+		return false
+	}
+	peers, err := n.server.ps.Peers(id)
+	if err != nil {
+		return false
+	}
+	return len(peers) > max
+}
@@ -0,0 +1,183 @@
+package net
+
+import (
+	"context"
+	"io"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+)
+
+// metrics instruments the network service and subscriptions. Methods on a
+// nil *metrics do nothing, so that nothing is recorded or allocated when
+// metrics are disabled.
+type metrics struct {
+	calls         *prometheus.CounterVec
+	latency       *prometheus.HistogramVec
+	clientCalls   *prometheus.CounterVec
+	clientLatency *prometheus.HistogramVec
+	recordSizes   prometheus.Histogram
+	subscriptions prometheus.Gauge
+}
+
+// newMetrics registers the network's collectors with r. A nil registerer
+// disables metrics.
+func newMetrics(r prometheus.Registerer) (*metrics, error) {
+	if r == nil {
+		return nil, nil
+	}
+	m := &metrics{
+		calls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "threads",
+			Subsystem: "net",
+			Name:      "rpc_calls_total",
+			Help:      "Number of service calls handled, by method and result.",
+		}, []string{"method", "result"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "threads",
+			Subsystem: "net",
+			Name:      "rpc_duration_seconds",
+			Help:      "Duration of service calls, by method.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method"}),
+		clientCalls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "threads",
+			Subsystem: "net",
+			Name:      "rpc_client_calls_total",
+			Help:      "Number of calls made to peers, by method and result.",
+		}, []string{"method", "result"}),
+		clientLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "threads",
+			Subsystem: "net",
+			Name:      "rpc_client_duration_seconds",
+			Help:      "Duration of calls made to peers, by method.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method"}),
+		recordSizes: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: "threads",
+			Subsystem: "net",
+			Name:      "pushed_record_bytes",
+			Help:      "Size of records pushed by peers.",
+			Buckets:   prometheus.ExponentialBuckets(256, 4, 8),
+		}),
+		subscriptions: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "threads",
+			Subsystem: "net",
+			Name:      "subscriptions",
+			Help:      "Number of active record subscriptions.",
+		}),
+	}
+	for _, c := range []prometheus.Collector{m.calls, m.latency, m.clientCalls, m.clientLatency, m.recordSizes, m.subscriptions} {
+		if err := r.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// serverOptions returns the interceptors that instrument service calls.
+func (m *metrics) serverOptions() []grpc.ServerOption {
+	if m == nil {
+		return nil
+	}
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			start := time.Now()
+			res, err := handler(ctx, req)
+			m.observeCall(info.FullMethod, start, err)
+			return res, err
+		}),
+		grpc.ChainStreamInterceptor(func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			start := time.Now()
+			err := handler(srv, ss)
+			m.observeCall(info.FullMethod, start, err)
+			return err
+		}),
+	}
+}
+
+// dialOptions returns the interceptors that instrument calls to peers.
+// Streams are measured until they end.
+func (m *metrics) dialOptions() []grpc.DialOption {
+	if m == nil {
+		return nil
+	}
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			start := time.Now()
+			err := invoker(ctx, method, req, reply, cc, opts...)
+			m.observeClientCall(method, start, err)
+			return err
+		}),
+		grpc.WithChainStreamInterceptor(func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			start := time.Now()
+			cs, err := streamer(ctx, desc, cc, method, opts...)
+			if err != nil {
+				m.observeClientCall(method, start, err)
+				return nil, err
+			}
+			return &measuredStream{ClientStream: cs, end: func(err error) {
+				m.observeClientCall(method, start, err)
+			}}, nil
+		}),
+	}
+}
+
+// measuredStream reports the end of a client stream, i.e., the first error
+// received from it, io.EOF meaning success.
+type measuredStream struct {
+	grpc.ClientStream
+	once sync.Once
+	end  func(error)
+}
+
+func (s *measuredStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err != nil {
+		s.once.Do(func() {
+			if err == io.EOF {
+				s.end(nil)
+			} else {
+				s.end(err)
+			}
+		})
+	}
+	return err
+}
+
+func (m *metrics) observeCall(fullMethod string, start time.Time, err error) {
+	observe(m.calls, m.latency, fullMethod, start, err)
+}
+
+func (m *metrics) observeClientCall(fullMethod string, start time.Time, err error) {
+	observe(m.clientCalls, m.clientLatency, fullMethod, start, err)
+}
+
+func observe(calls *prometheus.CounterVec, latency *prometheus.HistogramVec, fullMethod string, start time.Time, err error) {
+	method := path.Base(fullMethod)
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	calls.WithLabelValues(method, result).Inc()
+	latency.WithLabelValues(method).Observe(time.Since(start).Seconds())
+}
+
+// observeRecordSize records the size of a record pushed by a peer.
+func (m *metrics) observeRecordSize(size int) {
+	if m == nil {
+		return
+	}
+	m.recordSizes.Observe(float64(size))
+}
+
+// addSubscriptions adjusts the number of active subscriptions by delta.
+func (m *metrics) addSubscriptions(delta int) {
+	if m == nil {
+		return
+	}
+	m.subscriptions.Add(float64(delta))
+}
@@ -11,33 +11,53 @@ import (
 	"time"
 
 	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-datastore"
 	bs "github.com/ipfs/go-ipfs-blockstore"
 	format "github.com/ipfs/go-ipld-format"
 	logging "github.com/ipfs/go-log"
 	"github.com/libp2p/go-libp2p-core/crypto"
 	"github.com/libp2p/go-libp2p-core/host"
 	"github.com/libp2p/go-libp2p-core/peer"
-	pstore "github.com/libp2p/go-libp2p-core/peerstore"
+	"github.com/libp2p/go-libp2p-core/protocol"
 	gostream "github.com/libp2p/go-libp2p-gostream"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
 	ma "github.com/multiformats/go-multiaddr"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/textileio/go-threads/broadcast"
 	"github.com/textileio/go-threads/cbor"
 	"github.com/textileio/go-threads/core/app"
 	lstore "github.com/textileio/go-threads/core/logstore"
 	core "github.com/textileio/go-threads/core/net"
 	"github.com/textileio/go-threads/core/thread"
-	sym "github.com/textileio/go-threads/crypto/symmetric"
 	pb "github.com/textileio/go-threads/net/pb"
 	"github.com/textileio/go-threads/util"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 var (
-	log = logging.Logger("net")
-
-	// MaxPullLimit is the maximum page size for pulling records.
+	// MaxPullLimit is the maximum page size for pulling records, and the
+	// default of Config.MaxPullLimit.
 	MaxPullLimit = 10000
 
+	// MaxGetLogsBatchSize is the maximum number of threads in a batch get logs request.
+	MaxGetLogsBatchSize = 100
+
+	// MaxPushBatchSize is the maximum number of records in a batch push records request.
+	MaxPushBatchSize = 100
+
+	// MaxConns is the maximum number of cached peer connections. The least
+	// recently used connection is closed when the limit is reached.
+	MaxConns = 256
+
+	// DefaultLogPullWorkers is the default number of history pulls of new
+	// logs run at once.
+	DefaultLogPullWorkers = 8
+
+	// HistoryPullTimeout bounds the history pull of a new log.
+	HistoryPullTimeout = time.Minute
+
 	// InitialPullInterval is the interval between automatic log pulls.
 	InitialPullInterval = time.Second
 
@@ -56,12 +76,17 @@ var (
 
 // net is an implementation of core.DBNet.
 type net struct {
+	dropped uint64 // First for 64-bit alignment of atomic operations
+
 	format.DAGService
 	host   host.Host
 	bstore bs.Blockstore
 
 	store lstore.Logstore
 
+	conf Config
+	log  Logger
+
 	rpc    *grpc.Server
 	server *server
 	bus    *broadcast.Broadcaster
@@ -71,11 +96,272 @@ type net struct {
 
 	pullLock  sync.Mutex
 	pullLocks map[thread.ID]chan struct{}
+
+	orphans  *orphans
+	routines *routines
+	settings *settings
+	limiter  *logLimiter
+
+	reconnects *reconnects
+	logPulls   *logPulls
+	metrics    *metrics
+	tracer     *tracer
 }
 
 // Config is used to specify thread instance options.
 type Config struct {
 	Debug bool
+
+	// ConnHighWater is the number of open host connections at which the network
+	// is considered to be under connection pressure. Under pressure, automatic
+	// pulls are deferred and records are only pulled from connected peers.
+	// Pushes to peers that are not connected are queued for retry, unless the
+	// caller waits for replicas, and at most PressurePushConcurrency pushes
+	// run at once. Zero disables load shedding.
+	ConnHighWater int
+
+	// PressurePushConcurrency is the max number of direct record pushes run
+	// at once under connection pressure. Zero means DefaultPressurePushConcurrency.
+	PressurePushConcurrency int
+
+	// ReplicationFilter, if set, decides which record bodies received from
+	// other peers are stored locally. See ReplicationFilter for more.
+	ReplicationFilter ReplicationFilter
+
+	// ReadRepair enables pushing records back to peers that were found to be
+	// missing them while pulling.
+	ReadRepair bool
+
+	// FetchMissingLogs enables requesting an unknown log from the sender of a
+	// record, so that the record can be accepted instead of waiting for the
+	// sender to push the log or for the next pull. The records in the log that
+	// the pushed record builds on are then fetched in the background.
+	FetchMissingLogs bool
+
+	// UnreachableAfter is the number of consecutive request failures after
+	// which a peer's circuit breaker opens, and the peer is skipped by pulls
+	// and pushes for UnreachableBackoff. Zero uses DefaultUnreachableAfter.
+	UnreachableAfter int
+
+	// UnreachableBackoff is how long a peer is skipped after its circuit
+	// breaker opens, before a single request is let through as a probe.
+	// Zero uses the package var UnreachableBackoff.
+	UnreachableBackoff time.Duration
+
+	// EventPolicy determines what happens when a subscriber doesn't keep up
+	// with incoming records. See EventPolicy for more.
+	EventPolicy EventPolicy
+
+	// EventBuffer is the number of records buffered for each subscriber.
+	// The drop policies buffer at least one record.
+	EventBuffer int
+
+	// ThreadIntroducers, if not empty, limits the peers that may push logs for
+	// threads the host doesn't know about yet. Peers not listed can still push
+	// logs for existing threads.
+	ThreadIntroducers []peer.ID
+
+	// AddrTTLPolicy, if set, returns the TTL of log addresses by how they were
+	// learned. By default, all addresses are permanent.
+	AddrTTLPolicy AddrTTLPolicy
+
+	// PushExpiredHandler, if set, is called when a record that could not be
+	// pushed to a peer is dropped after PushRetryTTL, or evicted from a full
+	// queue.
+	PushExpiredHandler PushExpiredHandler
+
+	// PushRetryTTL is how long a record that could not be pushed to a peer
+	// is retried. Defaults to MaxPushRetryAge.
+	PushRetryTTL time.Duration
+
+	// OutboundQueueLimit is the max number of records queued for a peer that
+	// could not be pushed to it. When a peer's queue is full, its oldest
+	// records are dropped to make room. Defaults to MaxQueuedPushes.
+	OutboundQueueLimit int
+
+	// OutboundStore, if set, persists records that could not be pushed to
+	// peers, so that they are still delivered after a restart.
+	OutboundStore datastore.Datastore
+
+	// Metrics, if set, registers collectors for service calls, calls made to
+	// peers, pushed record sizes, and subscriptions. Nothing is recorded if
+	// nil.
+	Metrics prometheus.Registerer
+
+	// TracerProvider, if set, provides the tracer of spans for pushes and
+	// pulls of records, and for the handling of peer requests. Span contexts
+	// are sent to peers in request metadata. Nothing is traced if nil.
+	TracerProvider trace.TracerProvider
+
+	// MaxTopicPeers is the number of thread topic peers above which records
+	// are no longer published to the topic, relying on direct pushes to log
+	// addresses instead. Zero means no limit.
+	MaxTopicPeers int
+
+	// LocalOnly disables all networking. Threads and records are only read
+	// from and written to the local stores, and operations that require
+	// another peer fail with ErrLocalOnly.
+	LocalOnly bool
+
+	// OnThreadCreated, if set, is called after a thread is created or added
+	// on the host, or introduced to the host by a peer. An error from the hook
+	// fails and rolls back CreateThread and AddThread.
+	OnThreadCreated ThreadHook
+
+	// MaxThreadGoroutines caps the number of background goroutines, e.g.,
+	// auto-pulls and read-repairs, that may run at once on behalf of a single
+	// thread. Work that would exceed the cap is skipped. Zero means no limit.
+	MaxThreadGoroutines int
+
+	// CompactRecords enables the compact record format for pulls, which omits
+	// event nodes that can be rebuilt by the receiver. It is only used when
+	// both peers enable it.
+	CompactRecords bool
+
+	// LogRateLimit bounds the number of new records each log may receive by
+	// push per interval. Records over the limit are rejected, leaving them to
+	// be retried by the sender or pulled later.
+	LogRateLimit LogRateLimit
+
+	// DecodeLimits bound the resources used to decode records received from
+	// peers. Unset limits use cbor.DefaultDecodeLimits.
+	DecodeLimits cbor.DecodeLimits
+
+	// MaxRecordSize is the maximum size in bytes of the event body of a
+	// received record. Larger records are rejected before they are stored.
+	// Zero means no limit.
+	MaxRecordSize int
+
+	// MaxPullLimit is the max number of records per log sent in reply to a
+	// pull request. Replies to requests for more are truncated, and the
+	// requester continues from the last record sent. Zero means MaxPullLimit.
+	MaxPullLimit int
+
+	// PeerRateLimit bounds the rate of records accepted from each peer,
+	// directly or over pubsub. Pushes over the limit are rejected with
+	// codes.ResourceExhausted.
+	PeerRateLimit PeerRateLimit
+
+	// RecordValidators are applied in order to records pushed to the host,
+	// directly or over pubsub, after their signature is verified and before
+	// LogRateLimit is applied. See RecordValidator for more.
+	RecordValidators []RecordValidator
+
+	// DAGResolver, if set, selects the DAG service in which each thread's
+	// records are kept. Threads without a resolved DAG service use the shared
+	// DAG service. See DAGResolver for more.
+	DAGResolver DAGResolver
+
+	// LogPullWorkers is the number of history pulls of new logs, e.g., logs
+	// pushed by peers, run at once. Further pulls are queued.
+	// Zero means DefaultLogPullWorkers.
+	LogPullWorkers int
+
+	// TransportCredentials, if set, secure gRPC connections to peers on top
+	// of the libp2p stream, e.g., with TLS. Peers must be started with
+	// matching server credentials, given as a grpc.Creds server option.
+	// By default, connections rely on the libp2p stream's security alone.
+	TransportCredentials credentials.TransportCredentials
+
+	// AllowPrivateAddrs includes the host's loopback and private network
+	// addresses in the log addresses advertised to peers, e.g., for peers on
+	// the same LAN. By default, only addresses that are publicly dialable,
+	// including relay addresses, are advertised.
+	AllowPrivateAddrs bool
+
+	// Protocols are the thread protocol versions served and dialed by the
+	// host, newest first, e.g., to keep a host on older versions. Peers use
+	// the first version in the dialing host's list that both support, and
+	// fail to connect if there is none. Empty means thread.Protocols.
+	Protocols []protocol.ID
+
+	// PeerResolver, if set, is consulted for the addresses of thread members
+	// that are not known to the host when they are dialed, so that members
+	// whose addresses were lost can be rediscovered. See RoutingResolver.
+	PeerResolver PeerResolver
+
+	// RekeyCutover bounds how long after a thread is rekeyed records
+	// encrypted with the replaced service-keys are accepted from peers,
+	// including records pulled from them. Records stored locally remain
+	// readable. Zero means replaced keys are accepted indefinitely.
+	RekeyCutover time.Duration
+
+	// RequestTimeout is the max time to wait for a request to a peer.
+	// Requests made with a context that has an earlier deadline end at the
+	// deadline instead. Record streams apply the timeout to each message
+	// rather than the whole stream. Zero means DialTimeout.
+	RequestTimeout time.Duration
+
+	// MaxSubscriptions is the max number of thread topics subscribed at once.
+	// The topics of the least recently active threads are left as needed,
+	// and joined again when a record is published to them. Zero means no limit.
+	MaxSubscriptions int
+
+	// PubSub is an existing pubsub instance to use for thread topics. If nil,
+	// a new instance with PubSubRouter is started on the host. The network
+	// joins a topic named by each thread ID, so applications sharing the
+	// instance must use other topic names. Close leaves the network's topics,
+	// but the instance keeps running.
+	PubSub *pubsub.PubSub
+
+	// PubSubRouter selects the router of the pubsub instance started when
+	// PubSub is nil. Defaults to GossipSubRouter.
+	PubSubRouter PubSubRouter
+
+	// MaxPubSubMessageSize is the max size in bytes of a record push that is
+	// published to a thread topic. Larger records are only pushed directly to
+	// log addresses. Zero means DefaultMaxPubSubMessageSize.
+	MaxPubSubMessageSize int
+
+	// SubscribeWorkers is the number of workers handling the records received
+	// on each thread topic. Records in the same log are always handled by the
+	// same worker, in the order they were received.
+	// Zero means DefaultSubscribeWorkers.
+	SubscribeWorkers int
+
+	// SubscribeQueueSize is the number of received records each worker
+	// buffers before the topic stops reading new messages.
+	// Zero means DefaultSubscribeQueueSize.
+	SubscribeQueueSize int
+
+	// PublishTimeout is the max time to wait for a record to be published to
+	// a thread topic. The caller's deadline applies if it is sooner.
+	// Zero means DefaultPublishTimeout, and a negative value disables it, so
+	// that publishing only ends with the caller's context.
+	PublishTimeout time.Duration
+
+	// SubscribeRetryInterval is the delay before retrying to join a thread
+	// topic that could not be joined. The delay doubles with each failed
+	// attempt, up to MaxSubscribeRetryInterval.
+	// Zero means DefaultSubscribeRetryInterval.
+	SubscribeRetryInterval time.Duration
+
+	// MaxSubscribeRetryInterval is the max delay between attempts to join a
+	// thread topic or to replace a closed subscription.
+	// Zero means DefaultMaxSubscribeRetryInterval.
+	MaxSubscribeRetryInterval time.Duration
+
+	// SubscribeErrorLimit is the number of consecutive errors reading a thread
+	// topic after which the subscription is considered closed and replaced.
+	// Zero means DefaultSubscribeErrorLimit.
+	SubscribeErrorLimit int
+
+	// ResubscribeInterval is the delay before replacing a closed subscription.
+	// The delay doubles with each failed attempt, up to
+	// MaxSubscribeRetryInterval. Zero means DefaultResubscribeInterval.
+	ResubscribeInterval time.Duration
+
+	// Logger, if set, receives the network's logs. Defaults to the "net"
+	// subsystem logger of github.com/ipfs/go-log.
+	Logger Logger
+
+	// MaxOrphans is the max number of pushed records held while waiting for
+	// their ancestors. Zero means DefaultMaxOrphans.
+	MaxOrphans int
+
+	// OrphanTTL is how long a pushed record is held while waiting for its
+	// ancestors. Zero means DefaultOrphanTTL.
+	OrphanTTL time.Duration
 }
 
 // NewNetwork creates an instance of net from the given host and thread store.
@@ -90,39 +376,76 @@ func NewNetwork(ctx context.Context, h host.Host, bstore bs.Blockstore, ds forma
 		}
 	}
 
+	m, err := newMetrics(conf.Metrics)
+	if err != nil {
+		return nil, err
+	}
+	tr := newTracer(conf.TracerProvider)
+	opts = append(keepaliveServerOptions(), opts...)
+	opts = append(opts, m.serverOptions()...)
+	opts = append(opts, tr.serverOptions()...)
+
 	ctx, cancel := context.WithCancel(ctx)
 	t := &net{
 		DAGService: ds,
 		host:       h,
 		bstore:     bstore,
 		store:      ls,
+		conf:       conf,
 		rpc:        grpc.NewServer(opts...),
 		bus:        broadcast.NewBroadcaster(0),
 		ctx:        ctx,
 		cancel:     cancel,
 		pullLocks:  make(map[thread.ID]chan struct{}),
+		orphans:    newOrphans(conf.MaxOrphans, conf.OrphanTTL),
+		routines:   newRoutines(),
+		settings:   newSettings(conf),
+		limiter:    newLogLimiter(),
+
+		metrics: m,
+		tracer:  tr,
 	}
+	if t.log = conf.Logger; t.log == nil {
+		t.log = defaultLogger()
+	}
+	t.logPulls = newLogPulls(t.updateRecordsFromLog)
 	t.server, err = newServer(t)
 	if err != nil {
 		return nil, err
 	}
 
-	listener, err := gostream.Listen(h, thread.Protocol)
-	if err != nil {
-		return nil, err
+	if conf.LocalOnly {
+		return t, nil
 	}
-	go func() {
-		pb.RegisterServiceServer(t.rpc, t.server)
-		if err := t.rpc.Serve(listener); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
-			log.Fatalf("serve error: %v", err)
+
+	pb.RegisterServiceServer(t.rpc, t.server)
+	for _, p := range t.protocols() {
+		listener, err := gostream.Listen(h, p)
+		if err != nil {
+			return nil, err
 		}
-	}()
+		go func() {
+			if err := t.rpc.Serve(listener); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+				t.log.Errorf("serve error: %v", err)
+			}
+		}()
+	}
 
 	go t.startPulling()
+	go t.server.startRetryingPushes()
+	go t.server.startCheckingConns()
+	t.server.startFollowers()
+	workers := conf.LogPullWorkers
+	if workers <= 0 {
+		workers = DefaultLogPullWorkers
+	}
+	t.logPulls.start(ctx, workers)
+	t.startReconnectSync()
 	return t, nil
 }
 
 func (n *net) Close() (err error) {
+	n.stopReconnectSync()
 	n.pullLock.Lock()
 	defer n.pullLock.Unlock()
 	// Wait for all thread pulls to finish
@@ -133,11 +456,8 @@ func (n *net) Close() (err error) {
 	// Close peer connections and shutdown the server
 	n.server.Lock()
 	defer n.server.Unlock()
-	for _, c := range n.server.conns {
-		if err = c.Close(); err != nil {
-			log.Errorf("error closing connection: %v", err)
-		}
-	}
+	n.server.conns.closeAll()
+	n.server.followers.closeAll() // Subscribe streams would hold up the stop
 	n.rpc.GracefulStop()
 
 	var errs []error
@@ -148,6 +468,7 @@ func (n *net) Close() (err error) {
 			}
 		}
 	}
+	weakClose("pubsub", n.server.ps)
 	weakClose("DAGService", n.DAGService)
 	weakClose("host", n.host)
 	weakClose("threadstore", n.store)
@@ -164,6 +485,15 @@ func (n *net) Host() host.Host {
 	return n.host
 }
 
+// PubSub returns the pubsub instance used for thread topics.
+// Returns nil in local-only mode.
+func (n *net) PubSub() *pubsub.PubSub {
+	if n.server.ps == nil {
+		return nil
+	}
+	return n.server.ps.ps
+}
+
 func (n *net) Store() lstore.Logstore {
 	return n.store
 }
@@ -190,7 +520,7 @@ func (n *net) GetToken(ctx context.Context, identity thread.Identity) (tok threa
 	return thread.NewToken(n.getPrivKey(), key)
 }
 
-func (n *net) CreateThread(_ context.Context, id thread.ID, opts ...core.NewThreadOption) (info thread.Info, err error) {
+func (n *net) CreateThread(ctx context.Context, id thread.ID, opts ...core.NewThreadOption) (info thread.Info, err error) {
 	args := &core.NewThreadOptions{}
 	for _, opt := range opts {
 		opt(args)
@@ -201,7 +531,7 @@ func (n *net) CreateThread(_ context.Context, id thread.ID, opts ...core.NewThre
 		return
 	}
 	if identity != nil {
-		log.Debugf("creating thread with identity: %s", identity)
+		n.log.Debugf("creating thread with identity: %s", identity)
 	}
 
 	if err = n.ensureUnique(id); err != nil {
@@ -228,7 +558,16 @@ func (n *net) CreateThread(_ context.Context, id thread.ID, opts ...core.NewThre
 	if err = n.server.ps.Add(id); err != nil {
 		return
 	}
-	return n.getThreadWithAddrs(id)
+	if info, err = n.getThreadWithAddrs(id); err != nil {
+		return
+	}
+	if err = n.runCreatedHook(ctx, info); err != nil {
+		if err := n.deleteThread(ctx, id); err != nil {
+			n.log.Errorf("error rolling back thread %s: %s", id, err)
+		}
+		return thread.Info{}, err
+	}
+	return info, nil
 }
 
 func (n *net) ensureUnique(id thread.ID) error {
@@ -293,14 +632,23 @@ func (n *net) AddThread(ctx context.Context, addr ma.Multiaddr, opts ...core.New
 		return
 	}
 	for _, l := range lgs {
-		if err = n.createExternalLogIfNotExist(id, l.ID, l.PubKey, l.PrivKey, l.Addrs); err != nil {
+		if err = n.addExternalLog(id, l, AddrSourcePulled, addri.ID); err != nil {
 			return
 		}
 	}
 	if err = n.server.ps.Add(id); err != nil {
 		return
 	}
-	return n.getThreadWithAddrs(id)
+	if info, err = n.getThreadWithAddrs(id); err != nil {
+		return
+	}
+	if err = n.runCreatedHook(ctx, info); err != nil {
+		if err := n.deleteThread(ctx, id); err != nil {
+			n.log.Errorf("error rolling back thread %s: %s", id, err)
+		}
+		return thread.Info{}, err
+	}
+	return info, nil
 }
 
 func (n *net) GetThread(_ context.Context, id thread.ID, opts ...core.ThreadOption) (info thread.Info, err error) {
@@ -330,7 +678,7 @@ func (n *net) getThreadWithAddrs(id thread.ID) (info thread.Info, err error) {
 	if err != nil {
 		return
 	}
-	addrs := n.host.Addrs()
+	addrs := n.hostAddrs()
 	res := make([]ma.Multiaddr, len(addrs))
 	for i := range addrs {
 		res[i] = addrs[i].Encapsulate(peerID).Encapsulate(threadID)
@@ -363,7 +711,7 @@ func (n *net) PullThread(ctx context.Context, id thread.ID, opts ...core.ThreadO
 }
 
 func (n *net) pullThread(ctx context.Context, id thread.ID) error {
-	log.Debugf("pulling thread %s...", id)
+	n.log.Debugf("pulling thread %s...", id)
 	ptl := n.getThreadSemaphore(id)
 	select {
 	case ptl <- struct{}{}:
@@ -374,7 +722,7 @@ func (n *net) pullThread(ctx context.Context, id thread.ID) error {
 		}
 		<-ptl
 	default:
-		log.Warnf("pull thread %s ignored since already being pulled", id)
+		n.log.Warnf("pull thread %s ignored since already being pulled", id)
 	}
 	return nil
 }
@@ -382,9 +730,22 @@ func (n *net) pullThread(ctx context.Context, id thread.ID) error {
 // pullThreadUnsafe for new records.
 // This method is internal and *not* thread-safe. It assumes we currently own the thread-lock.
 func (n *net) pullThreadUnsafe(ctx context.Context, id thread.ID) error {
+	for {
+		more, err := n.pullThreadPage(ctx, id)
+		if err != nil || !more {
+			return err
+		}
+	}
+}
+
+// pullThreadPage pulls new records in each log of a thread, up to the pull
+// limit. It returns whether a peer truncated a log and a log was advanced,
+// in which case another page can be pulled.
+// This method is internal and *not* thread-safe. It assumes we currently own the thread-lock.
+func (n *net) pullThreadPage(ctx context.Context, id thread.ID) (bool, error) {
 	info, err := n.store.GetThread(id)
 	if err != nil {
-		return err
+		return false, err
 	}
 
 	// Gather offsets for each log
@@ -392,9 +753,9 @@ func (n *net) pullThreadUnsafe(ctx context.Context, id thread.ID) error {
 	for _, lg := range info.Logs {
 		var has bool
 		if lg.Head.Defined() {
-			has, err = n.bstore.Has(lg.Head)
+			has, err = n.hasBlock(id, lg.Head)
 			if err != nil {
-				return err
+				return false, err
 			}
 		}
 		if has {
@@ -403,37 +764,55 @@ func (n *net) pullThreadUnsafe(ctx context.Context, id thread.ID) error {
 			offsets[lg.ID] = cid.Undef
 		}
 	}
-	var lock sync.Mutex
-	var fetchedRcs []map[peer.ID][]core.Record
-	wg := sync.WaitGroup{}
+	// Pull all logs from each peer at once, since a single request covers every log
+	var addrs []ma.Multiaddr
+	seen := make(map[peer.ID]struct{})
 	for _, lg := range info.Logs {
-		wg.Add(1)
-		go func(lg thread.LogInfo) {
-			defer wg.Done()
-			// Pull from addresses
-			recs, err := n.server.getRecords(ctx, id, lg.ID, offsets, MaxPullLimit)
+		for _, addr := range lg.Addrs {
+			p, err := peerIDFromAddr(addr)
 			if err != nil {
-				log.Error(err)
-				return
+				n.log.Error(err)
+				continue
 			}
-			lock.Lock()
-			fetchedRcs = append(fetchedRcs, recs)
-			lock.Unlock()
-		}(lg)
-	}
-	wg.Wait()
-	for _, recs := range fetchedRcs {
-		for lid, rs := range recs {
-			for _, r := range rs {
-				if err = n.putRecord(ctx, id, lid, r); err != nil {
-					log.Error(err)
-					return err
-				}
+			if _, ok := seen[p]; ok {
+				continue
 			}
+			seen[p] = struct{}{}
+			addrs = append(addrs, addr)
+		}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	recs, err := n.server.streamRecordsFromAddrs(ctx, id, addrs, offsets, n.server.pullLimit())
+	if err != nil {
+		return false, err
+	}
+	var truncated bool
+	for r := range recs {
+		if r.rec == nil {
+			truncated = true
+			continue
 		}
+		if err = n.putRecord(ctx, id, r.lid, r.rec); err != nil {
+			n.log.Error(err)
+			return false, err
+		}
+	}
+	if !truncated {
+		return false, nil
 	}
 
-	return nil
+	// Another page is only worth pulling if this one advanced a log
+	info, err = n.store.GetThread(id)
+	if err != nil {
+		return false, err
+	}
+	for _, lg := range info.Logs {
+		if lg.Head.Defined() && !lg.Head.Equals(offsets[lg.ID]) {
+			return true, nil
+		}
+	}
+	return false, nil
 }
 
 func (n *net) DeleteThread(ctx context.Context, id thread.ID, opts ...core.ThreadOption) error {
@@ -445,7 +824,7 @@ func (n *net) DeleteThread(ctx context.Context, id thread.ID, opts ...core.Threa
 		return err
 	}
 
-	log.Debugf("deleting thread %s...", id)
+	n.log.Debugf("deleting thread %s...", id)
 	ptl := n.getThreadSemaphore(id)
 	select {
 	case ptl <- struct{}{}: // Must block in case the thread is being pulled
@@ -460,10 +839,12 @@ func (n *net) DeleteThread(ctx context.Context, id thread.ID, opts ...core.Threa
 }
 
 // deleteThread cleans up all the persistent and in-memory bits of a thread. This includes:
-// - Removing all record and event nodes.
-// - Deleting all logstore keys, addresses, and heads.
+// - Removing all record and event nodes that are held locally.
+// - Deleting all logstore keys, addresses, heads, and metadata.
 // - Cancelling the pubsub subscription and topic.
-// Local subscriptions will not be cancelled and will simply stop reporting.
+// - Dropping held out-of-order records and queued pushes.
+// Local subscriptions will not be cancelled. Those that asked for deletions
+// are notified. Deleting an unknown thread is a no-op.
 // This method is internal and *not* thread-safe. It assumes we currently own the thread-lock.
 func (n *net) deleteThread(ctx context.Context, id thread.ID) error {
 	if err := n.server.ps.Remove(id); err != nil {
@@ -471,20 +852,26 @@ func (n *net) deleteThread(ctx context.Context, id thread.ID) error {
 	}
 
 	info, err := n.store.GetThread(id)
-	if err != nil {
+	if errors.Is(err, lstore.ErrThreadNotFound) {
+		return nil
+	} else if err != nil {
 		return err
 	}
 	for _, lg := range info.Logs { // Walk logs, removing record and event nodes
-		head := lg.Head
-		for head.Defined() {
-			head, err = n.deleteRecord(ctx, head, info.Key.Service())
-			if err != nil {
-				return err
-			}
+		if err = n.deleteLogRecords(ctx, id, lg.ID); err != nil {
+			return err
 		}
 	}
 
-	return n.store.DeleteThread(id) // Delete logstore keys, addresses, and heads
+	if err = n.store.DeleteThread(id); err != nil { // Delete logstore keys, addresses, heads, and metadata
+		return err
+	}
+	n.orphans.drop(id)
+	n.server.outbound.drop(id)
+	if err = n.bus.SendWithTimeout(&threadDeletion{threadID: id}, notifyTimeout); err != nil {
+		n.log.Warnf("error notifying deletion of thread %s: %s", id, err)
+	}
+	return nil
 }
 
 func (n *net) AddReplicator(ctx context.Context, id thread.ID, paddr ma.Multiaddr, opts ...core.ThreadOption) (pid peer.ID, err error) {
@@ -520,7 +907,7 @@ func (n *net) AddReplicator(ctx context.Context, id thread.ID, paddr ma.Multiadd
 	if err != nil {
 		return
 	}
-	if err = n.store.AddAddr(info.ID, ownlg.ID, addr, pstore.PermanentAddrTTL); err != nil {
+	if err = n.store.AddAddr(info.ID, ownlg.ID, addr, n.addrTTL(AddrSourceDirect)); err != nil {
 		return
 	}
 	info, err = n.store.GetThread(info.ID) // Update info
@@ -531,16 +918,16 @@ func (n *net) AddReplicator(ctx context.Context, id thread.ID, paddr ma.Multiadd
 	// Update peerstore address
 	dialable, err := getDialable(paddr)
 	if err == nil {
-		n.host.Peerstore().AddAddr(pid, dialable, pstore.PermanentAddrTTL)
+		n.host.Peerstore().AddAddr(pid, dialable, n.addrTTL(AddrSourceDirect))
 	} else {
-		log.Warnf("peer %s address requires a DHT lookup", pid)
+		n.log.Warnf("peer %s address requires a DHT lookup", pid)
 	}
 
 	// Send all logs to the new replicator
 	for _, l := range info.Logs {
-		if err = n.server.pushLog(ctx, info.ID, l, pid, info.Key.Service(), nil); err != nil {
-			if err := n.store.SetAddrs(info.ID, ownlg.ID, ownlg.Addrs, pstore.PermanentAddrTTL); err != nil {
-				log.Errorf("error rolling back log address change: %s", err)
+		if err = n.server.pushLog(ctx, info.ID, l, pid, info.Key.Service(), nil, nil); err != nil {
+			if err := n.store.SetAddrs(info.ID, ownlg.ID, ownlg.Addrs, n.addrTTL(AddrSourceDirect)); err != nil {
+				n.log.Errorf("error rolling back log address change: %s", err)
 			}
 			return
 		}
@@ -557,22 +944,17 @@ func (n *net) AddReplicator(ctx context.Context, id thread.ID, paddr ma.Multiadd
 		wg.Add(1)
 		go func(addr ma.Multiaddr) {
 			defer wg.Done()
-			p, err := addr.ValueForProtocol(ma.P_P2P)
-			if err != nil {
-				log.Error(err)
-				return
-			}
-			pid, err := peer.Decode(p)
+			pid, err := peerIDFromAddr(addr)
 			if err != nil {
-				log.Error(err)
+				n.log.Error(err)
 				return
 			}
 			if pid.String() == n.host.ID().String() {
 				return
 			}
 
-			if err = n.server.pushLog(ctx, info.ID, ownlg, pid, nil, nil); err != nil {
-				log.Errorf("error pushing log %s to %s", ownlg.ID, p)
+			if err = n.server.pushLog(ctx, info.ID, ownlg, pid, nil, nil, nil); err != nil {
+				n.log.Errorf("error pushing log %s to %s", ownlg.ID, pid)
 			}
 		}(addr)
 	}
@@ -596,30 +978,46 @@ func (n *net) CreateRecord(ctx context.Context, id thread.ID, body format.Node,
 		return
 	}
 
-	lg, err := n.getOrCreateOwnLog(id)
-	if err != nil {
-		return
-	}
-	rec, err := n.newRecord(ctx, id, lg, body, pk)
+	rec, lg, err := n.appendOwnRecord(ctx, id, body, pk, false)
 	if err != nil {
 		return
 	}
-	if err = n.store.SetHead(id, lg.ID, rec.Cid()); err != nil {
-		return nil, err
-	}
 
-	log.Debugf("added record %s (thread=%s, log=%s)", rec.Cid(), id, lg.ID)
+	n.log.Debugf("added record %s (thread=%s, log=%s)", rec.Cid(), id, lg.ID)
 
 	r = NewRecord(rec, id, lg.ID)
 	if err = n.bus.SendWithTimeout(r, notifyTimeout); err != nil {
 		return
 	}
-	if err = n.server.pushRecord(ctx, id, lg.ID, rec); err != nil {
+	if err = n.pushRecord(ctx, id, lg.ID, rec, args); err != nil {
 		return
 	}
 	return r, nil
 }
 
+// appendOwnRecord creates a record in the host's own log and moves the log head.
+// The thread lock is held so that the record's blocks and the new head are
+// written together with respect to other writers and pulls.
+func (n *net) appendOwnRecord(ctx context.Context, id thread.ID, body format.Node, pk thread.PubKey, receipt bool) (rec core.Record, lg thread.LogInfo, err error) {
+	tsph := n.getThreadSemaphore(id)
+	tsph <- struct{}{}
+	defer func() { <-tsph }()
+
+	lg, err = n.getOrCreateOwnLog(id)
+	if err != nil {
+		return
+	}
+	rec, err = n.newRecord(ctx, id, lg, body, pk, receipt)
+	if err != nil {
+		return
+	}
+	if err = n.indexRecord(id, lg.ID, rec); err != nil {
+		return
+	}
+	err = n.store.SetHead(id, lg.ID, rec.Cid())
+	return
+}
+
 func (n *net) AddRecord(ctx context.Context, id thread.ID, lid peer.ID, rec core.Record, opts ...core.ThreadOption) error {
 	args := &core.ThreadOptions{}
 	for _, opt := range opts {
@@ -637,7 +1035,7 @@ func (n *net) AddRecord(ctx context.Context, id thread.ID, lid peer.ID, rec core
 		return lstore.ErrLogNotFound
 	}
 
-	knownRecord, err := n.bstore.Has(rec.Cid())
+	knownRecord, err := n.hasBlock(id, rec.Cid())
 	if err != nil {
 		return err
 	}
@@ -651,7 +1049,17 @@ func (n *net) AddRecord(ctx context.Context, id thread.ID, lid peer.ID, rec core
 	if err = n.PutRecord(ctx, id, lid, rec); err != nil {
 		return err
 	}
-	return n.server.pushRecord(ctx, id, lid, rec)
+	return n.pushRecord(ctx, id, lid, rec, args)
+}
+
+// pushRecord pushes a record to the thread's members, setting the push
+// result if one was requested with the thread options.
+func (n *net) pushRecord(ctx context.Context, id thread.ID, lid peer.ID, rec core.Record, args *core.ThreadOptions) error {
+	p, err := n.server.pushRecord(ctx, id, lid, rec, args.MinReplicas)
+	if p != nil && args.PushResult != nil {
+		*args.PushResult = p.result()
+	}
+	return err
 }
 
 func (n *net) GetRecord(ctx context.Context, id thread.ID, rid cid.Cid, opts ...core.ThreadOption) (core.Record, error) {
@@ -666,14 +1074,7 @@ func (n *net) GetRecord(ctx context.Context, id thread.ID, rid cid.Cid, opts ...
 }
 
 func (n *net) getRecord(ctx context.Context, id thread.ID, rid cid.Cid) (core.Record, error) {
-	sk, err := n.store.ServiceKey(id)
-	if err != nil {
-		return nil, err
-	}
-	if sk == nil {
-		return nil, fmt.Errorf("a service-key is required to get records")
-	}
-	return cbor.GetRecord(ctx, n, rid, sk)
+	return n.getRecordWithKeys(ctx, id, rid)
 }
 
 type Record struct {
@@ -714,15 +1115,35 @@ func (n *net) Subscribe(ctx context.Context, opts ...core.SubOption) (<-chan cor
 			filter[id] = struct{}{}
 		}
 	}
-	return n.subscribe(ctx, filter)
+	return n.subscribe(ctx, filter, args.Deletions)
+}
+
+// threadDeletion is sent to subscribers that asked for deletions when a
+// thread is deleted.
+type threadDeletion struct {
+	threadID thread.ID
+}
+
+func (d *threadDeletion) Value() core.Record {
+	return nil
+}
+
+func (d *threadDeletion) ThreadID() thread.ID {
+	return d.threadID
+}
+
+func (d *threadDeletion) LogID() peer.ID {
+	return ""
 }
 
-func (n *net) subscribe(ctx context.Context, filter map[thread.ID]struct{}) (<-chan core.ThreadRecord, error) {
-	channel := make(chan core.ThreadRecord)
+func (n *net) subscribe(ctx context.Context, filter map[thread.ID]struct{}, deletions bool) (<-chan core.ThreadRecord, error) {
+	channel := make(chan core.ThreadRecord, n.eventBuffer())
+	listener := n.bus.Listen() // Listen before returning so no records are missed
+	n.metrics.addSubscriptions(1)
 	go func() {
 		defer close(channel)
-		listener := n.bus.Listen()
 		defer listener.Discard()
+		defer n.metrics.addSubscriptions(-1)
 		for {
 			select {
 			case <-ctx.Done():
@@ -731,16 +1152,20 @@ func (n *net) subscribe(ctx context.Context, filter map[thread.ID]struct{}) (<-c
 				if !ok {
 					return
 				}
-				if rec, ok := i.(*Record); ok {
-					if len(filter) > 0 {
-						if _, ok := filter[rec.threadID]; ok {
-							channel <- rec
-						}
-					} else {
-						channel <- rec
+				rec, ok := i.(core.ThreadRecord)
+				if !ok {
+					n.log.Warn("listener received a non-record value")
+					continue
+				}
+				if _, ok := rec.(*threadDeletion); ok && !deletions {
+					continue
+				}
+				if len(filter) > 0 {
+					if _, ok := filter[rec.ThreadID()]; ok {
+						n.deliver(ctx, channel, rec)
 					}
 				} else {
-					log.Warn("listener received a non-record value")
+					n.deliver(ctx, channel, rec)
 				}
 			}
 		}
@@ -754,7 +1179,7 @@ func (n *net) ConnectApp(a app.App, threadID thread.ID) (*app.Connector, error)
 		return nil, fmt.Errorf("error getting thread %s: %v", threadID, err)
 	}
 	return app.NewConnector(a, n, info, func(ctx context.Context, id thread.ID) (<-chan core.ThreadRecord, error) {
-		return n.subscribe(ctx, map[thread.ID]struct{}{id: {}})
+		return n.subscribe(ctx, map[thread.ID]struct{}{id: {}}, false)
 	})
 }
 
@@ -772,7 +1197,7 @@ func (n *net) putRecord(ctx context.Context, id thread.ID, lid peer.ID, rec core
 	var unknownRecords []core.Record
 	c := rec.Cid()
 	for c.Defined() {
-		exist, err := n.bstore.Has(c)
+		exist, err := n.hasBlock(id, c)
 		if err != nil {
 			return err
 		}
@@ -799,11 +1224,15 @@ func (n *net) putRecord(ctx context.Context, id thread.ID, lid peer.ID, rec core
 		return err
 	}
 
+	ds := n.threadDAG(id)
 	for i := len(unknownRecords) - 1; i >= 0; i-- {
 		r := unknownRecords[i]
+		if err = n.checkEquivocation(ctx, id, lg.ID, r); err != nil {
+			return err
+		}
 		// Save the record locally
 		// Note: These get methods will return cached nodes.
-		block, err := r.GetBlock(ctx, n)
+		block, err := r.GetBlock(ctx, ds)
 		if err != nil {
 			return err
 		}
@@ -814,32 +1243,80 @@ func (n *net) putRecord(ctx context.Context, id thread.ID, lid peer.ID, rec core
 				return fmt.Errorf("invalid event: %v", err)
 			}
 		}
-		header, err := event.GetHeader(ctx, n, nil)
+		header, err := event.GetHeader(ctx, ds, nil)
 		if err != nil {
 			return err
 		}
-		body, err := event.GetBody(ctx, n, nil)
+		body, err := event.GetBody(ctx, ds, nil)
 		if err != nil {
 			return err
 		}
-		if err = n.AddMany(ctx, []format.Node{r, event, header, body}); err != nil {
+		nodes := []format.Node{r, event, header}
+		if n.shouldReplicate(ctx, id, lg, r, event, body) {
+			nodes = append(nodes, body)
+		} else {
+			n.log.Debugf("skipping body of record %s (thread=%s, log=%s)", r.Cid(), id, lg.ID)
+			if err = n.skipBody(id, r.Cid(), len(body.RawData())); err != nil {
+				return err
+			}
+		}
+		if err = ds.AddMany(ctx, nodes); err != nil {
 			return err
 		}
 
-		log.Debugf("put record %s (thread=%s, log=%s)", r.Cid(), id, lg.ID)
+		n.log.Debugf("put record %s (thread=%s, log=%s)", r.Cid(), id, lg.ID)
 
+		// The head is moved last, so a record is visible to readers only once
+		// all of its blocks and metadata are stored
+		if err = n.indexRecord(id, lg.ID, r); err != nil {
+			return err
+		}
 		if err = n.store.SetHead(id, lg.ID, r.Cid()); err != nil {
 			return err
 		}
+		leave, err := n.isLeave(ctx, id, lg.ID, event)
+		if err != nil {
+			return err
+		}
+		if leave {
+			if err = n.memberLeft(id, lg.ID, r.Cid()); err != nil {
+				return err
+			}
+			continue
+		}
+		if err = n.memberRejoined(id, lg.ID); err != nil {
+			return err
+		}
+		receipt, err := n.putReceipt(ctx, id, lg.ID, r, event)
+		if err != nil {
+			return err
+		}
+		if receipt {
+			continue
+		}
 		if err = n.bus.SendWithTimeout(NewRecord(r, id, lg.ID), notifyTimeout); err != nil {
 			return err
 		}
 	}
+
+	// Apply any buffered records that were waiting on the new ones
+	for _, r := range unknownRecords {
+		for _, or := range n.orphans.take(r.Cid()) {
+			if or.id != id || or.lid != lid {
+				n.orphans.add(or.id, or.lid, or.rec)
+				continue
+			}
+			if err = n.putRecord(ctx, id, lid, or.rec); err != nil {
+				n.log.Errorf("error applying buffered record %s: %s", or.rec.Cid(), err)
+			}
+		}
+	}
 	return nil
 }
 
-// newRecord creates a new record with the given body as a new event body.
-func (n *net) newRecord(ctx context.Context, id thread.ID, lg thread.LogInfo, body format.Node, pk thread.PubKey) (core.Record, error) {
+// newRecord creates a new record with the given body as a new event body. If
+// receipt is true, the event header marks the record as a receipt.
+func (n *net) newRecord(ctx context.Context, id thread.ID, lg thread.LogInfo, body format.Node, pk thread.PubKey, receipt bool) (core.Record, error) {
 	if lg.PrivKey == nil {
 		return nil, fmt.Errorf("a private-key is required to create records")
 	}
@@ -857,7 +1334,13 @@ func (n *net) newRecord(ctx context.Context, id thread.ID, lg thread.LogInfo, bo
 	if rk == nil {
 		return nil, fmt.Errorf("a read-key is required to create records")
 	}
-	event, err := cbor.CreateEvent(ctx, n, body, rk)
+	ds := n.threadDAG(id)
+	var event core.Event
+	if receipt {
+		event, err = cbor.CreateReceiptEvent(ctx, ds, body, rk)
+	} else {
+		event, err = cbor.CreateEvent(ctx, ds, body, rk)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -865,7 +1348,7 @@ func (n *net) newRecord(ctx context.Context, id thread.ID, lg thread.LogInfo, bo
 	if pk == nil {
 		pk = thread.NewLibp2pPubKey(n.getPrivKey().GetPublic())
 	}
-	return cbor.CreateRecord(ctx, n, cbor.CreateRecordConfig{
+	return cbor.CreateRecord(ctx, ds, cbor.CreateRecordConfig{
 		Block:      event,
 		Prev:       lg.Head,
 		Key:        lg.PrivKey,
@@ -881,59 +1364,127 @@ func (n *net) getPrivKey() crypto.PrivKey {
 
 // getLocalRecords returns local records from the given thread that are ahead of
 // offset but not farther than limit.
-// It is possible to reach limit before offset, meaning that the caller
+// It is possible to reach limit before the head, meaning that the caller
 // will be responsible for the remaining traversal.
 func (n *net) getLocalRecords(ctx context.Context, id thread.ID, lid peer.ID, offset cid.Cid, limit int) ([]core.Record, error) {
+	recs, _, err := n.getLocalRecordRange(ctx, id, lid, offset, cid.Undef, limit)
+	return recs, err
+}
+
+// getLocalRecordRange is like getLocalRecords, but only returns records up to
+// and including to. If to is undefined, records up to the head are returned.
+// No records are returned if to is not in the log after offset. If there are
+// more than limit records in the range, the oldest are returned and truncated
+// is true, so that the rest can be fetched with the last record as offset.
+// Only the records returned are read if the log's heights are indexed.
+func (n *net) getLocalRecordRange(ctx context.Context, id thread.ID, lid peer.ID, offset, to cid.Cid, limit int) (recs []core.Record, truncated bool, err error) {
 	lg, err := n.store.GetLog(id, lid)
 	if err != nil {
-		return nil, err
-	}
-	sk, err := n.store.ServiceKey(id)
-	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
-	if sk == nil {
-		return nil, fmt.Errorf("a service-key is required to get records")
+	if limit <= 0 || !lg.Head.Defined() {
+		return nil, false, nil
 	}
-
-	var recs []core.Record
-	if limit <= 0 {
-		return recs, nil
+	recs, truncated, ok, err := n.getIndexedRecordRange(ctx, id, lg, offset, to, limit)
+	if err != nil || ok {
+		return recs, truncated, err
 	}
 
+	// Records are collected newest first, keeping the oldest limit records
 	cursor := lg.Head
+	reached := !to.Defined()
 	for {
 		if !cursor.Defined() || cursor.String() == offset.String() {
 			break
 		}
-		r, err := cbor.GetRecord(ctx, n, cursor, sk) // Important invariant: heads are always in blockstore
+		r, err := n.getRecordWithKeys(ctx, id, cursor) // Important invariant: heads are always in blockstore
 		if err != nil {
-			return nil, err
+			return nil, false, err
 		}
-		recs = append([]core.Record{r}, recs...)
-		if len(recs) >= MaxPullLimit {
-			break
+		if !reached && cursor.Equals(to) {
+			reached = true
+		}
+		if reached {
+			recs = append(recs, r)
+			if len(recs) > limit {
+				recs = recs[1:]
+				truncated = true
+			}
 		}
 		cursor = r.PrevID()
 	}
+	if !reached {
+		return nil, false, nil
+	}
 
-	return recs, nil
+	for i, j := 0, len(recs)-1; i < j; i, j = i+1, j-1 {
+		recs[i], recs[j] = recs[j], recs[i]
+	}
+	return recs, truncated, nil
+}
+
+// getIndexedRecordRange is getLocalRecordRange for a log whose heights are
+// indexed, walking back from the last record returned instead of the head.
+// If the log is only held in part, ok is false.
+func (n *net) getIndexedRecordRange(ctx context.Context, id thread.ID, lg thread.LogInfo, offset, to cid.Cid, limit int) (recs []core.Record, truncated, ok bool, err error) {
+	top, err := n.headHeight(ctx, id, lg.ID, lg.Head)
+	if errors.Is(err, ErrPartialHistory) {
+		return nil, false, false, nil
+	} else if err != nil {
+		return nil, false, false, err
+	}
+	from := int64(-1)
+	if offset.Defined() {
+		// Records are returned from the start of the log if offset isn't in it
+		if h, err := n.logHeight(id, lg.ID, offset); err != nil {
+			return nil, false, false, err
+		} else if h >= 0 {
+			from = h
+		}
+	}
+	if to.Defined() {
+		if top, err = n.logHeight(id, lg.ID, to); err != nil {
+			return nil, false, false, err
+		}
+	}
+	if top <= from {
+		return nil, false, true, nil
+	}
+	if top-from > int64(limit) {
+		top = from + int64(limit)
+		truncated = true
+	}
+	cursor, err := n.recordAtHeight(id, lg.ID, top)
+	if err != nil {
+		return nil, false, false, err
+	}
+	recs = make([]core.Record, top-from)
+	for i := len(recs) - 1; i >= 0; i-- {
+		r, err := n.getRecordWithKeys(ctx, id, cursor)
+		if err != nil {
+			return nil, false, false, err
+		}
+		recs[i] = r
+		cursor = r.PrevID()
+	}
+	return recs, truncated, true, nil
 }
 
 // deleteRecord remove a record from the dag service.
-func (n *net) deleteRecord(ctx context.Context, rid cid.Cid, sk *sym.Key) (prev cid.Cid, err error) {
-	rec, err := cbor.GetRecord(ctx, n, rid, sk)
+func (n *net) deleteRecord(ctx context.Context, id thread.ID, rid cid.Cid) (prev cid.Cid, err error) {
+	rec, err := n.getRecordWithKeys(ctx, id, rid)
 	if err != nil {
 		return
 	}
-	if err = cbor.RemoveRecord(ctx, n, rec); err != nil {
+	ds := n.threadDAG(id)
+	if err = cbor.RemoveRecord(ctx, ds, rec); err != nil {
 		return
 	}
-	event, err := cbor.EventFromRecord(ctx, n, rec)
+	event, err := cbor.EventFromRecord(ctx, ds, rec)
 	if err != nil {
 		return
 	}
-	if err = cbor.RemoveEvent(ctx, n, event); err != nil {
+	if err = cbor.RemoveEvent(ctx, ds, event); err != nil {
 		return
 	}
 	return rec.PrevID(), nil
@@ -942,17 +1493,25 @@ func (n *net) deleteRecord(ctx context.Context, rid cid.Cid, sk *sym.Key) (prev
 // startPulling periodically pulls on all threads.
 func (n *net) startPulling() {
 	pull := func() {
+		if !n.settings.autoPull() {
+			return
+		}
+		if n.underPressure() {
+			n.log.Debug("deferring automatic pulls under connection pressure")
+			return
+		}
 		ts, err := n.store.Threads()
 		if err != nil {
-			log.Errorf("error listing threads: %s", err)
+			n.log.Errorf("error listing threads: %s", err)
 			return
 		}
 		for _, id := range ts {
-			go func(id thread.ID) {
+			id := id
+			n.spawn(id, func() {
 				if err := n.pullThread(n.ctx, id); err != nil {
-					log.Errorf("error pulling thread %s: %s", id, err)
+					n.log.Errorf("error pulling thread %s: %s", id, err)
 				}
-			}(id)
+			})
 		}
 	}
 	timer := time.NewTimer(InitialPullInterval)
@@ -1012,59 +1571,97 @@ func (n *net) getOrCreateOwnLog(id thread.ID) (info thread.LogInfo, err error) {
 	return info, err
 }
 
-// createExternalLogIfNotExist creates an external log if doesn't exists. The created
-// log will have cid.Undef as the current head. Is thread-safe.
-func (n *net) createExternalLogIfNotExist(tid thread.ID, lid peer.ID, pubKey crypto.PubKey,
-	privKey crypto.PrivKey, addrs []ma.Multiaddr) error {
+// addExternalLog adds an external log received from a peer, or merges it
+// into the existing log info if the log is already known. Is thread-safe.
+func (n *net) addExternalLog(tid thread.ID, lg thread.LogInfo, src AddrSource, from peer.ID) error {
 	tsph := n.getThreadSemaphore(tid)
 	tsph <- struct{}{}
 	defer func() { <-tsph }()
-	currHeads, err := n.Store().Heads(tid, lid)
-	if err != nil {
-		return err
-	}
-	if len(currHeads) == 0 {
-		lginfo := thread.LogInfo{
-			ID:      lid,
-			PubKey:  pubKey,
-			PrivKey: privKey,
-			Addrs:   addrs,
-			Head:    cid.Undef,
-		}
-		if err := n.store.AddLog(tid, lginfo); err != nil {
-			return err
-		}
-	}
-	return nil
+	return n.addLog(tid, lg, src, from)
 }
 
 // updateRecordsFromLog will fetch lid addrs for new logs & records,
 // and will add them in the local peer store. It assumes  Is thread-safe.
-func (n *net) updateRecordsFromLog(tid thread.ID, lid peer.ID) {
+// The pull is bounded by HistoryPullTimeout and abandoned when ctx is done.
+func (n *net) updateRecordsFromLog(ctx context.Context, tid thread.ID, lid peer.ID) {
+	ctx, cancel := context.WithTimeout(ctx, HistoryPullTimeout)
+	defer cancel()
+	ctx, span := n.tracer.start(ctx, "pullHistory", tid, lid, cid.Undef)
+	defer n.tracer.end(span, nil)
 	tsph := n.getThreadSemaphore(tid)
-	tsph <- struct{}{}
+	select {
+	case tsph <- struct{}{}:
+	case <-ctx.Done():
+		n.log.Debugf("abandoned pull of log %s in thread %s: %s", lid, tid, ctx.Err())
+		return
+	}
 	defer func() { <-tsph }()
-	// Get log records for this new log
-	recs, err := n.server.getRecords(
-		n.ctx,
-		tid,
-		lid,
-		map[peer.ID]cid.Cid{lid: cid.Undef},
-		MaxPullLimit)
+	offsets, err := n.logFrontier(tid, lid)
 	if err != nil {
-		log.Error(err)
+		n.log.Error(err)
 		return
 	}
-	for lid, rs := range recs {
-		for _, r := range rs {
-			if err = n.putRecord(n.ctx, tid, lid, r); err != nil {
-				log.Error(err)
+	// Get log records after each held head, page by page if truncated
+	for _, offset := range offsets {
+		for {
+			recs, truncated, err := n.server.getRecords(
+				ctx,
+				tid,
+				lid,
+				map[peer.ID]cid.Cid{lid: offset},
+				n.server.pullLimit())
+			if ctx.Err() != nil {
+				n.log.Debugf("abandoned pull of log %s in thread %s: %s", lid, tid, ctx.Err())
 				return
 			}
+			if err != nil {
+				n.log.Error(err)
+				return
+			}
+			for lid, rs := range recs {
+				for _, r := range rs {
+					if err = n.putRecord(ctx, tid, lid, r); err != nil {
+						n.log.Error(err)
+						return
+					}
+				}
+			}
+			rs := recs[lid]
+			if !truncated[lid] || len(rs) == 0 {
+				break
+			}
+			offset = rs[len(rs)-1].Cid()
 		}
 	}
 }
 
+// logFrontier returns the heads of a log that are held locally, from which
+// newer records can be pulled. A log without held heads is pulled from the
+// start, i.e., cid.Undef.
+func (n *net) logFrontier(tid thread.ID, lid peer.ID) ([]cid.Cid, error) {
+	heads, err := n.store.Heads(tid, lid)
+	if err != nil {
+		return nil, err
+	}
+	var offsets []cid.Cid
+	for _, h := range heads {
+		if !h.Defined() {
+			continue
+		}
+		has, err := n.hasBlock(tid, h)
+		if err != nil {
+			return nil, err
+		}
+		if has {
+			offsets = append(offsets, h)
+		}
+	}
+	if len(offsets) == 0 {
+		offsets = []cid.Cid{cid.Undef}
+	}
+	return offsets, nil
+}
+
 // createLog creates a new log with the given peer as host.
 func createLog(host peer.ID, key crypto.Key) (info thread.LogInfo, err error) {
 	var ok bool
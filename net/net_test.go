@@ -1,29 +1,61 @@
 package net
 
 import (
+	"bytes"
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
 	rand "crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	nnet "net"
+	"path"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	bserv "github.com/ipfs/go-blockservice"
+	"github.com/ipfs/go-cid"
 	ds "github.com/ipfs/go-datastore"
 	syncds "github.com/ipfs/go-datastore/sync"
 	bstore "github.com/ipfs/go-ipfs-blockstore"
 	offline "github.com/ipfs/go-ipfs-exchange-offline"
 	cbornode "github.com/ipfs/go-ipld-cbor"
+	format "github.com/ipfs/go-ipld-format"
 	dag "github.com/ipfs/go-merkledag"
 	"github.com/libp2p/go-libp2p"
 	"github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
 	"github.com/libp2p/go-libp2p-core/peerstore"
+	"github.com/libp2p/go-libp2p-core/protocol"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	pspb "github.com/libp2p/go-libp2p-pubsub/pb"
 	ma "github.com/multiformats/go-multiaddr"
 	mh "github.com/multiformats/go-multihash"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/textileio/go-threads/cbor"
 	"github.com/textileio/go-threads/core/logstore"
 	core "github.com/textileio/go-threads/core/net"
 	"github.com/textileio/go-threads/core/thread"
 	tstore "github.com/textileio/go-threads/logstore/lstoremem"
+	pb "github.com/textileio/go-threads/net/pb"
 	"github.com/textileio/go-threads/util"
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/oteltest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
 )
 
 func TestNet_GetToken(t *testing.T) {
@@ -109,6 +141,215 @@ func TestNet_CreateRecord(t *testing.T) {
 	})
 }
 
+func TestNet_CreateRecordMinReplicas(t *testing.T) {
+	t.Parallel()
+	n := makeNetwork(t)
+	defer n.Close()
+
+	ctx := context.Background()
+	info := createThread(t, ctx, n)
+
+	body, err := cbornode.WrapObject(map[string]interface{}{
+		"foo": "bar",
+	}, mh.SHA2_256, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := n.CreateRecord(ctx, info.ID, body, core.WithMinReplicas(1))
+	if !errors.Is(err, ErrInsufficientReplicas) {
+		t.Fatalf("expected insufficient replicas error, got %v", err)
+	}
+	if _, err = n.GetRecord(ctx, info.ID, r.Value().Cid()); err != nil {
+		t.Fatalf("expected record to be stored locally: %v", err)
+	}
+
+	// A record accepted by enough replicas is created without error
+	n2 := makeNetwork(t)
+	defer n2.Close()
+	n.Host().Peerstore().AddAddrs(n2.Host().ID(), n2.Host().Addrs(), peerstore.PermanentAddrTTL)
+	if _, err = n2.CreateThread(ctx, info.ID, core.WithThreadKey(info.Key)); err != nil {
+		t.Fatal(err)
+	}
+	tn, tn2 := n.(*net), n2.(*net)
+	lg, err := tn.getOwnLog(info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tn2.store.AddLog(info.ID, thread.LogInfo{ID: lg.ID, PubKey: lg.PubKey}); err != nil {
+		t.Fatal(err)
+	}
+	if err = n2.AddRecord(ctx, info.ID, lg.ID, r.Value()); err != nil {
+		t.Fatal(err)
+	}
+	lg2, err := tn2.getOwnLog(info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr, err := ma.NewMultiaddr("/p2p/" + n2.Host().ID().String() + "/thread/" + info.ID.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tn.store.AddLog(info.ID, thread.LogInfo{ID: lg2.ID, PubKey: lg2.PubKey, Addrs: []ma.Multiaddr{addr}}); err != nil {
+		t.Fatal(err)
+	}
+	if r, err = n.CreateRecord(ctx, info.ID, body, core.WithMinReplicas(1)); err != nil {
+		t.Fatalf("expected the record to reach a replica, got %v", err)
+	}
+	if _, err = n2.GetRecord(ctx, info.ID, r.Value().Cid()); err != nil {
+		t.Fatalf("expected the replica to hold the record: %v", err)
+	}
+}
+
+func TestNet_CreateRecordMinReplicasHeld(t *testing.T) {
+	t.Parallel()
+	n := makeNetwork(t)
+	defer n.Close()
+	n2 := makeNetwork(t)
+	defer n2.Close()
+	n.Host().Peerstore().AddAddrs(n2.Host().ID(), n2.Host().Addrs(), peerstore.PermanentAddrTTL)
+	n2.Host().Peerstore().AddAddrs(n.Host().ID(), n.Host().Addrs(), peerstore.PermanentAddrTTL)
+
+	ctx := context.Background()
+	info := createThread(t, ctx, n)
+	if _, err := n2.CreateThread(ctx, info.ID, core.WithThreadKey(info.Key)); err != nil {
+		t.Fatal(err)
+	}
+	tn, tn2 := n.(*net), n2.(*net)
+	lg, err := tn.getOwnLog(info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tn2.store.AddLog(info.ID, thread.LogInfo{ID: lg.ID, PubKey: lg.PubKey}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The first record is created before the replica is addressed, so the
+	// replica is missing the parent of the second one
+	body, err := cbornode.WrapObject(map[string]interface{}{
+		"foo": "bar",
+	}, mh.SHA2_256, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = n.CreateRecord(ctx, info.ID, body); err != nil {
+		t.Fatal(err)
+	}
+	lg2, err := tn2.getOwnLog(info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr, err := ma.NewMultiaddr("/p2p/" + n2.Host().ID().String() + "/thread/" + info.ID.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tn.store.AddLog(info.ID, thread.LogInfo{ID: lg2.ID, PubKey: lg2.PubKey, Addrs: []ma.Multiaddr{addr}}); err != nil {
+		t.Fatal(err)
+	}
+
+	body2, err := cbornode.WrapObject(map[string]interface{}{
+		"foo": "baz",
+	}, mh.SHA2_256, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := n.CreateRecord(ctx, info.ID, body2, core.WithMinReplicas(1))
+	if !errors.Is(err, ErrInsufficientReplicas) {
+		t.Fatalf("expected a held record not to count as a replica, got %v", err)
+	}
+	ds := tn.Deliveries(r.Value().Cid())
+	if len(ds) != 1 || ds[0].Peer != n2.Host().ID() || ds[0].Outcome != core.PushHeld {
+		t.Fatalf("expected the push to be held by the replica, got %v", ds)
+	}
+	if tn.server.health.unreachable(n2.Host().ID()) {
+		t.Fatal("expected a held push not to count as a peer failure")
+	}
+
+	// The replica fetches the missing parent and stores the held record
+	deadline := time.Now().Add(time.Second * 5)
+	for {
+		if _, err = n2.GetRecord(ctx, info.ID, r.Value().Cid()); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the replica to store the held record: %v", err)
+		}
+		time.Sleep(time.Millisecond * 50)
+	}
+}
+
+// headCheckStore calls check before a log head is set.
+type headCheckStore struct {
+	logstore.Logstore
+	check func(thread.ID, peer.ID, cid.Cid)
+}
+
+func (s *headCheckStore) SetHead(id thread.ID, lid peer.ID, head cid.Cid) error {
+	s.check(id, lid, head)
+	return s.Logstore.SetHead(id, lid, head)
+}
+
+func TestNet_CreateRecordBlocksBeforeHead(t *testing.T) {
+	t.Parallel()
+	sk, _, err := crypto.GenerateKeyPair(crypto.Ed25519, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	host, err := libp2p.New(context.Background(), libp2p.ListenAddrs(util.MustParseAddr("/ip4/127.0.0.1/tcp/0")), libp2p.Identity(sk))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bs := bstore.NewBlockstore(syncds.MutexWrap(ds.NewMapDatastore()))
+	bsrv := bserv.New(bs, offline.Exchange(bs))
+	store := &headCheckStore{Logstore: tstore.NewLogstore()}
+	n, err := NewNetwork(context.Background(), host, bsrv.Blockstore(), dag.NewDAGService(bsrv), store, Config{LocalOnly: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer n.Close()
+	tn := n.(*net)
+
+	ctx := context.Background()
+	var checked int32
+	store.check = func(id thread.ID, _ peer.ID, head cid.Cid) {
+		atomic.AddInt32(&checked, 1)
+		rec, err := tn.getRecord(ctx, id, head)
+		if err != nil {
+			t.Errorf("head %s is set before its record can be fetched: %v", head, err)
+			return
+		}
+		rk, err := tn.store.ReadKey(id)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		event, err := cbor.EventFromRecord(ctx, tn.DAGService, rec)
+		if err != nil {
+			t.Errorf("head %s is set before its event can be fetched: %v", head, err)
+			return
+		}
+		if _, err = event.GetBody(ctx, tn.DAGService, rk); err != nil {
+			t.Errorf("head %s is set before its body can be fetched: %v", head, err)
+		}
+		if h, err := tn.store.GetInt64(id, recordHeightKey(head)); err != nil || h == nil {
+			t.Errorf("head %s is set before it is indexed: %v", head, err)
+		}
+	}
+
+	info := createThread(t, ctx, n)
+	for i := 0; i < 3; i++ {
+		body, err := cbornode.WrapObject(map[string]interface{}{"foo": i}, mh.SHA2_256, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = n.CreateRecord(ctx, info.ID, body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if atomic.LoadInt32(&checked) < 3 {
+		t.Fatalf("expected the head to be set for each record, got %d", atomic.LoadInt32(&checked))
+	}
+}
+
 func TestNet_AddThread(t *testing.T) {
 	t.Parallel()
 	n1 := makeNetwork(t)
@@ -174,7 +415,7 @@ func TestNet_AddThread(t *testing.T) {
 	}
 }
 
-func TestNet_AddReplicator(t *testing.T) {
+func TestNet_LeaveThread(t *testing.T) {
 	t.Parallel()
 	n1 := makeNetwork(t)
 	defer n1.Close()
@@ -187,115 +428,7136 @@ func TestNet_AddReplicator(t *testing.T) {
 	ctx := context.Background()
 	info := createThread(t, ctx, n1)
 
+	addr, err := ma.NewMultiaddr("/p2p/" + n1.Host().ID().String() + "/thread/" + info.ID.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = n2.AddThread(ctx, addr, core.WithThreadKey(info.Key)); err != nil {
+		t.Fatal(err)
+	}
 	body, err := cbornode.WrapObject(map[string]interface{}{
 		"msg": "yo!",
 	}, mh.SHA2_256, -1)
 	if err != nil {
 		t.Fatal(err)
 	}
-	if _, err := n1.CreateRecord(ctx, info.ID, body); err != nil {
+	if _, err = n2.CreateRecord(ctx, info.ID, body); err != nil {
 		t.Fatal(err)
 	}
+	time.Sleep(time.Second)
 
-	addr, err := ma.NewMultiaddr("/p2p/" + n2.Host().ID().String())
+	lg, err := n2.(*net).getOwnLog(info.ID)
 	if err != nil {
 		t.Fatal(err)
 	}
-	if _, err = n1.AddReplicator(ctx, info.ID, addr); err != nil {
+	lg1, err := n1.(*net).store.GetLog(info.ID, lg.ID)
+	if err != nil {
 		t.Fatal(err)
 	}
+	if len(lg1.Addrs) == 0 {
+		t.Fatal("expected member log to have addresses")
+	}
 
-	info2, err := n1.GetThread(context.Background(), info.ID)
+	if err = n2.LeaveThread(ctx, info.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = n2.GetThread(ctx, info.ID); !errors.Is(err, logstore.ErrThreadNotFound) {
+		t.Fatalf("expected thread to be deleted, got %v", err)
+	}
+	time.Sleep(time.Second)
+
+	lg1, err = n1.(*net).store.GetLog(info.ID, lg.ID)
 	if err != nil {
 		t.Fatal(err)
 	}
-	if len(info2.Logs) != 1 {
-		t.Fatalf("expected 1 log got %d", len(info2.Logs))
+	if len(lg1.Addrs) != 0 {
+		t.Fatalf("expected addresses of departed member to be cleared, got %d", len(lg1.Addrs))
 	}
-	if len(info2.Logs[0].Addrs) != 2 {
-		t.Fatalf("expected 2 addresses got %d", len(info2.Logs[0].Addrs))
+	left, err := n1.(*net).hasLeft(info.ID, lg.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !left {
+		t.Fatal("expected member to be marked as left")
 	}
 
-	info3, err := n2.GetThread(context.Background(), info.ID)
+	// A later record in the log clears the mark
+	tn1 := n1.(*net)
+	lg1.PrivKey = lg.PrivKey
+	rec, err := tn1.newRecord(ctx, info.ID, lg1, body, nil, false)
 	if err != nil {
 		t.Fatal(err)
 	}
-	if len(info3.Logs) != 1 {
-		t.Fatalf("expected 1 log got %d", len(info2.Logs))
+	if err = cbor.RemoveRecord(ctx, tn1.threadDAG(info.ID), rec); err != nil {
+		t.Fatal(err)
 	}
-	if len(info3.Logs[0].Addrs) != 2 {
-		t.Fatalf("expected 2 addresses got %d", len(info3.Logs[0].Addrs))
+	if err = tn1.putRecord(ctx, info.ID, lg.ID, rec); err != nil {
+		t.Fatal(err)
+	}
+	if left, err = tn1.hasLeft(info.ID, lg.ID); err != nil {
+		t.Fatal(err)
+	}
+	if left {
+		t.Fatal("expected member to be marked as rejoined")
 	}
 }
 
-func TestNet_DeleteThread(t *testing.T) {
+func TestNet_LeaveThreadUnsubscribes(t *testing.T) {
 	t.Parallel()
-	n := makeNetwork(t)
-	defer n.Close()
+	n1 := makeNetwork(t)
+	defer n1.Close()
+	n2 := makeNetwork(t)
+	defer n2.Close()
 
-	ctx := context.Background()
-	info := createThread(t, ctx, n)
+	n1.Host().Peerstore().AddAddrs(n2.Host().ID(), n2.Host().Addrs(), peerstore.PermanentAddrTTL)
+	n2.Host().Peerstore().AddAddrs(n1.Host().ID(), n1.Host().Addrs(), peerstore.PermanentAddrTTL)
+
+	// Count the records n2 receives over pubsub
+	ps2 := n2.(*net).server.ps
+	var received int32
+	handler := ps2.handler
+	ps2.handler = func(ctx context.Context, req *pb.PushRecordRequest) {
+		atomic.AddInt32(&received, 1)
+		handler(ctx, req)
+	}
 
+	ctx := context.Background()
+	info := createThread(t, ctx, n1)
+	addr, err := ma.NewMultiaddr("/p2p/" + n1.Host().ID().String() + "/thread/" + info.ID.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = n2.AddThread(ctx, addr, core.WithThreadKey(info.Key)); err != nil {
+		t.Fatal(err)
+	}
 	body, err := cbornode.WrapObject(map[string]interface{}{
-		"foo": "bar",
-		"baz": []byte("howdy"),
+		"msg": "yo!",
 	}, mh.SHA2_256, -1)
 	if err != nil {
 		t.Fatal(err)
 	}
-	if _, err := n.CreateRecord(ctx, info.ID, body); err != nil {
+	time.Sleep(time.Second * 2) // Wait for the pubsub mesh
+	if _, err = n1.CreateRecord(ctx, info.ID, body); err != nil {
 		t.Fatal(err)
 	}
-	if _, err := n.CreateRecord(ctx, info.ID, body); err != nil {
-		t.Fatal(err)
+	time.Sleep(time.Second)
+	if atomic.LoadInt32(&received) == 0 {
+		t.Fatal("expected record to be received over pubsub")
 	}
 
-	if err = n.DeleteThread(ctx, info.ID); err != nil {
+	if err = n2.LeaveThread(ctx, info.ID); err != nil {
 		t.Fatal(err)
 	}
-	if _, err := n.GetThread(ctx, info.ID); err != logstore.ErrThreadNotFound {
-		t.Fatal("thread was not deleted")
+	if _, err = ps2.Peers(info.ID); err == nil {
+		t.Fatal("expected thread topic to be removed")
+	}
+	atomic.StoreInt32(&received, 0)
+	if _, err = n1.CreateRecord(ctx, info.ID, body); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Second)
+	if n := atomic.LoadInt32(&received); n != 0 {
+		t.Fatalf("expected no records over pubsub after leaving, got %d", n)
 	}
 }
 
-func TestClose(t *testing.T) {
+func TestPubSub_Routers(t *testing.T) {
 	t.Parallel()
-	n := makeNetwork(t)
-	defer n.Close()
+	routers := []struct {
+		name   string
+		router PubSubRouter
+		gossip bool
+	}{
+		{"gossipsub", GossipSubRouter, true},
+		{"floodsub", FloodSubRouter, false},
+	}
+	for _, r := range routers {
+		r := r
+		t.Run(r.name, func(t *testing.T) {
+			t.Parallel()
+			n1 := makeNetworkWithConfig(t, Config{PubSubRouter: r.router})
+			defer n1.Close()
+			n2 := makeNetworkWithConfig(t, Config{PubSubRouter: r.router})
+			defer n2.Close()
+			n2.Host().Peerstore().AddAddrs(n1.Host().ID(), n1.Host().Addrs(), peerstore.PermanentAddrTTL)
 
-	t.Run("test close", func(t *testing.T) {
-		if err := n.Close(); err != nil {
-			t.Fatal(err)
-		}
-	})
+			var gossip bool
+			for _, p := range n1.Host().Mux().Protocols() {
+				if p == string(pubsub.GossipSubID) {
+					gossip = true
+				}
+			}
+			if gossip != r.gossip {
+				t.Fatalf("expected gossipsub to be %t, got %t", r.gossip, gossip)
+			}
+
+			// Count the records n2 receives over pubsub
+			ps2 := n2.(*net).server.ps
+			var received int32
+			handler := ps2.handler
+			ps2.handler = func(ctx context.Context, req *pb.PushRecordRequest) {
+				atomic.AddInt32(&received, 1)
+				handler(ctx, req)
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			info := createThread(t, ctx, n1)
+			addr, err := ma.NewMultiaddr("/p2p/" + n1.Host().ID().String() + "/thread/" + info.ID.String())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err = n2.AddThread(ctx, addr, core.WithThreadKey(info.Key)); err != nil {
+				t.Fatal(err)
+			}
+			for start := time.Now(); ; time.Sleep(time.Millisecond * 50) {
+				if peers, _ := n1.TopicPeers(info.ID); len(peers) > 0 {
+					break
+				}
+				if time.Since(start) > time.Second*5 {
+					t.Fatal("timed out waiting for topic peers")
+				}
+			}
+
+			sub, err := n2.Subscribe(ctx, core.WithSubFilter(info.ID))
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, err := cbornode.WrapObject(map[string]interface{}{
+				"msg": "yo!",
+			}, mh.SHA2_256, -1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r1, err := n1.CreateRecord(ctx, info.ID, body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			select {
+			case got := <-sub:
+				if !got.Value().Cid().Equals(r1.Value().Cid()) {
+					t.Fatalf("expected record %s, got %s", r1.Value().Cid(), got.Value().Cid())
+				}
+			case <-time.After(time.Second * 5):
+				t.Fatal("timed out waiting for record")
+			}
+			// The record may be pushed directly first, so wait for the topic
+			for start := time.Now(); atomic.LoadInt32(&received) == 0; time.Sleep(time.Millisecond * 50) {
+				if time.Since(start) > time.Second*5 {
+					t.Fatal("expected record to be received over pubsub")
+				}
+			}
+		})
+	}
 }
 
-func makeNetwork(t *testing.T) core.Net {
+func TestNet_SharedPubSub(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 	sk, _, err := crypto.GenerateKeyPair(crypto.Ed25519, 0)
 	if err != nil {
 		t.Fatal(err)
 	}
-	addr := util.MustParseAddr("/ip4/127.0.0.1/tcp/0")
-
-	host, err := libp2p.New(
-		context.Background(),
-		libp2p.ListenAddrs(addr),
-		libp2p.Identity(sk),
-	)
+	h1, err := libp2p.New(ctx, libp2p.ListenAddrs(util.MustParseAddr("/ip4/127.0.0.1/tcp/0")), libp2p.Identity(sk))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ps, err := pubsub.NewGossipSub(ctx, h1)
 	if err != nil {
 		t.Fatal(err)
 	}
 	bs := bstore.NewBlockstore(syncds.MutexWrap(ds.NewMapDatastore()))
 	bsrv := bserv.New(bs, offline.Exchange(bs))
-	n, err := NewNetwork(
-		context.Background(),
-		host,
-		bsrv.Blockstore(),
-		dag.NewDAGService(bsrv),
-		tstore.NewLogstore(),
-		Config{
-			Debug: true,
-		})
+	n1, err := NewNetwork(ctx, h1, bsrv.Blockstore(), dag.NewDAGService(bsrv), tstore.NewLogstore(), Config{PubSub: ps})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n1.(*net).PubSub() != ps {
+		t.Fatal("expected the network to use the given pubsub instance")
+	}
+	n2 := makeNetwork(t)
+	defer n2.Close()
+	n2.Host().Peerstore().AddAddrs(n1.Host().ID(), n1.Host().Addrs(), peerstore.PermanentAddrTTL)
+
+	// Count the records n2 receives over pubsub
+	ps2 := n2.(*net).server.ps
+	var received int32
+	handler := ps2.handler
+	ps2.handler = func(ctx context.Context, req *pb.PushRecordRequest) {
+		atomic.AddInt32(&received, 1)
+		handler(ctx, req)
+	}
+
+	// An application topic shares the instance
+	app, err := ps.Join("app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	appSub, err := app.Subscribe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer appSub.Cancel()
+
+	// Two thread topics work on the instance
+	var infos []thread.Info
+	for i := 0; i < 2; i++ {
+		info := createThread(t, ctx, n1)
+		addr, err := ma.NewMultiaddr("/p2p/" + n1.Host().ID().String() + "/thread/" + info.ID.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = n2.AddThread(ctx, addr, core.WithThreadKey(info.Key)); err != nil {
+			t.Fatal(err)
+		}
+		infos = append(infos, info)
+	}
+	for _, info := range infos {
+		for start := time.Now(); ; time.Sleep(time.Millisecond * 50) {
+			if peers, _ := n1.TopicPeers(info.ID); len(peers) > 0 {
+				break
+			}
+			if time.Since(start) > time.Second*5 {
+				t.Fatal("timed out waiting for topic peers")
+			}
+		}
+	}
+	for i, info := range infos {
+		body, err := cbornode.WrapObject(map[string]interface{}{"msg": i}, mh.SHA2_256, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = n1.CreateRecord(ctx, info.ID, body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for start := time.Now(); atomic.LoadInt32(&received) < int32(len(infos)); time.Sleep(time.Millisecond * 50) {
+		if time.Since(start) > time.Second*5 {
+			t.Fatalf("expected records of both threads over pubsub, got %d", atomic.LoadInt32(&received))
+		}
+	}
+
+	// Closing the network leaves its topics, but not the instance
+	if err = n1.Close(); err != nil {
+		t.Fatal(err)
+	}
+	topics := ps.GetTopics()
+	if len(topics) != 1 || topics[0] != "app" {
+		t.Fatalf("expected only the application topic to be left, got %v", topics)
+	}
+	if err = app.Publish(ctx, []byte("hi")); err != nil {
+		t.Fatalf("expected the instance to keep running, got %v", err)
+	}
+	sctx, scancel := context.WithTimeout(ctx, time.Second*5)
+	defer scancel()
+	if m, err := appSub.Next(sctx); err != nil || string(m.Data) != "hi" {
+		t.Fatalf("expected the application topic to keep working, got %v", err)
+	}
+	for _, info := range infos {
+		tp, err := ps.Join(info.ID.String())
+		if err != nil {
+			t.Fatalf("expected thread topics to be joinable again, got %v", err)
+		}
+		_ = tp.Close()
+	}
+}
+
+func TestPubSub_MaxMessageSize(t *testing.T) {
+	t.Parallel()
+	n1 := makeNetworkWithConfig(t, Config{MaxPubSubMessageSize: 4096})
+	defer n1.Close()
+	n2 := makeNetwork(t)
+	defer n2.Close()
+	n2.Host().Peerstore().AddAddrs(n1.Host().ID(), n1.Host().Addrs(), peerstore.PermanentAddrTTL)
+
+	// Count the records n2 receives over pubsub
+	ps2 := n2.(*net).server.ps
+	var received int32
+	handler := ps2.handler
+	ps2.handler = func(ctx context.Context, req *pb.PushRecordRequest) {
+		atomic.AddInt32(&received, 1)
+		handler(ctx, req)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	info := createThread(t, ctx, n1)
+	addr, err := ma.NewMultiaddr("/p2p/" + n1.Host().ID().String() + "/thread/" + info.ID.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = n2.AddThread(ctx, addr, core.WithThreadKey(info.Key)); err != nil {
+		t.Fatal(err)
+	}
+	// n1 pushes records to n2 directly
+	lg2, err := n2.(*net).getOwnLog(info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = n1.(*net).store.AddLog(info.ID, thread.LogInfo{
+		ID:     lg2.ID,
+		PubKey: lg2.PubKey,
+		Addrs:  []ma.Multiaddr{util.MustParseAddr("/p2p/" + n2.Host().ID().String())},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	n1.Host().Peerstore().AddAddrs(n2.Host().ID(), n2.Host().Addrs(), peerstore.PermanentAddrTTL)
+	for start := time.Now(); ; time.Sleep(time.Millisecond * 50) {
+		if peers, _ := n1.TopicPeers(info.ID); len(peers) > 0 {
+			break
+		}
+		if time.Since(start) > time.Second*5 {
+			t.Fatal("timed out waiting for topic peers")
+		}
+	}
+	sub, err := n2.Subscribe(ctx, core.WithSubFilter(info.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	create := func(msg string) core.ThreadRecord {
+		body, err := cbornode.WrapObject(map[string]interface{}{"msg": msg}, mh.SHA2_256, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := n1.CreateRecord(ctx, info.ID, body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case got := <-sub:
+			if !got.Value().Cid().Equals(r.Value().Cid()) {
+				t.Fatalf("expected record %s, got %s", r.Value().Cid(), got.Value().Cid())
+			}
+		case <-time.After(time.Second * 5):
+			t.Fatal("timed out waiting for record")
+		}
+		return r
+	}
+
+	// A small record is published
+	create("yo!")
+	for start := time.Now(); atomic.LoadInt32(&received) == 0; time.Sleep(time.Millisecond * 50) {
+		if time.Since(start) > time.Second*5 {
+			t.Fatal("expected the small record to be received over pubsub")
+		}
+	}
+
+	// An oversized record is only pushed directly
+	r := create(strings.Repeat("x", 8192))
+	for start := time.Now(); ; time.Sleep(time.Millisecond * 50) {
+		ds := n1.Deliveries(r.Value().Cid())
+		if len(ds) > 0 {
+			if ds[0].Peer != n2.Host().ID() || ds[0].Outcome != core.PushAccepted {
+				t.Fatalf("expected the oversized record to be pushed directly, got %+v", ds[0])
+			}
+			break
+		}
+		if time.Since(start) > time.Second*5 {
+			t.Fatal("timed out waiting for the direct push")
+		}
+	}
+	time.Sleep(time.Millisecond * 500)
+	if got := atomic.LoadInt32(&received); got != 1 {
+		t.Fatalf("expected the oversized record not to be published, got %d messages", got)
+	}
+}
+
+func TestPubSub_MaxTopicPeers(t *testing.T) {
+	t.Parallel()
+	n1 := makeNetworkWithConfig(t, Config{MaxTopicPeers: 1})
+	defer n1.Close()
+	n2 := makeNetwork(t)
+	defer n2.Close()
+	n3 := makeNetwork(t)
+	defer n3.Close()
+	n2.Host().Peerstore().AddAddrs(n1.Host().ID(), n1.Host().Addrs(), peerstore.PermanentAddrTTL)
+	n3.Host().Peerstore().AddAddrs(n1.Host().ID(), n1.Host().Addrs(), peerstore.PermanentAddrTTL)
+
+	// Count the records n2 and n3 receive over pubsub
+	var received int32
+	for _, n := range []core.Net{n2, n3} {
+		ps := n.(*net).server.ps
+		handler := ps.handler
+		ps.handler = func(ctx context.Context, req *pb.PushRecordRequest) {
+			atomic.AddInt32(&received, 1)
+			handler(ctx, req)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	info := createThread(t, ctx, n1)
+	addr, err := ma.NewMultiaddr("/p2p/" + n1.Host().ID().String() + "/thread/" + info.ID.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range []core.Net{n2, n3} {
+		if _, err = n.AddThread(ctx, addr, core.WithThreadKey(info.Key)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// n1 pushes records to n2 directly
+	lg2, err := n2.(*net).getOwnLog(info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = n1.(*net).store.AddLog(info.ID, thread.LogInfo{
+		ID:     lg2.ID,
+		PubKey: lg2.PubKey,
+		Addrs:  []ma.Multiaddr{util.MustParseAddr("/p2p/" + n2.Host().ID().String())},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	n1.Host().Peerstore().AddAddrs(n2.Host().ID(), n2.Host().Addrs(), peerstore.PermanentAddrTTL)
+	for start := time.Now(); ; time.Sleep(time.Millisecond * 50) {
+		if peers, _ := n1.TopicPeers(info.ID); len(peers) > 1 {
+			break
+		}
+		if time.Since(start) > time.Second*5 {
+			t.Fatal("timed out waiting for topic peers")
+		}
+	}
+	create := func(msg string) core.ThreadRecord {
+		body, err := cbornode.WrapObject(map[string]interface{}{"msg": msg}, mh.SHA2_256, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := n1.CreateRecord(ctx, info.ID, body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+
+	// With more topic peers than the max, a record is only pushed directly
+	r := create("yo!")
+	for start := time.Now(); ; time.Sleep(time.Millisecond * 50) {
+		ds := n1.Deliveries(r.Value().Cid())
+		if len(ds) > 0 {
+			if ds[0].Peer != n2.Host().ID() || ds[0].Outcome != core.PushAccepted {
+				t.Fatalf("expected the record to be pushed directly, got %+v", ds[0])
+			}
+			break
+		}
+		if time.Since(start) > time.Second*5 {
+			t.Fatal("timed out waiting for the direct push")
+		}
+	}
+	time.Sleep(time.Millisecond * 500)
+	if got := atomic.LoadInt32(&received); got != 0 {
+		t.Fatalf("expected the record not to be published, got %d messages", got)
+	}
+
+	// Once the topic is within the max, records are published again
+	n1.(*net).SetMaxTopicPeers(2)
+	create("yo again!")
+	for start := time.Now(); atomic.LoadInt32(&received) == 0; time.Sleep(time.Millisecond * 50) {
+		if time.Since(start) > time.Second*5 {
+			t.Fatal("expected the record to be received over pubsub")
+		}
+	}
+}
+
+func TestPubSub_RejectsMalformedRecords(t *testing.T) {
+	t.Parallel()
+	n1 := makeNetwork(t)
+	defer n1.Close()
+	n2 := makeNetwork(t)
+	defer n2.Close()
+
+	n1.Host().Peerstore().AddAddrs(n2.Host().ID(), n2.Host().Addrs(), peerstore.PermanentAddrTTL)
+	n2.Host().Peerstore().AddAddrs(n1.Host().ID(), n1.Host().Addrs(), peerstore.PermanentAddrTTL)
+
+	ps2 := n2.(*net).server.ps
+	var received int32
+	handler := ps2.handler
+	ps2.handler = func(ctx context.Context, req *pb.PushRecordRequest) {
+		atomic.AddInt32(&received, 1)
+		handler(ctx, req)
+	}
+
+	ctx := context.Background()
+	info := createThread(t, ctx, n1)
+	addr, err := ma.NewMultiaddr("/p2p/" + n1.Host().ID().String() + "/thread/" + info.ID.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = n2.AddThread(ctx, addr, core.WithThreadKey(info.Key)); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Second * 2) // Wait for the pubsub mesh
+
+	// Watch the messages delivered by n2's pubsub layer
+	ps2.RLock()
+	sub, err := ps2.m[info.ID].t.Subscribe()
+	ps2.RUnlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Cancel()
+
+	// Replace n1's validator so that it publishes a corrupt payload
+	ps1 := n1.(*net).server.ps
+	if err = ps1.ps.UnregisterTopicValidator(info.ID.String()); err != nil {
+		t.Fatal(err)
+	}
+	accept := func(context.Context, peer.ID, *pubsub.Message) bool { return true }
+	if err = ps1.ps.RegisterTopicValidator(info.ID.String(), accept); err != nil {
+		t.Fatal(err)
+	}
+	ps1.RLock()
+	topic := ps1.m[info.ID]
+	ps1.RUnlock()
+	if err = topic.t.Publish(ctx, []byte("garbage")); err != nil {
+		t.Fatal(err)
+	}
+	sctx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	if _, err = sub.Next(sctx); err == nil {
+		t.Fatal("expected malformed records to be dropped by the validator")
+	}
+	if n := atomic.LoadInt32(&received); n != 0 {
+		t.Fatalf("expected malformed records to be rejected, got %d", n)
+	}
+
+	if err = ps1.ps.UnregisterTopicValidator(info.ID.String()); err != nil {
+		t.Fatal(err)
+	}
+	if err = ps1.ps.RegisterTopicValidator(info.ID.String(), ps1.topicValidator); err != nil {
+		t.Fatal(err)
+	}
+	body, err := cbornode.WrapObject(map[string]interface{}{
+		"msg": "yo!",
+	}, mh.SHA2_256, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = n1.CreateRecord(ctx, info.ID, body); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Second)
+	if atomic.LoadInt32(&received) == 0 {
+		t.Fatal("expected valid records to be received over pubsub")
+	}
+}
+
+func TestNet_Receipts(t *testing.T) {
+	t.Parallel()
+	n1 := makeNetwork(t)
+	defer n1.Close()
+	n2 := makeNetwork(t)
+	defer n2.Close()
+
+	n1.Host().Peerstore().AddAddrs(n2.Host().ID(), n2.Host().Addrs(), peerstore.PermanentAddrTTL)
+	n2.Host().Peerstore().AddAddrs(n1.Host().ID(), n1.Host().Addrs(), peerstore.PermanentAddrTTL)
+
+	ctx := context.Background()
+	info := createThread(t, ctx, n1)
+	addr, err := ma.NewMultiaddr("/p2p/" + n1.Host().ID().String() + "/thread/" + info.ID.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = n2.AddThread(ctx, addr, core.WithThreadKey(info.Key)); err != nil {
+		t.Fatal(err)
+	}
+	body, err := cbornode.WrapObject(map[string]interface{}{
+		"msg": "yo!",
+	}, mh.SHA2_256, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := n1.CreateRecord(ctx, info.ID, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Let n1 learn about n2's log
+	if _, err = n2.CreateRecord(ctx, info.ID, body); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Second)
+
+	if _, err = n2.SendReceipt(ctx, info.ID, r.Value().Cid()); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Second)
+
+	lg, err := n2.(*net).getOwnLog(info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, n := range []core.Net{n1, n2} {
+		receipts, err := n.Receipts(info.ID, r.Value().Cid())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(receipts) != 1 || !receipts[lg.ID].Equals(lg.Head) {
+			t.Fatalf("expected peer %d to have a receipt from log %s, got %v", i+1, lg.ID, receipts)
+		}
+	}
+
+	// A regular record with a body shaped like a receipt is not a receipt
+	fake, err := cbornode.WrapObject(&receiptBody{ThreadsReceipt: lg.Head}, mh.SHA2_256, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = n2.CreateRecord(ctx, info.ID, fake); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Second)
+	receipts, err := n1.Receipts(info.ID, lg.Head)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(receipts) != 0 {
+		t.Fatalf("expected a regular record not to be taken as a receipt, got %v", receipts)
+	}
+}
+
+func TestNet_Deliveries(t *testing.T) {
+	t.Parallel()
+	n1 := makeNetwork(t)
+	defer n1.Close()
+	n2 := makeNetwork(t)
+	defer n2.Close()
+
+	n1.Host().Peerstore().AddAddrs(n2.Host().ID(), n2.Host().Addrs(), peerstore.PermanentAddrTTL)
+	n2.Host().Peerstore().AddAddrs(n1.Host().ID(), n1.Host().Addrs(), peerstore.PermanentAddrTTL)
+
+	ctx := context.Background()
+	info := createThread(t, ctx, n1)
+	addr, err := ma.NewMultiaddr("/p2p/" + n1.Host().ID().String() + "/thread/" + info.ID.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = n2.AddThread(ctx, addr, core.WithThreadKey(info.Key)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Add an address for a peer that can't be reached
+	_, pk, err := crypto.GenerateEd25519Key(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gone, err := peer.IDFromPublicKey(pk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tn2 := n2.(*net)
+	lg, err := tn2.getOwnLog(info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	goneAddr := util.MustParseAddr("/ip4/127.0.0.1/tcp/1/p2p/" + gone.String())
+	if err = tn2.store.AddAddr(info.ID, lg.ID, goneAddr, peerstore.PermanentAddrTTL); err != nil {
+		t.Fatal(err)
+	}
+
+	body, err := cbornode.WrapObject(map[string]interface{}{
+		"msg": "yo!",
+	}, mh.SHA2_256, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The first push sends n2's log to n1 instead of the record
+	if _, err = n2.CreateRecord(ctx, info.ID, body); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Second)
+	r, err := n2.CreateRecord(ctx, info.ID, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Second)
+
+	outcomes := make(map[peer.ID]core.PushOutcome)
+	for _, d := range n2.Deliveries(r.Value().Cid()) {
+		outcomes[d.Peer] = d.Outcome
+	}
+	if len(outcomes) != 2 {
+		t.Fatalf("expected deliveries to 2 peers, got %v", outcomes)
+	}
+	if outcomes[n1.Host().ID()] != core.PushAccepted {
+		t.Fatalf("expected push to %s to be accepted, got %s", n1.Host().ID(), outcomes[n1.Host().ID()])
+	}
+	if o, ok := outcomes[gone]; !ok || o == core.PushAccepted {
+		t.Fatalf("expected push to %s not to be accepted, got %s", gone, o)
+	}
+}
+
+func TestNet_PushResult(t *testing.T) {
+	t.Parallel()
+	n1 := makeNetwork(t)
+	defer n1.Close()
+	n2 := makeNetwork(t)
+	defer n2.Close()
+	n1.Host().Peerstore().AddAddrs(n2.Host().ID(), n2.Host().Addrs(), peerstore.PermanentAddrTTL)
+
+	ctx := context.Background()
+	info := createThread(t, ctx, n1)
+	if _, err := n2.CreateThread(ctx, info.ID, core.WithThreadKey(info.Key)); err != nil {
+		t.Fatal(err)
+	}
+	tn1, tn2 := n1.(*net), n2.(*net)
+	lg1, err := tn1.getOwnLog(info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tn2.store.AddLog(info.ID, thread.LogInfo{ID: lg1.ID, PubKey: lg1.PubKey}); err != nil {
+		t.Fatal(err)
+	}
+	lg2, err := tn2.getOwnLog(info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr2, err := ma.NewMultiaddr("/p2p/" + n2.Host().ID().String() + "/thread/" + info.ID.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tn1.store.AddLog(info.ID, thread.LogInfo{ID: lg2.ID, PubKey: lg2.PubKey, Addrs: []ma.Multiaddr{addr2}}); err != nil {
+		t.Fatal(err)
+	}
+	// A member that can't be reached
+	_, pk, err := crypto.GenerateEd25519Key(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pid, err := peer.IDFromPublicKey(pk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr3, err := ma.NewMultiaddr("/p2p/" + pid.String() + "/thread/" + info.ID.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tn1.store.AddLog(info.ID, thread.LogInfo{ID: pid, PubKey: pk, Addrs: []ma.Multiaddr{addr3}}); err != nil {
+		t.Fatal(err)
+	}
+
+	body, err := cbornode.WrapObject(map[string]interface{}{
+		"foo": "bar",
+	}, mh.SHA2_256, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var res core.PushResult
+	if _, err = n1.CreateRecord(ctx, info.ID, body, core.WithPushResult(&res)); err != nil {
+		t.Fatal(err)
+	}
+	if res.Succeeded != 1 || res.Failed != 1 {
+		t.Fatalf("expected 1 succeeded and 1 failed peer, got %d and %d", res.Succeeded, res.Failed)
+	}
+	if len(res.Deliveries) != 2 {
+		t.Fatalf("expected 2 deliveries, got %d", len(res.Deliveries))
+	}
+	for _, d := range res.Deliveries {
+		if accepted := d.Outcome == core.PushAccepted; accepted != (d.Peer == n2.Host().ID()) {
+			t.Fatalf("unexpected outcome %s for peer %s", d.Outcome, d.Peer)
+		}
+	}
+}
+
+func TestNet_ReconnectSync(t *testing.T) {
+	t.Parallel()
+	n1 := makeNetwork(t)
+	defer n1.Close()
+	n2 := makeNetwork(t)
+	defer n2.Close()
+	n2.SetAutoPull(false)
+
+	n1.Host().Peerstore().AddAddrs(n2.Host().ID(), n2.Host().Addrs(), peerstore.PermanentAddrTTL)
+	n2.Host().Peerstore().AddAddrs(n1.Host().ID(), n1.Host().Addrs(), peerstore.PermanentAddrTTL)
+
+	ctx := context.Background()
+	info := createThread(t, ctx, n1)
+	addr, err := ma.NewMultiaddr("/p2p/" + n1.Host().ID().String() + "/thread/" + info.ID.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = n2.AddThread(ctx, addr, core.WithThreadKey(info.Key)); err != nil {
+		t.Fatal(err)
+	}
+	if err = n2.Host().Network().ClosePeer(n1.Host().ID()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Write a record on n1 without pushing it
+	body, err := cbornode.WrapObject(map[string]interface{}{
+		"msg": "while you were away",
+	}, mh.SHA2_256, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec, _, err := n1.(*net).appendOwnRecord(ctx, info.ID, body, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tn2 := n2.(*net)
+	tn2.reconnects.Lock()
+	tn2.reconnects.last = make(map[peer.ID]time.Time)
+	tn2.reconnects.Unlock()
+	if err = n2.Host().Connect(ctx, peer.AddrInfo{ID: n1.Host().ID(), Addrs: n1.Host().Addrs()}); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 50; i++ {
+		if _, err = n2.GetRecord(ctx, info.ID, rec.Cid()); err == nil {
+			return
+		}
+		time.Sleep(time.Millisecond * 100)
+	}
+	t.Fatal("expected record to be pulled after reconnecting")
+}
+
+func TestNet_AddReplicator(t *testing.T) {
+	t.Parallel()
+	n1 := makeNetwork(t)
+	defer n1.Close()
+	n2 := makeNetwork(t)
+	defer n2.Close()
+
+	n1.Host().Peerstore().AddAddrs(n2.Host().ID(), n2.Host().Addrs(), peerstore.PermanentAddrTTL)
+	n2.Host().Peerstore().AddAddrs(n1.Host().ID(), n1.Host().Addrs(), peerstore.PermanentAddrTTL)
+
+	ctx := context.Background()
+	info := createThread(t, ctx, n1)
+
+	body, err := cbornode.WrapObject(map[string]interface{}{
+		"msg": "yo!",
+	}, mh.SHA2_256, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := n1.CreateRecord(ctx, info.ID, body); err != nil {
+		t.Fatal(err)
+	}
+
+	addr, err := ma.NewMultiaddr("/p2p/" + n2.Host().ID().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = n1.AddReplicator(ctx, info.ID, addr); err != nil {
+		t.Fatal(err)
+	}
+
+	info2, err := n1.GetThread(context.Background(), info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(info2.Logs) != 1 {
+		t.Fatalf("expected 1 log got %d", len(info2.Logs))
+	}
+	if len(info2.Logs[0].Addrs) != 2 {
+		t.Fatalf("expected 2 addresses got %d", len(info2.Logs[0].Addrs))
+	}
+
+	info3, err := n2.GetThread(context.Background(), info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(info3.Logs) != 1 {
+		t.Fatalf("expected 1 log got %d", len(info2.Logs))
+	}
+	if len(info3.Logs[0].Addrs) != 2 {
+		t.Fatalf("expected 2 addresses got %d", len(info3.Logs[0].Addrs))
+	}
+}
+
+func TestNet_DeleteThread(t *testing.T) {
+	t.Parallel()
+	n := makeNetwork(t)
+	defer n.Close()
+
+	ctx := context.Background()
+	info := createThread(t, ctx, n)
+
+	body, err := cbornode.WrapObject(map[string]interface{}{
+		"foo": "bar",
+		"baz": []byte("howdy"),
+	}, mh.SHA2_256, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := n.CreateRecord(ctx, info.ID, body); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := n.CreateRecord(ctx, info.ID, body); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = n.DeleteThread(ctx, info.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := n.GetThread(ctx, info.ID); err != logstore.ErrThreadNotFound {
+		t.Fatal("thread was not deleted")
+	}
+}
+
+func TestNet_DeleteThreadClean(t *testing.T) {
+	t.Parallel()
+	n1 := makeNetwork(t)
+	defer n1.Close()
+	n2 := makeNetwork(t)
+	defer n2.Close()
+
+	ctx := context.Background()
+	info := createThread(t, ctx, n1)
+	tn1, tn2 := n1.(*net), n2.(*net)
+	var rs []core.Record
+	for i := 0; i < 2; i++ {
+		body, err := cbornode.WrapObject(map[string]interface{}{
+			"foo": i,
+		}, mh.SHA2_256, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := n1.CreateRecord(ctx, info.ID, body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rs = append(rs, r.Value())
+	}
+	if err := tn1.store.PutString(info.ID, "foo", "bar"); err != nil {
+		t.Fatal(err)
+	}
+	subCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	sub, err := n1.Subscribe(subCtx, core.WithSubFilter(info.ID), core.WithSubDeletions())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = n1.DeleteThread(ctx, info.ID); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case rec := <-sub:
+		if rec.Value() != nil || !rec.ThreadID().Equals(info.ID) {
+			t.Fatalf("expected a deletion of thread %s, got a record in %s", info.ID, rec.ThreadID())
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatal("expected subscribers to be notified of the deletion")
+	}
+	for _, r := range rs {
+		if has, err := tn1.hasBlock(info.ID, r.Cid()); err != nil || has {
+			t.Fatalf("expected record %s to be removed", r.Cid())
+		}
+	}
+	if v, err := tn1.store.GetString(info.ID, "foo"); err != nil || v != nil {
+		t.Fatal("expected thread metadata to be removed")
+	}
+	ts, err := tn1.store.Threads()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range ts {
+		if id.Equals(info.ID) {
+			t.Fatal("expected thread to be removed from the store")
+		}
+	}
+	if err = n1.DeleteThread(ctx, info.ID); err != nil {
+		t.Fatalf("expected deleting a deleted thread to succeed, got %v", err)
+	}
+
+	// Re-adding the thread starts clean
+	if _, err = n1.CreateThread(ctx, info.ID, core.WithThreadKey(info.Key)); err != nil {
+		t.Fatal(err)
+	}
+	info2, err := n1.GetThread(ctx, info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(info2.Logs) != 1 || info2.Logs[0].Head.Defined() {
+		t.Fatalf("expected a single empty log, got %v", info2.Logs)
+	}
+
+	// A followed thread may not hold the full history of its logs
+	if _, err = n2.CreateThread(ctx, info.ID, core.WithThreadKey(thread.NewServiceKey(info.Key.Service()))); err != nil {
+		t.Fatal(err)
+	}
+	lg1, err := tn1.getOwnLog(info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tn2.store.AddLog(info.ID, thread.LogInfo{ID: lg1.ID, PubKey: lg1.PubKey, Head: rs[1].Cid()}); err != nil {
+		t.Fatal(err)
+	}
+	if err = n2.DeleteThread(ctx, info.ID); err != nil {
+		t.Fatalf("expected deleting a followed thread to succeed, got %v", err)
+	}
+}
+
+func TestNet_DeleteLog(t *testing.T) {
+	t.Parallel()
+	n1 := makeNetwork(t)
+	defer n1.Close()
+	n2 := makeNetwork(t)
+	defer n2.Close()
+	n1.Host().Peerstore().AddAddrs(n2.Host().ID(), n2.Host().Addrs(), peerstore.PermanentAddrTTL)
+
+	ctx := context.Background()
+	info := createThread(t, ctx, n1)
+	if _, err := n2.CreateThread(ctx, info.ID, core.WithThreadKey(info.Key)); err != nil {
+		t.Fatal(err)
+	}
+	tn1, tn2 := n1.(*net), n2.(*net)
+	lg1, err := tn1.getOwnLog(info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tn2.store.AddLog(info.ID, thread.LogInfo{ID: lg1.ID, PubKey: lg1.PubKey}); err != nil {
+		t.Fatal(err)
+	}
+	lg2, err := tn2.getOwnLog(info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr, err := ma.NewMultiaddr("/p2p/" + n2.Host().ID().String() + "/thread/" + info.ID.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tn1.store.AddLog(info.ID, thread.LogInfo{ID: lg2.ID, PubKey: lg2.PubKey, Addrs: []ma.Multiaddr{addr}}); err != nil {
+		t.Fatal(err)
+	}
+	body, err := cbornode.WrapObject(map[string]interface{}{
+		"foo": "bar",
+	}, mh.SHA2_256, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r2, err := n2.CreateRecord(ctx, info.ID, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = n1.AddRecord(ctx, info.ID, lg2.ID, r2.Value()); err != nil {
+		t.Fatal(err)
+	}
+	var res core.PushResult
+	if _, err = n1.CreateRecord(ctx, info.ID, body, core.WithPushResult(&res)); err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Deliveries) != 1 || res.Deliveries[0].Peer != n2.Host().ID() {
+		t.Fatalf("expected a push to the follower, got %v", res.Deliveries)
+	}
+
+	if err = n1.DeleteLog(ctx, info.ID, lg1.ID); !errors.Is(err, ErrOwnLog) {
+		t.Fatalf("expected deleting the own log to be refused, got %v", err)
+	}
+	if err = n1.DeleteLog(ctx, info.ID, lg2.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = tn1.store.GetLog(info.ID, lg2.ID); !errors.Is(err, logstore.ErrLogNotFound) {
+		t.Fatalf("expected the log to be removed, got %v", err)
+	}
+	if has, err := tn1.hasBlock(info.ID, r2.Value().Cid()); err != nil || has {
+		t.Fatal("expected the log's records to be removed")
+	}
+	if _, err = n1.CreateRecord(ctx, info.ID, body, core.WithPushResult(&res)); err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Deliveries) != 0 {
+		t.Fatalf("expected no pushes to the deleted log, got %v", res.Deliveries)
+	}
+
+	// The deleted log is not added back when pushed or pulled
+	n2.Host().Peerstore().AddAddrs(n1.Host().ID(), n1.Host().Addrs(), peerstore.PermanentAddrTTL)
+	if err = tn2.server.pushLog(ctx, info.ID, lg2, n1.Host().ID(), nil, nil, nil); err == nil {
+		t.Fatal("expected pushing the deleted log to be refused")
+	}
+	req, err := tn2.server.newPushRecordRequest(ctx, info.ID, lg2.ID, r2.Value())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = tn1.server.PushRecord(ctx, req); status.Code(err) != codes.NotFound {
+		t.Fatalf("expected pushing a record to the deleted log to be refused, got %v", err)
+	}
+	paddr, err := ma.NewMultiaddr("/p2p/" + n2.Host().ID().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = n1.AddReplicator(ctx, info.ID, paddr); err != nil {
+		t.Fatal(err)
+	}
+	if err = n1.PullThread(ctx, info.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = tn1.store.GetLog(info.ID, lg2.ID); !errors.Is(err, logstore.ErrLogNotFound) {
+		t.Fatalf("expected the deleted log not to be pulled, got %v", err)
+	}
+	if has, err := tn1.hasBlock(info.ID, r2.Value().Cid()); err != nil || has {
+		t.Fatal("expected the deleted log's records not to be pulled")
+	}
+}
+
+func TestNet_RekeyThread(t *testing.T) {
+	t.Parallel()
+	n := makeNetwork(t)
+	defer n.Close()
+
+	ctx := context.Background()
+	info := createThread(t, ctx, n)
+
+	body, err := cbornode.WrapObject(map[string]interface{}{
+		"foo": "bar",
+	}, mh.SHA2_256, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r1, err := n.CreateRecord(ctx, info.ID, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := n.RekeyThread(ctx, info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key.String() == info.Key.String() {
+		t.Fatal("expected thread key to change")
+	}
+	info2, err := n.GetThread(ctx, info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info2.Key.String() != key.String() {
+		t.Fatal("expected new thread key to be stored")
+	}
+
+	r2, err := n.CreateRecord(ctx, info.ID, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = n.GetRecord(ctx, info.ID, r1.Value().Cid()); err != nil {
+		t.Fatalf("expected record from before rekey to be readable: %v", err)
+	}
+	if _, err = n.GetRecord(ctx, info.ID, r2.Value().Cid()); err != nil {
+		t.Fatalf("expected record from after rekey to be readable: %v", err)
+	}
+}
+
+func TestNet_RotateReadKey(t *testing.T) {
+	t.Parallel()
+	n1 := makeNetwork(t)
+	defer n1.Close()
+	n2 := makeNetwork(t)
+	defer n2.Close()
+	n3 := makeNetwork(t)
+	defer n3.Close()
+	for _, n := range []core.Net{n2, n3} {
+		n.Host().Peerstore().AddAddrs(n1.Host().ID(), n1.Host().Addrs(), peerstore.PermanentAddrTTL)
+		n1.Host().Peerstore().AddAddrs(n.Host().ID(), n.Host().Addrs(), peerstore.PermanentAddrTTL)
+	}
+
+	ctx := context.Background()
+	info := createThread(t, ctx, n1)
+	addr, err := ma.NewMultiaddr("/p2p/" + n1.Host().ID().String() + "/thread/" + info.ID.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Members are known by the addresses of their logs
+	for _, n := range []core.Net{n2, n3} {
+		if _, err = n.AddThread(ctx, addr, core.WithThreadKey(info.Key)); err != nil {
+			t.Fatal(err)
+		}
+		lg, err := n.(*net).getOwnLog(info.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		laddr, err := ma.NewMultiaddr("/p2p/" + n.Host().ID().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		lg = thread.LogInfo{ID: lg.ID, PubKey: lg.PubKey, Addrs: []ma.Multiaddr{laddr}}
+		if err = n1.(*net).store.AddLog(info.ID, lg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	key, err := n1.RotateReadKey(ctx, info.ID, n3.Host().ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key.Service().String() != info.Key.Service().String() || key.Read().String() == info.Key.Read().String() {
+		t.Fatal("expected only the read-key to change")
+	}
+	info2, err := n2.GetThread(ctx, info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info2.Key.Read().String() != key.Read().String() {
+		t.Fatal("expected member to receive the new read-key")
+	}
+	info3, err := n3.GetThread(ctx, info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info3.Key.Read().String() != info.Key.Read().String() {
+		t.Fatal("expected revoked member to keep the old read-key")
+	}
+
+	// The revoked member replicates new records, but can't read them
+	body, err := cbornode.WrapObject(map[string]interface{}{
+		"foo": "bar",
+	}, mh.SHA2_256, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := n1.CreateRecord(ctx, info.ID, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = n3.PullThread(ctx, info.ID); err != nil {
+		t.Fatal(err)
+	}
+	rec, err := n3.GetRecord(ctx, info.ID, r.Value().Cid())
+	if err != nil {
+		t.Fatal(err)
+	}
+	event, err := cbor.EventFromRecord(ctx, n3, rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = event.GetBody(ctx, n3, info3.Key.Read()); err == nil {
+		t.Fatal("expected revoked member not to read new records")
+	}
+	if _, err = event.GetBody(ctx, n3, key.Read()); err != nil {
+		t.Fatalf("expected new records to be readable with the new read-key: %v", err)
+	}
+
+	// The log of the revoked member is tombstoned by the members, and can no
+	// longer sign key changes
+	lg3, err := n3.(*net).getOwnLog(info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range []core.Net{n1, n2} {
+		if removed, err := n.(*net).isTombstoned(info.ID, lg3.ID); err != nil {
+			t.Fatal(err)
+		} else if !removed {
+			t.Fatal("expected revoked log to be tombstoned")
+		}
+	}
+	sk, _, err := crypto.GenerateKeyPair(crypto.Ed25519, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lg, err := createLog(n3.Host().ID(), sk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pushed := thread.NewKey(key.Service(), thread.NewRandomKey().Read())
+	kc, err := signKeyChange(info.ID, lg3, key, pushed.Service(), pushed.Read(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := &pb.PushLogRequest_Body{
+		ThreadID:   &pb.ProtoThreadID{ID: info.ID},
+		ServiceKey: &pb.ProtoKey{Key: pushed.Service()},
+		ReadKey:    &pb.ProtoKey{Key: pushed.Read()},
+		Log:        logToProto(lg),
+		KeyChange:  kc,
+	}
+	header, err := SignRequest(n3.(*net).getPrivKey(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = n1.(*net).server.PushLog(ctx, &pb.PushLogRequest{Header: header, Body: req}); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("expected key change of a revoked log to be denied, got %v", err)
+	}
+	cur, err := n1.GetThread(ctx, info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cur.Key.Read().String() != key.Read().String() {
+		t.Fatal("expected read-key not to change")
+	}
+}
+
+func TestNet_RekeyCutover(t *testing.T) {
+	t.Parallel()
+	n := makeNetworkWithConfig(t, Config{RekeyCutover: time.Hour})
+	defer n.Close()
+
+	ctx := context.Background()
+	info := createThread(t, ctx, n)
+	tn := n.(*net)
+	body, err := cbornode.WrapObject(map[string]interface{}{
+		"foo": "bar",
+	}, mh.SHA2_256, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := n.CreateRecord(ctx, info.ID, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pbrec, err := cbor.RecordToProto(ctx, tn.threadDAG(info.ID), r.Value())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = n.RekeyThread(ctx, info.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = tn.recordFromProto(info.ID, pbrec); err != nil {
+		t.Fatalf("expected record with the replaced key to be accepted during cutover: %v", err)
+	}
+	past := time.Now().Add(-2 * time.Hour).UnixNano()
+	if err = tn.store.PutInt64(info.ID, rekeyedAtKey, past); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = tn.recordFromProto(info.ID, pbrec); err == nil {
+		t.Fatal("expected record with the replaced key to be rejected after cutover")
+	}
+	if _, err = n.GetRecord(ctx, info.ID, r.Value().Cid()); err != nil {
+		t.Fatalf("expected stored record to remain readable: %v", err)
+	}
+}
+
+func TestServer_RekeyAuthorization(t *testing.T) {
+	t.Parallel()
+	n := makeNetwork(t)
+	defer n.Close()
+
+	ctx := context.Background()
+	info := createThread(t, ctx, n)
+	tn := n.(*net)
+	own, err := tn.getOwnLog(info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A peer with a log in the thread, but no log key in it
+	sk, _, err := crypto.GenerateKeyPair(crypto.Ed25519, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pid, err := peer.IDFromPrivateKey(sk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr, err := ma.NewMultiaddr("/p2p/" + pid.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tn.store.AddAddr(info.ID, own.ID, addr, peerstore.PermanentAddrTTL); err != nil {
+		t.Fatal(err)
+	}
+	lg, err := createLog(pid, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lg.Addrs = []ma.Multiaddr{addr}
+
+	push := func(key thread.Key, kc *pb.PushLogRequest_KeyChange) error {
+		body := &pb.PushLogRequest_Body{
+			ThreadID:   &pb.ProtoThreadID{ID: info.ID},
+			ServiceKey: &pb.ProtoKey{Key: key.Service()},
+			ReadKey:    &pb.ProtoKey{Key: key.Read()},
+			Log:        logToProto(lg),
+			KeyChange:  kc,
+		}
+		header, err := SignRequest(sk, body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = tn.server.PushLog(ctx, &pb.PushLogRequest{Header: header, Body: body})
+		return err
+	}
+	checkKey := func(key thread.Key) {
+		cur, err := n.GetThread(ctx, info.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cur.Key.String() != key.String() {
+			t.Fatal("unexpected thread key")
+		}
+	}
+
+	key := thread.NewRandomKey()
+	if err = push(key, nil); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("expected unsigned key change to be denied, got %v", err)
+	}
+	forged, err := signKeyChange(info.ID, thread.LogInfo{ID: own.ID, PrivKey: lg.PrivKey}, info.Key, key.Service(), key.Read(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = push(key, forged); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("expected key change not signed by the log's key to be denied, got %v", err)
+	}
+	unknown, err := signKeyChange(info.ID, lg, info.Key, key.Service(), key.Read(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = push(key, unknown); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("expected key change signed by a log not in the thread to be denied, got %v", err)
+	}
+	checkKey(info.Key)
+
+	// A change signed by a log in the thread is accepted, but can't be replayed
+	kc, err := signKeyChange(info.ID, own, info.Key, key.Service(), key.Read(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = push(key, kc); err != nil {
+		t.Fatal(err)
+	}
+	checkKey(key)
+	if _, err = n.RekeyThread(ctx, info.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err = push(key, kc); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("expected replayed key change to be denied, got %v", err)
+	}
+}
+
+func TestServer_ThreadIntroducers(t *testing.T) {
+	t.Parallel()
+	introducer, _, err := crypto.GenerateKeyPair(crypto.Ed25519, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ipid, err := peer.IDFromPrivateKey(introducer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, _, err := crypto.GenerateKeyPair(crypto.Ed25519, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := makeNetworkWithConfig(t, Config{ThreadIntroducers: []peer.ID{ipid}})
+	defer n.Close()
+	tn := n.(*net)
+
+	ctx := context.Background()
+	id := thread.NewIDV1(thread.Raw, 32)
+	key := thread.NewRandomKey()
+	push := func(sk crypto.PrivKey) error {
+		lg, err := createLog(tn.host.ID(), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body := &pb.PushLogRequest_Body{
+			ThreadID:   &pb.ProtoThreadID{ID: id},
+			ServiceKey: &pb.ProtoKey{Key: key.Service()},
+			ReadKey:    &pb.ProtoKey{Key: key.Read()},
+			Log:        logToProto(lg),
+		}
+		header, err := SignRequest(sk, body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = tn.server.PushLog(ctx, &pb.PushLogRequest{Header: header, Body: body})
+		return err
+	}
+
+	// A peer that is not an introducer can't push a log for an unknown thread
+	if err = push(other); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("expected permission denied, got %v", err)
+	}
+	if _, err = tn.store.GetThread(id); !errors.Is(err, logstore.ErrThreadNotFound) {
+		t.Fatalf("expected the thread not to be added, got %v", err)
+	}
+
+	// An introducer can
+	if err = push(introducer); err != nil {
+		t.Fatalf("expected the introducer to add the thread, got %v", err)
+	}
+	if _, err = tn.store.GetThread(id); err != nil {
+		t.Fatalf("expected the thread to be added: %v", err)
+	}
+}
+
+func TestNet_PullByHeight(t *testing.T) {
+	t.Parallel()
+	n := makeNetwork(t)
+	defer n.Close()
+
+	ctx := context.Background()
+	info := createThread(t, ctx, n)
+
+	var recs []core.ThreadRecord
+	for i := 0; i < 5; i++ {
+		body, err := cbornode.WrapObject(map[string]interface{}{
+			"count": i,
+		}, mh.SHA2_256, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := n.CreateRecord(ctx, info.ID, body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		recs = append(recs, r)
+	}
+	lid := recs[0].LogID()
+
+	page, err := n.PullByHeight(ctx, info.ID, lid, 1, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page) != 2 {
+		t.Fatalf("expected 2 records got %d", len(page))
+	}
+	for i, r := range page {
+		if !r.Cid().Equals(recs[i+1].Value().Cid()) {
+			t.Fatalf("expected record at height %d to be %s, got %s", i+1, recs[i+1].Value().Cid(), r.Cid())
+		}
+	}
+
+	page, err = n.PullByHeight(ctx, info.ID, lid, 3, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page) != 2 {
+		t.Fatalf("expected range to be clamped to 2 records got %d", len(page))
+	}
+
+	// Heights missing from the index are recovered from the log
+	tn := n.(*net)
+	if err = tn.store.ClearMetadata(info.ID); err != nil {
+		t.Fatal(err)
+	}
+	page, err = n.PullByHeight(ctx, info.ID, lid, 1, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page) != 2 || !page[0].Cid().Equals(recs[1].Value().Cid()) {
+		t.Fatal("expected records to be found by height after reindexing")
+	}
+
+	// Records held at the top of the range are returned from a partial log
+	if err = tn.threadDAG(info.ID).Remove(ctx, recs[1].Value().Cid()); err != nil {
+		t.Fatal(err)
+	}
+	page, err = n.PullByHeight(ctx, info.ID, lid, 0, 3)
+	if !errors.Is(err, ErrPartialHistory) {
+		t.Fatalf("expected ErrPartialHistory, got %v", err)
+	}
+	if len(page) != 1 || !page[0].Cid().Equals(recs[2].Value().Cid()) {
+		t.Fatalf("expected the record above the missing one, got %d records", len(page))
+	}
+	if err = tn.store.ClearMetadata(info.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = n.PullByHeight(ctx, info.ID, lid, 3, 5); !errors.Is(err, ErrPartialHistory) {
+		t.Fatalf("expected ErrPartialHistory for an unindexed partial log, got %v", err)
+	}
+}
+
+func TestNet_Ancestry(t *testing.T) {
+	t.Parallel()
+	n := makeNetwork(t)
+	defer n.Close()
+
+	ctx := context.Background()
+	info := createThread(t, ctx, n)
+
+	var recs []core.ThreadRecord
+	for i := 0; i < 5; i++ {
+		body, err := cbornode.WrapObject(map[string]interface{}{
+			"count": i,
+		}, mh.SHA2_256, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := n.CreateRecord(ctx, info.ID, body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		recs = append(recs, r)
+	}
+	lid := recs[0].LogID()
+
+	between, err := n.Ancestry(ctx, info.ID, lid, recs[1].Value().Cid(), recs[4].Value().Cid())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(between) != 3 {
+		t.Fatalf("expected 3 records got %d", len(between))
+	}
+	for i, r := range between {
+		if !r.Cid().Equals(recs[i+2].Value().Cid()) {
+			t.Fatalf("expected record %d to be %s, got %s", i, recs[i+2].Value().Cid(), r.Cid())
+		}
+	}
+
+	between, err = n.Ancestry(ctx, info.ID, lid, cid.Undef, recs[2].Value().Cid())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(between) != 3 {
+		t.Fatalf("expected 3 records got %d", len(between))
+	}
+
+	if _, err = n.Ancestry(ctx, info.ID, lid, recs[3].Value().Cid(), recs[1].Value().Cid()); !errors.Is(err, ErrNotAncestor) {
+		t.Fatalf("expected ErrNotAncestor, got %v", err)
+	}
+}
+
+func TestRecordDependencies(t *testing.T) {
+	t.Parallel()
+	n := makeNetwork(t)
+	defer n.Close()
+
+	ctx := context.Background()
+	info := createThread(t, ctx, n)
+	body, err := cbornode.WrapObject(map[string]interface{}{
+		"foo": "bar",
+	}, mh.SHA2_256, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r1, err := n.CreateRecord(ctx, info.ID, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r2, err := n.CreateRecord(ctx, info.ID, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tn := n.(*net)
+	deps := func(r core.ThreadRecord) []cid.Cid {
+		req, err := tn.server.newPushRecordRequest(ctx, info.ID, r.LogID(), r.Value())
+		if err != nil {
+			t.Fatal(err)
+		}
+		d, err := RecordDependencies(req, info.Key.Service())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+	if d := deps(r1); len(d) != 0 {
+		t.Fatalf("expected first record to have no dependencies, got %v", d)
+	}
+	if d := deps(r2); len(d) != 1 || !d[0].Equals(r1.Value().Cid()) {
+		t.Fatalf("expected dependency on %s, got %v", r1.Value().Cid(), d)
+	}
+}
+
+func TestNet_CAR(t *testing.T) {
+	t.Parallel()
+	n1 := makeNetwork(t)
+	defer n1.Close()
+	n2 := makeNetwork(t)
+	defer n2.Close()
+
+	ctx := context.Background()
+	info := createThread(t, ctx, n1)
+	body, err := cbornode.WrapObject(map[string]interface{}{
+		"foo": "bar",
+	}, mh.SHA2_256, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var last core.ThreadRecord
+	for i := 0; i < 3; i++ {
+		if last, err = n1.CreateRecord(ctx, info.ID, body); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err = n1.ExportCAR(ctx, info.ID, &buf); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	if _, err = n2.ImportCAR(ctx, bytes.NewReader(data)); err == nil {
+		t.Fatal("expected import of an unknown thread without a key to fail")
+	}
+	tampered := append([]byte(nil), data...)
+	tampered[len(tampered)-1] ^= 0xff
+	if _, err = n2.ImportCAR(ctx, bytes.NewReader(tampered), core.WithThreadKey(info.Key)); !errors.Is(err, ErrInvalidCAR) {
+		t.Fatalf("expected tampered CAR to be rejected, got %v", err)
+	}
+
+	if _, err = n2.ImportCAR(ctx, bytes.NewReader(data), core.WithThreadKey(info.Key)); err != nil {
+		t.Fatal(err)
+	}
+	lg, err := n2.(*net).store.GetLog(info.ID, last.LogID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !lg.Head.Equals(last.Value().Cid()) {
+		t.Fatalf("expected head %s, got %s", last.Value().Cid(), lg.Head)
+	}
+	recs, err := n2.(*net).Ancestry(ctx, info.ID, lg.ID, cid.Undef, lg.Head)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 3 {
+		t.Fatalf("expected 3 imported records, got %d", len(recs))
+	}
+
+	// n2's own log is empty
+	buf.Reset()
+	if err = n2.ExportCAR(ctx, info.ID, &buf); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNet_Bundle(t *testing.T) {
+	t.Parallel()
+	n1 := makeNetwork(t)
+	defer n1.Close()
+	n2 := makeNetwork(t)
+	defer n2.Close()
+
+	ctx := context.Background()
+	info := createThread(t, ctx, n1)
+	body, err := cbornode.WrapObject(map[string]interface{}{
+		"foo": "bar",
+	}, mh.SHA2_256, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var last core.ThreadRecord
+	for i := 0; i < 3; i++ {
+		if last, err = n1.CreateRecord(ctx, info.ID, body); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err = n1.ExportThread(ctx, info.ID, &buf); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	tmp, root, err := readCAR(ctx, bytes.NewReader(data), cbor.DecodeLimits{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	node, err := tmp.Get(ctx, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m bundleManifest
+	if err = cbornode.DecodeInto(node.RawData(), &m); err != nil {
+		t.Fatal(err)
+	}
+	if m.Version != BundleVersion || m.Key != info.Key.String() {
+		t.Fatal("expected a versioned manifest with the thread key")
+	}
+	recs, err := n1.(*net).Ancestry(ctx, info.ID, last.LogID(), cid.Undef, last.Value().Cid())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 3 {
+		t.Fatalf("expected 3 records, got %d", len(recs))
+	}
+	for _, r := range recs {
+		if _, err = tmp.Get(ctx, r.Cid()); err != nil {
+			t.Fatalf("expected bundle to contain record %s", r.Cid())
+		}
+	}
+
+	for _, l := range m.Logs {
+		if l.ID == last.LogID().String() && !l.Heads[0].Equals(last.Value().Cid()) {
+			t.Fatalf("expected log %s at %s, got %v", l.ID, last.Value().Cid(), l.Heads)
+		}
+	}
+
+	if _, err = n2.ImportThread(ctx, bytes.NewReader(data)); err != nil {
+		t.Fatal(err)
+	}
+	imported, err := n2.GetThread(ctx, info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(imported.Key.Bytes(), info.Key.Bytes()) {
+		t.Fatal("expected the thread key to be imported")
+	}
+	lg, err := n2.(*net).store.GetLog(info.ID, last.LogID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lg.PrivKey != nil || !lg.Head.Equals(last.Value().Cid()) {
+		t.Fatalf("expected log %s to be imported at %s without its private key", last.LogID(), last.Value().Cid())
+	}
+	own, err := n2.(*net).getOwnLog(info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if own.ID == last.LogID() || own.PrivKey == nil {
+		t.Fatal("expected the host to get a new log of its own")
+	}
+	if _, err = n2.TopicPeers(info.ID); err != nil {
+		t.Fatalf("expected to join the thread topic, got %v", err)
+	}
+}
+
+func TestNet_ImportThread(t *testing.T) {
+	t.Parallel()
+	n1 := makeNetwork(t)
+	defer n1.Close()
+	n2 := makeNetwork(t)
+	defer n2.Close()
+
+	ctx := context.Background()
+	info := createThread(t, ctx, n1)
+	body, err := cbornode.WrapObject(map[string]interface{}{
+		"foo": "bar",
+	}, mh.SHA2_256, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := n1.CreateRecord(ctx, info.ID, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// n2 already holds the thread, its own log, and n1's first record
+	if _, err = n2.CreateThread(ctx, info.ID, core.WithThreadKey(info.Key)); err != nil {
+		t.Fatal(err)
+	}
+	tn2 := n2.(*net)
+	lg1, err := n1.(*net).getOwnLog(info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tn2.store.AddLog(info.ID, thread.LogInfo{ID: lg1.ID, PubKey: lg1.PubKey}); err != nil {
+		t.Fatal(err)
+	}
+	if err = n2.AddRecord(ctx, info.ID, lg1.ID, first.Value()); err != nil {
+		t.Fatal(err)
+	}
+	own, err := n2.CreateRecord(ctx, info.ID, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var last core.ThreadRecord
+	for i := 0; i < 2; i++ {
+		if last, err = n1.CreateRecord(ctx, info.ID, body); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err = n1.ExportThread(ctx, info.ID, &buf); err != nil {
+		t.Fatal(err)
+	}
+	data := append([]byte(nil), buf.Bytes()...)
+
+	tampered := append([]byte(nil), data...)
+	tampered[len(tampered)-1] ^= 0xff
+	if _, err = n2.ImportThread(ctx, bytes.NewReader(tampered)); !errors.Is(err, ErrInvalidCAR) {
+		t.Fatalf("expected tampered bundle to be rejected, got %v", err)
+	}
+
+	var m bundleManifest
+	tmp, root, err := readCAR(ctx, bytes.NewReader(data), cbor.DecodeLimits{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	node, err := tmp.Get(ctx, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = cbornode.DecodeInto(node.RawData(), &m); err != nil {
+		t.Fatal(err)
+	}
+
+	// A record that matches its cid but isn't signed by the log key is rejected
+	sk2, _, err := crypto.GenerateEd25519Key(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tn1 := n1.(*net)
+	forged, err := tn1.newRecord(ctx, info.ID, thread.LogInfo{PrivKey: sk2, Head: last.Value().PrevID()}, body, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fm := bundleManifest{Version: m.Version, Thread: m.Thread, Key: m.Key}
+	for _, l := range m.Logs {
+		if l.ID == lg1.ID.String() {
+			l.Heads = []cid.Cid{forged.Cid()}
+			fm.Logs = append(fm.Logs, l)
+		}
+	}
+	froot, err := cbornode.WrapObject(fm, mh.SHA2_256, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fheader, err := cbornode.DumpObject(carHeader{Roots: []cid.Cid{froot.Cid()}, Version: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	if err = writeCARSection(&buf, fheader); err != nil {
+		t.Fatal(err)
+	}
+	if err = writeCARBlock(&buf, froot); err != nil {
+		t.Fatal(err)
+	}
+	recs, err := tn1.Ancestry(ctx, info.ID, lg1.ID, cid.Undef, last.Value().PrevID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range append(recs, forged) {
+		nodes, err := recordNodes(ctx, tn1.threadDAG(info.ID), r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, nd := range nodes {
+			if err = writeCARBlock(&buf, nd); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if _, err = n2.ImportThread(ctx, &buf); err == nil {
+		t.Fatal("expected a record with a bad signature to be rejected")
+	}
+	if lg, err := tn2.store.GetLog(info.ID, lg1.ID); err != nil || !lg.Head.Equals(first.Value().Cid()) {
+		t.Fatalf("expected log %s to stay at %s, got %s, %v", lg1.ID, first.Value().Cid(), lg.Head, err)
+	}
+	m.Version = BundleVersion + 1
+	newer, err := cbornode.WrapObject(m, mh.SHA2_256, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	header, err := cbornode.DumpObject(carHeader{Roots: []cid.Cid{newer.Cid()}, Version: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	if err = writeCARSection(&buf, header); err != nil {
+		t.Fatal(err)
+	}
+	if err = writeCARBlock(&buf, newer); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = n2.ImportThread(ctx, &buf); !errors.Is(err, ErrInvalidBundle) {
+		t.Fatalf("expected bundle with an unknown version to be rejected, got %v", err)
+	}
+
+	if _, err = n2.ImportThread(ctx, bytes.NewReader(data)); err != nil {
+		t.Fatal(err)
+	}
+	lg, err := tn2.store.GetLog(info.ID, lg1.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !lg.Head.Equals(last.Value().Cid()) {
+		t.Fatalf("expected head %s, got %s", last.Value().Cid(), lg.Head)
+	}
+	recs, err = tn2.Ancestry(ctx, info.ID, lg.ID, cid.Undef, lg.Head)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 3 {
+		t.Fatalf("expected 3 records, got %d", len(recs))
+	}
+	lg2, err := tn2.getOwnLog(info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lg2.ID != own.LogID() || !lg2.Head.Equals(own.Value().Cid()) {
+		t.Fatal("expected the host's own log to be kept")
+	}
+}
+
+func TestNet_ThreadStats(t *testing.T) {
+	t.Parallel()
+	n1 := makeNetwork(t)
+	defer n1.Close()
+	n2 := makeNetwork(t)
+	defer n2.Close()
+
+	ctx := context.Background()
+	info := createThread(t, ctx, n1)
+	if _, err := n2.CreateThread(ctx, info.ID, core.WithThreadKey(info.Key)); err != nil {
+		t.Fatal(err)
+	}
+	tn1, tn2 := n1.(*net), n2.(*net)
+	lg2, err := tn2.getOwnLog(info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tn1.store.AddLog(info.ID, thread.LogInfo{ID: lg2.ID, PubKey: lg2.PubKey}); err != nil {
+		t.Fatal(err)
+	}
+	body, err := cbornode.WrapObject(map[string]interface{}{
+		"foo": "bar",
+	}, mh.SHA2_256, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := map[peer.ID]int{}
+	heads := map[peer.ID]cid.Cid{}
+	var size int64
+	add := func(r core.Record) {
+		nodes, err := recordNodes(ctx, tn1.threadDAG(info.ID), r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, nd := range nodes {
+			size += int64(len(nd.RawData()))
+		}
+	}
+	for i := 0; i < 3; i++ {
+		r, err := n1.CreateRecord(ctx, info.ID, body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		counts[r.LogID()]++
+		heads[r.LogID()] = r.Value().Cid()
+		add(r.Value())
+	}
+	for i := 0; i < 2; i++ {
+		r, err := n2.CreateRecord(ctx, info.ID, body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = n1.AddRecord(ctx, info.ID, r.LogID(), r.Value()); err != nil {
+			t.Fatal(err)
+		}
+		counts[r.LogID()]++
+		heads[r.LogID()] = r.Value().Cid()
+		add(r.Value())
+	}
+
+	stats, err := n1.ThreadStats(info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats.Logs) != 2 || stats.Records != 5 || stats.Bytes != size {
+		t.Fatalf("expected 2 logs, 5 records, and %d bytes, got %d, %d, and %d", size, len(stats.Logs), stats.Records, stats.Bytes)
+	}
+	for lid, ls := range stats.Logs {
+		if ls.Records != counts[lid] {
+			t.Fatalf("expected %d records in log %s, got %d", counts[lid], lid, ls.Records)
+		}
+		if len(ls.Heads) != 1 || !ls.Heads[0].Equals(heads[lid]) {
+			t.Fatalf("expected head %s in log %s, got %v", heads[lid], lid, ls.Heads)
+		}
+	}
+}
+
+func TestNet_DAGResolver(t *testing.T) {
+	t.Parallel()
+	sep := bstore.NewBlockstore(syncds.MutexWrap(ds.NewMapDatastore()))
+	sepDAG := dag.NewDAGService(bserv.New(sep, offline.Exchange(sep)))
+	n := makeNetworkWithConfig(t, Config{
+		DAGResolver: func(thread.ID) format.DAGService {
+			return sepDAG
+		},
+	})
+	defer n.Close()
+
+	ctx := context.Background()
+	info := createThread(t, ctx, n)
+	body, err := cbornode.WrapObject(map[string]interface{}{
+		"foo": "bar",
+	}, mh.SHA2_256, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := n.CreateRecord(ctx, info.ID, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rid := r.Value().Cid()
+	if has, err := sep.Has(rid); err != nil || !has {
+		t.Fatalf("expected record in the resolved blockstore (err=%v)", err)
+	}
+	if has, err := n.(*net).bstore.Has(rid); err != nil || has {
+		t.Fatalf("expected record not to be in the shared blockstore (err=%v)", err)
+	}
+	if has, err := n.(*net).hasBlock(info.ID, rid); err != nil || !has {
+		t.Fatalf("expected record to be held (err=%v)", err)
+	}
+	stats, err := n.ThreadStats(info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Records != 1 || stats.Bytes == 0 {
+		t.Fatalf("expected stats of 1 record, got %d records of %d bytes", stats.Records, stats.Bytes)
+	}
+
+	rec, err := n.GetRecord(ctx, info.ID, rid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	event, err := cbor.EventFromRecord(ctx, n, rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = event.GetBody(ctx, n, info.Key.Read()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNet_VerifyThread(t *testing.T) {
+	t.Parallel()
+	n1 := makeNetwork(t)
+	defer n1.Close()
+	n2 := makeNetwork(t)
+	defer n2.Close()
+
+	n1.Host().Peerstore().AddAddrs(n2.Host().ID(), n2.Host().Addrs(), peerstore.PermanentAddrTTL)
+	n2.Host().Peerstore().AddAddrs(n1.Host().ID(), n1.Host().Addrs(), peerstore.PermanentAddrTTL)
+
+	ctx := context.Background()
+	info := createThread(t, ctx, n1)
+
+	body, err := cbornode.WrapObject(map[string]interface{}{
+		"msg": "yo!",
+	}, mh.SHA2_256, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = n1.CreateRecord(ctx, info.ID, body); err != nil {
+		t.Fatal(err)
+	}
+
+	addr, err := ma.NewMultiaddr("/p2p/" + n1.Host().ID().String() + "/thread/" + info.ID.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = n2.AddThread(ctx, addr, core.WithThreadKey(info.Key)); err != nil {
+		t.Fatal(err)
+	}
+	if err = n2.PullThread(ctx, info.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := n2.VerifyThread(ctx, info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Unreachable) != 0 {
+		t.Fatalf("expected all members to be reachable, got %v", report.Unreachable)
+	}
+	if len(report.Logs) != 2 || report.Logs[0].LogID >= report.Logs[1].LogID {
+		t.Fatalf("expected both logs in order of ID, got %+v", report.Logs)
+	}
+	var ownerLog *core.LogReport
+	for i, l := range report.Logs {
+		if _, ok := l.Heads[n1.Host().ID()]; ok && l.LocalHead.Defined() {
+			ownerLog = &report.Logs[i]
+		}
+	}
+	if ownerLog == nil {
+		t.Fatal("expected a report for the log of the thread creator")
+	}
+	if !ownerLog.Consistent() {
+		t.Fatalf("expected log %s to be consistent, got %+v", ownerLog.LogID, ownerLog)
+	}
+}
+
+func TestNet_IsReplicatedTo(t *testing.T) {
+	t.Parallel()
+	n1 := makeNetwork(t)
+	defer n1.Close()
+	n2 := makeNetwork(t)
+	defer n2.Close()
+
+	n1.Host().Peerstore().AddAddrs(n2.Host().ID(), n2.Host().Addrs(), peerstore.PermanentAddrTTL)
+	n2.Host().Peerstore().AddAddrs(n1.Host().ID(), n1.Host().Addrs(), peerstore.PermanentAddrTTL)
+
+	ctx := context.Background()
+	info := createThread(t, ctx, n1)
+	tn1 := n1.(*net)
+	lg, err := tn1.getOwnLog(info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = n2.CreateThread(ctx, info.ID, core.WithThreadKey(info.Key)); err != nil {
+		t.Fatal(err)
+	}
+	if err = n2.(*net).store.AddLog(info.ID, thread.LogInfo{ID: lg.ID, PubKey: lg.PubKey}); err != nil {
+		t.Fatal(err)
+	}
+
+	var recs []core.Record
+	for i := 0; i < 2; i++ {
+		body, err := cbornode.WrapObject(map[string]interface{}{
+			"foo": i,
+		}, mh.SHA2_256, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := n1.CreateRecord(ctx, info.ID, body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		recs = append(recs, r.Value())
+	}
+
+	ok, err := n1.IsReplicatedTo(ctx, info.ID, n2.Host().ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Fatal("expected thread not to be replicated to a peer without records")
+	}
+	if err = n2.AddRecord(ctx, info.ID, lg.ID, recs[0]); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err = n1.IsReplicatedTo(ctx, info.ID, n2.Host().ID()); err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Fatal("expected thread not to be replicated to a peer that is behind")
+	}
+	if err = n2.AddRecord(ctx, info.ID, lg.ID, recs[1]); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err = n1.IsReplicatedTo(ctx, info.ID, n2.Host().ID()); err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Fatal("expected thread to be replicated to a peer with the same heads")
+	}
+
+	// n1 holds the first record of n2's log, and then a fork of the second
+	newBody := func(v interface{}) format.Node {
+		body, err := cbornode.WrapObject(map[string]interface{}{
+			"bar": v,
+		}, mh.SHA2_256, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return body
+	}
+	tn2 := n2.(*net)
+	lg2, err := tn2.getOwnLog(info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var recs2 []core.Record
+	for i := 0; i < 3; i++ {
+		r, err := n2.CreateRecord(ctx, info.ID, newBody(i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		recs2 = append(recs2, r.Value())
+	}
+	if err = tn1.store.AddLog(info.ID, thread.LogInfo{ID: lg2.ID, PubKey: lg2.PubKey}); err != nil {
+		t.Fatal(err)
+	}
+	if err = n1.AddRecord(ctx, info.ID, lg2.ID, recs2[0]); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err = n1.IsReplicatedTo(ctx, info.ID, n2.Host().ID()); err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Fatal("expected thread to be replicated to a peer that is ahead")
+	}
+	fork, err := tn2.newRecord(ctx, info.ID, thread.LogInfo{PrivKey: lg2.PrivKey, Head: recs2[0].Cid()}, newBody("fork"), nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = n1.AddRecord(ctx, info.ID, lg2.ID, fork); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err = n1.IsReplicatedTo(ctx, info.ID, n2.Host().ID()); err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Fatal("expected thread not to be replicated to a peer with an unrelated head")
+	}
+}
+
+// rawBody is a pre-encoded request body.
+type rawBody []byte
+
+func (b rawBody) Marshal() ([]byte, error) {
+	return b, nil
+}
+
+func TestSignRequest_Vectors(t *testing.T) {
+	t.Parallel()
+	data, err := ioutil.ReadFile("testdata/request_signatures.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var file struct {
+		Vectors []struct {
+			Name       string `json:"name"`
+			PrivateKey string `json:"private_key"`
+			Body       string `json:"body"`
+			Signature  string `json:"signature"`
+			Header     string `json:"header"`
+			PeerID     string `json:"peer_id"`
+		} `json:"vectors"`
+	}
+	if err = json.Unmarshal(data, &file); err != nil {
+		t.Fatal(err)
+	}
+	decode := func(s string) []byte {
+		b, err := hex.DecodeString(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+	for _, v := range file.Vectors {
+		t.Run(v.Name, func(t *testing.T) {
+			sk, err := crypto.UnmarshalPrivateKey(decode(v.PrivateKey))
+			if err != nil {
+				t.Fatal(err)
+			}
+			body := rawBody(decode(v.Body))
+			header, err := SignRequest(sk, body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(header.Signature, decode(v.Signature)) {
+				t.Fatalf("signature mismatch: got %x", header.Signature)
+			}
+			hb, err := header.Marshal()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(hb, decode(v.Header)) {
+				t.Fatalf("header mismatch: got %x", hb)
+			}
+
+			var h pb.Header
+			if err = h.Unmarshal(decode(v.Header)); err != nil {
+				t.Fatal(err)
+			}
+			pid, err := VerifyRequest(&h, body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if pid.String() != v.PeerID {
+				t.Fatalf("expected peer %s, got %s", v.PeerID, pid)
+			}
+			if _, err = VerifyRequest(&h, append(rawBody{0}, body...)); err == nil {
+				t.Fatal("expected verification of a modified body to fail")
+			}
+		})
+	}
+}
+
+func TestNet_LocalOnly(t *testing.T) {
+	t.Parallel()
+	n := makeNetworkWithConfig(t, Config{
+		LocalOnly: true,
+	})
+	defer n.Close()
+
+	ctx := context.Background()
+	info := createThread(t, ctx, n)
+
+	body, err := cbornode.WrapObject(map[string]interface{}{
+		"foo": "bar",
+	}, mh.SHA2_256, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := n.CreateRecord(ctx, info.ID, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = n.GetRecord(ctx, info.ID, r.Value().Cid()); err != nil {
+		t.Fatal(err)
+	}
+	if err = n.PullThread(ctx, info.ID); err != nil {
+		t.Fatal(err)
+	}
+	if n.PubSub() != nil {
+		t.Fatal("expected no pubsub in local-only mode")
+	}
+
+	addr, err := ma.NewMultiaddr("/p2p/" + n.Host().ID().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = n.AddReplicator(ctx, info.ID, addr); !errors.Is(err, ErrLocalOnly) {
+		t.Fatalf("expected local-only error, got %v", err)
+	}
+}
+
+func TestServer_SignedRequests(t *testing.T) {
+	t.Parallel()
+	n := makeNetwork(t)
+	defer n.Close()
+
+	ctx := context.Background()
+	info := createThread(t, ctx, n)
+	s := n.(*net).server
+
+	body := &pb.GetLogsRequest_Body{
+		ThreadID:   &pb.ProtoThreadID{ID: info.ID},
+		ServiceKey: &pb.ProtoKey{Key: info.Key.Service()},
+	}
+	sig, key, err := s.signRequestBody(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := &pb.Header{
+		PubKey:    &pb.ProtoPubKey{PubKey: key},
+		Signature: sig,
+	}
+	if _, err = s.GetLogs(ctx, &pb.GetLogsRequest{Header: header, Body: body}); err != nil {
+		t.Fatalf("expected signed get logs request to succeed: %v", err)
+	}
+	rbody := &pb.GetRecordsRequest_Body{
+		ThreadID:   body.ThreadID,
+		ServiceKey: body.ServiceKey,
+		Logs: []*pb.GetRecordsRequest_Body_LogEntry{{
+			LogID:  &pb.ProtoPeerID{ID: n.Host().ID()},
+			Offset: &pb.ProtoCid{Cid: cid.Undef},
+			Limit:  1,
+		}},
+	}
+	if _, err = s.GetRecords(ctx, &pb.GetRecordsRequest{Header: header, Body: rbody}); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected get records request with a mismatched signature to be rejected, got %v", err)
+	}
+	if _, err = s.GetLogs(ctx, &pb.GetLogsRequest{Body: body}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected unsigned get logs request to be rejected, got %v", err)
+	}
+}
+
+func TestServer_GetHeads(t *testing.T) {
+	t.Parallel()
+	n1 := makeNetwork(t)
+	defer n1.Close()
+	n2 := makeNetwork(t)
+	defer n2.Close()
+	n2.Host().Peerstore().AddAddrs(n1.Host().ID(), n1.Host().Addrs(), peerstore.PermanentAddrTTL)
+
+	ctx := context.Background()
+	info := createThread(t, ctx, n1)
+	if _, err := n2.CreateThread(ctx, info.ID, core.WithThreadKey(info.Key)); err != nil {
+		t.Fatal(err)
+	}
+	tn1, tn2 := n1.(*net), n2.(*net)
+	lg, err := tn1.getOwnLog(info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		body, err := cbornode.WrapObject(map[string]interface{}{
+			"foo": i,
+		}, mh.SHA2_256, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = n1.CreateRecord(ctx, info.ID, body); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	heads, err := tn2.server.getHeads(ctx, info.ID, lg.ID, n1.Host().ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := tn1.store.Heads(info.ID, lg.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(heads) != len(want) || len(heads) != 1 || !heads[0].Equals(want[0]) {
+		t.Fatalf("expected heads %v, got %v", want, heads)
+	}
+
+	own, err := tn2.getOwnLog(info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = tn2.server.getHeads(ctx, info.ID, own.ID, n1.Host().ID()); status.Code(err) != codes.NotFound {
+		t.Fatalf("expected an unknown log to be not found, got %v", err)
+	}
+
+	// Requests without a body, thread or log are rejected
+	request := func(body *pb.GetHeadsRequest_Body) *pb.GetHeadsRequest {
+		sig, key, err := tn2.server.signRequestBody(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &pb.GetHeadsRequest{
+			Header: &pb.Header{PubKey: &pb.ProtoPubKey{PubKey: key}, Signature: sig},
+			Body:   body,
+		}
+	}
+	noBody := request(&pb.GetHeadsRequest_Body{})
+	noBody.Body = nil
+	reqs := map[string]*pb.GetHeadsRequest{
+		"no body":   noBody,
+		"no thread": request(&pb.GetHeadsRequest_Body{LogID: &pb.ProtoPeerID{ID: lg.ID}}),
+		"no log":    request(&pb.GetHeadsRequest_Body{ThreadID: &pb.ProtoThreadID{ID: info.ID}}),
+	}
+	for name, req := range reqs {
+		if _, err = tn1.server.GetHeads(ctx, req); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("%s: expected get heads to be invalid, got %v", name, err)
+		}
+	}
+}
+
+func TestServer_GetLogsBatch(t *testing.T) {
+	t.Parallel()
+	n := makeNetwork(t)
+	defer n.Close()
+
+	ctx := context.Background()
+	info1 := createThread(t, ctx, n)
+	info2 := createThread(t, ctx, n)
+	s := n.(*net).server
+
+	body := &pb.GetLogsBatchRequest_Body{
+		Threads: []*pb.GetLogsRequest_Body{{
+			ThreadID:   &pb.ProtoThreadID{ID: info1.ID},
+			ServiceKey: &pb.ProtoKey{Key: info1.Key.Service()},
+		}, {
+			ThreadID:   &pb.ProtoThreadID{ID: info2.ID},
+			ServiceKey: &pb.ProtoKey{Key: info1.Key.Service()},
+		}},
+	}
+	sig, key, err := s.signRequestBody(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reply, err := s.GetLogsBatch(ctx, &pb.GetLogsBatchRequest{
+		Header: &pb.Header{
+			PubKey:    &pb.ProtoPubKey{PubKey: key},
+			Signature: sig,
+		},
+		Body: body,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reply.Threads) != 2 {
+		t.Fatalf("expected an entry for each thread, got %d", len(reply.Threads))
+	}
+	if e := reply.Threads[0]; e.ThreadID.ID != info1.ID || e.Error != "" || len(e.Logs) != 1 {
+		t.Fatalf("expected the logs of the first thread, got %v", e)
+	}
+	if e := reply.Threads[1]; e.ThreadID.ID != info2.ID || e.Error == "" || len(e.Logs) != 0 {
+		t.Fatalf("expected the second thread to fail authorization, got %v", e)
+	}
+
+	limit := MaxGetLogsBatchSize
+	MaxGetLogsBatchSize = 1
+	defer func() { MaxGetLogsBatchSize = limit }()
+	if _, err = s.GetLogsBatch(ctx, &pb.GetLogsBatchRequest{
+		Header: &pb.Header{
+			PubKey:    &pb.ProtoPubKey{PubKey: key},
+			Signature: sig,
+		},
+		Body: body,
+	}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected a batch over the limit to be rejected, got %v", err)
+	}
+}
+
+func TestServer_GetRecordsProof(t *testing.T) {
+	t.Parallel()
+	n := makeNetwork(t)
+	defer n.Close()
+
+	ctx := context.Background()
+	info := createThread(t, ctx, n)
+	for i := 0; i < 3; i++ {
+		body, err := cbornode.WrapObject(map[string]interface{}{
+			"foo": i,
+		}, mh.SHA2_256, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = n.CreateRecord(ctx, info.ID, body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tn := n.(*net)
+	lg, err := tn.getOwnLog(info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body := &pb.GetRecordsRequest_Body{
+		ThreadID:   &pb.ProtoThreadID{ID: info.ID},
+		ServiceKey: &pb.ProtoKey{Key: info.Key.Service()},
+		Proof:      true,
+	}
+	header, err := SignRequest(tn.getPrivKey(), body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reply, err := tn.server.GetRecords(ctx, &pb.GetRecordsRequest{Header: header, Body: body})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reply.Logs) != 1 {
+		t.Fatalf("expected 1 log, got %d", len(reply.Logs))
+	}
+	entry := reply.Logs[0]
+	if len(entry.Records) != 3 {
+		t.Fatalf("expected 3 records, got %d", len(entry.Records))
+	}
+	sk := info.Key.Service()
+	pid, err := VerifyManifest(entry.Manifest, lg.PubKey, sk, entry.Records)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pid != n.Host().ID() {
+		t.Fatalf("expected manifest to be signed by %s, got %s", n.Host().ID(), pid)
+	}
+
+	if _, err = VerifyManifest(entry.Manifest, lg.PubKey, sk, entry.Records[1:]); !errors.Is(err, ErrInvalidManifest) {
+		t.Fatalf("expected incomplete records to be rejected, got %v", err)
+	}
+	swapped := []*pb.Log_Record{entry.Records[1], entry.Records[0], entry.Records[2]}
+	if _, err = VerifyManifest(entry.Manifest, lg.PubKey, sk, swapped); !errors.Is(err, ErrInvalidManifest) {
+		t.Fatalf("expected reordered records to be rejected, got %v", err)
+	}
+
+	// A manifest signed over records that don't form the log must be
+	// rejected too, even though the records hash to the listed cids.
+	forged := func(pbrecs []*pb.Log_Record) *pb.Manifest {
+		recs := make([]core.Record, len(pbrecs))
+		for i, r := range pbrecs {
+			if recs[i], err = cbor.RecordFromProto(r, sk); err != nil {
+				t.Fatal(err)
+			}
+		}
+		m, err := tn.server.manifest(info.ID, lg.ID, cid.Undef, recs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return m
+	}
+	if _, err = VerifyManifest(forged(swapped), lg.PubKey, sk, swapped); !errors.Is(err, ErrInvalidManifest) {
+		t.Fatalf("expected a manifest of reordered records to be rejected, got %v", err)
+	}
+	// The replacement links to the first record, but is signed by another key.
+	sk2, _, err := crypto.GenerateEd25519Key(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := cbor.RecordFromProto(entry.Records[0], sk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	obody, err := cbornode.WrapObject(map[string]interface{}{
+		"foo": "other",
+	}, mh.SHA2_256, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec, err := tn.newRecord(ctx, info.ID, thread.LogInfo{PrivKey: sk2, Head: first.Cid()}, obody, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := cbor.RecordToProto(ctx, tn.threadDAG(info.ID), rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replaced := []*pb.Log_Record{entry.Records[0], other}
+	if _, err = VerifyManifest(forged(replaced), lg.PubKey, sk, replaced); !errors.Is(err, ErrInvalidManifest) {
+		t.Fatalf("expected a manifest of replaced records to be rejected, got %v", err)
+	}
+}
+
+func TestServer_CompactRecords(t *testing.T) {
+	t.Parallel()
+	n := makeNetworkWithConfig(t, Config{
+		CompactRecords: true,
+	})
+	defer n.Close()
+
+	ctx := context.Background()
+	info := createThread(t, ctx, n)
+	body, err := cbornode.WrapObject(map[string]interface{}{
+		"foo": "bar",
+	}, mh.SHA2_256, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := n.CreateRecord(ctx, info.ID, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tn := n.(*net)
+	lg, err := tn.getOwnLog(info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rbody := &pb.GetRecordsRequest_Body{
+		ThreadID:   &pb.ProtoThreadID{ID: info.ID},
+		ServiceKey: &pb.ProtoKey{Key: info.Key.Service()},
+	}
+	header, err := SignRequest(tn.getPrivKey(), rbody)
+	if err != nil {
+		t.Fatal(err)
+	}
+	get := func(compact bool) *pb.Log_Record {
+		header.Compact = compact
+		reply, err := tn.server.GetRecords(ctx, &pb.GetRecordsRequest{Header: header, Body: rbody})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(reply.Logs) != 1 || len(reply.Logs[0].Records) != 1 {
+			t.Fatal("expected 1 record")
+		}
+		return reply.Logs[0].Records[0]
+	}
+	full := get(false)
+	compact := get(true)
+	if len(compact.EventNode) != 0 {
+		t.Fatal("expected compact record to omit the event node")
+	}
+	if compact.Size() >= full.Size() {
+		t.Fatalf("expected compact record to be smaller (%d >= %d)", compact.Size(), full.Size())
+	}
+
+	rec, err := tn.recordFromProto(info.ID, compact)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rec.Cid().Equals(r.Value().Cid()) {
+		t.Fatalf("expected record %s, got %s", r.Value().Cid(), rec.Cid())
+	}
+	if err = rec.Verify(lg.PubKey); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestServer_PushRecordUsesStoredKeys(t *testing.T) {
+	t.Parallel()
+	n1 := makeNetwork(t)
+	defer n1.Close()
+	n2 := makeNetwork(t)
+	defer n2.Close()
+
+	// n2 has its own copy of the thread under a different key
+	ctx := context.Background()
+	info := createThread(t, ctx, n1)
+	if _, err := n2.CreateThread(ctx, info.ID); err != nil {
+		t.Fatal(err)
+	}
+	body, err := cbornode.WrapObject(map[string]interface{}{
+		"foo": "bar",
+	}, mh.SHA2_256, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := n2.CreateRecord(ctx, info.ID, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tn1, tn2 := n1.(*net), n2.(*net)
+	lg, err := tn2.store.GetLog(info.ID, r.LogID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tn1.store.AddLog(info.ID, thread.LogInfo{ID: lg.ID, PubKey: lg.PubKey}); err != nil {
+		t.Fatal(err)
+	}
+
+	req, err := tn2.server.newPushRecordRequest(ctx, info.ID, r.LogID(), r.Value())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = tn1.server.PushRecord(ctx, req); err == nil {
+		t.Fatal("expected record under a foreign service key to be rejected")
+	}
+	has, err := tn1.hasBlock(info.ID, r.Value().Cid())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if has {
+		t.Fatal("expected rejected record not to be stored")
+	}
+}
+
+func TestServer_Equivocation(t *testing.T) {
+	t.Parallel()
+	n1 := makeNetwork(t)
+	defer n1.Close()
+	n2 := makeNetwork(t)
+	defer n2.Close()
+
+	ctx := context.Background()
+	info := createThread(t, ctx, n1)
+	if _, err := n2.CreateThread(ctx, info.ID, core.WithThreadKey(info.Key)); err != nil {
+		t.Fatal(err)
+	}
+	tn1, tn2 := n1.(*net), n2.(*net)
+	lg, err := tn2.getOwnLog(info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tn1.store.AddLog(info.ID, thread.LogInfo{ID: lg.ID, PubKey: lg.PubKey}); err != nil {
+		t.Fatal(err)
+	}
+
+	newBody := func(v string) format.Node {
+		body, err := cbornode.WrapObject(map[string]interface{}{
+			"foo": v,
+		}, mh.SHA2_256, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return body
+	}
+	push := func(rec core.Record) error {
+		req, err := tn2.server.newPushRecordRequest(ctx, info.ID, lg.ID, rec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = tn1.server.PushRecord(ctx, req)
+		return err
+	}
+	r1, err := n2.CreateRecord(ctx, info.ID, newBody("one"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r2, err := n2.CreateRecord(ctx, info.ID, newBody("two"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range []core.ThreadRecord{r1, r2} {
+		if err = push(r.Value()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// n2 signs another record on top of r1
+	lg.Head = r1.Value().Cid()
+	fork, err := tn2.newRecord(ctx, info.ID, lg, newBody("fork"), nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = push(fork); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected conflicting record to be rejected, got %v", err)
+	}
+	head, err := tn1.store.Heads(info.ID, lg.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(head) != 1 || !head[0].Equals(r2.Value().Cid()) {
+		t.Fatalf("expected head to remain at %s, got %v", r2.Value().Cid(), head)
+	}
+
+	eqs, err := n1.Equivocations(info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(eqs) != 1 {
+		t.Fatalf("expected one equivocation, got %d", len(eqs))
+	}
+	eq := eqs[0]
+	if eq.Log != lg.ID || !eq.Prev.Equals(r1.Value().Cid()) ||
+		!eq.Records[0].Cid.Equals(r2.Value().Cid()) || !eq.Records[1].Cid.Equals(fork.Cid()) {
+		t.Fatalf("unexpected equivocation %v", eq)
+	}
+	for _, r := range eq.Records {
+		ok, err := lg.PubKey.Verify(cbor.RecordPayload(r.Block, eq.Prev, r.PubKey), r.Sig)
+		if !ok || err != nil {
+			t.Fatal("expected evidence to be signed by the log key")
+		}
+	}
+
+	// Pushing the same record again doesn't duplicate the evidence
+	if err = push(fork); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected conflicting record to be rejected, got %v", err)
+	}
+	if eqs, err = n1.Equivocations(info.ID); err != nil || len(eqs) != 1 {
+		t.Fatalf("expected one equivocation, got %d, %v", len(eqs), err)
+	}
+
+	// The fork is reported for the forked log only
+	forks, err := n1.LogForks(info.ID, lg.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(forks) != 1 || !forks[0].Records[1].Cid.Equals(fork.Cid()) {
+		t.Fatalf("expected the fork of log %s, got %v", lg.ID, forks)
+	}
+	own, err := tn1.getOwnLog(info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if forks, err = n1.LogForks(info.ID, own.ID); err != nil || len(forks) != 0 {
+		t.Fatalf("expected no forks of log %s, got %v, %v", own.ID, forks, err)
+	}
+
+	// Evidence of a log is capped
+	for i := 0; i < maxLogForks+1; i++ {
+		h, err := mh.Sum([]byte(fmt.Sprint(i)), mh.SHA2_256, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		eq := eqs[0]
+		eq.Records[1].Cid = cid.NewCidV1(cid.DagCBOR, h)
+		if err = tn1.addEquivocation(info.ID, eq); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if forks, err = n1.LogForks(info.ID, lg.ID); err != nil || len(forks) != maxLogForks {
+		t.Fatalf("expected %d forks of log %s, got %d, %v", maxLogForks, lg.ID, len(forks), err)
+	}
+}
+
+func TestServer_PushRecords(t *testing.T) {
+	t.Parallel()
+	n1 := makeNetwork(t)
+	defer n1.Close()
+	n2 := makeNetwork(t)
+	defer n2.Close()
+
+	ctx := context.Background()
+	info := createThread(t, ctx, n1)
+	if _, err := n2.CreateThread(ctx, info.ID, core.WithThreadKey(info.Key)); err != nil {
+		t.Fatal(err)
+	}
+	tn1, tn2 := n1.(*net), n2.(*net)
+	lg, err := tn2.getOwnLog(info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tn1.store.AddLog(info.ID, thread.LogInfo{ID: lg.ID, PubKey: lg.PubKey}); err != nil {
+		t.Fatal(err)
+	}
+
+	var rids []cid.Cid
+	var pbrecs []*pb.Log_Record
+	for i := 0; i < 50; i++ {
+		body, err := cbornode.WrapObject(map[string]interface{}{
+			"foo": i,
+		}, mh.SHA2_256, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := n2.CreateRecord(ctx, info.ID, body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pbrec, err := cbor.RecordToProto(ctx, tn2.threadDAG(info.ID), r.Value())
+		if err != nil {
+			t.Fatal(err)
+		}
+		rids = append(rids, r.Value().Cid())
+		pbrecs = append(pbrecs, pbrec)
+	}
+	push := func(recs []*pb.Log_Record) *pb.PushRecordsReply {
+		body := &pb.PushRecordsRequest_Body{
+			ThreadID: &pb.ProtoThreadID{ID: info.ID},
+			LogID:    &pb.ProtoPeerID{ID: lg.ID},
+			Records:  recs,
+		}
+		sig, key, err := tn2.server.signRequestBody(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reply, err := tn1.server.PushRecords(ctx, &pb.PushRecordsRequest{
+			Header: &pb.Header{
+				PubKey:    &pb.ProtoPubKey{PubKey: key},
+				Signature: sig,
+			},
+			Body: body,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(reply.Results) != len(recs) {
+			t.Fatalf("expected a result for each record, got %d", len(reply.Results))
+		}
+		return reply
+	}
+	checkLog := func() {
+		for i, rid := range rids {
+			c, err := tn1.recordAtHeight(info.ID, lg.ID, int64(i))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !c.Equals(rid) {
+				t.Fatalf("expected record %s at height %d, got %s", rid, i, c)
+			}
+		}
+		heads, err := tn1.store.Heads(info.ID, lg.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(heads) != 1 || !heads[0].Equals(rids[len(rids)-1]) {
+			t.Fatalf("expected head %s, got %v", rids[len(rids)-1], heads)
+		}
+	}
+
+	// A bad record stops the batch, so the sender can resume from it
+	bad := *pbrecs[1]
+	bad.RecordNode = []byte("garbage")
+	reply := push([]*pb.Log_Record{pbrecs[0], &bad, pbrecs[2]})
+	if codes.Code(reply.Results[0].Code) != codes.OK ||
+		codes.Code(reply.Results[1].Code) == codes.OK ||
+		codes.Code(reply.Results[2].Code) != codes.Aborted {
+		t.Fatalf("unexpected results %v", reply.Results)
+	}
+
+	for i := 0; i < 2; i++ {
+		reply = push(pbrecs)
+		for j, res := range reply.Results {
+			if codes.Code(res.Code) != codes.OK {
+				t.Fatalf("expected record %d to be accepted, got %s", j, res.Message)
+			}
+		}
+		checkLog()
+	}
+}
+
+func TestServer_PushMalformedRequests(t *testing.T) {
+	t.Parallel()
+	n := makeNetwork(t)
+	defer n.Close()
+	tn := n.(*net)
+
+	ctx := context.Background()
+	info := createThread(t, ctx, n)
+	lg, err := tn.getOwnLog(info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tid := &pb.ProtoThreadID{ID: info.ID}
+	lid := &pb.ProtoPeerID{ID: lg.ID}
+	header := func(body interface{ Marshal() ([]byte, error) }) *pb.Header {
+		sig, key, err := tn.server.signRequestBody(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &pb.Header{PubKey: &pb.ProtoPubKey{PubKey: key}, Signature: sig}
+	}
+
+	pushes := map[string]*pb.PushRecordRequest{
+		"no body":      {},
+		"no thread":    {Body: &pb.PushRecordRequest_Body{LogID: lid, Record: &pb.Log_Record{}}},
+		"no log":       {Body: &pb.PushRecordRequest_Body{ThreadID: tid, Record: &pb.Log_Record{}}},
+		"no record":    {Body: &pb.PushRecordRequest_Body{ThreadID: tid, LogID: lid}},
+		"no header":    {Body: &pb.PushRecordRequest_Body{ThreadID: tid, LogID: lid, Record: &pb.Log_Record{}}},
+		"no signer":    {Header: &pb.Header{}, Body: &pb.PushRecordRequest_Body{ThreadID: tid, LogID: lid, Record: &pb.Log_Record{}}},
+		"empty signer": {Header: &pb.Header{PubKey: &pb.ProtoPubKey{}}, Body: &pb.PushRecordRequest_Body{ThreadID: tid, LogID: lid, Record: &pb.Log_Record{}}},
+	}
+	for name, req := range pushes {
+		if _, err := tn.server.PushRecord(ctx, req); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("%s: expected push record to be invalid, got %v", name, err)
+		}
+	}
+
+	batches := map[string]*pb.PushRecordsRequest{
+		"no body":    {},
+		"no thread":  {Body: &pb.PushRecordsRequest_Body{LogID: lid}},
+		"no log":     {Body: &pb.PushRecordsRequest_Body{ThreadID: tid}},
+		"nil record": {Body: &pb.PushRecordsRequest_Body{ThreadID: tid, LogID: lid, Records: []*pb.Log_Record{nil}}},
+		"no header":  {Body: &pb.PushRecordsRequest_Body{ThreadID: tid, LogID: lid}},
+		"no signer":  {Header: &pb.Header{}, Body: &pb.PushRecordsRequest_Body{ThreadID: tid, LogID: lid}},
+	}
+	for name, req := range batches {
+		if _, err := tn.server.PushRecords(ctx, req); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("%s: expected push records to be invalid, got %v", name, err)
+		}
+	}
+
+	// A well-formed request is still accepted
+	body := &pb.PushRecordsRequest_Body{ThreadID: tid, LogID: lid}
+	if _, err = tn.server.PushRecords(ctx, &pb.PushRecordsRequest{Header: header(body), Body: body}); err != nil {
+		t.Fatalf("expected an empty batch to be accepted, got %v", err)
+	}
+}
+
+func TestServer_GetLogsMalformedRequests(t *testing.T) {
+	t.Parallel()
+	n := makeNetwork(t)
+	defer n.Close()
+	tn := n.(*net)
+
+	ctx := context.Background()
+	info := createThread(t, ctx, n)
+	header := func(body interface{ Marshal() ([]byte, error) }) *pb.Header {
+		sig, key, err := tn.server.signRequestBody(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &pb.Header{PubKey: &pb.ProtoPubKey{PubKey: key}, Signature: sig}
+	}
+	signed := header(&pb.GetLogsRequest_Body{})
+
+	if _, err := verifyRequest(signed, (*pb.GetLogsRequest_Body)(nil)); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected a typed nil body to be invalid, got %v", err)
+	}
+
+	noThread := &pb.GetLogsRequest_Body{ServiceKey: &pb.ProtoKey{Key: info.Key.Service()}}
+	gets := map[string]*pb.GetLogsRequest{
+		"no body":   {Header: signed},
+		"no thread": {Header: header(noThread), Body: noThread},
+	}
+	for name, req := range gets {
+		if _, err := tn.server.GetLogs(ctx, req); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("%s: expected get logs to be invalid, got %v", name, err)
+		}
+	}
+	if _, err := tn.server.GetLogsBatch(ctx, &pb.GetLogsBatchRequest{Header: signed}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("no body: expected get logs batch to be invalid, got %v", err)
+	}
+
+	// A well-formed batch is still served
+	body := &pb.GetLogsBatchRequest_Body{Threads: []*pb.GetLogsRequest_Body{{
+		ThreadID:   &pb.ProtoThreadID{ID: info.ID},
+		ServiceKey: &pb.ProtoKey{Key: info.Key.Service()},
+	}}}
+	reply, err := tn.server.GetLogsBatch(ctx, &pb.GetLogsBatchRequest{Header: header(body), Body: body})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reply.Threads) != 1 || reply.Threads[0].Error != "" {
+		t.Fatalf("expected the thread's logs to be served, got %v", reply.Threads)
+	}
+}
+
+func TestServer_GetRecordsMalformedRequests(t *testing.T) {
+	t.Parallel()
+	n := makeNetwork(t)
+	defer n.Close()
+	tn := n.(*net)
+
+	ctx := context.Background()
+	info := createThread(t, ctx, n)
+	lg, err := tn.getOwnLog(info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := func(body *pb.GetRecordsRequest_Body) *pb.GetRecordsRequest {
+		sig, key, err := tn.server.signRequestBody(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &pb.GetRecordsRequest{
+			Header: &pb.Header{PubKey: &pb.ProtoPubKey{PubKey: key}, Signature: sig},
+			Body:   body,
+		}
+	}
+	withLogs := func(logs ...*pb.GetRecordsRequest_Body_LogEntry) *pb.GetRecordsRequest {
+		return request(&pb.GetRecordsRequest_Body{
+			ThreadID:   &pb.ProtoThreadID{ID: info.ID},
+			ServiceKey: &pb.ProtoKey{Key: info.Key.Service()},
+			Logs:       logs,
+		})
+	}
+	noBody := request(&pb.GetRecordsRequest_Body{})
+	noBody.Body = nil
+
+	reqs := map[string]*pb.GetRecordsRequest{
+		"no body":   noBody,
+		"no thread": request(&pb.GetRecordsRequest_Body{ServiceKey: &pb.ProtoKey{Key: info.Key.Service()}}),
+		"no log":    withLogs(&pb.GetRecordsRequest_Body_LogEntry{Offset: &pb.ProtoCid{Cid: cid.Undef}}),
+		"no offset": withLogs(&pb.GetRecordsRequest_Body_LogEntry{LogID: &pb.ProtoPeerID{ID: lg.ID}}),
+	}
+	for name, req := range reqs {
+		if _, err := tn.server.GetRecords(ctx, req); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("%s: expected get records to be invalid, got %v", name, err)
+		}
+		// The request is rejected before anything is sent on the stream
+		if err := tn.server.GetRecordsStream(req, nil); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("%s: expected get records stream to be invalid, got %v", name, err)
+		}
+	}
+
+	// A well-formed request is still served
+	req := withLogs(&pb.GetRecordsRequest_Body_LogEntry{
+		LogID:  &pb.ProtoPeerID{ID: lg.ID},
+		Offset: &pb.ProtoCid{Cid: cid.Undef},
+		Limit:  1,
+	})
+	if _, err = tn.server.GetRecords(ctx, req); err != nil {
+		t.Fatalf("expected get records to be served, got %v", err)
+	}
+}
+
+func TestServer_RetryPushesInBatches(t *testing.T) {
+	t.Parallel()
+	n1 := makeNetwork(t)
+	defer n1.Close()
+	n2 := makeNetwork(t)
+	defer n2.Close()
+	n2.Host().Peerstore().AddAddrs(n1.Host().ID(), n1.Host().Addrs(), peerstore.PermanentAddrTTL)
+
+	ctx := context.Background()
+	info := createThread(t, ctx, n1)
+	if _, err := n2.CreateThread(ctx, info.ID, core.WithThreadKey(info.Key)); err != nil {
+		t.Fatal(err)
+	}
+	tn1, tn2 := n1.(*net), n2.(*net)
+	lg, err := tn2.getOwnLog(info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tn1.store.AddLog(info.ID, thread.LogInfo{ID: lg.ID, PubKey: lg.PubKey}); err != nil {
+		t.Fatal(err)
+	}
+
+	var last cid.Cid
+	for i := 0; i < 5; i++ {
+		body, err := cbornode.WrapObject(map[string]interface{}{
+			"foo": i,
+		}, mh.SHA2_256, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := n2.CreateRecord(ctx, info.ID, body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req, err := tn2.server.newPushRecordRequest(ctx, info.ID, lg.ID, r.Value())
+		if err != nil {
+			t.Fatal(err)
+		}
+		tn2.server.outbound.enqueue(n1.Host().ID(), r.Value().Cid(), req)
+		last = r.Value().Cid()
+	}
+	tn2.server.retryPushes()
+
+	if stats := n2.OutboundQueueStats(); len(stats.Peers) != 0 {
+		t.Fatalf("expected the queue to be delivered, got %v", stats.Peers)
+	}
+	heads, err := tn1.store.Heads(info.ID, lg.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(heads) != 1 || !heads[0].Equals(last) {
+		t.Fatalf("expected head %s, got %v", last, heads)
+	}
+}
+
+func TestServer_OfflineQueue(t *testing.T) {
+	t.Parallel()
+	store := syncds.MutexWrap(ds.NewMapDatastore())
+	n1 := makeNetworkWithConfig(t, Config{OutboundStore: store})
+	defer n1.Close()
+	n2 := makeNetwork(t)
+	defer n2.Close()
+
+	ctx := context.Background()
+	info := createThread(t, ctx, n1)
+	if _, err := n2.CreateThread(ctx, info.ID, core.WithThreadKey(info.Key)); err != nil {
+		t.Fatal(err)
+	}
+	tn1, tn2 := n1.(*net), n2.(*net)
+	lg1, err := tn1.getOwnLog(info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tn2.store.AddLog(info.ID, thread.LogInfo{ID: lg1.ID, PubKey: lg1.PubKey}); err != nil {
+		t.Fatal(err)
+	}
+	// n1 doesn't know how to reach n2 yet
+	lg2, err := tn2.getOwnLog(info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr, err := ma.NewMultiaddr("/p2p/" + n2.Host().ID().String() + "/thread/" + info.ID.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tn1.store.AddLog(info.ID, thread.LogInfo{ID: lg2.ID, PubKey: lg2.PubKey, Addrs: []ma.Multiaddr{addr}}); err != nil {
+		t.Fatal(err)
+	}
+
+	body, err := cbornode.WrapObject(map[string]interface{}{
+		"foo": "bar",
+	}, mh.SHA2_256, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := n1.CreateRecord(ctx, info.ID, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitFor := func(cond func() bool) bool {
+		for deadline := time.Now().Add(time.Second * 10); time.Now().Before(deadline); {
+			if cond() {
+				return true
+			}
+			time.Sleep(time.Millisecond * 100)
+		}
+		return false
+	}
+	if !waitFor(func() bool { return tn1.server.outbound.len() == 1 }) {
+		t.Fatal("expected the push to the unreachable peer to be queued")
+	}
+
+	// The same record is queued once, and survives a restart
+	req, err := tn1.server.newPushRecordRequest(ctx, info.ID, lg1.ID, r.Value())
+	if err != nil {
+		t.Fatal(err)
+	}
+	tn1.server.outbound.enqueue(n2.Host().ID(), r.Value().Cid(), req)
+	if l := tn1.server.outbound.len(); l != 1 {
+		t.Fatalf("expected 1 queued push, got %d", l)
+	}
+	loaded, err := newOutbound(0, 0, store, defaultLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ps := loaded.m[n2.Host().ID()]; len(ps) != 1 || !ps[0].rid.Equals(r.Value().Cid()) {
+		t.Fatalf("expected the queued push to be loaded from the store, got %v", ps)
+	}
+
+	// The queued record is delivered when n2 comes online
+	if err = n1.Host().Connect(ctx, peer.AddrInfo{ID: n2.Host().ID(), Addrs: n2.Host().Addrs()}); err != nil {
+		t.Fatal(err)
+	}
+	if !waitFor(func() bool {
+		has, err := tn2.hasBlock(info.ID, r.Value().Cid())
+		return err == nil && has
+	}) {
+		t.Fatal("expected the queued record to be delivered")
+	}
+	if !waitFor(func() bool { return tn1.server.outbound.len() == 0 }) {
+		t.Fatal("expected the delivered push to be dequeued")
+	}
+	if loaded, err = newOutbound(0, 0, store, defaultLogger()); err != nil {
+		t.Fatal(err)
+	}
+	if l := loaded.len(); l != 0 {
+		t.Fatalf("expected the delivered push to be removed from the store, got %d", l)
+	}
+}
+
+func TestPushBackoff(t *testing.T) {
+	t.Parallel()
+	if d := pushBackoff(1); d != PushRetryInterval {
+		t.Fatalf("expected backoff %s, got %s", PushRetryInterval, d)
+	}
+	if d := pushBackoff(2); d != PushRetryInterval*2 {
+		t.Fatalf("expected backoff %s, got %s", PushRetryInterval*2, d)
+	}
+	if d := pushBackoff(100); d != MaxPushRetryBackoff {
+		t.Fatalf("expected backoff %s, got %s", MaxPushRetryBackoff, d)
+	}
+}
+
+func TestOutbound_Limit(t *testing.T) {
+	t.Parallel()
+	q, err := newOutbound(0, 2, nil, defaultLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pid := peer.ID("peer")
+	req := &pb.PushRecordRequest{Body: &pb.PushRecordRequest_Body{
+		ThreadID: &pb.ProtoThreadID{ID: thread.NewIDV1(thread.Raw, 32)},
+	}}
+	var rids []cid.Cid
+	for i := 0; i < 3; i++ {
+		h, err := mh.Sum([]byte{byte(i)}, mh.SHA2_256, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rids = append(rids, cid.NewCidV1(cid.Raw, h))
+	}
+	for _, rid := range rids[:2] {
+		if evicted := q.enqueue(pid, rid, req); len(evicted) != 0 {
+			t.Fatalf("expected nothing to be evicted, got %d", len(evicted))
+		}
+	}
+
+	// The oldest push makes room for a new one
+	evicted := q.enqueue(pid, rids[2], req)
+	if len(evicted) != 1 || !evicted[0].rid.Equals(rids[0]) {
+		t.Fatalf("expected the oldest push to be evicted, got %v", evicted)
+	}
+	if ps := q.m[pid]; len(ps) != 2 || !ps[0].rid.Equals(rids[1]) || !ps[1].rid.Equals(rids[2]) {
+		t.Fatalf("expected the newest pushes to be queued, got %v", ps)
+	}
+
+	// Pushes taken for a retry count against the limit when put back
+	taken := q.take(pid)[pid]
+	q.enqueue(pid, rids[0], req)
+	if evicted = q.requeue(pid, taken); len(evicted) != 1 || !evicted[0].rid.Equals(rids[1]) {
+		t.Fatalf("expected the oldest push to be evicted on requeue, got %v", evicted)
+	}
+	if l := q.len(); l != 2 {
+		t.Fatalf("expected 2 queued pushes, got %d", l)
+	}
+}
+
+func TestOutbound_Drop(t *testing.T) {
+	t.Parallel()
+	q, err := newOutbound(0, 0, nil, defaultLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pid := peer.ID("peer")
+	ids := []thread.ID{thread.NewIDV1(thread.Raw, 32), thread.NewIDV1(thread.Raw, 32)}
+	var rids []cid.Cid
+	for i := 0; i < 3; i++ {
+		h, err := mh.Sum([]byte{byte(i)}, mh.SHA2_256, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rids = append(rids, cid.NewCidV1(cid.Raw, h))
+		req := &pb.PushRecordRequest{Body: &pb.PushRecordRequest_Body{
+			ThreadID: &pb.ProtoThreadID{ID: ids[i%2]},
+		}}
+		q.enqueue(pid, rids[i], req)
+	}
+
+	// Pushes held by a retry are not put back once their thread is dropped
+	taken := q.take(pid)[pid]
+	q.drop(ids[0])
+	q.requeue(pid, taken)
+	if ps := q.m[pid]; len(ps) != 1 || !ps[0].rid.Equals(rids[1]) {
+		t.Fatalf("expected only the push of the other thread to be queued, got %v", ps)
+	}
+
+	// Pushes taken after a drop are put back
+	taken = q.take(pid)[pid]
+	q.requeue(pid, taken)
+	if l := q.len(); l != 1 {
+		t.Fatalf("expected 1 queued push, got %d", l)
+	}
+}
+
+func TestServer_ReusesConns(t *testing.T) {
+	t.Parallel()
+	n1 := makeNetwork(t)
+	defer n1.Close()
+	n2 := makeNetwork(t)
+	defer n2.Close()
+	n2.Host().Peerstore().AddAddrs(n1.Host().ID(), n1.Host().Addrs(), peerstore.PermanentAddrTTL)
+
+	ctx := context.Background()
+	info := createThread(t, ctx, n1)
+	if _, err := n2.CreateThread(ctx, info.ID, core.WithThreadKey(info.Key)); err != nil {
+		t.Fatal(err)
+	}
+	tn1, tn2 := n1.(*net), n2.(*net)
+	lg, err := tn2.getOwnLog(info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tn1.store.AddLog(info.ID, thread.LogInfo{ID: lg.ID, PubKey: lg.PubKey}); err != nil {
+		t.Fatal(err)
+	}
+
+	var conns []*grpc.ClientConn
+	for i := 0; i < 2; i++ {
+		body, err := cbornode.WrapObject(map[string]interface{}{
+			"foo": i,
+		}, mh.SHA2_256, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := n2.CreateRecord(ctx, info.ID, body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req, err := tn2.server.newPushRecordRequest(ctx, info.ID, lg.ID, r.Value())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = tn2.server.retryPush(n1.Host().ID(), req); err != nil {
+			t.Fatal(err)
+		}
+		conns = append(conns, tn2.server.conns.get(n1.Host().ID()))
+	}
+	if conns[0] == nil || conns[0] != conns[1] {
+		t.Fatal("expected pushes to the same peer to reuse a connection")
+	}
+	if l := tn2.server.conns.len(); l != 1 {
+		t.Fatalf("expected 1 cached connection, got %d", l)
+	}
+}
+
+func TestServer_MaxRecordSize(t *testing.T) {
+	t.Parallel()
+	bodies := make([]format.Node, 2)
+	for i := range bodies {
+		body, err := cbornode.WrapObject(map[string]interface{}{
+			"foo": strings.Repeat("a", 100+i),
+		}, mh.SHA2_256, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		bodies[i] = body
+	}
+	n1 := makeNetworkWithConfig(t, Config{
+		MaxRecordSize: len(bodies[0].RawData()),
+	})
+	defer n1.Close()
+	n2 := makeNetwork(t)
+	defer n2.Close()
+	n2.Host().Peerstore().AddAddrs(n1.Host().ID(), n1.Host().Addrs(), peerstore.PermanentAddrTTL)
+
+	ctx := context.Background()
+	info := createThread(t, ctx, n1)
+	if _, err := n2.CreateThread(ctx, info.ID, core.WithThreadKey(info.Key)); err != nil {
+		t.Fatal(err)
+	}
+	tn1, tn2 := n1.(*net), n2.(*net)
+	lg, err := tn2.getOwnLog(info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tn1.store.AddLog(info.ID, thread.LogInfo{ID: lg.ID, PubKey: lg.PubKey}); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, body := range bodies {
+		r, err := n2.CreateRecord(ctx, info.ID, body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req, err := tn2.server.newPushRecordRequest(ctx, info.ID, lg.ID, r.Value())
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = tn2.server.retryPush(n1.Host().ID(), req)
+		has, herr := tn1.hasBlock(info.ID, r.Value().Cid())
+		if herr != nil {
+			t.Fatal(herr)
+		}
+		if i == 0 {
+			if err != nil || !has {
+				t.Fatalf("expected a record at the limit to be stored, got %v", err)
+			}
+		} else if status.Code(err) != codes.InvalidArgument || has {
+			t.Fatalf("expected a record over the limit to be rejected, got %v", err)
+		}
+	}
+
+	// Records encrypted with a retired read key are measured decrypted
+	key := thread.NewKey(info.Key.Service(), thread.NewRandomKey().Read())
+	if err = tn1.rotateKey(info.ID, info.Key, key); err != nil {
+		t.Fatal(err)
+	}
+	body, err := cbornode.WrapObject(map[string]interface{}{
+		"foo": strings.Repeat("b", 100),
+	}, mh.SHA2_256, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := n2.CreateRecord(ctx, info.ID, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, err := tn2.server.newPushRecordRequest(ctx, info.ID, lg.ID, r.Value())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tn2.server.retryPush(n1.Host().ID(), req); err != nil {
+		t.Fatalf("expected a record at the limit with a retired read-key to be stored, got %v", err)
+	}
+}
+
+func TestServer_PeerRateLimit(t *testing.T) {
+	t.Parallel()
+	n1 := makeNetworkWithConfig(t, Config{
+		PeerRateLimit: PeerRateLimit{Rate: 0.01, Burst: 2},
+	})
+	defer n1.Close()
+	n2 := makeNetwork(t)
+	defer n2.Close()
+	n2.Host().Peerstore().AddAddrs(n1.Host().ID(), n1.Host().Addrs(), peerstore.PermanentAddrTTL)
+
+	ctx := context.Background()
+	info := createThread(t, ctx, n1)
+	if _, err := n2.CreateThread(ctx, info.ID, core.WithThreadKey(info.Key)); err != nil {
+		t.Fatal(err)
+	}
+	tn1, tn2 := n1.(*net), n2.(*net)
+	lg, err := tn2.getOwnLog(info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tn1.store.AddLog(info.ID, thread.LogInfo{ID: lg.ID, PubKey: lg.PubKey}); err != nil {
+		t.Fatal(err)
+	}
+
+	var rejected int
+	for i := 0; i < 5; i++ {
+		body, err := cbornode.WrapObject(map[string]interface{}{
+			"foo": i,
+		}, mh.SHA2_256, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := n2.CreateRecord(ctx, info.ID, body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req, err := tn2.server.newPushRecordRequest(ctx, info.ID, lg.ID, r.Value())
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = tn2.server.retryPush(n1.Host().ID(), req)
+		if status.Code(err) == codes.ResourceExhausted {
+			rejected++
+		} else if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if rejected != 3 {
+		t.Fatalf("expected 3 pushes over the burst to be rejected, got %d", rejected)
+	}
+
+	// Records received over pubsub are limited as well
+	body, err := cbornode.WrapObject(map[string]interface{}{
+		"foo": "pubsub",
+	}, mh.SHA2_256, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := n2.CreateRecord(ctx, info.ID, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, err := tn2.server.newPushRecordRequest(ctx, info.ID, lg.ID, r.Value())
+	if err != nil {
+		t.Fatal(err)
+	}
+	tn1.server.pubsubHandler(ctx, req)
+	if _, err = n1.GetRecord(ctx, info.ID, r.Value().Cid()); err == nil {
+		t.Fatal("expected record over the limit received over pubsub to be dropped")
+	}
+}
+
+func TestPeerLimiter(t *testing.T) {
+	t.Parallel()
+	trusted := peer.ID("trusted")
+	l := newPeerLimiter(PeerRateLimit{Rate: 2, Burst: 2, Trusted: []peer.ID{trusted}})
+	pid := peer.ID("peer")
+
+	now := time.Now()
+	for i := 0; i < 2; i++ {
+		if !l.allow(pid, 1, now) {
+			t.Fatalf("expected request %d to be allowed", i)
+		}
+	}
+	if l.allow(pid, 1, now) {
+		t.Fatal("expected request over the burst to be rejected")
+	}
+	if !l.allow(pid, 1, now.Add(time.Millisecond*500)) {
+		t.Fatal("expected a refilled token to be allowed")
+	}
+	if l.allow(pid, 1, now.Add(time.Millisecond*500)) {
+		t.Fatal("expected request over the rate to be rejected")
+	}
+	for i := 0; i < 10; i++ {
+		if !l.allow(trusted, 1, now) {
+			t.Fatal("expected a trusted peer not to be limited")
+		}
+	}
+	if !newPeerLimiter(PeerRateLimit{}).allow(pid, 1, now) {
+		t.Fatal("expected a zero limit to allow all requests")
+	}
+
+	// Batches take a token per record, and may overdraw a full bucket
+	batch := peer.ID("batch")
+	if !l.allow(batch, 3, now) {
+		t.Fatal("expected a batch larger than the burst to be allowed from a full bucket")
+	}
+	if l.allow(batch, 1, now.Add(time.Millisecond*500)) {
+		t.Fatal("expected overdrawn tokens to be waited for")
+	}
+	if !l.allow(batch, 1, now.Add(time.Second)) {
+		t.Fatal("expected a refilled token to be allowed")
+	}
+	if l.allow(batch, 2, now.Add(time.Second)) {
+		t.Fatal("expected a batch over the remaining tokens to be rejected")
+	}
+}
+
+func TestServer_CoalescesPushes(t *testing.T) {
+	t.Parallel()
+	n1 := makeNetwork(t)
+	defer n1.Close()
+	// Count direct pushes, holding each long enough for the others to coalesce
+	var pushed int32
+	n2 := makeNetworkWithConfig(t, Config{},
+		grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, h grpc.UnaryHandler) (interface{}, error) {
+			if _, ok := req.(*pb.PushRecordRequest); ok {
+				atomic.AddInt32(&pushed, 1)
+				time.Sleep(time.Millisecond * 200)
+			}
+			return h(ctx, req)
+		}))
+	defer n2.Close()
+	n1.Host().Peerstore().AddAddrs(n2.Host().ID(), n2.Host().Addrs(), peerstore.PermanentAddrTTL)
+
+	ctx := context.Background()
+	info := createThread(t, ctx, n1)
+	if _, err := n2.CreateThread(ctx, info.ID, core.WithThreadKey(info.Key)); err != nil {
+		t.Fatal(err)
+	}
+	tn1, tn2 := n1.(*net), n2.(*net)
+	lg1, err := tn1.getOwnLog(info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tn2.store.AddLog(info.ID, thread.LogInfo{ID: lg1.ID, PubKey: lg1.PubKey}); err != nil {
+		t.Fatal(err)
+	}
+	lg2, err := tn2.getOwnLog(info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr, err := ma.NewMultiaddr("/p2p/" + n2.Host().ID().String() + "/thread/" + info.ID.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tn1.store.AddLog(info.ID, thread.LogInfo{ID: lg2.ID, PubKey: lg2.PubKey, Addrs: []ma.Multiaddr{addr}}); err != nil {
+		t.Fatal(err)
+	}
+
+	body, err := cbornode.WrapObject(map[string]interface{}{
+		"foo": "bar",
+	}, mh.SHA2_256, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec, _, err := tn1.appendOwnRecord(ctx, info.ID, body, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	results := make([]core.PushResult, 10)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			p, err := tn1.server.pushRecord(ctx, info.ID, lg1.ID, rec, 0)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			results[i] = p.result()
+		}(i)
+	}
+	wg.Wait()
+	if p := atomic.LoadInt32(&pushed); p != 1 {
+		t.Fatalf("expected 1 push to the address, got %d", p)
+	}
+	for _, res := range results {
+		if res.Succeeded != 1 {
+			t.Fatalf("expected every caller to see the push succeed, got %+v", res)
+		}
+	}
+	for deadline := time.Now().Add(time.Second); tn1.server.inflight.len() > 0; {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the record to leave flight")
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+}
+
+func TestConnCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	t.Parallel()
+	c := newConnCache(2, defaultLogger())
+	defer c.closeAll()
+	pids := make([]peer.ID, 3)
+	for i := range pids {
+		pids[i] = peer.ID(fmt.Sprintf("peer%d", i))
+		// Connections never leave the connecting state
+		conn, err := grpc.Dial(pids[i].Pretty(), grpc.WithInsecure(), grpc.WithContextDialer(
+			func(ctx context.Context, _ string) (nnet.Conn, error) {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		c.put(pids[i], conn)
+		if i == 1 {
+			c.get(pids[0])
+		}
+	}
+	if c.len() != 2 {
+		t.Fatalf("expected 2 cached connections, got %d", c.len())
+	}
+	if c.get(pids[1]) != nil {
+		t.Fatal("expected the least recently used connection to be evicted")
+	}
+	if c.get(pids[0]) == nil || c.get(pids[2]) == nil {
+		t.Fatal("expected recently used connections to be kept")
+	}
+}
+
+func TestServer_CheckConns(t *testing.T) {
+	t.Parallel()
+	n1 := makeNetwork(t)
+	defer n1.Close()
+	n2 := makeNetwork(t)
+	n1.Host().Peerstore().AddAddrs(n2.Host().ID(), n2.Host().Addrs(), peerstore.PermanentAddrTTL)
+	tn1 := n1.(*net)
+	pid := n2.Host().ID()
+
+	ctx := context.Background()
+	client, err := tn1.server.dial(pid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = client.Ping(ctx, &pb.PingRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	tn1.server.checkConns(ctx)
+	if tn1.server.conns.len() != 1 {
+		t.Fatal("expected the live connection to be kept")
+	}
+
+	// Closing the peer closes the underlying stream
+	if err = n2.Close(); err != nil {
+		t.Fatal(err)
+	}
+	tn1.server.checkConns(ctx)
+	if tn1.server.conns.len() != 0 {
+		t.Fatal("expected the dead connection to be evicted")
+	}
+	if b := n1.PeerBreakers()[pid]; b.Failures != 1 {
+		t.Fatalf("expected the failed check to be recorded, got %+v", b)
+	}
+
+	// Peers on older versions are alive without Ping
+	old := makeNetworkWithConfig(t, Config{Protocols: []protocol.ID{"/thread/0.0.1"}},
+		grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, h grpc.UnaryHandler) (interface{}, error) {
+			if path.Base(info.FullMethod) == "Ping" {
+				return nil, status.Error(codes.Unimplemented, "unknown method Ping")
+			}
+			return h(ctx, req)
+		}))
+	defer old.Close()
+	n1.Host().Peerstore().AddAddrs(old.Host().ID(), old.Host().Addrs(), peerstore.PermanentAddrTTL)
+	if _, err = tn1.server.dial(old.Host().ID()); err != nil {
+		t.Fatal(err)
+	}
+	tn1.server.checkConns(ctx)
+	if tn1.server.conns.len() != 1 {
+		t.Fatal("expected the connection to a peer without Ping to be kept")
+	}
+	if b, ok := n1.PeerBreakers()[old.Host().ID()]; ok {
+		t.Fatalf("expected no failures to be recorded, got %+v", b)
+	}
+}
+
+func TestSupportsKeepalive(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		proto protocol.ID
+		want  bool
+	}{
+		{thread.Protocol, true},
+		{"/thread/0.0.1", false},
+		{"/thread/0.1.0", true},
+		{"/thread/1.0", true},
+		{"/thread/0.0", false},
+		{"/thread/x.y.z", false},
+		{"/other/1.0.0", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := supportsKeepalive(tt.proto); got != tt.want {
+			t.Fatalf("%q: expected %v, got %v", tt.proto, tt.want, got)
+		}
+	}
+}
+
+func TestNet_ProtocolVersions(t *testing.T) {
+	t.Parallel()
+	n1 := makeNetwork(t)
+	defer n1.Close()
+	next := protocol.ID("/thread/9.9.9")
+	newer := makeNetworkWithConfig(t, Config{Protocols: []protocol.ID{next, thread.Protocol}})
+	defer newer.Close()
+	only := makeNetworkWithConfig(t, Config{Protocols: []protocol.ID{next}})
+	defer only.Close()
+	newer.Host().Peerstore().AddAddrs(n1.Host().ID(), n1.Host().Addrs(), peerstore.PermanentAddrTTL)
+	only.Host().Peerstore().AddAddrs(newer.Host().ID(), newer.Host().Addrs(), peerstore.PermanentAddrTTL)
+	n1.Host().Peerstore().AddAddrs(only.Host().ID(), only.Host().Addrs(), peerstore.PermanentAddrTTL)
+
+	// A host on a newer version falls back to the current one to pull a
+	// thread from a current peer
+	ctx := context.Background()
+	info := createThread(t, ctx, n1)
+	body, err := cbornode.WrapObject(map[string]interface{}{"foo": "bar"}, mh.SHA2_256, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec, err := n1.CreateRecord(ctx, info.ID, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr, err := ma.NewMultiaddr("/p2p/" + n1.Host().ID().String() + "/thread/" + info.ID.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = newer.AddThread(ctx, addr, core.WithThreadKey(info.Key)); err != nil {
+		t.Fatal(err)
+	}
+	if err = newer.PullThread(ctx, info.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = newer.GetRecord(ctx, info.ID, rec.Value().Cid()); err != nil {
+		t.Fatalf("expected the record to be pulled from the current peer: %v", err)
+	}
+
+	// The newer host serves both versions
+	addr, err = ma.NewMultiaddr("/p2p/" + newer.Host().ID().String() + "/thread/" + info.ID.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = only.AddThread(ctx, addr, core.WithThreadKey(info.Key)); err != nil {
+		t.Fatal(err)
+	}
+	if err = only.PullThread(ctx, info.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = only.GetRecord(ctx, info.ID, rec.Value().Cid()); err != nil {
+		t.Fatalf("expected the record to be pulled with the newer version: %v", err)
+	}
+
+	// A peer without a version in common fails to connect
+	info2 := createThread(t, ctx, only)
+	addr, err = ma.NewMultiaddr("/p2p/" + only.Host().ID().String() + "/thread/" + info2.ID.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = n1.AddThread(ctx, addr, core.WithThreadKey(info2.Key)); err == nil {
+		t.Fatal("expected a peer without a common version to fail")
+	}
+	only.Host().Peerstore().AddAddrs(n1.Host().ID(), n1.Host().Addrs(), peerstore.PermanentAddrTTL)
+	info3 := createThread(t, ctx, n1)
+	addr, err = ma.NewMultiaddr("/p2p/" + n1.Host().ID().String() + "/thread/" + info3.ID.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = only.AddThread(ctx, addr, core.WithThreadKey(info3.Key)); err == nil {
+		t.Fatal("expected dialing a peer without a common version to fail")
+	}
+}
+
+func TestPeerHealth_Breaker(t *testing.T) {
+	t.Parallel()
+	h := newPeerHealth(2, time.Millisecond*50, defaultLogger())
+	pid := peer.ID("peer")
+	state := func() core.BreakerState {
+		return h.breakers()[pid].State
+	}
+
+	h.failure(pid)
+	if h.unreachable(pid) || state() != core.BreakerClosed {
+		t.Fatalf("expected a closed breaker after 1 failure, got %s", state())
+	}
+	h.failure(pid)
+	if !h.unreachable(pid) || state() != core.BreakerOpen {
+		t.Fatalf("expected an open breaker after 2 failures, got %s", state())
+	}
+
+	// A failed probe opens the breaker again
+	time.Sleep(time.Millisecond * 60)
+	if state() != core.BreakerHalfOpen {
+		t.Fatalf("expected a half-open breaker after the cooldown, got %s", state())
+	}
+	if h.unreachable(pid) {
+		t.Fatal("expected a probe to be let through")
+	}
+	if !h.unreachable(pid) {
+		t.Fatal("expected requests to be skipped during the probe")
+	}
+	h.failure(pid)
+	if !h.unreachable(pid) || state() != core.BreakerOpen {
+		t.Fatalf("expected an open breaker after a failed probe, got %s", state())
+	}
+
+	// A successful probe closes it
+	time.Sleep(time.Millisecond * 60)
+	if h.unreachable(pid) {
+		t.Fatal("expected a probe to be let through")
+	}
+	h.success(pid)
+	if h.unreachable(pid) {
+		t.Fatal("expected requests to be made after a successful probe")
+	}
+	if _, ok := h.breakers()[pid]; ok {
+		t.Fatal("expected the breaker to be reset")
+	}
+}
+
+func TestNet_BreakerSkipsDials(t *testing.T) {
+	t.Parallel()
+	n := makeNetworkWithConfig(t, Config{UnreachableAfter: 2, UnreachableBackoff: time.Hour})
+	defer n.Close()
+	tn := n.(*net)
+
+	// A member that can't be reached
+	ctx := context.Background()
+	info := createThread(t, ctx, n)
+	_, pk, err := crypto.GenerateEd25519Key(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dead, err := peer.IDFromPublicKey(pk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tn.store.AddLog(info.ID, thread.LogInfo{
+		ID:     dead,
+		PubKey: pk,
+		Addrs:  []ma.Multiaddr{util.MustParseAddr("/p2p/" + dead.String())},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	push := func(i int) core.Delivery {
+		body, err := cbornode.WrapObject(map[string]interface{}{"foo": i}, mh.SHA2_256, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := n.CreateRecord(ctx, info.ID, body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for start := time.Now(); ; time.Sleep(time.Millisecond * 10) {
+			if ds := n.Deliveries(r.Value().Cid()); len(ds) > 0 {
+				return ds[0]
+			}
+			if time.Since(start) > time.Second*5 {
+				t.Fatal("timed out waiting for the delivery")
+			}
+		}
+	}
+	for i := 0; i < 2; i++ {
+		if d := push(i); d.Outcome != core.PushFailed {
+			t.Fatalf("expected push %d to fail, got %s", i, d.Outcome)
+		}
+	}
+	b := n.PeerBreakers()[dead]
+	if b.State != core.BreakerOpen || b.Failures != 2 {
+		t.Fatalf("expected an open breaker after 2 failures, got %+v", b)
+	}
+
+	// Pushes during the cooldown are deferred without dialing the peer
+	if d := push(2); d.Outcome != core.PushDeferred {
+		t.Fatalf("expected the push to be deferred, got %s", d.Outcome)
+	}
+	if b = n.PeerBreakers()[dead]; b.Failures != 2 {
+		t.Fatalf("expected no further attempts, got %d failures", b.Failures)
+	}
+
+	// Queued pushes are held by the retry loop without counting attempts
+	before := n.OutboundQueueStats().Peers[dead]
+	if before.Pending == 0 {
+		t.Fatal("expected pushes to be queued for the peer")
+	}
+	tn.server.retryPushesTo(dead)
+	if b = n.PeerBreakers()[dead]; b.Failures != 2 {
+		t.Fatalf("expected queued pushes not to be retried, got %d failures", b.Failures)
+	}
+	if after := n.OutboundQueueStats().Peers[dead]; after.Pending != before.Pending || after.Attempts != before.Attempts {
+		t.Fatalf("expected queued pushes to be held, got %+v", after)
+	}
+}
+
+func TestNet_ConnectionPressure(t *testing.T) {
+	t.Parallel()
+	n1 := makeNetworkWithConfig(t, Config{ConnHighWater: 1})
+	defer n1.Close()
+	n2 := makeNetwork(t)
+	defer n2.Close()
+	n3 := makeNetwork(t)
+	defer n3.Close()
+	tn1 := n1.(*net)
+
+	// A single connection puts n1 under pressure
+	ctx := context.Background()
+	for _, n := range []core.Net{n2, n3} {
+		n1.Host().Peerstore().AddAddrs(n.Host().ID(), n.Host().Addrs(), peerstore.PermanentAddrTTL)
+	}
+	if err := n1.Host().Connect(ctx, peer.AddrInfo{ID: n2.Host().ID(), Addrs: n2.Host().Addrs()}); err != nil {
+		t.Fatal(err)
+	}
+	if !tn1.underPressure() {
+		t.Fatal("expected the host to be under connection pressure")
+	}
+	info := createThread(t, ctx, n1)
+	for _, n := range []core.Net{n2, n3} {
+		lg, err := createLog(n.Host().ID(), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lg.Addrs = []ma.Multiaddr{util.MustParseAddr("/p2p/" + n.Host().ID().String())}
+		lg.PrivKey = nil
+		if err = tn1.store.AddLog(info.ID, lg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	connected := func(n core.Net) bool {
+		return n1.Host().Network().Connectedness(n.Host().ID()) == network.Connected
+	}
+
+	// Pulls skip peers that are not connected
+	if err := n1.PullThread(ctx, info.ID); err != nil {
+		t.Fatal(err)
+	}
+	if connected(n3) {
+		t.Fatal("expected the pull not to dial an unconnected peer")
+	}
+
+	// Pushes to peers that are not connected are deferred
+	deliveries := func(opts ...core.ThreadOption) map[peer.ID]core.Delivery {
+		body, err := cbornode.WrapObject(map[string]interface{}{"foo": "bar"}, mh.SHA2_256, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := n1.CreateRecord(ctx, info.ID, body, opts...)
+		if r == nil {
+			t.Fatal(err)
+		}
+		for start := time.Now(); ; time.Sleep(time.Millisecond * 10) {
+			if ds := n1.Deliveries(r.Value().Cid()); len(ds) == 2 {
+				m := make(map[peer.ID]core.Delivery)
+				for _, d := range ds {
+					m[d.Peer] = d
+				}
+				return m
+			}
+			if time.Since(start) > time.Second*5 {
+				t.Fatal("timed out waiting for deliveries")
+			}
+		}
+	}
+	ds := deliveries()
+	if ds[n3.Host().ID()].Outcome != core.PushDeferred {
+		t.Fatalf("expected the push to the unconnected peer to be deferred, got %s", ds[n3.Host().ID()].Outcome)
+	}
+	if ds[n2.Host().ID()].Outcome == core.PushDeferred {
+		t.Fatal("expected the push to the connected peer to go ahead")
+	}
+	if connected(n3) {
+		t.Fatal("expected the deferred push not to dial the peer")
+	}
+
+	// Critical pushes go ahead
+	ds = deliveries(core.WithMinReplicas(2))
+	if ds[n3.Host().ID()].Outcome == core.PushDeferred {
+		t.Fatal("expected a critical push not to be deferred")
+	}
+	if !connected(n3) {
+		t.Fatal("expected the critical push to dial the peer")
+	}
+}
+
+func TestNet_ReplicationFilter(t *testing.T) {
+	t.Parallel()
+	n2 := makeNetwork(t)
+	defer n2.Close()
+
+	ctx := context.Background()
+	info := createThread(t, ctx, n2)
+	var recs []core.ThreadRecord
+	for i := 0; i < 3; i++ {
+		body, err := cbornode.WrapObject(map[string]interface{}{"count": i}, mh.SHA2_256, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := n2.CreateRecord(ctx, info.ID, body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		recs = append(recs, r)
+	}
+	skipped := recs[1].Value().Cid()
+
+	// A replica that skips the body of the second record
+	n1 := makeNetworkWithConfig(t, Config{ReplicationFilter: func(m RecordMeta) bool {
+		return !m.Record.Cid().Equals(skipped)
+	}})
+	defer n1.Close()
+	n1.Host().Peerstore().AddAddrs(n2.Host().ID(), n2.Host().Addrs(), peerstore.PermanentAddrTTL)
+	addr, err := ma.NewMultiaddr("/p2p/" + n2.Host().ID().String() + "/thread/" + info.ID.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = n1.AddThread(ctx, addr, core.WithThreadKey(info.Key)); err != nil {
+		t.Fatal(err)
+	}
+	if err = n1.PullThread(ctx, info.ID); err != nil {
+		t.Fatal(err)
+	}
+	tn1 := n1.(*net)
+	lg, err := tn1.store.GetLog(info.ID, recs[0].LogID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !lg.Head.Equals(recs[2].Value().Cid()) {
+		t.Fatal("expected the log head to advance past the skipped body")
+	}
+
+	// The log is served up to the record with the skipped body
+	body := &pb.GetRecordsRequest_Body{
+		ThreadID:   &pb.ProtoThreadID{ID: info.ID},
+		ServiceKey: &pb.ProtoKey{Key: info.Key.Service()},
+	}
+	header, err := SignRequest(n2.(*net).getPrivKey(), body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reply, err := tn1.server.GetRecords(ctx, &pb.GetRecordsRequest{Header: header, Body: body})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range reply.Logs {
+		if entry.LogID.ID != lg.ID {
+			continue
+		}
+		if len(entry.Records) != 1 || entry.Truncated {
+			t.Fatalf("expected only the record before the skipped body, got %d", len(entry.Records))
+		}
+		rec, err := cbor.RecordFromProto(entry.Records[0], info.Key.Service())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !rec.Cid().Equals(recs[0].Value().Cid()) {
+			t.Fatal("expected the first record to be served")
+		}
+		return
+	}
+	t.Fatal("expected the log to be served")
+}
+
+func TestNet_ReadRepair(t *testing.T) {
+	t.Parallel()
+	n1 := makeNetworkWithConfig(t, Config{Debug: true, ReadRepair: true})
+	defer n1.Close()
+	n2 := makeNetwork(t)
+	defer n2.Close()
+	n1.Host().Peerstore().AddAddrs(n2.Host().ID(), n2.Host().Addrs(), peerstore.PermanentAddrTTL)
+
+	ctx := context.Background()
+	info := createThread(t, ctx, n1)
+	if _, err := n2.CreateThread(ctx, info.ID, core.WithThreadKey(info.Key)); err != nil {
+		t.Fatal(err)
+	}
+	tn1, tn2 := n1.(*net), n2.(*net)
+	lg1, err := tn1.getOwnLog(info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tn2.store.AddLog(info.ID, thread.LogInfo{ID: lg1.ID, PubKey: lg1.PubKey}); err != nil {
+		t.Fatal(err)
+	}
+	var recs []core.Record
+	for i := 0; i < 4; i++ {
+		body, err := cbornode.WrapObject(map[string]interface{}{"count": i}, mh.SHA2_256, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := n1.CreateRecord(ctx, info.ID, body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		recs = append(recs, r.Value())
+	}
+
+	// A peer at the offset is missing the merged records
+	missing, err := tn1.server.missingRecords(ctx, info.ID, lg1.ID, recs[1].Cid(), nil, recs[2:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(missing) != 2 || !missing[0].Cid().Equals(recs[2].Cid()) {
+		t.Fatalf("expected the merged records to be missing, got %d", len(missing))
+	}
+
+	// A peer on another branch of the log is not repaired
+	other := createThread(t, ctx, n1)
+	body, err := cbornode.WrapObject(map[string]interface{}{"count": 0}, mh.SHA2_256, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	branch, err := n1.CreateRecord(ctx, other.ID, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	missing, err = tn1.server.missingRecords(ctx, info.ID, lg1.ID, recs[3].Cid(), []core.Record{branch.Value()}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(missing) != 0 {
+		t.Fatalf("expected nothing to repair on another branch, got %d", len(missing))
+	}
+
+	// A peer behind the offset is repaired with the local records after its head
+	for _, r := range recs[:2] {
+		if err = n2.AddRecord(ctx, info.ID, lg1.ID, r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	addr, err := ma.NewMultiaddr("/p2p/" + n2.Host().ID().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = tn1.server.getRecordRange(ctx, info.ID, lg1.ID, recs[3].Cid(), cid.Undef, []ma.Multiaddr{addr}); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(time.Second * 5)
+	for {
+		lg, err := tn2.store.GetLog(info.ID, lg1.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if lg.Head.Equals(recs[3].Cid()) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected the peer to be repaired")
+		}
+		time.Sleep(time.Millisecond * 50)
+	}
+}
+
+func TestNet_Flush(t *testing.T) {
+	t.Parallel()
+	n1 := makeNetwork(t)
+	defer n1.Close()
+	n2 := makeNetworkWithConfig(t, Config{RequestTimeout: time.Millisecond * 500})
+	defer n2.Close()
+	n2.Host().Peerstore().AddAddrs(n1.Host().ID(), n1.Host().Addrs(), peerstore.PermanentAddrTTL)
+
+	ctx := context.Background()
+	info := createThread(t, ctx, n1)
+	if _, err := n2.CreateThread(ctx, info.ID, core.WithThreadKey(info.Key)); err != nil {
+		t.Fatal(err)
+	}
+	tn1, tn2 := n1.(*net), n2.(*net)
+	lg, err := tn2.getOwnLog(info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tn1.store.AddLog(info.ID, thread.LogInfo{ID: lg.ID, PubKey: lg.PubKey}); err != nil {
+		t.Fatal(err)
+	}
+	var recs []core.Record
+	for i := 0; i < 2; i++ {
+		body, err := cbornode.WrapObject(map[string]interface{}{
+			"foo": i,
+		}, mh.SHA2_256, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := n2.CreateRecord(ctx, info.ID, body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		recs = append(recs, r.Value())
+	}
+
+	// A record whose previous record arrived while it was buffered is applied
+	if err = n1.AddRecord(ctx, info.ID, lg.ID, recs[0]); err != nil {
+		t.Fatal(err)
+	}
+	if !tn1.orphans.add(info.ID, lg.ID, recs[1]) {
+		t.Fatal("expected record to be buffered")
+	}
+	if err = n1.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	heads, err := tn1.store.Heads(info.ID, lg.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(heads) != 1 || !heads[0].Equals(recs[1].Cid()) {
+		t.Fatalf("expected buffered record to be applied, got heads %v", heads)
+	}
+
+	// Queued pushes are delivered
+	req, err := tn2.server.newPushRecordRequest(ctx, info.ID, lg.ID, recs[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	tn2.server.outbound.enqueue(n1.Host().ID(), recs[1].Cid(), req)
+	if err = n2.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if stats := n2.OutboundQueueStats(); len(stats.Peers) != 0 {
+		t.Fatalf("expected the queue to be delivered, got %v", stats.Peers)
+	}
+
+	// Pushes that can't be delivered hold the flush until the context is done
+	n3 := makeNetwork(t)
+	tn2.server.outbound.enqueue(n3.Host().ID(), recs[1].Cid(), req)
+	n3.Close()
+	fctx, cancel := context.WithTimeout(ctx, time.Millisecond*100)
+	defer cancel()
+	if err = n2.Flush(fctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected flush to end with the context, got %v", err)
+	}
+}
+
+func TestServer_RecordValidators(t *testing.T) {
+	t.Parallel()
+	var verdict int32 // 0 accepts, 1 rejects, 2 fails
+	var calls int32
+	n1 := makeNetworkWithConfig(t, Config{
+		RecordValidators: []RecordValidator{
+			func(_ context.Context, r PushedRecord) (Verdict, error) {
+				atomic.AddInt32(&calls, 1)
+				switch atomic.LoadInt32(&verdict) {
+				case 1:
+					return VerdictReject, nil
+				case 2:
+					return VerdictAccept, fmt.Errorf("validator failed")
+				default:
+					return VerdictAccept, nil
+				}
+			},
+		},
+	})
+	defer n1.Close()
+	n2 := makeNetwork(t)
+	defer n2.Close()
+
+	ctx := context.Background()
+	info := createThread(t, ctx, n1)
+	if _, err := n2.CreateThread(ctx, info.ID, core.WithThreadKey(info.Key)); err != nil {
+		t.Fatal(err)
+	}
+	tn1, tn2 := n1.(*net), n2.(*net)
+	lg, err := tn2.getOwnLog(info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tn1.store.AddLog(info.ID, thread.LogInfo{ID: lg.ID, PubKey: lg.PubKey}); err != nil {
+		t.Fatal(err)
+	}
+	body, err := cbornode.WrapObject(map[string]interface{}{
+		"foo": "bar",
+	}, mh.SHA2_256, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := n2.CreateRecord(ctx, info.ID, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, err := tn2.server.newPushRecordRequest(ctx, info.ID, lg.ID, r.Value())
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored := func() bool {
+		has, err := tn1.hasBlock(info.ID, r.Value().Cid())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return has
+	}
+
+	atomic.StoreInt32(&verdict, 1)
+	if _, err = tn1.server.PushRecord(ctx, req); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("expected rejected record to be denied, got %v", err)
+	}
+	tn1.server.pubsubHandler(ctx, req)
+	if stored() || atomic.LoadInt32(&calls) != 2 {
+		t.Fatal("expected pushed and published records to be rejected")
+	}
+
+	atomic.StoreInt32(&verdict, 2)
+	if _, err = tn1.server.PushRecord(ctx, req); status.Code(err) != codes.Internal {
+		t.Fatalf("expected failed validation to be an internal error, got %v", err)
+	}
+
+	atomic.StoreInt32(&verdict, 0)
+	if _, err = tn1.server.PushRecord(ctx, req); err != nil {
+		t.Fatal(err)
+	}
+	if !stored() {
+		t.Fatal("expected accepted record to be stored")
+	}
+}
+
+func TestServer_FetchMissingLogs(t *testing.T) {
+	t.Parallel()
+	n1 := makeNetworkWithConfig(t, Config{
+		FetchMissingLogs: true,
+	})
+	defer n1.Close()
+	n2 := makeNetwork(t)
+	defer n2.Close()
+	n1.Host().Peerstore().AddAddrs(n2.Host().ID(), n2.Host().Addrs(), peerstore.PermanentAddrTTL)
+
+	ctx := context.Background()
+	info := createThread(t, ctx, n1)
+	if _, err := n2.CreateThread(ctx, info.ID, core.WithThreadKey(info.Key)); err != nil {
+		t.Fatal(err)
+	}
+	body, err := cbornode.WrapObject(map[string]interface{}{
+		"foo": "bar",
+	}, mh.SHA2_256, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var last core.ThreadRecord
+	for i := 0; i < 2; i++ {
+		if last, err = n2.CreateRecord(ctx, info.ID, body); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// n1 has neither n2's log nor the record before the pushed one
+	tn1, tn2 := n1.(*net), n2.(*net)
+	req, err := tn2.server.newPushRecordRequest(ctx, info.ID, last.LogID(), last.Value())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = tn1.server.PushRecord(ctx, req); !isHeld(err) {
+		t.Fatalf("expected the record to be held, got %v", err)
+	}
+	// The records before the pushed one are fetched in the background
+	for deadline := time.Now().Add(time.Second * 5); ; {
+		heads, err := tn1.store.Heads(info.ID, last.LogID())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(heads) == 1 && heads[0].Equals(last.Value().Cid()) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the fetched log to include the pushed record, got %v", heads)
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	// A log the sender doesn't have is not added
+	_, pk, err := crypto.GenerateEd25519Key(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lid, err := peer.IDFromPublicKey(pk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lpk, err := tn1.server.fetchLog(ctx, info.ID, lid, n2.Host().ID()); err != nil || lpk != nil {
+		t.Fatalf("expected an unknown log not to be fetched, got %v", err)
+	}
+
+	// Only one fetch of a log runs at a time
+	if err = tn2.store.AddLog(info.ID, thread.LogInfo{ID: lid, PubKey: pk}); err != nil {
+		t.Fatal(err)
+	}
+	k := logKey{id: info.ID, lid: lid}
+	tn1.server.logFetches[k] = struct{}{}
+	if lpk, err := tn1.server.fetchLog(ctx, info.ID, lid, n2.Host().ID()); err != nil || lpk != nil {
+		t.Fatalf("expected a log being fetched to be skipped, got %v", err)
+	}
+	delete(tn1.server.logFetches, k)
+	if lpk, err := tn1.server.fetchLog(ctx, info.ID, lid, n2.Host().ID()); err != nil || !lpk.Equals(pk) {
+		t.Fatalf("expected the log to be fetched, got %v", err)
+	}
+}
+
+func TestServer_FillGap(t *testing.T) {
+	t.Parallel()
+	n1 := makeNetwork(t)
+	defer n1.Close()
+	n2 := makeNetwork(t)
+	defer n2.Close()
+	n2.Host().Peerstore().AddAddrs(n1.Host().ID(), n1.Host().Addrs(), peerstore.PermanentAddrTTL)
+
+	ctx := context.Background()
+	info := createThread(t, ctx, n1)
+	if _, err := n2.CreateThread(ctx, info.ID, core.WithThreadKey(info.Key)); err != nil {
+		t.Fatal(err)
+	}
+	tn1, tn2 := n1.(*net), n2.(*net)
+	lg, err := tn1.getOwnLog(info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tn2.store.AddLog(info.ID, thread.LogInfo{ID: lg.ID, PubKey: lg.PubKey}); err != nil {
+		t.Fatal(err)
+	}
+	body, err := cbornode.WrapObject(map[string]interface{}{
+		"foo": "bar",
+	}, mh.SHA2_256, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var recs []core.Record
+	for i := 0; i < 4; i++ {
+		r, err := n1.CreateRecord(ctx, info.ID, body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		recs = append(recs, r.Value())
+	}
+
+	// Only the newest records are pushed, newest first
+	for i := len(recs) - 1; i >= 2; i-- {
+		req, err := tn1.server.newPushRecordRequest(ctx, info.ID, lg.ID, recs[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = tn2.server.PushRecord(ctx, req); err != nil && !isHeld(err) {
+			t.Fatal(err)
+		}
+	}
+	head := recs[len(recs)-1].Cid()
+	for deadline := time.Now().Add(time.Second * 10); ; {
+		heads, err := tn2.store.Heads(info.ID, lg.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(heads) == 1 && heads[0].Equals(head) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected head %s, got %v", head, heads)
+		}
+		time.Sleep(time.Millisecond * 50)
+	}
+	got, err := tn2.Ancestry(ctx, info.ID, lg.ID, cid.Undef, head)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(recs) {
+		t.Fatalf("expected %d records, got %d", len(recs), len(got))
+	}
+	for i, r := range got {
+		if !r.Cid().Equals(recs[i].Cid()) {
+			t.Fatalf("expected record %d to be %s, got %s", i, recs[i].Cid(), r.Cid())
+		}
+	}
+}
+
+func TestServer_FillGapValidates(t *testing.T) {
+	t.Parallel()
+	n1 := makeNetwork(t)
+	defer n1.Close()
+	var reject atomic.Value
+	reject.Store(cid.Undef)
+	var rejected int32
+	n2 := makeNetworkWithConfig(t, Config{
+		RecordValidators: []RecordValidator{func(_ context.Context, r PushedRecord) (Verdict, error) {
+			if r.Record.Cid().Equals(reject.Load().(cid.Cid)) {
+				atomic.StoreInt32(&rejected, 1)
+				return VerdictReject, nil
+			}
+			return VerdictAccept, nil
+		}},
+	})
+	defer n2.Close()
+	n2.Host().Peerstore().AddAddrs(n1.Host().ID(), n1.Host().Addrs(), peerstore.PermanentAddrTTL)
+
+	ctx := context.Background()
+	info := createThread(t, ctx, n1)
+	if _, err := n2.CreateThread(ctx, info.ID, core.WithThreadKey(info.Key)); err != nil {
+		t.Fatal(err)
+	}
+	tn1, tn2 := n1.(*net), n2.(*net)
+	lg, err := tn1.getOwnLog(info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tn2.store.AddLog(info.ID, thread.LogInfo{ID: lg.ID, PubKey: lg.PubKey}); err != nil {
+		t.Fatal(err)
+	}
+	body, err := cbornode.WrapObject(map[string]interface{}{
+		"foo": "bar",
+	}, mh.SHA2_256, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := n1.CreateRecord(ctx, info.ID, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := n1.CreateRecord(ctx, info.ID, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reject.Store(first.Value().Cid())
+
+	// The fetched first record is rejected like a pushed one
+	req, err := tn1.server.newPushRecordRequest(ctx, info.ID, lg.ID, second.Value())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = tn2.server.PushRecord(ctx, req); !isHeld(err) {
+		t.Fatalf("expected the record to be held, got %v", err)
+	}
+	k := logKey{id: info.ID, lid: lg.ID}
+	for deadline := time.Now().Add(time.Second * 10); ; {
+		tn2.server.gaps.Lock()
+		_, filling := tn2.server.gaps.m[k]
+		tn2.server.gaps.Unlock()
+		if !filling && atomic.LoadInt32(&rejected) == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected the fetched record to be validated")
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+	for _, c := range []cid.Cid{first.Value().Cid(), second.Value().Cid()} {
+		if has, err := tn2.hasBlock(info.ID, c); err != nil || has {
+			t.Fatalf("expected record %s not to be stored, got %v", c, err)
+		}
+	}
+}
+
+func TestServer_Metrics(t *testing.T) {
+	t.Parallel()
+	reg1 := prometheus.NewRegistry()
+	n1 := makeNetworkWithConfig(t, Config{Metrics: reg1})
+	defer n1.Close()
+	reg := prometheus.NewRegistry()
+	n2 := makeNetworkWithConfig(t, Config{Metrics: reg})
+	defer n2.Close()
+	n1.Host().Peerstore().AddAddrs(n2.Host().ID(), n2.Host().Addrs(), peerstore.PermanentAddrTTL)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	info := createThread(t, ctx, n1)
+	if _, err := n2.CreateThread(ctx, info.ID, core.WithThreadKey(info.Key)); err != nil {
+		t.Fatal(err)
+	}
+	tn1, tn2 := n1.(*net), n2.(*net)
+	lg1, err := tn1.getOwnLog(info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tn2.store.AddLog(info.ID, thread.LogInfo{ID: lg1.ID, PubKey: lg1.PubKey}); err != nil {
+		t.Fatal(err)
+	}
+	lg2, err := tn2.getOwnLog(info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr, err := ma.NewMultiaddr("/p2p/" + n2.Host().ID().String() + "/thread/" + info.ID.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tn1.store.AddLog(info.ID, thread.LogInfo{ID: lg2.ID, PubKey: lg2.PubKey, Addrs: []ma.Multiaddr{addr}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// valueIn returns the value of a metric with a label value, or -1
+	valueIn := func(reg *prometheus.Registry, name, label string) float64 {
+		mfs, err := reg.Gather()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, mf := range mfs {
+			if mf.GetName() != name {
+				continue
+			}
+			for _, m := range mf.GetMetric() {
+				match := label == ""
+				for _, l := range m.GetLabel() {
+					if l.GetValue() == label {
+						match = true
+					}
+				}
+				if !match {
+					continue
+				}
+				switch {
+				case m.GetCounter() != nil:
+					return m.GetCounter().GetValue()
+				case m.GetGauge() != nil:
+					return m.GetGauge().GetValue()
+				case m.GetHistogram() != nil:
+					return float64(m.GetHistogram().GetSampleCount())
+				}
+			}
+		}
+		return -1
+	}
+	value := func(name, label string) float64 {
+		return valueIn(reg, name, label)
+	}
+
+	sub, err := n2.Subscribe(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := value("threads_net_subscriptions", ""); v != 1 {
+		t.Fatalf("expected 1 subscription, got %v", v)
+	}
+	body, err := cbornode.WrapObject(map[string]interface{}{
+		"foo": "bar",
+	}, mh.SHA2_256, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var res core.PushResult
+	if _, err = n1.CreateRecord(ctx, info.ID, body, core.WithPushResult(&res)); err != nil {
+		t.Fatal(err)
+	}
+	if res.Succeeded != 1 {
+		t.Fatalf("expected the record to be pushed, got %v", res.Deliveries)
+	}
+	<-sub
+	if v := value("threads_net_rpc_calls_total", "PushRecord"); v != 1 {
+		t.Fatalf("expected 1 push call, got %v", v)
+	}
+	if v := value("threads_net_rpc_calls_total", "success"); v != 1 {
+		t.Fatalf("expected 1 successful call, got %v", v)
+	}
+	if v := value("threads_net_rpc_duration_seconds", "PushRecord"); v != 1 {
+		t.Fatalf("expected 1 push call duration, got %v", v)
+	}
+	if v := value("threads_net_pushed_record_bytes", ""); v != 1 {
+		t.Fatalf("expected 1 pushed record size, got %v", v)
+	}
+
+	// Calls to peers are measured by the caller
+	if v := valueIn(reg1, "threads_net_rpc_client_calls_total", "PushRecord"); v != 1 {
+		t.Fatalf("expected 1 push call to a peer, got %v", v)
+	}
+	if v := valueIn(reg1, "threads_net_rpc_client_duration_seconds", "PushRecord"); v != 1 {
+		t.Fatalf("expected 1 push call duration, got %v", v)
+	}
+	if v := valueIn(reg, "threads_net_rpc_client_calls_total", "PushRecord"); v != -1 {
+		t.Fatalf("expected no push calls from the receiver, got %v", v)
+	}
+	if err = n1.PullThread(ctx, info.ID); err != nil {
+		t.Fatal(err)
+	}
+	if v := valueIn(reg1, "threads_net_rpc_client_calls_total", "GetRecordsStream"); v < 1 {
+		t.Fatalf("expected records streams to be measured when they end, got %v", v)
+	}
+
+	cancel()
+	for deadline := time.Now().Add(time.Second * 5); value("threads_net_subscriptions", "") != 0; {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the subscription to end")
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+}
+
+func TestServer_Tracing(t *testing.T) {
+	t.Parallel()
+	sr := new(oteltest.StandardSpanRecorder)
+	tp := oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))
+	n1 := makeNetworkWithConfig(t, Config{TracerProvider: tp})
+	defer n1.Close()
+	n2 := makeNetworkWithConfig(t, Config{TracerProvider: tp})
+	defer n2.Close()
+	n1.Host().Peerstore().AddAddrs(n2.Host().ID(), n2.Host().Addrs(), peerstore.PermanentAddrTTL)
+
+	ctx := context.Background()
+	info := createThread(t, ctx, n1)
+	if _, err := n2.CreateThread(ctx, info.ID, core.WithThreadKey(info.Key)); err != nil {
+		t.Fatal(err)
+	}
+	tn1, tn2 := n1.(*net), n2.(*net)
+	lg1, err := tn1.getOwnLog(info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tn2.store.AddLog(info.ID, thread.LogInfo{ID: lg1.ID, PubKey: lg1.PubKey}); err != nil {
+		t.Fatal(err)
+	}
+	lg2, err := tn2.getOwnLog(info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr, err := ma.NewMultiaddr("/p2p/" + n2.Host().ID().String() + "/thread/" + info.ID.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tn1.store.AddLog(info.ID, thread.LogInfo{ID: lg2.ID, PubKey: lg2.PubKey, Addrs: []ma.Multiaddr{addr}}); err != nil {
+		t.Fatal(err)
+	}
+
+	body, err := cbornode.WrapObject(map[string]interface{}{
+		"foo": "bar",
+	}, mh.SHA2_256, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var res core.PushResult
+	r, err := n1.CreateRecord(ctx, info.ID, body, core.WithPushResult(&res))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Succeeded != 1 {
+		t.Fatalf("expected the record to be pushed, got %v", res.Deliveries)
+	}
+
+	var push *oteltest.Span
+	for _, s := range sr.Completed() {
+		if s.Name() == "pushRecord" {
+			push = s
+		}
+	}
+	if push == nil {
+		t.Fatal("expected a push span")
+	}
+	attrs := push.Attributes()
+	if attrs[label.Key("thread.id")].AsString() != info.ID.String() ||
+		attrs[label.Key("log.id")].AsString() != lg1.ID.String() ||
+		attrs[label.Key("record.cid")].AsString() != r.Value().Cid().String() {
+		t.Fatalf("expected the push span to describe the record, got %v", attrs)
+	}
+	var handled bool
+	for _, s := range sr.Completed() {
+		if s.Name() == "PushRecord" &&
+			s.SpanContext().TraceID == push.SpanContext().TraceID &&
+			s.ParentSpanID() == push.SpanContext().SpanID {
+			handled = true
+		}
+	}
+	if !handled {
+		t.Fatal("expected the handling of the push to continue the push span")
+	}
+}
+
+func TestServer_GetRecordsStream(t *testing.T) {
+	t.Parallel()
+	n1 := makeNetwork(t)
+	defer n1.Close()
+	n2 := makeNetwork(t)
+	defer n2.Close()
+	n2.Host().Peerstore().AddAddrs(n1.Host().ID(), n1.Host().Addrs(), peerstore.PermanentAddrTTL)
+
+	ctx := context.Background()
+	info := createThread(t, ctx, n1)
+	if _, err := n2.CreateThread(ctx, info.ID, core.WithThreadKey(info.Key)); err != nil {
+		t.Fatal(err)
+	}
+	tn1, tn2 := n1.(*net), n2.(*net)
+	lg, err := tn1.getOwnLog(info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tn2.store.AddLog(info.ID, thread.LogInfo{ID: lg.ID, PubKey: lg.PubKey}); err != nil {
+		t.Fatal(err)
+	}
+	var rids []cid.Cid
+	for i := 0; i < 5000; i++ {
+		body, err := cbornode.WrapObject(map[string]interface{}{
+			"foo": i,
+		}, mh.SHA2_256, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := n1.CreateRecord(ctx, info.ID, body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rids = append(rids, r.Value().Cid())
+	}
+
+	offsets := map[peer.ID]cid.Cid{lg.ID: cid.Undef}
+	req, err := tn2.server.newGetRecordsRequest(info.ID, offsets, nil, MaxPullLimit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr, err := ma.NewMultiaddr("/p2p/" + n1.Host().ID().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := make(chan logRecord)
+	recs := newRecordStream(ctx, out, false)
+	go func() {
+		tn2.server.fetchRecords(ctx, info.ID, []ma.Multiaddr{addr}, offsets, req, recs)
+		close(out)
+	}()
+	var i int
+	for r := range out {
+		if i >= len(rids) || !r.rec.Cid().Equals(rids[i]) {
+			t.Fatalf("expected record %d to be streamed in order", i)
+		}
+		i++
+	}
+	if i != len(rids) {
+		t.Fatalf("expected %d streamed records, got %d", len(rids), i)
+	}
+	// Records are not held once they are streamed
+	if l := recs.List()[lg.ID]; len(l) != 1 {
+		t.Fatalf("expected only the last record to be kept, got %d", len(l))
+	}
+}
+
+func TestServer_GetRecordsStreamTimeout(t *testing.T) {
+	t.Parallel()
+	// Each record is sent after a delay, and the stream stalls after stallAt
+	// records if it's positive
+	delay := time.Millisecond * 50
+	var stallAt int32
+	n1 := makeNetworkWithConfig(t, Config{},
+		grpc.StreamInterceptor(func(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, h grpc.StreamHandler) error {
+			return h(srv, &delayedStream{ServerStream: ss, delay: delay, stallAt: atomic.LoadInt32(&stallAt)})
+		}))
+	defer n1.Close()
+	timeout := delay * 4
+	n2 := makeNetworkWithConfig(t, Config{RequestTimeout: timeout})
+	defer n2.Close()
+	n2.Host().Peerstore().AddAddrs(n1.Host().ID(), n1.Host().Addrs(), peerstore.PermanentAddrTTL)
+
+	ctx := context.Background()
+	info := createThread(t, ctx, n1)
+	if _, err := n2.CreateThread(ctx, info.ID, core.WithThreadKey(info.Key)); err != nil {
+		t.Fatal(err)
+	}
+	tn1, tn2 := n1.(*net), n2.(*net)
+	lg, err := tn1.getOwnLog(info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tn2.store.AddLog(info.ID, thread.LogInfo{ID: lg.ID, PubKey: lg.PubKey}); err != nil {
+		t.Fatal(err)
+	}
+	total := 12
+	for i := 0; i < total; i++ {
+		body, err := cbornode.WrapObject(map[string]interface{}{"foo": i}, mh.SHA2_256, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = n1.CreateRecord(ctx, info.ID, body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	addr, err := ma.NewMultiaddr("/p2p/" + n1.Host().ID().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream := func() (int, time.Duration) {
+		offsets := map[peer.ID]cid.Cid{lg.ID: cid.Undef}
+		req, err := tn2.server.newGetRecordsRequest(info.ID, offsets, nil, MaxPullLimit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out := make(chan logRecord)
+		start := time.Now()
+		go func() {
+			tn2.server.fetchRecords(ctx, info.ID, []ma.Multiaddr{addr}, offsets, req, newRecordStream(ctx, out, false))
+			close(out)
+		}()
+		var got int
+		for r := range out {
+			if r.rec != nil {
+				got++
+			}
+		}
+		return got, time.Since(start)
+	}
+
+	// A stream that keeps sending outlives the request timeout
+	got, took := stream()
+	if got != total {
+		t.Fatalf("expected %d streamed records, got %d", total, got)
+	}
+	if took < timeout {
+		t.Fatalf("expected the stream to take longer than the request timeout, took %s", took)
+	}
+
+	// A stalled stream is abandoned
+	atomic.StoreInt32(&stallAt, 3)
+	if got, _ = stream(); got != 3 {
+		t.Fatalf("expected the stream to be abandoned after 3 records, got %d", got)
+	}
+}
+
+// delayedStream delays each record sent on a get records stream. If stallAt
+// is positive, the stream stalls until it's canceled once stallAt records
+// were sent on it.
+type delayedStream struct {
+	grpc.ServerStream
+	delay   time.Duration
+	stallAt int32
+	sent    int32
+}
+
+func (s *delayedStream) SendMsg(m interface{}) error {
+	if reply, ok := m.(*pb.GetRecordsStreamReply); ok && reply.Record != nil {
+		if s.stallAt > 0 && s.sent >= s.stallAt {
+			<-s.Context().Done()
+			return s.Context().Err()
+		}
+		time.Sleep(s.delay)
+		s.sent++
+	}
+	return s.ServerStream.SendMsg(m)
+}
+
+func TestRecords_CausalOrder(t *testing.T) {
+	t.Parallel()
+	n := makeNetworkWithConfig(t, Config{LocalOnly: true})
+	defer n.Close()
+	tn := n.(*net)
+
+	// A log with two branches: r0 <- r1 <- r2 <- r3, and r1 <- f2 <- f3
+	ctx := context.Background()
+	info := createThread(t, ctx, n)
+	newBody := func(i int) format.Node {
+		body, err := cbornode.WrapObject(map[string]interface{}{"foo": i}, mh.SHA2_256, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return body
+	}
+	var all []core.Record
+	for i := 0; i < 4; i++ {
+		r, err := n.CreateRecord(ctx, info.ID, newBody(i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		all = append(all, r.Value())
+	}
+	lg, err := tn.getOwnLog(info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lg.Head = all[1].Cid()
+	for i := 2; i < 4; i++ {
+		r, err := tn.newRecord(ctx, info.ID, lg, newBody(i+10), nil, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		all = append(all, r)
+		lg.Head = r.Cid()
+	}
+
+	orders := [][]int{
+		{0, 1, 2, 3, 4, 5},
+		{5, 4, 3, 2, 1, 0},
+		{3, 5, 1, 4, 0, 2},
+		{2, 0, 5, 3, 1, 4},
+	}
+	var first []core.Record
+	for _, order := range orders {
+		recs := newRecords()
+		for _, i := range order {
+			recs.Store(lg.ID, all[i].Cid(), all[i])
+		}
+		list := recs.List()[lg.ID]
+		if len(list) != len(all) {
+			t.Fatalf("expected %d records, got %d", len(all), len(list))
+		}
+		pos := make(map[cid.Cid]int)
+		for i, r := range list {
+			pos[r.Cid()] = i
+		}
+		for _, r := range list {
+			if p, ok := pos[r.PrevID()]; ok && p > pos[r.Cid()] {
+				t.Fatalf("order %v: expected %s before %s", order, r.PrevID(), r.Cid())
+			}
+		}
+		if first == nil {
+			first = list
+			continue
+		}
+		for i := range list {
+			if !list[i].Cid().Equals(first[i].Cid()) {
+				t.Fatalf("order %v: expected the same order regardless of arrival", order)
+			}
+		}
+	}
+}
+
+func TestNet_GetLocalRecordRange(t *testing.T) {
+	t.Parallel()
+	n := makeNetwork(t)
+	defer n.Close()
+
+	ctx := context.Background()
+	info := createThread(t, ctx, n)
+	tn := n.(*net)
+	lg, err := tn.getOwnLog(info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rids []cid.Cid
+	for i := 0; i < 5; i++ {
+		body, err := cbornode.WrapObject(map[string]interface{}{
+			"foo": i,
+		}, mh.SHA2_256, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := n.CreateRecord(ctx, info.ID, body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rids = append(rids, r.Value().Cid())
+	}
+
+	tests := []struct {
+		name     string
+		from, to cid.Cid
+		want     []cid.Cid
+	}{
+		{"open-ended", rids[1], cid.Undef, rids[2:]},
+		{"bounded", rids[0], rids[3], rids[1:4]},
+		{"empty", rids[2], rids[2], nil},
+	}
+	for _, tt := range tests {
+		recs, _, err := tn.getLocalRecordRange(ctx, info.ID, lg.ID, tt.from, tt.to, MaxPullLimit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(recs) != len(tt.want) {
+			t.Fatalf("%s: expected %d records, got %d", tt.name, len(tt.want), len(recs))
+		}
+		for i, r := range recs {
+			if !r.Cid().Equals(tt.want[i]) {
+				t.Fatalf("%s: unexpected record at %d", tt.name, i)
+			}
+		}
+	}
+
+	// Bounded ranges are honored by peers
+	n2 := makeNetwork(t)
+	defer n2.Close()
+	n2.Host().Peerstore().AddAddrs(n.Host().ID(), n.Host().Addrs(), peerstore.PermanentAddrTTL)
+	if _, err = n2.CreateThread(ctx, info.ID, core.WithThreadKey(info.Key)); err != nil {
+		t.Fatal(err)
+	}
+	tn2 := n2.(*net)
+	if err = tn2.store.AddLog(info.ID, thread.LogInfo{ID: lg.ID, PubKey: lg.PubKey}); err != nil {
+		t.Fatal(err)
+	}
+	addr, err := ma.NewMultiaddr("/p2p/" + n.Host().ID().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	recs, err := tn2.server.getRecordRange(ctx, info.ID, lg.ID, rids[0], rids[3], []ma.Multiaddr{addr})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 3 || !recs[0].Cid().Equals(rids[1]) || !recs[2].Cid().Equals(rids[3]) {
+		t.Fatalf("expected records 1 to 3 from peer, got %d records", len(recs))
+	}
+
+	// Only the records in a truncated range are read
+	if err = tn.threadDAG(info.ID).Remove(ctx, rids[4]); err != nil {
+		t.Fatal(err)
+	}
+	recs, truncated, err := tn.getLocalRecordRange(ctx, info.ID, lg.ID, rids[0], cid.Undef, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !truncated || len(recs) != 2 || !recs[0].Cid().Equals(rids[1]) || !recs[1].Cid().Equals(rids[2]) {
+		t.Fatalf("expected records 1 and 2 to be returned truncated, got %d records", len(recs))
+	}
+}
+
+func TestServer_MaxPullLimit(t *testing.T) {
+	t.Parallel()
+	n1 := makeNetworkWithConfig(t, Config{MaxPullLimit: 2})
+	defer n1.Close()
+
+	ctx := context.Background()
+	info := createThread(t, ctx, n1)
+	tn1 := n1.(*net)
+	lg, err := tn1.getOwnLog(info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rids []cid.Cid
+	for i := 0; i < 5; i++ {
+		body, err := cbornode.WrapObject(map[string]interface{}{
+			"foo": i,
+		}, mh.SHA2_256, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := n1.CreateRecord(ctx, info.ID, body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rids = append(rids, r.Value().Cid())
+	}
+
+	// Requests for more than the limit are capped, oldest records first
+	tests := []struct {
+		name      string
+		offset    cid.Cid
+		want      []cid.Cid
+		truncated bool
+	}{
+		{"capped", cid.Undef, rids[:2], true},
+		{"continued", rids[1], rids[2:4], true},
+		{"last", rids[3], rids[4:], false},
+	}
+	for _, tt := range tests {
+		req, err := tn1.server.newGetRecordsRequest(info.ID, map[peer.ID]cid.Cid{lg.ID: tt.offset}, nil, MaxPullLimit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reply, err := tn1.server.GetRecords(ctx, req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(reply.Logs) != 1 {
+			t.Fatalf("%s: expected 1 log, got %d", tt.name, len(reply.Logs))
+		}
+		entry := reply.Logs[0]
+		if entry.Truncated != tt.truncated {
+			t.Fatalf("%s: expected truncated to be %t", tt.name, tt.truncated)
+		}
+		if len(entry.Records) != len(tt.want) {
+			t.Fatalf("%s: expected %d records, got %d", tt.name, len(tt.want), len(entry.Records))
+		}
+		for i, r := range entry.Records {
+			rec, err := cbor.RecordFromProto(r, info.Key.Service())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !rec.Cid().Equals(tt.want[i]) {
+				t.Fatalf("%s: unexpected record at %d", tt.name, i)
+			}
+		}
+	}
+
+	// Peers pull truncated logs page by page
+	addr, err := ma.NewMultiaddr("/p2p/" + n1.Host().ID().String() + "/thread/" + info.ID.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, stream := range []bool{true, false} {
+		n2 := makeNetwork(t)
+		defer n2.Close()
+		n2.Host().Peerstore().AddAddrs(n1.Host().ID(), n1.Host().Addrs(), peerstore.PermanentAddrTTL)
+		if _, err = n2.CreateThread(ctx, info.ID, core.WithThreadKey(info.Key)); err != nil {
+			t.Fatal(err)
+		}
+		tn2 := n2.(*net)
+		if err = tn2.store.AddLog(info.ID, thread.LogInfo{ID: lg.ID, PubKey: lg.PubKey, Addrs: []ma.Multiaddr{addr}}); err != nil {
+			t.Fatal(err)
+		}
+		if stream {
+			err = n2.PullThread(ctx, info.ID)
+		} else {
+			tn2.updateRecordsFromLog(ctx, info.ID, lg.ID)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		heads, err := tn2.store.Heads(info.ID, lg.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(heads) != 1 || !heads[0].Equals(rids[4]) {
+			t.Fatalf("expected head %s after pull (stream: %t), got %v", rids[4], stream, heads)
+		}
+	}
+}
+
+func TestNet_OnThreadCreated(t *testing.T) {
+	t.Parallel()
+	body, err := cbornode.WrapObject(map[string]interface{}{
+		"admins": []string{"foo"},
+	}, mh.SHA2_256, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := makeNetworkWithConfig(t, Config{
+		OnThreadCreated: func(ctx context.Context, n core.Net, info thread.Info) error {
+			_, err := n.CreateRecord(ctx, info.ID, body)
+			return err
+		},
+	})
+	defer n.Close()
+
+	ctx := context.Background()
+	info := createThread(t, ctx, n)
+	info, err = n.GetThread(ctx, info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(info.Logs) != 1 || !info.Logs[0].Head.Defined() {
+		t.Fatal("expected hook to create an initial record")
+	}
+
+	n2 := makeNetworkWithConfig(t, Config{
+		OnThreadCreated: func(context.Context, core.Net, thread.Info) error {
+			return errors.New("denied")
+		},
+	})
+	defer n2.Close()
+	id := thread.NewIDV1(thread.Raw, 32)
+	if _, err = n2.CreateThread(ctx, id); err == nil {
+		t.Fatal("expected hook error to fail thread creation")
+	}
+	if _, err = n2.GetThread(ctx, id); !errors.Is(err, logstore.ErrThreadNotFound) {
+		t.Fatalf("expected thread to be rolled back, got %v", err)
+	}
+
+	// Adding a thread from a peer runs the hook too
+	n2.Host().Peerstore().AddAddrs(n.Host().ID(), n.Host().Addrs(), peerstore.PermanentAddrTTL)
+	addr, err := ma.NewMultiaddr("/p2p/" + n.Host().ID().String() + "/thread/" + info.ID.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = n2.AddThread(ctx, addr, core.WithThreadKey(info.Key)); err == nil {
+		t.Fatal("expected hook error to fail adding the thread")
+	}
+	if _, err = n2.GetThread(ctx, info.ID); !errors.Is(err, logstore.ErrThreadNotFound) {
+		t.Fatalf("expected added thread to be rolled back, got %v", err)
+	}
+}
+
+func TestNet_ThreadGoroutines(t *testing.T) {
+	t.Parallel()
+	n := makeNetworkWithConfig(t, Config{
+		LocalOnly:           true,
+		MaxThreadGoroutines: 1,
+	})
+	defer n.Close()
+
+	ctx := context.Background()
+	info := createThread(t, ctx, n)
+
+	tn := n.(*net)
+	release := make(chan struct{})
+	done := make(chan struct{})
+	if !tn.spawn(info.ID, func() {
+		<-release
+		close(done)
+	}) {
+		t.Fatal("expected goroutine to be spawned")
+	}
+	if tn.spawn(info.ID, func() {}) {
+		t.Fatal("expected goroutine cap to be enforced")
+	}
+	if c := n.ThreadGoroutines()[info.ID]; c != 1 {
+		t.Fatalf("expected 1 goroutine, got %d", c)
+	}
+
+	close(release)
+	<-done
+	for i := 0; i < 100; i++ {
+		if _, ok := n.ThreadGoroutines()[info.ID]; !ok {
+			return
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+	t.Fatal("expected goroutine count to be released")
+}
+
+func TestNet_AddLogMerge(t *testing.T) {
+	t.Parallel()
+	n := makeNetworkWithConfig(t, Config{
+		LocalOnly: true,
+	})
+	defer n.Close()
+
+	ctx := context.Background()
+	info := createThread(t, ctx, n)
+	body, err := cbornode.WrapObject(map[string]interface{}{
+		"foo": "bar",
+	}, mh.SHA2_256, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = n.CreateRecord(ctx, info.ID, body); err != nil {
+		t.Fatal(err)
+	}
+	tn := n.(*net)
+	cur, err := tn.getOwnLog(info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newPeer := func() peer.ID {
+		_, pk, err := crypto.GenerateEd25519Key(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pid, err := peer.IDFromPublicKey(pk)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return pid
+	}
+	owner, other := newPeer(), newPeer()
+
+	// A peer can't update the host's own log
+	addr, err := ma.NewMultiaddr("/ip4/1.2.3.4/tcp/1234/p2p/" + other.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale := thread.LogInfo{
+		ID:     cur.ID,
+		PubKey: cur.PubKey,
+		Addrs:  []ma.Multiaddr{addr},
+		Head:   body.Cid(),
+	}
+	if err = tn.addExternalLog(info.ID, stale, AddrSourcePushed, other); err != nil {
+		t.Fatal(err)
+	}
+	lg, err := tn.store.GetLog(info.ID, cur.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !lg.Head.Equals(cur.Head) {
+		t.Fatalf("expected head %s to be kept, got %s", cur.Head, lg.Head)
+	}
+	if lg.PrivKey == nil {
+		t.Fatal("expected private key to be kept")
+	}
+	if len(lg.Addrs) != len(cur.Addrs) {
+		t.Fatalf("expected %d addresses, got %d", len(cur.Addrs), len(lg.Addrs))
+	}
+	if addrs := n.Host().Peerstore().Addrs(other); len(addrs) != 0 {
+		t.Fatalf("expected no addresses of the sender in the peerstore, got %v", addrs)
+	}
+
+	// A log's key must match its ID
+	ext, err := createLog(owner, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ext.PrivKey = nil
+	mismatched := ext
+	mismatched.ID = other
+	if err = tn.addExternalLog(info.ID, mismatched, AddrSourcePushed, owner); err == nil {
+		t.Fatal("expected a log with a mismatched key to be refused")
+	}
+
+	// Only the owner of a log updates its addresses, and only the sender's
+	// transport addresses are learned
+	ownerAddr, err := ma.NewMultiaddr("/ip4/1.2.3.4/tcp/1234/p2p/" + owner.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ext.Addrs = []ma.Multiaddr{ownerAddr, addr}
+	if err = tn.addExternalLog(info.ID, ext, AddrSourcePushed, owner); err != nil {
+		t.Fatal(err)
+	}
+	if addrs := n.Host().Peerstore().Addrs(owner); len(addrs) != 1 || !addrs[0].Equal(util.MustParseAddr("/ip4/1.2.3.4/tcp/1234")) {
+		t.Fatalf("expected the transport address of the sender in the peerstore, got %v", addrs)
+	}
+	if addrs := n.Host().Peerstore().Addrs(other); len(addrs) != 0 {
+		t.Fatalf("expected no addresses of another peer in the peerstore, got %v", addrs)
+	}
+	third := newPeer()
+	thirdAddr, err := ma.NewMultiaddr("/p2p/" + third.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ext.Addrs = []ma.Multiaddr{thirdAddr}
+	if err = tn.addExternalLog(info.ID, ext, AddrSourcePushed, third); err != nil {
+		t.Fatal(err)
+	}
+	if lg, err = tn.store.GetLog(info.ID, ext.ID); err != nil {
+		t.Fatal(err)
+	}
+	if len(lg.Addrs) != 2 {
+		t.Fatalf("expected the addresses from the owner only, got %v", lg.Addrs)
+	}
+	if err = tn.addExternalLog(info.ID, ext, AddrSourcePushed, other); err != nil {
+		t.Fatal(err)
+	}
+	if lg, err = tn.store.GetLog(info.ID, ext.ID); err != nil {
+		t.Fatal(err)
+	}
+	if len(lg.Addrs) != 3 {
+		t.Fatalf("expected an address added by a peer among the log's addresses, got %v", lg.Addrs)
+	}
+}
+
+func TestNet_AddrTTLPolicy(t *testing.T) {
+	t.Parallel()
+	pushedTTL := time.Millisecond * 300
+	n := makeNetworkWithConfig(t, Config{
+		AddrTTLPolicy: func(src AddrSource) time.Duration {
+			if src == AddrSourcePushed {
+				return pushedTTL
+			}
+			return peerstore.PermanentAddrTTL
+		},
+	})
+	defer n.Close()
+	tn := n.(*net)
+
+	ctx := context.Background()
+	info := createThread(t, ctx, n)
+	sk, _, err := crypto.GenerateKeyPair(crypto.Ed25519, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pid, err := peer.IDFromPrivateKey(sk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr, err := ma.NewMultiaddr("/ip4/1.2.3.4/tcp/1234/p2p/" + pid.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	transport, _ := splitPeerAddr(addr)
+	newLog := func() thread.LogInfo {
+		lg, err := createLog(pid, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lg.PrivKey = nil
+		lg.Addrs = []ma.Multiaddr{addr}
+		return lg
+	}
+	hasAddrs := func(lid peer.ID) (inLog, inPeerstore bool) {
+		addrs, err := tn.store.Addrs(info.ID, lid)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, a := range tn.host.Peerstore().Addrs(pid) {
+			if a.Equal(transport) {
+				inPeerstore = true
+			}
+		}
+		return len(addrs) > 0, inPeerstore
+	}
+
+	// Addresses of a pushed log get the pushed TTL
+	pushed := newLog()
+	body := &pb.PushLogRequest_Body{
+		ThreadID:   &pb.ProtoThreadID{ID: info.ID},
+		ServiceKey: &pb.ProtoKey{Key: info.Key.Service()},
+		Log:        logToProto(pushed),
+	}
+	header, err := SignRequest(sk, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = tn.server.PushLog(ctx, &pb.PushLogRequest{Header: header, Body: body}); err != nil {
+		t.Fatal(err)
+	}
+	if inLog, inPeerstore := hasAddrs(pushed.ID); !inLog || !inPeerstore {
+		t.Fatalf("expected pushed addresses to be added, got log %v, peerstore %v", inLog, inPeerstore)
+	}
+	time.Sleep(pushedTTL * 2)
+	if inLog, inPeerstore := hasAddrs(pushed.ID); inLog || inPeerstore {
+		t.Fatalf("expected pushed addresses to expire, got log %v, peerstore %v", inLog, inPeerstore)
+	}
+
+	// Addresses of a pulled log get the pulled TTL
+	pulled := newLog()
+	if err = tn.addExternalLog(info.ID, pulled, AddrSourcePulled, pid); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(pushedTTL * 2)
+	if inLog, inPeerstore := hasAddrs(pulled.ID); !inLog || !inPeerstore {
+		t.Fatalf("expected pulled addresses to be kept, got log %v, peerstore %v", inLog, inPeerstore)
+	}
+}
+
+func TestPeerIDFromAddr(t *testing.T) {
+	t.Parallel()
+	ids := make([]peer.ID, 2)
+	for i := range ids {
+		_, pk, err := crypto.GenerateEd25519Key(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ids[i], err = peer.IDFromPublicKey(pk); err != nil {
+			t.Fatal(err)
+		}
+	}
+	relay, target := ids[0], ids[1]
+
+	direct := util.MustParseAddr("/ip4/127.0.0.1/tcp/4006/p2p/" + target.String())
+	relayed := util.MustParseAddr("/ip4/127.0.0.1/tcp/4006/p2p/" + relay.String() + "/p2p-circuit/p2p/" + target.String())
+	for _, addr := range []ma.Multiaddr{direct, relayed} {
+		pid, err := peerIDFromAddr(addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pid != target {
+			t.Fatalf("expected %s from %s, got %s", target, addr, pid)
+		}
+	}
+	if _, err := peerIDFromAddr(util.MustParseAddr("/ip4/127.0.0.1/tcp/4006")); err == nil {
+		t.Fatal("expected an error for an address without a peer ID")
+	}
+}
+
+func TestNet_HostAddrs(t *testing.T) {
+	t.Parallel()
+	_, pk, err := crypto.GenerateEd25519Key(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	relay, err := peer.IDFromPublicKey(pk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	public := util.MustParseAddr("/ip4/1.2.3.4/tcp/4006")
+	public6 := util.MustParseAddr("/ip6/2001:db8::1/tcp/4006")
+	dns := util.MustParseAddr("/dns4/threads.example.com/tcp/4006")
+	relayed := util.MustParseAddr("/ip4/1.2.3.4/tcp/4006/p2p/" + relay.String() + "/p2p-circuit")
+	loopback := util.MustParseAddr("/ip4/127.0.0.1/tcp/4006")
+	private := util.MustParseAddr("/ip4/192.168.1.2/tcp/4006")
+	linkLocal := util.MustParseAddr("/ip6/fe80::1/tcp/4006")
+	unspecified := util.MustParseAddr("/ip4/0.0.0.0/tcp/4006")
+
+	tests := []struct {
+		name     string
+		announce []ma.Multiaddr
+		private  bool
+		host     []ma.Multiaddr
+		dialable []ma.Multiaddr
+	}{
+		{
+			name:     "public",
+			announce: []ma.Multiaddr{public, public6, dns},
+			host:     []ma.Multiaddr{public, public6, dns},
+			dialable: []ma.Multiaddr{public, public6, dns},
+		},
+		{
+			name:     "relay",
+			announce: []ma.Multiaddr{relayed},
+			host:     []ma.Multiaddr{relayed},
+			dialable: []ma.Multiaddr{relayed},
+		},
+		{
+			name:     "nil",
+			announce: []ma.Multiaddr{nil, public, nil},
+			host:     []ma.Multiaddr{public},
+			dialable: []ma.Multiaddr{public},
+		},
+		{
+			name:     "private filtered",
+			announce: []ma.Multiaddr{loopback, private, linkLocal, public},
+			host:     []ma.Multiaddr{loopback, private, linkLocal, public},
+			dialable: []ma.Multiaddr{public},
+		},
+		{
+			name:     "private allowed",
+			announce: []ma.Multiaddr{loopback, private, linkLocal, public},
+			private:  true,
+			host:     []ma.Multiaddr{loopback, private, linkLocal, public},
+			dialable: []ma.Multiaddr{loopback, private, linkLocal, public},
+		},
+		{
+			name:     "unspecified",
+			announce: []ma.Multiaddr{unspecified, public},
+			private:  true,
+			host:     []ma.Multiaddr{unspecified, public},
+			dialable: []ma.Multiaddr{public},
+		},
+		{
+			name:     "nothing announced",
+			announce: []ma.Multiaddr{},
+			host:     []ma.Multiaddr{},
+			dialable: []ma.Multiaddr{},
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			// The announced addresses replace the host's loopback listen address
+			h, err := libp2p.New(
+				context.Background(),
+				libp2p.ListenAddrs(util.MustParseAddr("/ip4/127.0.0.1/tcp/0")),
+				libp2p.AddrsFactory(func([]ma.Multiaddr) []ma.Multiaddr {
+					return tc.announce
+				}),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer h.Close()
+			tn := &net{host: h, conf: Config{AllowPrivateAddrs: tc.private}}
+
+			checkAddrs(t, "host", tn.hostAddrs(), tc.host)
+			checkAddrs(t, "dialable", tn.dialableAddrs(), tc.dialable)
+			// Filtering a snapshot doesn't change the next one
+			checkAddrs(t, "host", tn.hostAddrs(), tc.host)
+		})
+	}
+}
+
+func checkAddrs(t *testing.T, kind string, got, want []ma.Multiaddr) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("expected %s addresses %v, got %v", kind, want, got)
+	}
+	for i := range want {
+		if !got[i].Equal(want[i]) {
+			t.Fatalf("expected %s addresses %v, got %v", kind, want, got)
+		}
+	}
+}
+
+func TestNet_AdvertisedAddrs(t *testing.T) {
+	t.Parallel()
+	n1 := makeNetworkWithConfig(t, Config{AllowPrivateAddrs: true})
+	defer n1.Close()
+	n2 := makeNetwork(t)
+	defer n2.Close()
+	tn1, tn2 := n1.(*net), n2.(*net)
+
+	// Test hosts only listen on loopback, which isn't advertised by default
+	bare := util.MustParseAddr("/p2p/" + n2.Host().ID().String())
+	adv := tn2.advertisedLog(thread.LogInfo{Addrs: []ma.Multiaddr{bare}})
+	if len(adv.Addrs) != 1 || !adv.Addrs[0].Equal(bare) {
+		t.Fatalf("expected only the peer ID to be advertised, got %v", adv.Addrs)
+	}
+
+	ctx := context.Background()
+	info := createThread(t, ctx, n1)
+	body, err := cbornode.WrapObject(map[string]interface{}{"foo": "bar"}, mh.SHA2_256, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec, err := n1.CreateRecord(ctx, info.ID, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lg, err := tn1.getOwnLog(info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	adv = tn1.advertisedLog(lg)
+	if len(adv.Addrs) != len(n1.Host().Addrs()) {
+		t.Fatalf("expected %d advertised addresses, got %v", len(n1.Host().Addrs()), adv.Addrs)
+	}
+	for _, a := range adv.Addrs {
+		transport, pid := splitPeerAddr(a)
+		if transport == nil || pid != n1.Host().ID() {
+			t.Fatalf("expected a dialable address of %s, got %s", n1.Host().ID(), a)
+		}
+	}
+
+	// n2 has never seen n1, and reaches it through the advertised log alone
+	if len(n2.Host().Peerstore().Addrs(n1.Host().ID())) != 0 {
+		t.Fatal("expected n2 to have no addresses for n1")
+	}
+	if _, err = n2.CreateThread(ctx, info.ID, core.WithThreadKey(info.Key)); err != nil {
+		t.Fatal(err)
+	}
+	if err = tn2.addExternalLog(info.ID, logFromProto(logToProto(adv)), AddrSourcePushed, n1.Host().ID()); err != nil {
+		t.Fatal(err)
+	}
+	stored, err := tn2.store.GetLog(info.ID, lg.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored.Addrs) != 1 || !stored.Addrs[0].Equal(util.MustParseAddr("/p2p/"+n1.Host().ID().String())) {
+		t.Fatalf("expected the log to be addressed by peer ID, got %v", stored.Addrs)
+	}
+	if err = n2.PullThread(ctx, info.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = n2.GetRecord(ctx, info.ID, rec.Value().Cid()); err != nil {
+		t.Fatalf("expected the record to be pulled from the advertised address: %v", err)
+	}
+}
+
+type recordingLogger struct {
+	Logger
+	sync.Mutex
+	lines []string
+}
+
+func (l *recordingLogger) Debugf(format string, args ...interface{}) {
+	l.Lock()
+	defer l.Unlock()
+	l.lines = append(l.lines, fmt.Sprintf(format, args...))
+}
+
+func TestPubSub_MaxTopics(t *testing.T) {
+	t.Parallel()
+	n := makeNetworkWithConfig(t, Config{
+		MaxSubscriptions: 2,
+	})
+	defer n.Close()
+
+	ctx := context.Background()
+	ids := make([]thread.ID, 3)
+	for i := range ids {
+		ids[i] = createThread(t, ctx, n).ID
+	}
+	ps := n.(*net).server.ps
+	subscribed := func(id thread.ID) bool {
+		ps.RLock()
+		defer ps.RUnlock()
+		_, ok := ps.m[id]
+		return ok
+	}
+	if subscribed(ids[0]) || !subscribed(ids[1]) || !subscribed(ids[2]) {
+		t.Fatal("expected the least recently active topic to be evicted")
+	}
+	if peers, err := n.TopicPeers(ids[0]); err != nil || len(peers) != 0 {
+		t.Fatalf("expected no peers for an evicted topic, got %v, %v", peers, err)
+	}
+
+	body, err := cbornode.WrapObject(map[string]interface{}{
+		"foo": "bar",
+	}, mh.SHA2_256, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = n.CreateRecord(ctx, ids[0], body); err != nil {
+		t.Fatal(err)
+	}
+	if !subscribed(ids[0]) || subscribed(ids[1]) || !subscribed(ids[2]) {
+		t.Fatal("expected publishing to rejoin the evicted topic")
+	}
+}
+
+func TestPubSub_RetryJoin(t *testing.T) {
+	t.Parallel()
+	n := makeNetwork(t)
+	defer n.Close()
+
+	ps := n.(*net).server.ps
+	id := thread.NewIDV1(thread.Raw, 32)
+	// A conflicting validator makes joining the topic fail
+	validator := func(context.Context, peer.ID, *pubsub.Message) bool { return true }
+	if err := ps.ps.RegisterTopicValidator(id.String(), validator); err != nil {
+		t.Fatal(err)
+	}
+	if err := ps.Add(id); err == nil {
+		t.Fatal("expected joining the topic to fail")
+	}
+	ps.Lock()
+	r, ok := ps.failed[id]
+	if !ok || r.attempts != 1 || !r.next.After(time.Now()) {
+		ps.Unlock()
+		t.Fatal("expected the failed topic to be scheduled for a retry")
+	}
+	r.next = time.Now()
+	ps.Unlock()
+
+	if err := ps.ps.UnregisterTopicValidator(id.String()); err != nil {
+		t.Fatal(err)
+	}
+	ps.retryJoins()
+	ps.RLock()
+	defer ps.RUnlock()
+	if _, ok := ps.m[id]; !ok {
+		t.Fatal("expected the retry to join the topic")
+	}
+	if _, ok := ps.failed[id]; ok {
+		t.Fatal("expected the retry to be cleared")
+	}
+}
+
+func TestNet_Logger(t *testing.T) {
+	t.Parallel()
+	l := &recordingLogger{Logger: defaultLogger()}
+	n := makeNetworkWithConfig(t, Config{Logger: l})
+	defer n.Close()
+
+	ctx := context.Background()
+	info := createThread(t, ctx, n)
+	if err := n.DeleteThread(ctx, info.ID); err != nil {
+		t.Fatal(err)
+	}
+	want := fmt.Sprintf("deleting thread %s...", info.ID)
+	l.Lock()
+	defer l.Unlock()
+	for _, line := range l.lines {
+		if line == want {
+			return
+		}
+	}
+	t.Fatalf("expected log to be routed to the configured logger, got %v", l.lines)
+}
+
+func TestPubSub_WorkerIndex(t *testing.T) {
+	t.Parallel()
+	_, pk, err := crypto.GenerateEd25519Key(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lid, err := peer.IDFromPublicKey(pk)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := &pb.PushRecordRequest{Body: &pb.PushRecordRequest_Body{
+		LogID: &pb.ProtoPeerID{ID: lid},
+	}}
+	i := workerIndex(req, 8)
+	if i < 0 || i >= 8 {
+		t.Fatalf("worker index %d out of range", i)
+	}
+	for j := 0; j < 10; j++ {
+		if workerIndex(req, 8) != i {
+			t.Fatal("expected records in the same log to use the same worker")
+		}
+	}
+	if workerIndex(&pb.PushRecordRequest{}, 8) != 0 {
+		t.Fatal("expected requests without a log to use the first worker")
+	}
+}
+
+// mockSubscription returns queued results from Next.
+type mockSubscription struct {
+	results   chan mockResult
+	cancelled int32
+}
+
+type mockResult struct {
+	msg *pubsub.Message
+	err error
+}
+
+func (m *mockSubscription) Next(ctx context.Context) (*pubsub.Message, error) {
+	select {
+	case r := <-m.results:
+		return r.msg, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (m *mockSubscription) Cancel() {
+	atomic.AddInt32(&m.cancelled, 1)
+}
+
+func TestPubSub_SubscribeTransientErrors(t *testing.T) {
+	t.Parallel()
+	sk, pk, err := crypto.GenerateEd25519Key(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	from, err := peer.IDFromPrivateKey(sk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := (&pb.PushRecordRequest{
+		Header: &pb.Header{PubKey: &pb.ProtoPubKey{PubKey: pk}},
+	}).Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := &pubsub.Message{Message: &pspb.Message{From: []byte(from), Data: data}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	received := make(chan struct{}, 2)
+	ps := NewPubSub(ctx, peer.ID("host"), nil, func(context.Context, *pb.PushRecordRequest) {
+		received <- struct{}{}
+	}, Config{})
+	sub := &mockSubscription{results: make(chan mockResult, 3)}
+	sub.results <- mockResult{err: fmt.Errorf("transient")}
+	sub.results <- mockResult{msg: msg}
+	sub.results <- mockResult{msg: msg}
+
+	done := make(chan struct{})
+	go func() {
+		ps.subscribe(ctx, thread.NewIDV1(thread.Raw, 32), &topic{s: sub})
+		close(done)
+	}()
+	for i := 0; i < 2; i++ {
+		select {
+		case <-received:
+		case <-time.After(time.Second * 5):
+			t.Fatal("expected records after a transient error to be handled")
+		}
+	}
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second * 5):
+		t.Fatal("expected the subscribe loop to exit when its context is done")
+	}
+}
+
+func TestPubSub_ResubscribeCancels(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ps := NewPubSub(ctx, peer.ID("host"), nil, func(context.Context, *pb.PushRecordRequest) {}, Config{
+		SubscribeErrorLimit: 1,
+		ResubscribeInterval: time.Hour,
+	})
+	sub := &mockSubscription{results: make(chan mockResult, 1)}
+	sub.results <- mockResult{err: fmt.Errorf("closed")}
+
+	done := make(chan struct{})
+	tp := &topic{s: sub}
+	go func() {
+		ps.subscribe(ctx, thread.NewIDV1(thread.Raw, 32), tp)
+		close(done)
+	}()
+	for deadline := time.Now().Add(time.Second * 5); atomic.LoadInt32(&sub.cancelled) == 0; {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the failed subscription to be cancelled")
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second * 5):
+		t.Fatal("expected the subscribe loop to exit when its context is done")
+	}
+	if n := atomic.LoadInt32(&sub.cancelled); n != 1 {
+		t.Fatalf("expected the failed subscription to be cancelled once, got %d", n)
+	}
+}
+
+// stalledPublisher is a topic that doesn't take records until released,
+// ignoring the context like some pubsub versions do.
+type stalledPublisher struct {
+	release chan struct{}
+}
+
+func (p *stalledPublisher) Publish(context.Context, []byte, ...pubsub.PubOpt) error {
+	<-p.release
+	return nil
+}
+
+func TestPubSub_PublishTimeout(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	publish := func(timeout time.Duration) (*stalledPublisher, chan error) {
+		ps := NewPubSub(ctx, peer.ID("host"), nil, func(context.Context, *pb.PushRecordRequest) {}, Config{
+			PublishTimeout: timeout,
+		})
+		id := thread.NewIDV1(thread.Raw, 32)
+		p := &stalledPublisher{release: make(chan struct{})}
+		ps.m[id] = &topic{p: p}
+		errc := make(chan error, 1)
+		go func() { errc <- ps.Publish(ctx, id, &pb.PushRecordRequest{}) }()
+		return p, errc
+	}
+
+	// A stalled topic is given up on after the timeout
+	p, errc := publish(time.Millisecond * 100)
+	defer close(p.release)
+	select {
+	case err := <-errc:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected the publish to time out, got %v", err)
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatal("expected the publish to end with the timeout")
+	}
+
+	// A negative timeout waits for the topic
+	p, errc = publish(-1)
+	select {
+	case err := <-errc:
+		t.Fatalf("expected the publish to wait for the topic, got %v", err)
+	case <-time.After(time.Millisecond * 300):
+	}
+	close(p.release)
+	select {
+	case err := <-errc:
+		if err != nil {
+			t.Fatalf("expected the publish to succeed, got %v", err)
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatal("expected the publish to end once the topic takes the record")
+	}
+}
+
+func TestNet_RuntimeSettings(t *testing.T) {
+	t.Parallel()
+	n := makeNetworkWithConfig(t, Config{
+		LocalOnly: true,
+	})
+	defer n.Close()
+
+	ctx := context.Background()
+	info := createThread(t, ctx, n)
+	tn := n.(*net)
+
+	n.SetMaxThreadGoroutines(1)
+	release := make(chan struct{})
+	defer close(release)
+	if !tn.spawn(info.ID, func() { <-release }) {
+		t.Fatal("expected goroutine to be spawned")
+	}
+	if tn.spawn(info.ID, func() {}) {
+		t.Fatal("expected new goroutine cap to be enforced")
+	}
+	n.SetMaxThreadGoroutines(0)
+	if !tn.spawn(info.ID, func() {}) {
+		t.Fatal("expected goroutine cap to be lifted")
+	}
+
+	n.SetRequestTimeout(time.Second)
+	if tn.settings.requestTimeout() != time.Second {
+		t.Fatalf("expected request timeout to be updated")
+	}
+	n.SetRequestTimeout(0)
+	if tn.settings.requestTimeout() != DialTimeout {
+		t.Fatalf("expected request timeout to be reset to the default")
+	}
+	n.SetAutoPull(false)
+	if tn.settings.autoPull() {
+		t.Fatal("expected auto-pull to be disabled")
+	}
+}
+
+func TestLogPulls(t *testing.T) {
+	t.Parallel()
+	const workers = 4
+	var running, max, pulls int32
+	release := make(chan struct{})
+	p := newLogPulls(func(context.Context, thread.ID, peer.ID) {
+		r := atomic.AddInt32(&running, 1)
+		for {
+			m := atomic.LoadInt32(&max)
+			if r <= m || atomic.CompareAndSwapInt32(&max, m, r) {
+				break
+			}
+		}
+		<-release
+		atomic.AddInt32(&running, -1)
+		atomic.AddInt32(&pulls, 1)
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p.start(ctx, workers)
+
+	id := thread.NewIDV1(thread.Raw, 32)
+	for i := 0; i < 100; i++ {
+		lid := peer.ID(fmt.Sprintf("log%d", i))
+		if !p.enqueue(id, lid) {
+			t.Fatalf("expected log %d to be queued", i)
+		}
+		if p.enqueue(id, lid) {
+			t.Fatalf("expected log %d not to be queued twice", i)
+		}
+	}
+	time.Sleep(time.Millisecond * 50)
+	close(release)
+	deadline := time.Now().Add(time.Second * 5)
+	for atomic.LoadInt32(&pulls) < 100 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond * 10)
+	}
+	if n := atomic.LoadInt32(&pulls); n != 100 {
+		t.Fatalf("expected 100 pulls, got %d", n)
+	}
+	if m := atomic.LoadInt32(&max); m > workers {
+		t.Fatalf("expected at most %d pulls at once, got %d", workers, m)
+	}
+}
+
+func TestLogPulls_Cancel(t *testing.T) {
+	t.Parallel()
+	started := make(chan struct{})
+	cancelled := make(chan struct{})
+	p := newLogPulls(func(ctx context.Context, _ thread.ID, _ peer.ID) {
+		close(started)
+		<-ctx.Done()
+		close(cancelled)
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p.start(ctx, 1)
+	id := thread.NewIDV1(thread.Raw, 32)
+	running, queued := peer.ID("running"), peer.ID("queued")
+	p.enqueue(id, running)
+	<-started
+	p.enqueue(id, queued)
+
+	p.cancel(id, queued)
+	p.cancel(id, running)
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second * 5):
+		t.Fatal("expected the running pull to be cancelled")
+	}
+	for deadline := time.Now().Add(time.Second); !p.idle(); {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the queued pull to be removed")
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+}
+
+func TestNet_TransportCredentials(t *testing.T) {
+	t.Parallel()
+	serverConf, clientConf := makeTLSConfigs(t)
+	n1 := makeNetworkWithConfig(t, Config{}, grpc.Creds(credentials.NewTLS(serverConf)))
+	defer n1.Close()
+	n2 := makeNetworkWithConfig(t, Config{TransportCredentials: credentials.NewTLS(clientConf)})
+	defer n2.Close()
+	n3 := makeNetworkWithConfig(t, Config{RequestTimeout: time.Second})
+	defer n3.Close()
+
+	ctx := context.Background()
+	info := createThread(t, ctx, n1)
+	for _, n := range []core.Net{n2, n3} {
+		n.Host().Peerstore().AddAddrs(n1.Host().ID(), n1.Host().Addrs(), peerstore.PermanentAddrTTL)
+		if _, err := n.CreateThread(ctx, info.ID, core.WithThreadKey(info.Key)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := n2.(*net).server.getLogs(ctx, info.ID, n1.Host().ID()); err != nil {
+		t.Fatalf("expected secured connection to succeed: %v", err)
+	}
+	if _, err := n3.(*net).server.getLogs(ctx, info.ID, n1.Host().ID()); err == nil {
+		t.Fatal("expected insecure connection to be refused")
+	}
+}
+
+// makeTLSConfigs returns server and client TLS configs sharing a self-signed
+// certificate for the name "threads".
+func makeTLSConfigs(t *testing.T) (*tls.Config, *tls.Config) {
+	sk, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "threads"},
+		DNSNames:     []string{"threads"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IsCA:         true,
+
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &sk.PublicKey, sk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	server := &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: sk}},
+	}
+	client := &tls.Config{RootCAs: pool, ServerName: "threads"}
+	return server, client
+}
+
+func TestNet_PeerResolver(t *testing.T) {
+	t.Parallel()
+	n1 := makeNetwork(t)
+	defer n1.Close()
+	var calls int32
+	n2 := makeNetworkWithConfig(t, Config{
+		PeerResolver: func(_ context.Context, pid peer.ID) ([]ma.Multiaddr, error) {
+			atomic.AddInt32(&calls, 1)
+			if pid != n1.Host().ID() {
+				return nil, fmt.Errorf("unknown peer")
+			}
+			return n1.Host().Addrs(), nil
+		},
+	})
+	defer n2.Close()
+
+	ctx := context.Background()
+	info := createThread(t, ctx, n1)
+	if _, err := n2.CreateThread(ctx, info.ID, core.WithThreadKey(info.Key)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := n2.(*net).server.getLogs(ctx, info.ID, n1.Host().ID()); err != nil {
+		t.Fatalf("expected peer to be resolved: %v", err)
+	}
+	if atomic.LoadInt32(&calls) != 1 {
+		t.Fatalf("expected resolver to be called once, got %d", calls)
+	}
+	// Known peers are not resolved again
+	if _, err := n2.(*net).server.getLogs(ctx, info.ID, n1.Host().ID()); err != nil {
+		t.Fatal(err)
+	}
+	if atomic.LoadInt32(&calls) != 1 {
+		t.Fatalf("expected resolver not to be called again, got %d", calls)
+	}
+}
+
+func TestServer_RequestContext(t *testing.T) {
+	t.Parallel()
+	n := makeNetworkWithConfig(t, Config{RequestTimeout: time.Minute})
+	defer n.Close()
+	tn := n.(*net)
+
+	ctx, cancel := tn.server.requestContext(context.Background())
+	defer cancel()
+	if dl, ok := ctx.Deadline(); !ok || time.Until(dl) > time.Minute || time.Until(dl) < time.Second*50 {
+		t.Fatalf("expected the configured request timeout, got %v", dl)
+	}
+	// The earlier of the caller's deadline and the request timeout applies
+	for _, d := range []time.Duration{time.Second, time.Hour} {
+		want := time.Now().Add(d)
+		pctx, pcancel := context.WithDeadline(context.Background(), want)
+		ctx, cancel := tn.server.requestContext(pctx)
+		dl, _ := ctx.Deadline()
+		if d == time.Second && !dl.Equal(want) {
+			t.Fatalf("expected the caller's deadline %v, got %v", want, dl)
+		}
+		if d == time.Hour && time.Until(dl) > time.Minute {
+			t.Fatalf("expected the configured request timeout, got %v", dl)
+		}
+		cancel()
+
+		// Pushes keep the deadline, but not the caller's cancellation
+		ctx, cancel = tn.server.pushContext(pctx)
+		pcancel()
+		dl, _ = ctx.Deadline()
+		if d == time.Second && !dl.Equal(want) {
+			t.Fatalf("expected the caller's deadline %v, got %v", want, dl)
+		}
+		if d == time.Hour && (time.Until(dl) > time.Minute || ctx.Err() != nil) {
+			t.Fatalf("expected push context to outlive the caller until the request timeout, got %v", dl)
+		}
+		cancel()
+	}
+}
+
+func TestLogLimiter(t *testing.T) {
+	t.Parallel()
+	l := newLogLimiter()
+	id := thread.NewIDV1(thread.Raw, 32)
+	_, pk, err := crypto.GenerateEd25519Key(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lid, err := peer.IDFromPublicKey(pk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	limit := LogRateLimit{Records: 2, Interval: time.Second}
+
+	now := time.Now()
+	for i := 0; i < 2; i++ {
+		if !l.allow(id, lid, limit, now) {
+			t.Fatalf("expected record %d to be allowed", i)
+		}
+	}
+	if l.allow(id, lid, limit, now.Add(time.Millisecond*500)) {
+		t.Fatal("expected record over the limit to be rejected")
+	}
+	if !l.allow(id, lid, limit, now.Add(time.Second)) {
+		t.Fatal("expected record in the next interval to be allowed")
+	}
+	if !l.allow(id, lid, LogRateLimit{}, now) {
+		t.Fatal("expected a zero limit to allow all records")
+	}
+}
+
+func TestRecords_List(t *testing.T) {
+	t.Parallel()
+	n := makeNetwork(t)
+	defer n.Close()
+
+	ctx := context.Background()
+	info := createThread(t, ctx, n)
+	body, err := cbornode.WrapObject(map[string]interface{}{
+		"foo": "bar",
+	}, mh.SHA2_256, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rs []core.Record
+	var lid peer.ID
+	for i := 0; i < 2; i++ {
+		r, err := n.CreateRecord(ctx, info.ID, body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rs = append(rs, r.Value())
+		lid = r.LogID()
+	}
+
+	out := make(chan logRecord, len(rs))
+	recs := newRecordStream(ctx, out, true)
+	for _, r := range rs {
+		recs.Store(lid, r.Cid(), r)
+	}
+	close(out)
+	var i int
+	for r := range out {
+		if r.rec.Cid() != rs[i].Cid() {
+			t.Fatalf("expected record %d to be streamed in order", i)
+		}
+		i++
+	}
+	if i != len(rs) {
+		t.Fatalf("expected %d streamed records, got %d", len(rs), i)
+	}
+
+	list := recs.List()
+	for lid := range list {
+		list[lid][0] = nil
+		delete(list, lid)
+	}
+	for _, l := range recs.List() {
+		if len(l) != len(rs) || l[0] == nil {
+			t.Fatal("expected list to be a copy of the stored records")
+		}
+	}
+
+	// A record waiting to be received doesn't block readers
+	blocked := make(chan logRecord)
+	recs = newRecordStream(ctx, blocked, true)
+	go recs.Store(lid, rs[0].Cid(), rs[0])
+	time.Sleep(time.Millisecond * 100)
+	listed := make(chan struct{})
+	go func() {
+		recs.List()
+		close(listed)
+	}()
+	select {
+	case <-listed:
+	case <-time.After(time.Second * 5):
+		t.Fatal("expected list not to wait for the stream")
+	}
+	if r := <-blocked; r.rec.Cid() != rs[0].Cid() {
+		t.Fatal("expected record to be streamed")
+	}
+}
+
+func TestNet_UpdateRecordsFromLogFrontier(t *testing.T) {
+	t.Parallel()
+	// Count the records served over the wire
+	var served int32
+	n1 := makeNetworkWithConfig(t, Config{},
+		grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, h grpc.UnaryHandler) (interface{}, error) {
+			res, err := h(ctx, req)
+			if reply, ok := res.(*pb.GetRecordsReply); ok {
+				for _, l := range reply.Logs {
+					atomic.AddInt32(&served, int32(len(l.Records)))
+				}
+			}
+			return res, err
+		}),
+		grpc.StreamInterceptor(func(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, h grpc.StreamHandler) error {
+			return h(srv, &countingStream{ServerStream: ss, n: &served})
+		}))
+	defer n1.Close()
+	n2 := makeNetwork(t)
+	defer n2.Close()
+	n2.Host().Peerstore().AddAddrs(n1.Host().ID(), n1.Host().Addrs(), peerstore.PermanentAddrTTL)
+
+	ctx := context.Background()
+	info := createThread(t, ctx, n1)
+	if _, err := n2.CreateThread(ctx, info.ID, core.WithThreadKey(info.Key)); err != nil {
+		t.Fatal(err)
+	}
+	tn1, tn2 := n1.(*net), n2.(*net)
+	lg, err := tn1.getOwnLog(info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr, err := ma.NewMultiaddr("/p2p/" + n1.Host().ID().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tn2.store.AddLog(info.ID, thread.LogInfo{ID: lg.ID, PubKey: lg.PubKey, Addrs: []ma.Multiaddr{addr}}); err != nil {
+		t.Fatal(err)
+	}
+	var rs []core.Record
+	for i := 0; i < 10; i++ {
+		body, err := cbornode.WrapObject(map[string]interface{}{
+			"foo": i,
+		}, mh.SHA2_256, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := n1.CreateRecord(ctx, info.ID, body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rs = append(rs, r.Value())
+	}
+	for _, r := range rs[:5] {
+		if err = n2.AddRecord(ctx, info.ID, lg.ID, r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tn2.updateRecordsFromLog(ctx, info.ID, lg.ID)
+	if s := atomic.LoadInt32(&served); s != 5 {
+		t.Fatalf("expected only the 5 missing records to be served, got %d", s)
+	}
+	heads, err := tn2.store.Heads(info.ID, lg.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(heads) != 1 || !heads[0].Equals(rs[9].Cid()) {
+		t.Fatalf("expected head %s, got %v", rs[9].Cid(), heads)
+	}
+}
+
+// countingStream counts the records sent on a get records stream. If max is
+// set and positive, the stream fails once max records were sent on it.
+type countingStream struct {
+	grpc.ServerStream
+	n    *int32
+	max  *int32
+	sent int32
+}
+
+func (s *countingStream) SendMsg(m interface{}) error {
+	if reply, ok := m.(*pb.GetRecordsStreamReply); ok && reply.Record != nil {
+		if s.max != nil && atomic.LoadInt32(s.max) > 0 && s.sent >= atomic.LoadInt32(s.max) {
+			return fmt.Errorf("stream interrupted")
+		}
+		s.sent++
+		atomic.AddInt32(s.n, 1)
+	}
+	return s.ServerStream.SendMsg(m)
+}
+
+func TestNet_HistoryPullCancel(t *testing.T) {
+	t.Parallel()
+	// Hold record requests until the puller gives up
+	started := make(chan struct{}, 1)
+	hold := func(ctx context.Context) error {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	n1 := makeNetworkWithConfig(t, Config{},
+		grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, h grpc.UnaryHandler) (interface{}, error) {
+			if _, ok := req.(*pb.GetRecordsRequest); ok {
+				return nil, hold(ctx)
+			}
+			return h(ctx, req)
+		}),
+		grpc.StreamInterceptor(func(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, h grpc.StreamHandler) error {
+			return hold(ss.Context())
+		}))
+	defer n1.Close()
+	n2 := makeNetwork(t)
+	defer n2.Close()
+	n2.Host().Peerstore().AddAddrs(n1.Host().ID(), n1.Host().Addrs(), peerstore.PermanentAddrTTL)
+
+	ctx := context.Background()
+	info := createThread(t, ctx, n1)
+	if _, err := n2.CreateThread(ctx, info.ID, core.WithThreadKey(info.Key)); err != nil {
+		t.Fatal(err)
+	}
+	tn1, tn2 := n1.(*net), n2.(*net)
+	lg, err := tn1.getOwnLog(info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr, err := ma.NewMultiaddr("/p2p/" + n1.Host().ID().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tn2.store.AddLog(info.ID, thread.LogInfo{ID: lg.ID, PubKey: lg.PubKey, Addrs: []ma.Multiaddr{addr}}); err != nil {
+		t.Fatal(err)
+	}
+
+	tn2.logPulls.enqueue(info.ID, lg.ID)
+	select {
+	case <-started:
+	case <-time.After(time.Second * 5):
+		t.Fatal("expected the history pull to request records")
+	}
+	tn2.cancel()
+	for deadline := time.Now().Add(time.Second); !tn2.logPulls.idle(); {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the history pull to be abandoned when the service stops")
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+}
+
+func TestNet_PullThreadResumes(t *testing.T) {
+	t.Parallel()
+	var served int32
+	max := int32(4)
+	n1 := makeNetworkWithConfig(t, Config{}, grpc.StreamInterceptor(
+		func(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, h grpc.StreamHandler) error {
+			return h(srv, &countingStream{ServerStream: ss, n: &served, max: &max})
+		}))
+	defer n1.Close()
+	n2 := makeNetwork(t)
+	defer n2.Close()
+	n2.Host().Peerstore().AddAddrs(n1.Host().ID(), n1.Host().Addrs(), peerstore.PermanentAddrTTL)
+
+	ctx := context.Background()
+	info := createThread(t, ctx, n1)
+	var rs []core.Record
+	for i := 0; i < 10; i++ {
+		body, err := cbornode.WrapObject(map[string]interface{}{
+			"foo": i,
+		}, mh.SHA2_256, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := n1.CreateRecord(ctx, info.ID, body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rs = append(rs, r.Value())
+	}
+	addr, err := ma.NewMultiaddr("/p2p/" + n1.Host().ID().String() + "/thread/" + info.ID.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = n2.AddThread(ctx, addr, core.WithThreadKey(info.Key)); err != nil {
+		t.Fatal(err)
+	}
+	lg, err := n1.(*net).getOwnLog(info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The log head is the checkpoint of an interrupted pull
+	if err = n2.PullThread(ctx, info.ID); err != nil {
+		t.Fatal(err)
+	}
+	tn2 := n2.(*net)
+	heads, err := tn2.store.Heads(info.ID, lg.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(heads) != 1 || !heads[0].Equals(rs[3].Cid()) {
+		t.Fatalf("expected interrupted pull to stop at %s, got %v", rs[3].Cid(), heads)
+	}
+
+	atomic.StoreInt32(&max, 0)
+	atomic.StoreInt32(&served, 0)
+	if err = n2.PullThread(ctx, info.ID); err != nil {
+		t.Fatal(err)
+	}
+	if s := atomic.LoadInt32(&served); s != 6 {
+		t.Fatalf("expected the pull to resume with the 6 remaining records, got %d", s)
+	}
+	if heads, err = tn2.store.Heads(info.ID, lg.ID); err != nil {
+		t.Fatal(err)
+	}
+	if len(heads) != 1 || !heads[0].Equals(rs[9].Cid()) {
+		t.Fatalf("expected head %s, got %v", rs[9].Cid(), heads)
+	}
+}
+
+func TestNet_SubscribePushedRecords(t *testing.T) {
+	t.Parallel()
+	n1 := makeNetworkWithConfig(t, Config{EventBuffer: 10})
+	defer n1.Close()
+	n2 := makeNetwork(t)
+	defer n2.Close()
+	n2.Host().Peerstore().AddAddrs(n1.Host().ID(), n1.Host().Addrs(), peerstore.PermanentAddrTTL)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	info := createThread(t, ctx, n1)
+	if _, err := n2.CreateThread(ctx, info.ID, core.WithThreadKey(info.Key)); err != nil {
+		t.Fatal(err)
+	}
+	tn1, tn2 := n1.(*net), n2.(*net)
+	lg, err := tn2.getOwnLog(info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tn1.store.AddLog(info.ID, thread.LogInfo{ID: lg.ID, PubKey: lg.PubKey}); err != nil {
+		t.Fatal(err)
+	}
+
+	var subs []<-chan core.ThreadRecord
+	for i := 0; i < 2; i++ {
+		sub, err := n1.Subscribe(ctx, core.WithSubFilter(info.ID))
+		if err != nil {
+			t.Fatal(err)
+		}
+		subs = append(subs, sub)
+	}
+	var rids []cid.Cid
+	for i := 0; i < 5; i++ {
+		body, err := cbornode.WrapObject(map[string]interface{}{
+			"foo": i,
+		}, mh.SHA2_256, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := n2.CreateRecord(ctx, info.ID, body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req, err := tn2.server.newPushRecordRequest(ctx, info.ID, lg.ID, r.Value())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = tn2.server.retryPush(n1.Host().ID(), req); err != nil {
+			t.Fatal(err)
+		}
+		rids = append(rids, r.Value().Cid())
+	}
+
+	// Each subscriber is buffered, so they are read one after the other
+	for i, sub := range subs {
+		for j, rid := range rids {
+			select {
+			case r := <-sub:
+				if r.ThreadID() != info.ID || r.LogID() != lg.ID || !r.Value().Cid().Equals(rid) {
+					t.Fatalf("subscriber %d: expected record %d in order", i, j)
+				}
+			case <-time.After(time.Second * 5):
+				t.Fatalf("subscriber %d: timed out waiting for record %d", i, j)
+			}
+		}
+	}
+}
+
+func TestNet_EventDropOldest(t *testing.T) {
+	t.Parallel()
+	// Without a buffer, the subscriber still holds the latest record
+	n := makeNetworkWithConfig(t, Config{EventPolicy: EventDropOldest})
+	defer n.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	info := createThread(t, ctx, n)
+	sub, err := n.Subscribe(ctx, core.WithSubFilter(info.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var last core.ThreadRecord
+	for i := 0; i < 3; i++ {
+		body, err := cbornode.WrapObject(map[string]interface{}{
+			"foo": i,
+		}, mh.SHA2_256, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if last, err = n.CreateRecord(ctx, info.ID, body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tn := n.(*net)
+	deadline := time.Now().Add(time.Second * 5)
+	for tn.DroppedEvents() < 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected 2 dropped records, got %d", tn.DroppedEvents())
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+	select {
+	case rec := <-sub:
+		if !rec.Value().Cid().Equals(last.Value().Cid()) {
+			t.Fatal("expected the latest record to be delivered")
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatal("expected a record to be delivered")
+	}
+}
+
+func TestNet_Follow(t *testing.T) {
+	t.Parallel()
+	n1 := makeNetwork(t)
+	defer n1.Close()
+	n2 := makeNetwork(t)
+	defer n2.Close()
+	n2.Host().Peerstore().AddAddrs(n1.Host().ID(), n1.Host().Addrs(), peerstore.PermanentAddrTTL)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	info := createThread(t, ctx, n1)
+	if _, err := n2.CreateThread(ctx, info.ID, core.WithThreadKey(info.Key)); err != nil {
+		t.Fatal(err)
+	}
+	tn1, tn2 := n1.(*net), n2.(*net)
+	lg, err := tn1.getOwnLog(info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tn2.store.AddLog(info.ID, thread.LogInfo{ID: lg.ID, PubKey: lg.PubKey}); err != nil {
+		t.Fatal(err)
+	}
+	// Records can only reach n2 over the stream
+	if err = tn2.server.ps.Remove(info.ID); err != nil {
+		t.Fatal(err)
+	}
+	following := func() int {
+		tn1.server.followers.Lock()
+		defer tn1.server.followers.Unlock()
+		return len(tn1.server.followers.m[info.ID])
+	}
+
+	fctx, fcancel := context.WithCancel(ctx)
+	done := make(chan error, 1)
+	go func() {
+		done <- n2.Follow(fctx, n1.Host().ID(), info.ID)
+	}()
+	for start := time.Now(); following() == 0; time.Sleep(time.Millisecond * 10) {
+		if time.Since(start) > time.Second*5 {
+			t.Fatal("timed out waiting for follower")
+		}
+	}
+
+	sub, err := n2.Subscribe(ctx, core.WithSubFilter(info.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		body, err := cbornode.WrapObject(map[string]interface{}{
+			"foo": i,
+		}, mh.SHA2_256, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := n1.CreateRecord(ctx, info.ID, body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Each record is received as it's created
+		select {
+		case got := <-sub:
+			if got.LogID() != lg.ID || !got.Value().Cid().Equals(r.Value().Cid()) {
+				t.Fatalf("expected record %d, got %s", i, got.Value().Cid())
+			}
+		case <-time.After(time.Second * 5):
+			t.Fatalf("timed out waiting for record %d", i)
+		}
+	}
+
+	// The stream is unregistered once the follower disconnects
+	fcancel()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected follow to be canceled, got %v", err)
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out waiting for follow to end")
+	}
+	for start := time.Now(); following() != 0; time.Sleep(time.Millisecond * 10) {
+		if time.Since(start) > time.Second*5 {
+			t.Fatal("timed out waiting for follower to be removed")
+		}
+	}
+
+	// Threads can only be followed with their service-key
+	ctx2, cancel2 := context.WithTimeout(ctx, time.Second*5)
+	defer cancel2()
+	other := createThread(t, ctx2, n2)
+	if err = n2.Follow(ctx2, n1.Host().ID(), other.ID); status.Code(err) != codes.NotFound {
+		t.Fatalf("expected follow of unknown thread to fail with not found, got %v", err)
+	}
+
+	// Requests without a body or thread end the stream
+	client, err := tn2.server.dial(n1.Host().ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, key, err := tn2.server.signRequestBody(&pb.SubscribeRequest_Body{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := &pb.Header{PubKey: &pb.ProtoPubKey{PubKey: key}, Signature: sig}
+	reqs := map[string]*pb.SubscribeRequest{
+		"no body":   {Header: header},
+		"no thread": {Header: header, Body: &pb.SubscribeRequest_Body{}},
+	}
+	for name, req := range reqs {
+		stream, err := client.Subscribe(ctx2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = stream.Send(req); err != nil {
+			t.Fatal(err)
+		}
+		if _, err = stream.Recv(); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("%s: expected subscribe to be invalid, got %v", name, err)
+		}
+	}
+}
+
+func TestRecords_ConcurrentList(t *testing.T) {
+	t.Parallel()
+	n := makeNetwork(t)
+	defer n.Close()
+
+	ctx := context.Background()
+	info := createThread(t, ctx, n)
+	var rs []core.Record
+	var lid peer.ID
+	for i := 0; i < 50; i++ {
+		body, err := cbornode.WrapObject(map[string]interface{}{
+			"foo": i,
+		}, mh.SHA2_256, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := n.CreateRecord(ctx, info.ID, body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rs = append(rs, r.Value())
+		lid = r.LogID()
+	}
+
+	recs := newRecords()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for _, r := range rs {
+			recs.Store(lid, r.Cid(), r)
+		}
+	}()
+	for stored := false; !stored; {
+		select {
+		case <-done:
+			stored = true
+		default:
+		}
+		for _, l := range recs.List() {
+			for i, r := range l {
+				if !r.Cid().Equals(rs[i].Cid()) {
+					t.Fatalf("expected record %d to be listed in order", i)
+				}
+			}
+		}
+	}
+
+	if l := recs.Len(); l != len(rs) {
+		t.Fatalf("expected %d records, got %d", len(rs), l)
+	}
+	if r, ok := recs.Get(lid, rs[10].Cid()); !ok || !r.Cid().Equals(rs[10].Cid()) {
+		t.Fatal("expected stored record to be found")
+	}
+	if _, ok := recs.Get(lid, rs[0].BlockID()); ok {
+		t.Fatal("expected unknown record not to be found")
+	}
+}
+
+func TestClose(t *testing.T) {
+	t.Parallel()
+	n := makeNetwork(t)
+	defer n.Close()
+
+	t.Run("test close", func(t *testing.T) {
+		if err := n.Close(); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func makeNetwork(t *testing.T) core.Net {
+	return makeNetworkWithConfig(t, Config{
+		Debug: true,
+	})
+}
+
+func makeNetworkWithConfig(t *testing.T, conf Config, opts ...grpc.ServerOption) core.Net {
+	sk, _, err := crypto.GenerateKeyPair(crypto.Ed25519, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := util.MustParseAddr("/ip4/127.0.0.1/tcp/0")
+
+	host, err := libp2p.New(
+		context.Background(),
+		libp2p.ListenAddrs(addr),
+		libp2p.Identity(sk),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bs := bstore.NewBlockstore(syncds.MutexWrap(ds.NewMapDatastore()))
+	bsrv := bserv.New(bs, offline.Exchange(bs))
+	n, err := NewNetwork(
+		context.Background(),
+		host,
+		bsrv.Blockstore(),
+		dag.NewDAGService(bsrv),
+		tstore.NewLogstore(),
+		conf,
+		opts...)
 	if err != nil {
 		t.Fatal(err)
 	}
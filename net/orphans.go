@@ -0,0 +1,159 @@
+package net
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gogo/status"
+	"github.com/ipfs/go-cid"
+	"github.com/libp2p/go-libp2p-core/peer"
+	core "github.com/textileio/go-threads/core/net"
+	"github.com/textileio/go-threads/core/thread"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	// DefaultMaxOrphans is the default max number of records held while
+	// waiting for their ancestors.
+	DefaultMaxOrphans = 1000
+
+	// DefaultOrphanTTL is the default time a record is held while waiting for
+	// its ancestors.
+	DefaultOrphanTTL = time.Minute
+)
+
+// errRecordHeld is returned for a pushed record that is held until its
+// ancestors arrive. It is not stored yet, so it doesn't count as a replica.
+var errRecordHeld = status.Error(codes.FailedPrecondition, "record held until its ancestors arrive")
+
+// isHeld returns whether a push failed because the record is held by the peer.
+func isHeld(err error) bool {
+	return status.Convert(err).Code() == codes.FailedPrecondition
+}
+
+// orphan is a record whose previous record was not available when it arrived.
+type orphan struct {
+	id    thread.ID
+	lid   peer.ID
+	rec   core.Record
+	added time.Time
+}
+
+// orphans buffers out-of-order records by the cid of their previous record.
+type orphans struct {
+	sync.Mutex
+	m   map[cid.Cid][]orphan
+	n   int
+	max int
+	ttl time.Duration
+}
+
+// newOrphans returns a buffer of at most max records, each held for ttl.
+// Zero values use DefaultMaxOrphans and DefaultOrphanTTL.
+func newOrphans(max int, ttl time.Duration) *orphans {
+	if max <= 0 {
+		max = DefaultMaxOrphans
+	}
+	if ttl <= 0 {
+		ttl = DefaultOrphanTTL
+	}
+	return &orphans{m: make(map[cid.Cid][]orphan), max: max, ttl: ttl}
+}
+
+// add buffers a record until its previous record arrives.
+// Returns false if the buffer is full.
+func (o *orphans) add(id thread.ID, lid peer.ID, rec core.Record) bool {
+	o.Lock()
+	defer o.Unlock()
+	if o.n >= o.max {
+		o.prune()
+		if o.n >= o.max {
+			return false
+		}
+	}
+	prev := rec.PrevID()
+	for _, or := range o.m[prev] {
+		if or.rec.Cid() == rec.Cid() {
+			return true
+		}
+	}
+	o.m[prev] = append(o.m[prev], orphan{id: id, lid: lid, rec: rec, added: time.Now()})
+	o.n++
+	return true
+}
+
+// take removes and returns the unexpired records waiting on prev.
+func (o *orphans) take(prev cid.Cid) []orphan {
+	o.Lock()
+	defer o.Unlock()
+	ors, ok := o.m[prev]
+	if !ok {
+		return nil
+	}
+	delete(o.m, prev)
+	o.n -= len(ors)
+	res := ors[:0]
+	for _, or := range ors {
+		if time.Since(or.added) < o.ttl {
+			res = append(res, or)
+		}
+	}
+	return res
+}
+
+// ready removes and returns the unexpired records whose previous record is
+// held, as reported by has.
+func (o *orphans) ready(has func(id thread.ID, c cid.Cid) (bool, error)) []orphan {
+	o.Lock()
+	defer o.Unlock()
+	o.prune()
+	var res []orphan
+	for prev, ors := range o.m {
+		keep := ors[:0]
+		for _, or := range ors {
+			if ok, err := has(or.id, prev); err == nil && ok {
+				res = append(res, or)
+			} else {
+				keep = append(keep, or)
+			}
+		}
+		o.n -= len(ors) - len(keep)
+		if len(keep) == 0 {
+			delete(o.m, prev)
+		} else {
+			o.m[prev] = keep
+		}
+	}
+	return res
+}
+
+// drop removes the records of a thread.
+func (o *orphans) drop(id thread.ID) {
+	o.Lock()
+	defer o.Unlock()
+	o.filter(func(or orphan) bool { return or.id != id })
+}
+
+// prune drops expired records. The caller must hold the lock.
+func (o *orphans) prune() {
+	o.filter(func(or orphan) bool { return time.Since(or.added) < o.ttl })
+}
+
+// filter drops the records for which keep returns false. The caller must
+// hold the lock.
+func (o *orphans) filter(keep func(or orphan) bool) {
+	for prev, ors := range o.m {
+		kept := ors[:0]
+		for _, or := range ors {
+			if keep(or) {
+				kept = append(kept, or)
+			}
+		}
+		o.n -= len(ors) - len(kept)
+		if len(kept) == 0 {
+			delete(o.m, prev)
+		} else {
+			o.m[prev] = kept
+		}
+	}
+}
@@ -0,0 +1,564 @@
+package net
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gogo/status"
+	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/query"
+	"github.com/libp2p/go-libp2p-core/peer"
+	core "github.com/textileio/go-threads/core/net"
+	"github.com/textileio/go-threads/core/thread"
+	pb "github.com/textileio/go-threads/net/pb"
+	"google.golang.org/grpc/codes"
+)
+
+var (
+	// PushRetryInterval is the interval between attempts to push queued records.
+	PushRetryInterval = time.Second * 30
+
+	// MaxPushRetryBackoff is the max time between attempts to push a queued
+	// record. The time doubles with each failed attempt, starting at
+	// PushRetryInterval.
+	MaxPushRetryBackoff = time.Minute * 10
+
+	// MaxPushRetryAge is the default time a record is retried before it's
+	// dropped. See Config.PushRetryTTL.
+	MaxPushRetryAge = time.Hour
+
+	// MaxQueuedPushes is the default max number of pushes queued per peer.
+	// See Config.OutboundQueueLimit.
+	MaxQueuedPushes = 1000
+
+	// outboundKey is the datastore namespace of queued pushes.
+	outboundKey = datastore.NewKey("/outbound")
+)
+
+// PushExpiredHandler is called when a queued record is dropped without being delivered.
+type PushExpiredHandler func(pid peer.ID, id thread.ID, rid cid.Cid)
+
+// pendingPush is a push record request that failed to reach a peer.
+type pendingPush struct {
+	rid      cid.Cid
+	req      *pb.PushRecordRequest
+	added    time.Time
+	attempts int
+	// next is the earliest time of the next attempt.
+	next time.Time
+	// gen is the drop generation of the push's thread when it was taken.
+	gen uint64
+}
+
+// key returns the datastore key of a push queued for a peer. Keys sort in
+// the order pushes were queued.
+func (p *pendingPush) key(pid peer.ID) datastore.Key {
+	return outboundKey.ChildString(pid.String()).
+		ChildString(fmt.Sprintf("%020d", p.added.UnixNano())).
+		ChildString(p.rid.String())
+}
+
+// outbound queues records that could not be pushed to peers.
+type outbound struct {
+	sync.Mutex
+	m     map[peer.ID][]*pendingPush
+	ttl   time.Duration
+	limit int
+	store datastore.Datastore
+	log   Logger
+	// drops counts the drops of each thread's pushes, so that pushes taken
+	// before a drop are not put back.
+	drops map[thread.ID]uint64
+	// retrying is held while queued pushes are retried.
+	retrying sync.Mutex
+}
+
+// newOutbound returns a queue that drops pushes older than ttl, and that
+// holds up to limit pushes per peer. If store is not nil, queued pushes are
+// persisted in it, and the ones left from a previous run are loaded.
+func newOutbound(ttl time.Duration, limit int, store datastore.Datastore, l Logger) (*outbound, error) {
+	if ttl <= 0 {
+		ttl = MaxPushRetryAge
+	}
+	if limit <= 0 {
+		limit = MaxQueuedPushes
+	}
+	o := &outbound{
+		m:     make(map[peer.ID][]*pendingPush),
+		drops: make(map[thread.ID]uint64),
+		ttl:   ttl,
+		limit: limit,
+		store: store,
+		log:   l,
+	}
+	if store == nil {
+		return o, nil
+	}
+	res, err := store.Query(query.Query{
+		Prefix: outboundKey.String(),
+		Orders: []query.Order{query.OrderByKey{}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+	for e := range res.Next() {
+		if e.Error != nil {
+			return nil, e.Error
+		}
+		pid, p, err := pushFromEntry(e.Entry)
+		if err != nil {
+			o.log.Warnf("dropping invalid queued push %s: %s", e.Key, err)
+			if err = store.Delete(datastore.NewKey(e.Key)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		o.m[pid] = append(o.m[pid], p)
+	}
+	for pid := range o.m {
+		if evicted := o.evict(pid); len(evicted) > 0 {
+			o.log.Warnf("dropping %d queued pushes to %s over the queue limit", len(evicted), pid)
+		}
+	}
+	return o, nil
+}
+
+// pushFromEntry decodes a persisted push.
+func pushFromEntry(e query.Entry) (peer.ID, *pendingPush, error) {
+	ns := datastore.NewKey(e.Key).Namespaces()
+	if len(ns) != 4 {
+		return "", nil, fmt.Errorf("invalid key")
+	}
+	pid, err := peer.Decode(ns[1])
+	if err != nil {
+		return "", nil, err
+	}
+	added, err := strconv.ParseInt(ns[2], 10, 64)
+	if err != nil {
+		return "", nil, err
+	}
+	rid, err := cid.Decode(ns[3])
+	if err != nil {
+		return "", nil, err
+	}
+	req := new(pb.PushRecordRequest)
+	if err = req.Unmarshal(e.Value); err != nil {
+		return "", nil, err
+	}
+	return pid, &pendingPush{rid: rid, req: req, added: time.Unix(0, added), attempts: 1}, nil
+}
+
+// enqueue adds a failed push for later delivery. A record that is already
+// queued for the peer is not queued again. If the peer's queue is full, the
+// oldest pushes are evicted and returned.
+func (o *outbound) enqueue(pid peer.ID, rid cid.Cid, req *pb.PushRecordRequest) []*pendingPush {
+	o.Lock()
+	defer o.Unlock()
+	for _, p := range o.m[pid] {
+		if p.rid.Equals(rid) {
+			return nil
+		}
+	}
+	p := &pendingPush{rid: rid, req: req, added: time.Now(), attempts: 1}
+	o.m[pid] = append(o.m[pid], p)
+	o.persist(pid, p)
+	return o.evict(pid)
+}
+
+// evict removes and returns the oldest pushes queued for a peer beyond the
+// limit. Evicted pushes are removed from the store.
+func (o *outbound) evict(pid peer.ID) []*pendingPush {
+	ps := o.m[pid]
+	over := len(ps) - o.limit
+	if over <= 0 {
+		return nil
+	}
+	evicted := make([]*pendingPush, over)
+	copy(evicted, ps[:over])
+	for _, p := range evicted {
+		o.remove(pid, p)
+	}
+	o.m[pid] = ps[over:]
+	return evicted
+}
+
+// take removes and returns the pushes queued for the given peers, or for
+// all peers if none are given.
+func (o *outbound) take(pids ...peer.ID) map[peer.ID][]*pendingPush {
+	o.Lock()
+	defer o.Unlock()
+	var queued map[peer.ID][]*pendingPush
+	if len(pids) == 0 {
+		queued = o.m
+		o.m = make(map[peer.ID][]*pendingPush)
+	} else {
+		queued = make(map[peer.ID][]*pendingPush)
+		for _, pid := range pids {
+			if ps, ok := o.m[pid]; ok {
+				queued[pid] = ps
+				delete(o.m, pid)
+			}
+		}
+	}
+	for _, ps := range queued {
+		for _, p := range ps {
+			p.gen = o.drops[p.req.Body.ThreadID.ID]
+		}
+	}
+	return queued
+}
+
+// requeue puts back pushes that were taken, ahead of pushes queued since.
+// Pushes of threads that were dropped since they were taken are removed
+// instead. If the peer's queue is full, the oldest pushes are evicted and
+// returned.
+func (o *outbound) requeue(pid peer.ID, ps []*pendingPush) []*pendingPush {
+	o.Lock()
+	defer o.Unlock()
+	keep := make([]*pendingPush, 0, len(ps))
+	for _, p := range ps {
+		if p.gen != o.drops[p.req.Body.ThreadID.ID] {
+			o.remove(pid, p)
+		} else {
+			keep = append(keep, p)
+		}
+	}
+	ps = keep
+	for _, p := range o.m[pid] {
+		if !containsPush(ps, p.rid) {
+			ps = append(ps, p)
+		}
+	}
+	o.m[pid] = ps
+	return o.evict(pid)
+}
+
+func containsPush(ps []*pendingPush, rid cid.Cid) bool {
+	for _, p := range ps {
+		if p.rid.Equals(rid) {
+			return true
+		}
+	}
+	return false
+}
+
+// drop removes the pushes of a thread's records. Pushes being retried are
+// removed when they are put back.
+func (o *outbound) drop(id thread.ID) {
+	o.Lock()
+	defer o.Unlock()
+	o.drops[id]++
+	for pid, ps := range o.m {
+		keep := ps[:0]
+		for _, p := range ps {
+			if p.req.Body.ThreadID.ID == id {
+				o.remove(pid, p)
+			} else {
+				keep = append(keep, p)
+			}
+		}
+		if len(keep) == 0 {
+			delete(o.m, pid)
+		} else {
+			o.m[pid] = keep
+		}
+	}
+}
+
+// persist writes a queued push to the store, if any.
+func (o *outbound) persist(pid peer.ID, p *pendingPush) {
+	if o.store == nil {
+		return
+	}
+	data, err := p.req.Marshal()
+	if err != nil {
+		o.log.Errorf("error encoding queued push: %s", err)
+		return
+	}
+	if err = o.store.Put(p.key(pid), data); err != nil {
+		o.log.Errorf("error persisting queued push: %s", err)
+	}
+}
+
+// remove deletes a delivered or expired push from the store, if any.
+func (o *outbound) remove(pid peer.ID, p *pendingPush) {
+	if o.store == nil {
+		return
+	}
+	if err := o.store.Delete(p.key(pid)); err != nil {
+		o.log.Errorf("error removing queued push: %s", err)
+	}
+}
+
+// len returns the number of queued pushes.
+func (o *outbound) len() int {
+	o.Lock()
+	defer o.Unlock()
+	var l int
+	for _, ps := range o.m {
+		l += len(ps)
+	}
+	return l
+}
+
+// pushBackoff returns the time to wait before the next attempt of a push
+// that failed the given number of times.
+func pushBackoff(attempts int) time.Duration {
+	d := PushRetryInterval
+	for i := 1; i < attempts && d < MaxPushRetryBackoff; i++ {
+		d *= 2
+	}
+	if d > MaxPushRetryBackoff {
+		d = MaxPushRetryBackoff
+	}
+	return d
+}
+
+// OutboundQueueStats returns stats about records waiting to be pushed to peers.
+func (n *net) OutboundQueueStats() core.OutboundQueueStats {
+	q := n.server.outbound
+	q.Lock()
+	defer q.Unlock()
+	stats := core.OutboundQueueStats{Peers: make(map[peer.ID]core.PeerQueueStats)}
+	for pid, ps := range q.m {
+		var ps2 core.PeerQueueStats
+		for _, p := range ps {
+			ps2.Pending++
+			ps2.Attempts += p.attempts
+			if age := time.Since(p.added); age > ps2.OldestAge {
+				ps2.OldestAge = age
+			}
+		}
+		stats.Peers[pid] = ps2
+	}
+	return stats
+}
+
+// startRetryingPushes periodically retries queued pushes until the network is closed.
+func (s *server) startRetryingPushes() {
+	tick := time.NewTicker(PushRetryInterval)
+	defer tick.Stop()
+	for {
+		select {
+		case <-tick.C:
+			s.retryPushes()
+		case <-s.net.ctx.Done():
+			return
+		}
+	}
+}
+
+// retryPushes attempts delivery of queued pushes whose backoff has passed,
+// dropping those that are too old.
+func (s *server) retryPushes() {
+	s.outbound.retrying.Lock()
+	defer s.outbound.retrying.Unlock()
+	for pid, ps := range s.outbound.take() {
+		s.retryPeerPushes(pid, ps, false)
+	}
+}
+
+// retryPushesTo attempts delivery of all pushes queued for a peer regardless
+// of backoff, e.g., when the peer connects to the host.
+func (s *server) retryPushesTo(pid peer.ID) {
+	s.outbound.retrying.Lock()
+	defer s.outbound.retrying.Unlock()
+	for pid, ps := range s.outbound.take(pid) {
+		s.retryPeerPushes(pid, ps, true)
+	}
+}
+
+// retryPeerPushes attempts delivery of pushes taken from a peer's queue in
+// order, putting back the ones that are not delivered.
+func (s *server) retryPeerPushes(pid peer.ID, ps []*pendingPush, force bool) {
+	for len(ps) > 0 {
+		if time.Since(ps[0].added) > s.outbound.ttl {
+			s.expirePush(pid, ps[0])
+			ps = ps[1:]
+			continue
+		}
+		if !force && time.Now().Before(ps[0].next) {
+			break
+		}
+		if !force && s.net.shedPush(pid, false) {
+			s.log.Debugf("holding queued pushes to %s under connection pressure", pid)
+			break
+		}
+		batch := s.pushBatch(ps)
+		sent, err := s.retryPushBatch(pid, batch)
+		if errors.Is(err, errUnreachable) {
+			s.log.Debugf("holding queued pushes to unreachable peer %s", pid)
+			break
+		}
+		for _, p := range batch[:sent] {
+			s.outbound.remove(pid, p)
+			d := newDelivery(nil)
+			d.Peer = pid
+			s.deliveries.add(p.rid, d)
+		}
+		ps = ps[sent:]
+		if err != nil {
+			s.log.Debugf("retrying push to %s failed: %s", pid, err)
+			d := newDelivery(err)
+			d.Peer = pid
+			s.deliveries.add(ps[0].rid, d)
+			ps[0].attempts++
+			ps[0].next = time.Now().Add(pushBackoff(ps[0].attempts))
+			break // Keep order by holding the rest of this peer's queue
+		}
+	}
+	if len(ps) > 0 {
+		s.evictPushes(pid, s.outbound.requeue(pid, ps))
+	}
+}
+
+// pushBatch returns the leading queued pushes that can be sent in a single
+// push records request, i.e., unexpired records in the same log.
+func (s *server) pushBatch(ps []*pendingPush) []*pendingPush {
+	first := ps[0].req
+	n := 1
+	for ; n < len(ps) && n < MaxPushBatchSize; n++ {
+		p := ps[n]
+		if time.Since(p.added) > s.outbound.ttl ||
+			p.req.Body.ThreadID.ID != first.Body.ThreadID.ID ||
+			p.req.Body.LogID.ID != first.Body.LogID.ID {
+			break
+		}
+	}
+	return ps[:n]
+}
+
+// retryPushBatch delivers queued pushes to a peer in a single request,
+// returning the number of pushes that were delivered. Peers that don't
+// support batches are sent each push separately. Nothing is sent while the
+// peer's breaker is open, and errUnreachable is returned.
+func (s *server) retryPushBatch(pid peer.ID, batch []*pendingPush) (int, error) {
+	if s.health.unreachable(pid) {
+		return 0, errUnreachable
+	}
+	if len(batch) == 1 {
+		if err := s.retryPush(pid, batch[0].req); err != nil {
+			return 0, err
+		}
+		return 1, nil
+	}
+
+	first := batch[0].req
+	body := &pb.PushRecordsRequest_Body{
+		ThreadID: first.Body.ThreadID,
+		LogID:    first.Body.LogID,
+		Records:  make([]*pb.Log_Record, len(batch)),
+	}
+	for i, p := range batch {
+		body.Records[i] = p.req.Body.Record
+	}
+	sig, key, err := s.signRequestBody(body)
+	if err != nil {
+		return 0, err
+	}
+	req := &pb.PushRecordsRequest{
+		Header: &pb.Header{
+			PubKey:    &pb.ProtoPubKey{PubKey: key},
+			Signature: sig,
+		},
+		Body: body,
+	}
+
+	client, err := s.dial(pid)
+	if err != nil {
+		s.health.failure(pid)
+		return 0, err
+	}
+	ctx, cancel := context.WithTimeout(s.net.ctx, s.net.settings.requestTimeout())
+	defer cancel()
+	reply, err := client.PushRecords(ctx, req)
+	if err != nil {
+		switch status.Convert(err).Code() {
+		case codes.Unimplemented:
+			for i, p := range batch {
+				if err = s.retryPush(pid, p.req); err != nil {
+					return i, err
+				}
+			}
+			return len(batch), nil
+		case codes.NotFound:
+			// The peer doesn't know the log, it will get the records when it does
+			s.log.Debugf("dropping queued pushes to %s: %s", pid, err)
+			s.health.success(pid)
+			return len(batch), nil
+		default:
+			s.health.failure(pid)
+			return 0, err
+		}
+	}
+	s.health.success(pid)
+	for i, res := range reply.Results {
+		if i >= len(batch) {
+			break
+		}
+		if code := codes.Code(res.Code); code != codes.OK && code != codes.NotFound && code != codes.FailedPrecondition {
+			return i, status.Error(code, res.Message)
+		}
+	}
+	if len(reply.Results) < len(batch) {
+		return len(reply.Results), fmt.Errorf("missing results for %d records", len(batch)-len(reply.Results))
+	}
+	return len(batch), nil
+}
+
+// retryPush delivers a single push request to a peer.
+func (s *server) retryPush(pid peer.ID, req *pb.PushRecordRequest) error {
+	client, err := s.dial(pid)
+	if err != nil {
+		s.health.failure(pid)
+		return err
+	}
+	ctx, cancel := context.WithTimeout(s.net.ctx, s.net.settings.requestTimeout())
+	defer cancel()
+	if _, err = client.PushRecord(ctx, req); err != nil {
+		if status.Convert(err).Code() != codes.NotFound && !isHeld(err) {
+			s.health.failure(pid)
+			return err
+		}
+		// The peer doesn't know the log, or holds the record until it
+		// fetches its ancestors, it will store the record when it does
+		s.log.Debugf("dropping queued push to %s: %s", pid, err)
+	}
+	s.health.success(pid)
+	return nil
+}
+
+// queuePush queues a failed push for retry, dropping the oldest pushes to
+// the peer if its queue is full.
+func (s *server) queuePush(pid peer.ID, rid cid.Cid, req *pb.PushRecordRequest) {
+	s.evictPushes(pid, s.outbound.enqueue(pid, rid, req))
+}
+
+// evictPushes notifies the configured handler of pushes dropped from a full
+// queue.
+func (s *server) evictPushes(pid peer.ID, ps []*pendingPush) {
+	if len(ps) == 0 {
+		return
+	}
+	s.log.Warnf("dropping %d undelivered pushes to %s from a full queue", len(ps), pid)
+	if h := s.net.conf.PushExpiredHandler; h != nil {
+		for _, p := range ps {
+			h(pid, p.req.Body.ThreadID.ID, p.rid)
+		}
+	}
+}
+
+// expirePush drops a queued push and notifies the configured handler.
+func (s *server) expirePush(pid peer.ID, p *pendingPush) {
+	s.log.Warnf("dropping undelivered push to %s after %d attempts", pid, p.attempts)
+	s.outbound.remove(pid, p)
+	if h := s.net.conf.PushExpiredHandler; h != nil {
+		h(pid, p.req.Body.ThreadID.ID, p.rid)
+	}
+}
@@ -30,6 +30,9 @@ type Header struct {
 	PubKey *ProtoPubKey `protobuf:"bytes,1,opt,name=pubKey,proto3,customtype=ProtoPubKey" json:"pubKey,omitempty"`
 	// signature is the message signature.
 	Signature []byte `protobuf:"bytes,2,opt,name=signature,proto3" json:"signature,omitempty"`
+	// compact indicates that the sender accepts records in the compact format,
+	// which omits event nodes that can be rebuilt from their header and body.
+	Compact bool `protobuf:"varint,3,opt,name=compact,proto3" json:"compact,omitempty"`
 }
 
 func (m *Header) Reset()         { *m = Header{} }
@@ -72,6 +75,13 @@ func (m *Header) GetSignature() []byte {
 	return nil
 }
 
+func (m *Header) GetCompact() bool {
+	if m != nil {
+		return m.Compact
+	}
+	return false
+}
+
 // Log represents a thread log.
 type Log struct {
 	// ID of the log.
@@ -331,6 +341,345 @@ func (m *GetLogsReply) GetLogs() []*Log {
 	return nil
 }
 
+// GetLogsBatchRequest is used to request the logs of multiple threads.
+type GetLogsBatchRequest struct {
+	// header is the message header.
+	Header *Header `protobuf:"bytes,1,opt,name=header,proto3" json:"header,omitempty"`
+	// body is the message body.
+	Body *GetLogsBatchRequest_Body `protobuf:"bytes,2,opt,name=body,proto3" json:"body,omitempty"`
+}
+
+func (m *GetLogsBatchRequest) Reset()         { *m = GetLogsBatchRequest{} }
+func (m *GetLogsBatchRequest) String() string { return proto.CompactTextString(m) }
+func (*GetLogsBatchRequest) ProtoMessage()    {}
+func (*GetLogsBatchRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a5b10ce944527a32, []int{4}
+}
+func (m *GetLogsBatchRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *GetLogsBatchRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_GetLogsBatchRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *GetLogsBatchRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetLogsBatchRequest.Merge(m, src)
+}
+func (m *GetLogsBatchRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *GetLogsBatchRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetLogsBatchRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetLogsBatchRequest proto.InternalMessageInfo
+
+func (m *GetLogsBatchRequest) GetHeader() *Header {
+	if m != nil {
+		return m.Header
+	}
+	return nil
+}
+
+func (m *GetLogsBatchRequest) GetBody() *GetLogsBatchRequest_Body {
+	if m != nil {
+		return m.Body
+	}
+	return nil
+}
+
+type GetLogsBatchRequest_Body struct {
+	// threads are the target threads, each with its service key.
+	Threads []*GetLogsRequest_Body `protobuf:"bytes,1,rep,name=threads,proto3" json:"threads,omitempty"`
+}
+
+func (m *GetLogsBatchRequest_Body) Reset()         { *m = GetLogsBatchRequest_Body{} }
+func (m *GetLogsBatchRequest_Body) String() string { return proto.CompactTextString(m) }
+func (*GetLogsBatchRequest_Body) ProtoMessage()    {}
+func (*GetLogsBatchRequest_Body) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a5b10ce944527a32, []int{4, 0}
+}
+func (m *GetLogsBatchRequest_Body) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *GetLogsBatchRequest_Body) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_GetLogsBatchRequest_Body.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *GetLogsBatchRequest_Body) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetLogsBatchRequest_Body.Merge(m, src)
+}
+func (m *GetLogsBatchRequest_Body) XXX_Size() int {
+	return m.Size()
+}
+func (m *GetLogsBatchRequest_Body) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetLogsBatchRequest_Body.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetLogsBatchRequest_Body proto.InternalMessageInfo
+
+func (m *GetLogsBatchRequest_Body) GetThreads() []*GetLogsRequest_Body {
+	if m != nil {
+		return m.Threads
+	}
+	return nil
+}
+
+// GetLogsBatchReply is the response from a GetLogsBatchRequest.
+type GetLogsBatchReply struct {
+	// threads are the results of the request, in request order.
+	Threads []*GetLogsBatchReply_ThreadEntry `protobuf:"bytes,1,rep,name=threads,proto3" json:"threads,omitempty"`
+}
+
+func (m *GetLogsBatchReply) Reset()         { *m = GetLogsBatchReply{} }
+func (m *GetLogsBatchReply) String() string { return proto.CompactTextString(m) }
+func (*GetLogsBatchReply) ProtoMessage()    {}
+func (*GetLogsBatchReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a5b10ce944527a32, []int{5}
+}
+func (m *GetLogsBatchReply) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *GetLogsBatchReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_GetLogsBatchReply.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *GetLogsBatchReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetLogsBatchReply.Merge(m, src)
+}
+func (m *GetLogsBatchReply) XXX_Size() int {
+	return m.Size()
+}
+func (m *GetLogsBatchReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetLogsBatchReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetLogsBatchReply proto.InternalMessageInfo
+
+func (m *GetLogsBatchReply) GetThreads() []*GetLogsBatchReply_ThreadEntry {
+	if m != nil {
+		return m.Threads
+	}
+	return nil
+}
+
+// ThreadEntry represents the logs of a single thread.
+type GetLogsBatchReply_ThreadEntry struct {
+	// threadID of this entry.
+	ThreadID *ProtoThreadID `protobuf:"bytes,1,opt,name=threadID,proto3,customtype=ProtoThreadID" json:"threadID,omitempty"`
+	// logs of the thread.
+	Logs []*Log `protobuf:"bytes,2,rep,name=logs,proto3" json:"logs,omitempty"`
+	// error is set if the logs of the thread could not be returned.
+	Error string `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+}
+
+func (m *GetLogsBatchReply_ThreadEntry) Reset()         { *m = GetLogsBatchReply_ThreadEntry{} }
+func (m *GetLogsBatchReply_ThreadEntry) String() string { return proto.CompactTextString(m) }
+func (*GetLogsBatchReply_ThreadEntry) ProtoMessage()    {}
+func (*GetLogsBatchReply_ThreadEntry) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a5b10ce944527a32, []int{5, 0}
+}
+func (m *GetLogsBatchReply_ThreadEntry) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *GetLogsBatchReply_ThreadEntry) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_GetLogsBatchReply_ThreadEntry.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *GetLogsBatchReply_ThreadEntry) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetLogsBatchReply_ThreadEntry.Merge(m, src)
+}
+func (m *GetLogsBatchReply_ThreadEntry) XXX_Size() int {
+	return m.Size()
+}
+func (m *GetLogsBatchReply_ThreadEntry) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetLogsBatchReply_ThreadEntry.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetLogsBatchReply_ThreadEntry proto.InternalMessageInfo
+
+func (m *GetLogsBatchReply_ThreadEntry) GetLogs() []*Log {
+	if m != nil {
+		return m.Logs
+	}
+	return nil
+}
+
+func (m *GetLogsBatchReply_ThreadEntry) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+// GetHeadsRequest is used to request the heads of a log.
+type GetHeadsRequest struct {
+	// header is the message header.
+	Header *Header `protobuf:"bytes,1,opt,name=header,proto3" json:"header,omitempty"`
+	// body is the message body.
+	Body *GetHeadsRequest_Body `protobuf:"bytes,2,opt,name=body,proto3" json:"body,omitempty"`
+}
+
+func (m *GetHeadsRequest) Reset()         { *m = GetHeadsRequest{} }
+func (m *GetHeadsRequest) String() string { return proto.CompactTextString(m) }
+func (*GetHeadsRequest) ProtoMessage()    {}
+func (*GetHeadsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a5b10ce944527a32, []int{6}
+}
+func (m *GetHeadsRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *GetHeadsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_GetHeadsRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *GetHeadsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetHeadsRequest.Merge(m, src)
+}
+func (m *GetHeadsRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *GetHeadsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetHeadsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetHeadsRequest proto.InternalMessageInfo
+
+func (m *GetHeadsRequest) GetHeader() *Header {
+	if m != nil {
+		return m.Header
+	}
+	return nil
+}
+
+func (m *GetHeadsRequest) GetBody() *GetHeadsRequest_Body {
+	if m != nil {
+		return m.Body
+	}
+	return nil
+}
+
+type GetHeadsRequest_Body struct {
+	// threadID is the target thread's ID.
+	ThreadID *ProtoThreadID `protobuf:"bytes,1,opt,name=threadID,proto3,customtype=ProtoThreadID" json:"threadID,omitempty"`
+	// serviceKey for the thread.
+	ServiceKey *ProtoKey `protobuf:"bytes,2,opt,name=serviceKey,proto3,customtype=ProtoKey" json:"serviceKey,omitempty"`
+	// logID is the target log's ID.
+	LogID *ProtoPeerID `protobuf:"bytes,3,opt,name=logID,proto3,customtype=ProtoPeerID" json:"logID,omitempty"`
+}
+
+func (m *GetHeadsRequest_Body) Reset()         { *m = GetHeadsRequest_Body{} }
+func (m *GetHeadsRequest_Body) String() string { return proto.CompactTextString(m) }
+func (*GetHeadsRequest_Body) ProtoMessage()    {}
+func (*GetHeadsRequest_Body) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a5b10ce944527a32, []int{6, 0}
+}
+func (m *GetHeadsRequest_Body) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *GetHeadsRequest_Body) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_GetHeadsRequest_Body.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *GetHeadsRequest_Body) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetHeadsRequest_Body.Merge(m, src)
+}
+func (m *GetHeadsRequest_Body) XXX_Size() int {
+	return m.Size()
+}
+func (m *GetHeadsRequest_Body) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetHeadsRequest_Body.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetHeadsRequest_Body proto.InternalMessageInfo
+
+// GetHeadsReply is the response from a GetHeadsRequest.
+type GetHeadsReply struct {
+	// heads of the log. Empty if the log has no records.
+	Heads []ProtoCid `protobuf:"bytes,1,rep,name=heads,proto3,customtype=ProtoCid" json:"heads,omitempty"`
+}
+
+func (m *GetHeadsReply) Reset()         { *m = GetHeadsReply{} }
+func (m *GetHeadsReply) String() string { return proto.CompactTextString(m) }
+func (*GetHeadsReply) ProtoMessage()    {}
+func (*GetHeadsReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a5b10ce944527a32, []int{7}
+}
+func (m *GetHeadsReply) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *GetHeadsReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_GetHeadsReply.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *GetHeadsReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetHeadsReply.Merge(m, src)
+}
+func (m *GetHeadsReply) XXX_Size() int {
+	return m.Size()
+}
+func (m *GetHeadsReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetHeadsReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetHeadsReply proto.InternalMessageInfo
+
 // PushLogRequest is used to push a thread log to a peer.
 type PushLogRequest struct {
 	// header is the message header.
@@ -343,7 +692,7 @@ func (m *PushLogRequest) Reset()         { *m = PushLogRequest{} }
 func (m *PushLogRequest) String() string { return proto.CompactTextString(m) }
 func (*PushLogRequest) ProtoMessage()    {}
 func (*PushLogRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_a5b10ce944527a32, []int{4}
+	return fileDescriptor_a5b10ce944527a32, []int{8}
 }
 func (m *PushLogRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -395,13 +744,15 @@ type PushLogRequest_Body struct {
 	ReadKey *ProtoKey `protobuf:"bytes,3,opt,name=readKey,proto3,customtype=ProtoKey" json:"readKey,omitempty"`
 	// log is the actual log payload.
 	Log *Log `protobuf:"bytes,4,opt,name=log,proto3" json:"log,omitempty"`
+	// keyChange authorizes replacing the thread key with the keys above.
+	KeyChange *PushLogRequest_KeyChange `protobuf:"bytes,5,opt,name=keyChange,proto3" json:"keyChange,omitempty"`
 }
 
 func (m *PushLogRequest_Body) Reset()         { *m = PushLogRequest_Body{} }
 func (m *PushLogRequest_Body) String() string { return proto.CompactTextString(m) }
 func (*PushLogRequest_Body) ProtoMessage()    {}
 func (*PushLogRequest_Body) Descriptor() ([]byte, []int) {
-	return fileDescriptor_a5b10ce944527a32, []int{4, 0}
+	return fileDescriptor_a5b10ce944527a32, []int{8, 0}
 }
 func (m *PushLogRequest_Body) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -437,6 +788,65 @@ func (m *PushLogRequest_Body) GetLog() *Log {
 	return nil
 }
 
+func (m *PushLogRequest_Body) GetKeyChange() *PushLogRequest_KeyChange {
+	if m != nil {
+		return m.KeyChange
+	}
+	return nil
+}
+
+// KeyChange is a signature over a thread key change by the private key
+// of a log already in the thread.
+type PushLogRequest_KeyChange struct {
+	// logID is the ID of the signing log.
+	LogID *ProtoPeerID `protobuf:"bytes,1,opt,name=logID,proto3,customtype=ProtoPeerID" json:"logID,omitempty"`
+	// signature covers the thread ID, the replaced and new keys, and
+	// the revoked logs.
+	Signature []byte `protobuf:"bytes,2,opt,name=signature,proto3" json:"signature,omitempty"`
+	// revoked are the logs of members that lost access with the change.
+	Revoked []ProtoPeerID `protobuf:"bytes,3,rep,name=revoked,proto3,customtype=ProtoPeerID" json:"revoked,omitempty"`
+}
+
+func (m *PushLogRequest_KeyChange) Reset()         { *m = PushLogRequest_KeyChange{} }
+func (m *PushLogRequest_KeyChange) String() string { return proto.CompactTextString(m) }
+func (*PushLogRequest_KeyChange) ProtoMessage()    {}
+func (*PushLogRequest_KeyChange) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a5b10ce944527a32, []int{8, 1}
+}
+func (m *PushLogRequest_KeyChange) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *PushLogRequest_KeyChange) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_PushLogRequest_KeyChange.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *PushLogRequest_KeyChange) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PushLogRequest_KeyChange.Merge(m, src)
+}
+func (m *PushLogRequest_KeyChange) XXX_Size() int {
+	return m.Size()
+}
+func (m *PushLogRequest_KeyChange) XXX_DiscardUnknown() {
+	xxx_messageInfo_PushLogRequest_KeyChange.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PushLogRequest_KeyChange proto.InternalMessageInfo
+
+func (m *PushLogRequest_KeyChange) GetSignature() []byte {
+	if m != nil {
+		return m.Signature
+	}
+	return nil
+}
+
 // PushLogReply is the response from a PushLogRequest.
 type PushLogReply struct {
 }
@@ -445,7 +855,7 @@ func (m *PushLogReply) Reset()         { *m = PushLogReply{} }
 func (m *PushLogReply) String() string { return proto.CompactTextString(m) }
 func (*PushLogReply) ProtoMessage()    {}
 func (*PushLogReply) Descriptor() ([]byte, []int) {
-	return fileDescriptor_a5b10ce944527a32, []int{5}
+	return fileDescriptor_a5b10ce944527a32, []int{9}
 }
 func (m *PushLogReply) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -486,7 +896,7 @@ func (m *GetRecordsRequest) Reset()         { *m = GetRecordsRequest{} }
 func (m *GetRecordsRequest) String() string { return proto.CompactTextString(m) }
 func (*GetRecordsRequest) ProtoMessage()    {}
 func (*GetRecordsRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_a5b10ce944527a32, []int{6}
+	return fileDescriptor_a5b10ce944527a32, []int{10}
 }
 func (m *GetRecordsRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -536,13 +946,15 @@ type GetRecordsRequest_Body struct {
 	ServiceKey *ProtoKey `protobuf:"bytes,2,opt,name=serviceKey,proto3,customtype=ProtoKey" json:"serviceKey,omitempty"`
 	// List of requested logs.
 	Logs []*GetRecordsRequest_Body_LogEntry `protobuf:"bytes,3,rep,name=logs,proto3" json:"logs,omitempty"`
+	// proof requests a signed manifest for each log in the reply.
+	Proof bool `protobuf:"varint,4,opt,name=proof,proto3" json:"proof,omitempty"`
 }
 
 func (m *GetRecordsRequest_Body) Reset()         { *m = GetRecordsRequest_Body{} }
 func (m *GetRecordsRequest_Body) String() string { return proto.CompactTextString(m) }
 func (*GetRecordsRequest_Body) ProtoMessage()    {}
 func (*GetRecordsRequest_Body) Descriptor() ([]byte, []int) {
-	return fileDescriptor_a5b10ce944527a32, []int{6, 0}
+	return fileDescriptor_a5b10ce944527a32, []int{10, 0}
 }
 func (m *GetRecordsRequest_Body) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -578,7 +990,14 @@ func (m *GetRecordsRequest_Body) GetLogs() []*GetRecordsRequest_Body_LogEntry {
 	return nil
 }
 
-// LogEntry represents a single log.
+func (m *GetRecordsRequest_Body) GetProof() bool {
+	if m != nil {
+		return m.Proof
+	}
+	return false
+}
+
+// LogEntry represents a single log.
 type GetRecordsRequest_Body_LogEntry struct {
 	// logID of this entry.
 	LogID *ProtoPeerID `protobuf:"bytes,1,opt,name=logID,proto3,customtype=ProtoPeerID" json:"logID,omitempty"`
@@ -586,13 +1005,15 @@ type GetRecordsRequest_Body_LogEntry struct {
 	Offset *ProtoCid `protobuf:"bytes,2,opt,name=offset,proto3,customtype=ProtoCid" json:"offset,omitempty"`
 	// limit indicates the max number of records to return.
 	Limit int32 `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	// to is the last record to return. If undefined, records up to the log head are returned.
+	To *ProtoCid `protobuf:"bytes,4,opt,name=to,proto3,customtype=ProtoCid" json:"to,omitempty"`
 }
 
 func (m *GetRecordsRequest_Body_LogEntry) Reset()         { *m = GetRecordsRequest_Body_LogEntry{} }
 func (m *GetRecordsRequest_Body_LogEntry) String() string { return proto.CompactTextString(m) }
 func (*GetRecordsRequest_Body_LogEntry) ProtoMessage()    {}
 func (*GetRecordsRequest_Body_LogEntry) Descriptor() ([]byte, []int) {
-	return fileDescriptor_a5b10ce944527a32, []int{6, 0, 0}
+	return fileDescriptor_a5b10ce944527a32, []int{10, 0, 0}
 }
 func (m *GetRecordsRequest_Body_LogEntry) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -638,7 +1059,7 @@ func (m *GetRecordsReply) Reset()         { *m = GetRecordsReply{} }
 func (m *GetRecordsReply) String() string { return proto.CompactTextString(m) }
 func (*GetRecordsReply) ProtoMessage()    {}
 func (*GetRecordsReply) Descriptor() ([]byte, []int) {
-	return fileDescriptor_a5b10ce944527a32, []int{7}
+	return fileDescriptor_a5b10ce944527a32, []int{11}
 }
 func (m *GetRecordsReply) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -682,13 +1103,18 @@ type GetRecordsReply_LogEntry struct {
 	Records []*Log_Record `protobuf:"bytes,2,rep,name=records,proto3" json:"records,omitempty"`
 	// log contains new log info that was missing from the request.
 	Log *Log `protobuf:"bytes,3,opt,name=log,proto3" json:"log,omitempty"`
+	// manifest proves the records are the log's records up to its head.
+	Manifest *Manifest `protobuf:"bytes,4,opt,name=manifest,proto3" json:"manifest,omitempty"`
+	// truncated indicates that records were left out to stay within the
+	// server's pull limit. They are pulled with the last record as offset.
+	Truncated bool `protobuf:"varint,5,opt,name=truncated,proto3" json:"truncated,omitempty"`
 }
 
 func (m *GetRecordsReply_LogEntry) Reset()         { *m = GetRecordsReply_LogEntry{} }
 func (m *GetRecordsReply_LogEntry) String() string { return proto.CompactTextString(m) }
 func (*GetRecordsReply_LogEntry) ProtoMessage()    {}
 func (*GetRecordsReply_LogEntry) Descriptor() ([]byte, []int) {
-	return fileDescriptor_a5b10ce944527a32, []int{7, 0}
+	return fileDescriptor_a5b10ce944527a32, []int{11, 0}
 }
 func (m *GetRecordsReply_LogEntry) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -731,6 +1157,210 @@ func (m *GetRecordsReply_LogEntry) GetLog() *Log {
 	return nil
 }
 
+func (m *GetRecordsReply_LogEntry) GetManifest() *Manifest {
+	if m != nil {
+		return m.Manifest
+	}
+	return nil
+}
+
+func (m *GetRecordsReply_LogEntry) GetTruncated() bool {
+	if m != nil {
+		return m.Truncated
+	}
+	return false
+}
+
+// GetRecordsStreamReply is a single message of a GetRecordsStream reply.
+type GetRecordsStreamReply struct {
+	// logID of this message's log.
+	LogID *ProtoPeerID `protobuf:"bytes,1,opt,name=logID,proto3,customtype=ProtoPeerID" json:"logID,omitempty"`
+	// record is the next record in the log, oldest first.
+	Record *Log_Record `protobuf:"bytes,2,opt,name=record,proto3" json:"record,omitempty"`
+	// log contains new log info that was missing from the request. It is sent
+	// in a message without a record, before the log's records.
+	Log *Log `protobuf:"bytes,3,opt,name=log,proto3" json:"log,omitempty"`
+	// truncated is sent in a message without a record, after the log's
+	// records, if records were left out to stay within the server's pull limit.
+	Truncated bool `protobuf:"varint,5,opt,name=truncated,proto3" json:"truncated,omitempty"`
+}
+
+func (m *GetRecordsStreamReply) Reset()         { *m = GetRecordsStreamReply{} }
+func (m *GetRecordsStreamReply) String() string { return proto.CompactTextString(m) }
+func (*GetRecordsStreamReply) ProtoMessage()    {}
+func (*GetRecordsStreamReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a5b10ce944527a32, []int{12}
+}
+func (m *GetRecordsStreamReply) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *GetRecordsStreamReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_GetRecordsStreamReply.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *GetRecordsStreamReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetRecordsStreamReply.Merge(m, src)
+}
+func (m *GetRecordsStreamReply) XXX_Size() int {
+	return m.Size()
+}
+func (m *GetRecordsStreamReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetRecordsStreamReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetRecordsStreamReply proto.InternalMessageInfo
+
+func (m *GetRecordsStreamReply) GetRecord() *Log_Record {
+	if m != nil {
+		return m.Record
+	}
+	return nil
+}
+
+func (m *GetRecordsStreamReply) GetLog() *Log {
+	if m != nil {
+		return m.Log
+	}
+	return nil
+}
+
+func (m *GetRecordsStreamReply) GetTruncated() bool {
+	if m != nil {
+		return m.Truncated
+	}
+	return false
+}
+
+// Manifest is a signed summary of the records returned for a log.
+type Manifest struct {
+	// header is signed by the peer serving the records.
+	Header *Header `protobuf:"bytes,1,opt,name=header,proto3" json:"header,omitempty"`
+	// body is the manifest body.
+	Body *Manifest_Body `protobuf:"bytes,2,opt,name=body,proto3" json:"body,omitempty"`
+}
+
+func (m *Manifest) Reset()         { *m = Manifest{} }
+func (m *Manifest) String() string { return proto.CompactTextString(m) }
+func (*Manifest) ProtoMessage()    {}
+func (*Manifest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a5b10ce944527a32, []int{13}
+}
+func (m *Manifest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *Manifest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_Manifest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *Manifest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Manifest.Merge(m, src)
+}
+func (m *Manifest) XXX_Size() int {
+	return m.Size()
+}
+func (m *Manifest) XXX_DiscardUnknown() {
+	xxx_messageInfo_Manifest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Manifest proto.InternalMessageInfo
+
+func (m *Manifest) GetHeader() *Header {
+	if m != nil {
+		return m.Header
+	}
+	return nil
+}
+
+func (m *Manifest) GetBody() *Manifest_Body {
+	if m != nil {
+		return m.Body
+	}
+	return nil
+}
+
+type Manifest_Body struct {
+	// threadID is the thread's ID.
+	ThreadID *ProtoThreadID `protobuf:"bytes,1,opt,name=threadID,proto3,customtype=ProtoThreadID" json:"threadID,omitempty"`
+	// logID is the log's ID.
+	LogID *ProtoPeerID `protobuf:"bytes,2,opt,name=logID,proto3,customtype=ProtoPeerID" json:"logID,omitempty"`
+	// offset is the record after which the returned records start.
+	Offset *ProtoCid `protobuf:"bytes,3,opt,name=offset,proto3,customtype=ProtoCid" json:"offset,omitempty"`
+	// records are the cids of the returned records, oldest first. The last is the log head.
+	Records []ProtoCid `protobuf:"bytes,4,rep,name=records,proto3,customtype=ProtoCid" json:"records,omitempty"`
+	// headBlock is the event cid of the head record.
+	HeadBlock *ProtoCid `protobuf:"bytes,5,opt,name=headBlock,proto3,customtype=ProtoCid" json:"headBlock,omitempty"`
+	// headPrev is the previous record cid of the head record.
+	HeadPrev *ProtoCid `protobuf:"bytes,6,opt,name=headPrev,proto3,customtype=ProtoCid" json:"headPrev,omitempty"`
+	// headSig is the head record's signature by the log key.
+	HeadSig []byte `protobuf:"bytes,7,opt,name=headSig,proto3" json:"headSig,omitempty"`
+	// headPubKey is the head record's author key, which is signed instead
+	// of the block and previous record when the head is the first record.
+	HeadPubKey []byte `protobuf:"bytes,8,opt,name=headPubKey,proto3" json:"headPubKey,omitempty"`
+}
+
+func (m *Manifest_Body) Reset()         { *m = Manifest_Body{} }
+func (m *Manifest_Body) String() string { return proto.CompactTextString(m) }
+func (*Manifest_Body) ProtoMessage()    {}
+func (*Manifest_Body) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a5b10ce944527a32, []int{13, 0}
+}
+func (m *Manifest_Body) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *Manifest_Body) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_Manifest_Body.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *Manifest_Body) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Manifest_Body.Merge(m, src)
+}
+func (m *Manifest_Body) XXX_Size() int {
+	return m.Size()
+}
+func (m *Manifest_Body) XXX_DiscardUnknown() {
+	xxx_messageInfo_Manifest_Body.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Manifest_Body proto.InternalMessageInfo
+
+func (m *Manifest_Body) GetHeadSig() []byte {
+	if m != nil {
+		return m.HeadSig
+	}
+	return nil
+}
+
+func (m *Manifest_Body) GetHeadPubKey() []byte {
+	if m != nil {
+		return m.HeadPubKey
+	}
+	return nil
+}
+
 // PushRecordRequest is used to push a log record to a peer.
 type PushRecordRequest struct {
 	// header is the message header.
@@ -743,7 +1373,7 @@ func (m *PushRecordRequest) Reset()         { *m = PushRecordRequest{} }
 func (m *PushRecordRequest) String() string { return proto.CompactTextString(m) }
 func (*PushRecordRequest) ProtoMessage()    {}
 func (*PushRecordRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_a5b10ce944527a32, []int{8}
+	return fileDescriptor_a5b10ce944527a32, []int{14}
 }
 func (m *PushRecordRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -799,7 +1429,7 @@ func (m *PushRecordRequest_Body) Reset()         { *m = PushRecordRequest_Body{}
 func (m *PushRecordRequest_Body) String() string { return proto.CompactTextString(m) }
 func (*PushRecordRequest_Body) ProtoMessage()    {}
 func (*PushRecordRequest_Body) Descriptor() ([]byte, []int) {
-	return fileDescriptor_a5b10ce944527a32, []int{8, 0}
+	return fileDescriptor_a5b10ce944527a32, []int{14, 0}
 }
 func (m *PushRecordRequest_Body) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -843,7 +1473,7 @@ func (m *PushRecordReply) Reset()         { *m = PushRecordReply{} }
 func (m *PushRecordReply) String() string { return proto.CompactTextString(m) }
 func (*PushRecordReply) ProtoMessage()    {}
 func (*PushRecordReply) Descriptor() ([]byte, []int) {
-	return fileDescriptor_a5b10ce944527a32, []int{9}
+	return fileDescriptor_a5b10ce944527a32, []int{15}
 }
 func (m *PushRecordReply) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -872,777 +1502,1012 @@ func (m *PushRecordReply) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_PushRecordReply proto.InternalMessageInfo
 
-func init() {
-	proto.RegisterType((*Header)(nil), "net.pb.Header")
-	proto.RegisterType((*Log)(nil), "net.pb.Log")
-	proto.RegisterType((*Log_Record)(nil), "net.pb.Log.Record")
-	proto.RegisterType((*GetLogsRequest)(nil), "net.pb.GetLogsRequest")
-	proto.RegisterType((*GetLogsRequest_Body)(nil), "net.pb.GetLogsRequest.Body")
-	proto.RegisterType((*GetLogsReply)(nil), "net.pb.GetLogsReply")
-	proto.RegisterType((*PushLogRequest)(nil), "net.pb.PushLogRequest")
-	proto.RegisterType((*PushLogRequest_Body)(nil), "net.pb.PushLogRequest.Body")
-	proto.RegisterType((*PushLogReply)(nil), "net.pb.PushLogReply")
-	proto.RegisterType((*GetRecordsRequest)(nil), "net.pb.GetRecordsRequest")
-	proto.RegisterType((*GetRecordsRequest_Body)(nil), "net.pb.GetRecordsRequest.Body")
-	proto.RegisterType((*GetRecordsRequest_Body_LogEntry)(nil), "net.pb.GetRecordsRequest.Body.LogEntry")
-	proto.RegisterType((*GetRecordsReply)(nil), "net.pb.GetRecordsReply")
-	proto.RegisterType((*GetRecordsReply_LogEntry)(nil), "net.pb.GetRecordsReply.LogEntry")
-	proto.RegisterType((*PushRecordRequest)(nil), "net.pb.PushRecordRequest")
-	proto.RegisterType((*PushRecordRequest_Body)(nil), "net.pb.PushRecordRequest.Body")
-	proto.RegisterType((*PushRecordReply)(nil), "net.pb.PushRecordReply")
+// PushRecordsRequest is used to push a batch of records in a log to a peer.
+type PushRecordsRequest struct {
+	// header is the message header.
+	Header *Header `protobuf:"bytes,1,opt,name=header,proto3" json:"header,omitempty"`
+	// body is the message body.
+	Body *PushRecordsRequest_Body `protobuf:"bytes,2,opt,name=body,proto3" json:"body,omitempty"`
 }
 
-func init() { proto.RegisterFile("net.proto", fileDescriptor_a5b10ce944527a32) }
-
-var fileDescriptor_a5b10ce944527a32 = []byte{
-	// 760 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xbc, 0x56, 0xcd, 0x6e, 0xd3, 0x4c,
-	0x14, 0x8d, 0xed, 0xc4, 0x4d, 0x6f, 0xd2, 0x54, 0x19, 0x55, 0xdf, 0x17, 0x0c, 0x38, 0x91, 0x81,
-	0xb6, 0x42, 0x6d, 0x22, 0x05, 0x36, 0x88, 0x15, 0xa1, 0xa8, 0x54, 0x54, 0x50, 0x0d, 0xbc, 0x40,
-	0x12, 0x4f, 0x9d, 0x48, 0x6e, 0x26, 0xd8, 0x4e, 0x25, 0x6f, 0x90, 0x60, 0x03, 0x3b, 0x58, 0xb1,
-	0xe1, 0x0d, 0x78, 0x0a, 0x76, 0xb0, 0x42, 0x5d, 0xa2, 0x2c, 0x22, 0x48, 0x5f, 0x02, 0xb1, 0x42,
-	0x33, 0xe3, 0xf8, 0xa7, 0xf9, 0x91, 0x8a, 0xaa, 0xee, 0x3c, 0xf7, 0xdc, 0x7b, 0xe7, 0xcc, 0xb9,
-	0x67, 0x46, 0x86, 0xe5, 0x1e, 0xf1, 0xaa, 0x7d, 0x87, 0x7a, 0x14, 0xa9, 0xfc, 0xb3, 0xa5, 0x6d,
-	0x5b, 0x5d, 0xaf, 0x33, 0x68, 0x55, 0xdb, 0xf4, 0xa8, 0x66, 0x51, 0x8b, 0xd6, 0x38, 0xdc, 0x1a,
-	0x1c, 0xf2, 0x15, 0x5f, 0xf0, 0x2f, 0x51, 0x66, 0x3c, 0x03, 0xf5, 0x31, 0x69, 0x9a, 0xc4, 0x41,
-	0x1b, 0xa0, 0xf6, 0x07, 0xad, 0x27, 0xc4, 0x2f, 0x49, 0x15, 0x69, 0x33, 0xdf, 0x58, 0x1d, 0x8e,
-	0xca, 0xb9, 0x03, 0x96, 0x74, 0xc0, 0xc3, 0x38, 0x80, 0xd1, 0x35, 0x58, 0x76, 0xbb, 0x56, 0xaf,
-	0xe9, 0x0d, 0x1c, 0x52, 0x92, 0x59, 0x2e, 0x8e, 0x02, 0xc6, 0x27, 0x19, 0x94, 0x7d, 0x6a, 0xa1,
-	0x32, 0xc8, 0x7b, 0x3b, 0xd3, 0xad, 0x08, 0x71, 0xf6, 0x76, 0xb0, 0xbc, 0xb7, 0x13, 0xdb, 0x4f,
-	0x5e, 0xbc, 0xdf, 0x0d, 0xc8, 0x34, 0x4d, 0xd3, 0x71, 0x4b, 0x4a, 0x45, 0xd9, 0xcc, 0x37, 0x56,
-	0x86, 0xa3, 0xf2, 0x32, 0xcf, 0x7b, 0x60, 0x9a, 0x0e, 0x16, 0x18, 0xaa, 0x40, 0xba, 0x43, 0x9a,
-	0x66, 0x29, 0xcd, 0x7b, 0xe5, 0x87, 0xa3, 0x72, 0x96, 0xe7, 0x3c, 0xec, 0x9a, 0x98, 0x23, 0xda,
-	0x1b, 0x09, 0x54, 0x4c, 0xda, 0xd4, 0x31, 0x91, 0x0e, 0xe0, 0xf0, 0xaf, 0xa7, 0xd4, 0x24, 0x82,
-	0x23, 0x8e, 0x45, 0xd8, 0x09, 0xc9, 0x31, 0xe9, 0x79, 0x1c, 0x0e, 0x4e, 0x18, 0x06, 0x58, 0x75,
-	0x87, 0x4b, 0xc6, 0x61, 0x45, 0x54, 0x47, 0x11, 0xa4, 0x41, 0xb6, 0x45, 0x4d, 0x9f, 0xa3, 0x9c,
-	0x0e, 0x0e, 0xd7, 0xc6, 0x77, 0x09, 0x0a, 0xbb, 0xc4, 0xdb, 0xa7, 0x96, 0x8b, 0xc9, 0xcb, 0x01,
-	0x71, 0x3d, 0xb4, 0x0e, 0xaa, 0x28, 0xe6, 0x44, 0x72, 0xf5, 0x42, 0x55, 0x4c, 0xb2, 0x2a, 0xe6,
-	0x82, 0x03, 0x14, 0xd5, 0x20, 0xcd, 0xda, 0x70, 0x3e, 0xb9, 0xfa, 0xd5, 0x49, 0x56, 0xb2, 0x5b,
-	0xb5, 0x41, 0x4d, 0x1f, 0xf3, 0x44, 0xad, 0x0d, 0x69, 0xb6, 0x42, 0xdb, 0x90, 0xf5, 0x3a, 0x0e,
-	0x69, 0x9a, 0xe1, 0x3c, 0x8a, 0xc3, 0x51, 0x79, 0x85, 0xcb, 0xf3, 0x22, 0x00, 0x70, 0x98, 0x82,
-	0xb6, 0x00, 0x5c, 0xe2, 0x1c, 0x77, 0xdb, 0x24, 0x9a, 0x4d, 0xa4, 0x27, 0x1b, 0x4c, 0x0c, 0x37,
-	0x6a, 0x90, 0x0f, 0x19, 0xf4, 0x6d, 0x1f, 0x95, 0x21, 0x6d, 0x53, 0xcb, 0x2d, 0x49, 0x15, 0x65,
-	0x33, 0x57, 0xcf, 0x4d, 0x58, 0xee, 0x53, 0x0b, 0x73, 0xc0, 0xf8, 0x28, 0x43, 0xe1, 0x60, 0xe0,
-	0x76, 0x58, 0xe4, 0x62, 0x14, 0x48, 0x76, 0x8b, 0x2b, 0xf0, 0x59, 0xba, 0x04, 0x09, 0xd0, 0x3a,
-	0x2c, 0xb1, 0x3a, 0x96, 0xaa, 0xcc, 0x48, 0x9d, 0x80, 0xe8, 0x3a, 0x28, 0x36, 0xb5, 0xb8, 0x25,
-	0xce, 0x28, 0xc3, 0xe2, 0x46, 0x01, 0xf2, 0xe1, 0x49, 0xfa, 0xb6, 0x6f, 0xbc, 0x56, 0xa0, 0xb8,
-	0x4b, 0x3c, 0x61, 0xd9, 0x73, 0xbb, 0xa5, 0x9e, 0xd0, 0x4a, 0x8f, 0xb9, 0x25, 0xd9, 0x30, 0x2e,
-	0xd7, 0x7b, 0xf9, 0x32, 0xe4, 0xba, 0x1f, 0x38, 0x44, 0xe1, 0x0e, 0xd9, 0x58, 0xcc, 0x8c, 0xc9,
-	0xf3, 0xa8, 0xe7, 0x39, 0xbe, 0x70, 0x8f, 0x76, 0x04, 0xd9, 0x49, 0x04, 0xdd, 0x82, 0x8c, 0x4d,
-	0xad, 0xf9, 0x8f, 0x8c, 0x40, 0xd1, 0x4d, 0x50, 0xe9, 0xe1, 0xa1, 0x4b, 0xbc, 0x29, 0x66, 0xec,
-	0x6d, 0x08, 0x30, 0xb4, 0x06, 0x19, 0xbb, 0x7b, 0xd4, 0xf5, 0xf8, 0x08, 0x33, 0x58, 0x2c, 0x8c,
-	0xaf, 0x12, 0xac, 0xc6, 0x89, 0x31, 0x87, 0xdf, 0x4d, 0x38, 0xbc, 0x32, 0x8b, 0x7f, 0xdf, 0x9e,
-	0x22, 0xfe, 0xea, 0xfc, 0xc4, 0xb7, 0x98, 0xaf, 0x78, 0xc7, 0x92, 0xcc, 0xf7, 0x42, 0x31, 0xcf,
-	0x54, 0xc5, 0x66, 0x78, 0x92, 0x32, 0x71, 0x97, 0x32, 0xc7, 0x5d, 0x7f, 0x24, 0x28, 0x32, 0x7b,
-	0x05, 0x65, 0x17, 0xe3, 0xa6, 0xa9, 0x86, 0x71, 0x37, 0xbd, 0xfb, 0xc7, 0xcb, 0x17, 0xaa, 0x23,
-	0x2f, 0x54, 0xe7, 0x36, 0xa8, 0xe2, 0xe8, 0xc1, 0x91, 0x67, 0x89, 0x13, 0x64, 0x18, 0x45, 0x58,
-	0x8d, 0x53, 0xed, 0xdb, 0x7e, 0xfd, 0xad, 0x0c, 0x4b, 0xcf, 0x85, 0x29, 0xd1, 0x3d, 0x58, 0x0a,
-	0xde, 0x30, 0xf4, 0xdf, 0xec, 0x67, 0x55, 0x5b, 0x9b, 0x8a, 0xb3, 0x2b, 0x9a, 0x62, 0xa5, 0xc1,
-	0xa5, 0x8d, 0x4a, 0x93, 0xef, 0x51, 0x54, 0x9a, 0xb8, 0xdd, 0x29, 0xd4, 0x00, 0x88, 0x3c, 0x83,
-	0xae, 0xcc, 0xbd, 0x07, 0xda, 0xff, 0x73, 0x2c, 0x26, 0x7a, 0x44, 0x07, 0x8b, 0x7a, 0x4c, 0xcd,
-	0x25, 0xea, 0x71, 0x46, 0x07, 0x23, 0xd5, 0xa8, 0xfc, 0xfe, 0xa5, 0x4b, 0x5f, 0xc6, 0xba, 0xf4,
-	0x6d, 0xac, 0x4b, 0x27, 0x63, 0x5d, 0xfa, 0x39, 0xd6, 0xa5, 0x0f, 0xa7, 0x7a, 0xea, 0xe4, 0x54,
-	0x4f, 0xfd, 0x38, 0xd5, 0x53, 0x2d, 0x95, 0xff, 0x2a, 0xdc, 0xf9, 0x1b, 0x00, 0x00, 0xff, 0xff,
-	0x7c, 0xcb, 0xbb, 0x8b, 0x6e, 0x08, 0x00, 0x00,
+func (m *PushRecordsRequest) Reset()         { *m = PushRecordsRequest{} }
+func (m *PushRecordsRequest) String() string { return proto.CompactTextString(m) }
+func (*PushRecordsRequest) ProtoMessage()    {}
+func (*PushRecordsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a5b10ce944527a32, []int{16}
 }
-
-// Reference imports to suppress errors if they are not otherwise used.
-var _ context.Context
-var _ grpc.ClientConn
-
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the grpc package it is being compiled against.
-const _ = grpc.SupportPackageIsVersion4
-
-// ServiceClient is the client API for Service service.
-//
-// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
-type ServiceClient interface {
-	// GetLogs from a peer.
-	GetLogs(ctx context.Context, in *GetLogsRequest, opts ...grpc.CallOption) (*GetLogsReply, error)
-	// PushLog to a peer.
-	PushLog(ctx context.Context, in *PushLogRequest, opts ...grpc.CallOption) (*PushLogReply, error)
-	// GetRecords from a peer.
-	GetRecords(ctx context.Context, in *GetRecordsRequest, opts ...grpc.CallOption) (*GetRecordsReply, error)
-	// PushRecord to a peer.
-	PushRecord(ctx context.Context, in *PushRecordRequest, opts ...grpc.CallOption) (*PushRecordReply, error)
+func (m *PushRecordsRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
 }
-
-type serviceClient struct {
-	cc *grpc.ClientConn
+func (m *PushRecordsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_PushRecordsRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
 }
-
-func NewServiceClient(cc *grpc.ClientConn) ServiceClient {
-	return &serviceClient{cc}
+func (m *PushRecordsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PushRecordsRequest.Merge(m, src)
+}
+func (m *PushRecordsRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *PushRecordsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_PushRecordsRequest.DiscardUnknown(m)
 }
 
-func (c *serviceClient) GetLogs(ctx context.Context, in *GetLogsRequest, opts ...grpc.CallOption) (*GetLogsReply, error) {
-	out := new(GetLogsReply)
-	err := c.cc.Invoke(ctx, "/net.pb.Service/GetLogs", in, out, opts...)
-	if err != nil {
-		return nil, err
+var xxx_messageInfo_PushRecordsRequest proto.InternalMessageInfo
+
+func (m *PushRecordsRequest) GetHeader() *Header {
+	if m != nil {
+		return m.Header
 	}
-	return out, nil
+	return nil
 }
 
-func (c *serviceClient) PushLog(ctx context.Context, in *PushLogRequest, opts ...grpc.CallOption) (*PushLogReply, error) {
-	out := new(PushLogReply)
-	err := c.cc.Invoke(ctx, "/net.pb.Service/PushLog", in, out, opts...)
-	if err != nil {
-		return nil, err
+func (m *PushRecordsRequest) GetBody() *PushRecordsRequest_Body {
+	if m != nil {
+		return m.Body
 	}
-	return out, nil
+	return nil
 }
 
-func (c *serviceClient) GetRecords(ctx context.Context, in *GetRecordsRequest, opts ...grpc.CallOption) (*GetRecordsReply, error) {
-	out := new(GetRecordsReply)
-	err := c.cc.Invoke(ctx, "/net.pb.Service/GetRecords", in, out, opts...)
-	if err != nil {
-		return nil, err
-	}
-	return out, nil
+type PushRecordsRequest_Body struct {
+	// threadID is the target thread's ID.
+	ThreadID *ProtoThreadID `protobuf:"bytes,1,opt,name=threadID,proto3,customtype=ProtoThreadID" json:"threadID,omitempty"`
+	// logID is the target log's ID.
+	LogID *ProtoPeerID `protobuf:"bytes,2,opt,name=logID,proto3,customtype=ProtoPeerID" json:"logID,omitempty"`
+	// records are the actual record payloads, oldest first.
+	Records []*Log_Record `protobuf:"bytes,3,rep,name=records,proto3" json:"records,omitempty"`
 }
 
-func (c *serviceClient) PushRecord(ctx context.Context, in *PushRecordRequest, opts ...grpc.CallOption) (*PushRecordReply, error) {
-	out := new(PushRecordReply)
-	err := c.cc.Invoke(ctx, "/net.pb.Service/PushRecord", in, out, opts...)
-	if err != nil {
-		return nil, err
+func (m *PushRecordsRequest_Body) Reset()         { *m = PushRecordsRequest_Body{} }
+func (m *PushRecordsRequest_Body) String() string { return proto.CompactTextString(m) }
+func (*PushRecordsRequest_Body) ProtoMessage()    {}
+func (*PushRecordsRequest_Body) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a5b10ce944527a32, []int{16, 0}
+}
+func (m *PushRecordsRequest_Body) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *PushRecordsRequest_Body) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_PushRecordsRequest_Body.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
 	}
-	return out, nil
+}
+func (m *PushRecordsRequest_Body) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PushRecordsRequest_Body.Merge(m, src)
+}
+func (m *PushRecordsRequest_Body) XXX_Size() int {
+	return m.Size()
+}
+func (m *PushRecordsRequest_Body) XXX_DiscardUnknown() {
+	xxx_messageInfo_PushRecordsRequest_Body.DiscardUnknown(m)
 }
 
-// ServiceServer is the server API for Service service.
-type ServiceServer interface {
-	// GetLogs from a peer.
-	GetLogs(context.Context, *GetLogsRequest) (*GetLogsReply, error)
-	// PushLog to a peer.
-	PushLog(context.Context, *PushLogRequest) (*PushLogReply, error)
-	// GetRecords from a peer.
-	GetRecords(context.Context, *GetRecordsRequest) (*GetRecordsReply, error)
-	// PushRecord to a peer.
-	PushRecord(context.Context, *PushRecordRequest) (*PushRecordReply, error)
+var xxx_messageInfo_PushRecordsRequest_Body proto.InternalMessageInfo
+
+func (m *PushRecordsRequest_Body) GetRecords() []*Log_Record {
+	if m != nil {
+		return m.Records
+	}
+	return nil
 }
 
-func RegisterServiceServer(s *grpc.Server, srv ServiceServer) {
-	s.RegisterService(&_Service_serviceDesc, srv)
+// PushRecordsReply is the response from a PushRecordsRequest.
+type PushRecordsReply struct {
+	// results are the outcomes of the records, in request order.
+	Results []*PushRecordsReply_Result `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
 }
 
-func _Service_GetLogs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(GetLogsRequest)
-	if err := dec(in); err != nil {
-		return nil, err
-	}
-	if interceptor == nil {
-		return srv.(ServiceServer).GetLogs(ctx, in)
-	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/net.pb.Service/GetLogs",
-	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ServiceServer).GetLogs(ctx, req.(*GetLogsRequest))
-	}
-	return interceptor(ctx, in, info, handler)
+func (m *PushRecordsReply) Reset()         { *m = PushRecordsReply{} }
+func (m *PushRecordsReply) String() string { return proto.CompactTextString(m) }
+func (*PushRecordsReply) ProtoMessage()    {}
+func (*PushRecordsReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a5b10ce944527a32, []int{17}
 }
-
-func _Service_PushLog_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(PushLogRequest)
-	if err := dec(in); err != nil {
-		return nil, err
-	}
-	if interceptor == nil {
-		return srv.(ServiceServer).PushLog(ctx, in)
-	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/net.pb.Service/PushLog",
-	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ServiceServer).PushLog(ctx, req.(*PushLogRequest))
-	}
-	return interceptor(ctx, in, info, handler)
+func (m *PushRecordsReply) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
 }
-
-func _Service_GetRecords_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(GetRecordsRequest)
-	if err := dec(in); err != nil {
-		return nil, err
-	}
-	if interceptor == nil {
-		return srv.(ServiceServer).GetRecords(ctx, in)
-	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/net.pb.Service/GetRecords",
-	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ServiceServer).GetRecords(ctx, req.(*GetRecordsRequest))
+func (m *PushRecordsReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_PushRecordsReply.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
 	}
-	return interceptor(ctx, in, info, handler)
+}
+func (m *PushRecordsReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PushRecordsReply.Merge(m, src)
+}
+func (m *PushRecordsReply) XXX_Size() int {
+	return m.Size()
+}
+func (m *PushRecordsReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_PushRecordsReply.DiscardUnknown(m)
 }
 
-func _Service_PushRecord_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(PushRecordRequest)
-	if err := dec(in); err != nil {
-		return nil, err
-	}
-	if interceptor == nil {
-		return srv.(ServiceServer).PushRecord(ctx, in)
-	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/net.pb.Service/PushRecord",
-	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ServiceServer).PushRecord(ctx, req.(*PushRecordRequest))
+var xxx_messageInfo_PushRecordsReply proto.InternalMessageInfo
+
+func (m *PushRecordsReply) GetResults() []*PushRecordsReply_Result {
+	if m != nil {
+		return m.Results
 	}
-	return interceptor(ctx, in, info, handler)
+	return nil
 }
 
-var _Service_serviceDesc = grpc.ServiceDesc{
-	ServiceName: "net.pb.Service",
-	HandlerType: (*ServiceServer)(nil),
-	Methods: []grpc.MethodDesc{
-		{
-			MethodName: "GetLogs",
-			Handler:    _Service_GetLogs_Handler,
-		},
-		{
-			MethodName: "PushLog",
-			Handler:    _Service_PushLog_Handler,
-		},
-		{
-			MethodName: "GetRecords",
-			Handler:    _Service_GetRecords_Handler,
-		},
-		{
-			MethodName: "PushRecord",
-			Handler:    _Service_PushRecord_Handler,
-		},
-	},
-	Streams:  []grpc.StreamDesc{},
-	Metadata: "net.proto",
+// Result is the outcome of a single record.
+type PushRecordsReply_Result struct {
+	// code is the gRPC status code of the record. OK means the record was accepted.
+	Code int32 `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	// message describes why the record was not accepted.
+	Message string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
 }
 
-func (m *Header) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalTo(dAtA)
-	if err != nil {
-		return nil, err
-	}
-	return dAtA[:n], nil
+func (m *PushRecordsReply_Result) Reset()         { *m = PushRecordsReply_Result{} }
+func (m *PushRecordsReply_Result) String() string { return proto.CompactTextString(m) }
+func (*PushRecordsReply_Result) ProtoMessage()    {}
+func (*PushRecordsReply_Result) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a5b10ce944527a32, []int{17, 0}
 }
-
-func (m *Header) MarshalTo(dAtA []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	if m.PubKey != nil {
-		dAtA[i] = 0xa
-		i++
-		i = encodeVarintNet(dAtA, i, uint64(m.PubKey.Size()))
-		n1, err := m.PubKey.MarshalTo(dAtA[i:])
+func (m *PushRecordsReply_Result) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *PushRecordsReply_Result) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_PushRecordsReply_Result.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
 		if err != nil {
-			return 0, err
+			return nil, err
 		}
-		i += n1
+		return b[:n], nil
 	}
-	if len(m.Signature) > 0 {
-		dAtA[i] = 0x12
-		i++
-		i = encodeVarintNet(dAtA, i, uint64(len(m.Signature)))
-		i += copy(dAtA[i:], m.Signature)
+}
+func (m *PushRecordsReply_Result) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PushRecordsReply_Result.Merge(m, src)
+}
+func (m *PushRecordsReply_Result) XXX_Size() int {
+	return m.Size()
+}
+func (m *PushRecordsReply_Result) XXX_DiscardUnknown() {
+	xxx_messageInfo_PushRecordsReply_Result.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PushRecordsReply_Result proto.InternalMessageInfo
+
+func (m *PushRecordsReply_Result) GetCode() int32 {
+	if m != nil {
+		return m.Code
 	}
-	return i, nil
+	return 0
 }
 
-func (m *Log) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalTo(dAtA)
-	if err != nil {
-		return nil, err
+func (m *PushRecordsReply_Result) GetMessage() string {
+	if m != nil {
+		return m.Message
 	}
-	return dAtA[:n], nil
+	return ""
 }
 
-func (m *Log) MarshalTo(dAtA []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	if m.ID != nil {
-		dAtA[i] = 0xa
-		i++
-		i = encodeVarintNet(dAtA, i, uint64(m.ID.Size()))
-		n2, err := m.ID.MarshalTo(dAtA[i:])
+// SubscribeRequest subscribes a Subscribe stream to new records in a thread.
+type SubscribeRequest struct {
+	// header is the message header.
+	Header *Header `protobuf:"bytes,1,opt,name=header,proto3" json:"header,omitempty"`
+	// body is the message body.
+	Body *SubscribeRequest_Body `protobuf:"bytes,2,opt,name=body,proto3" json:"body,omitempty"`
+}
+
+func (m *SubscribeRequest) Reset()         { *m = SubscribeRequest{} }
+func (m *SubscribeRequest) String() string { return proto.CompactTextString(m) }
+func (*SubscribeRequest) ProtoMessage()    {}
+func (*SubscribeRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a5b10ce944527a32, []int{18}
+}
+func (m *SubscribeRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *SubscribeRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_SubscribeRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
 		if err != nil {
-			return 0, err
+			return nil, err
 		}
-		i += n2
+		return b[:n], nil
 	}
-	if m.PubKey != nil {
-		dAtA[i] = 0x12
-		i++
-		i = encodeVarintNet(dAtA, i, uint64(m.PubKey.Size()))
-		n3, err := m.PubKey.MarshalTo(dAtA[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += n3
+}
+func (m *SubscribeRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SubscribeRequest.Merge(m, src)
+}
+func (m *SubscribeRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *SubscribeRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SubscribeRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SubscribeRequest proto.InternalMessageInfo
+
+func (m *SubscribeRequest) GetHeader() *Header {
+	if m != nil {
+		return m.Header
 	}
-	if len(m.Addrs) > 0 {
-		for _, msg := range m.Addrs {
-			dAtA[i] = 0x1a
-			i++
-			i = encodeVarintNet(dAtA, i, uint64(msg.Size()))
-			n, err := msg.MarshalTo(dAtA[i:])
-			if err != nil {
-				return 0, err
-			}
-			i += n
-		}
+	return nil
+}
+
+func (m *SubscribeRequest) GetBody() *SubscribeRequest_Body {
+	if m != nil {
+		return m.Body
 	}
-	if m.Head != nil {
-		dAtA[i] = 0x22
-		i++
-		i = encodeVarintNet(dAtA, i, uint64(m.Head.Size()))
-		n4, err := m.Head.MarshalTo(dAtA[i:])
+	return nil
+}
+
+type SubscribeRequest_Body struct {
+	// threadID is the target thread's ID.
+	ThreadID *ProtoThreadID `protobuf:"bytes,1,opt,name=threadID,proto3,customtype=ProtoThreadID" json:"threadID,omitempty"`
+	// serviceKey for the thread.
+	ServiceKey *ProtoKey `protobuf:"bytes,2,opt,name=serviceKey,proto3,customtype=ProtoKey" json:"serviceKey,omitempty"`
+}
+
+func (m *SubscribeRequest_Body) Reset()         { *m = SubscribeRequest_Body{} }
+func (m *SubscribeRequest_Body) String() string { return proto.CompactTextString(m) }
+func (*SubscribeRequest_Body) ProtoMessage()    {}
+func (*SubscribeRequest_Body) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a5b10ce944527a32, []int{18, 0}
+}
+func (m *SubscribeRequest_Body) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *SubscribeRequest_Body) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_SubscribeRequest_Body.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
 		if err != nil {
-			return 0, err
+			return nil, err
 		}
-		i += n4
+		return b[:n], nil
 	}
-	return i, nil
+}
+func (m *SubscribeRequest_Body) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SubscribeRequest_Body.Merge(m, src)
+}
+func (m *SubscribeRequest_Body) XXX_Size() int {
+	return m.Size()
+}
+func (m *SubscribeRequest_Body) XXX_DiscardUnknown() {
+	xxx_messageInfo_SubscribeRequest_Body.DiscardUnknown(m)
 }
 
-func (m *Log_Record) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalTo(dAtA)
-	if err != nil {
-		return nil, err
-	}
-	return dAtA[:n], nil
+var xxx_messageInfo_SubscribeRequest_Body proto.InternalMessageInfo
+
+// SubscribeReply is a new record in a thread subscribed by a SubscribeRequest.
+type SubscribeReply struct {
+	// threadID of the record's thread.
+	ThreadID *ProtoThreadID `protobuf:"bytes,1,opt,name=threadID,proto3,customtype=ProtoThreadID" json:"threadID,omitempty"`
+	// logID of the record's log.
+	LogID *ProtoPeerID `protobuf:"bytes,2,opt,name=logID,proto3,customtype=ProtoPeerID" json:"logID,omitempty"`
+	// record is the new record.
+	Record *Log_Record `protobuf:"bytes,3,opt,name=record,proto3" json:"record,omitempty"`
 }
 
-func (m *Log_Record) MarshalTo(dAtA []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	if len(m.RecordNode) > 0 {
-		dAtA[i] = 0xa
-		i++
-		i = encodeVarintNet(dAtA, i, uint64(len(m.RecordNode)))
-		i += copy(dAtA[i:], m.RecordNode)
-	}
-	if len(m.EventNode) > 0 {
-		dAtA[i] = 0x12
-		i++
-		i = encodeVarintNet(dAtA, i, uint64(len(m.EventNode)))
-		i += copy(dAtA[i:], m.EventNode)
-	}
-	if len(m.HeaderNode) > 0 {
-		dAtA[i] = 0x1a
-		i++
-		i = encodeVarintNet(dAtA, i, uint64(len(m.HeaderNode)))
-		i += copy(dAtA[i:], m.HeaderNode)
-	}
-	if len(m.BodyNode) > 0 {
-		dAtA[i] = 0x22
-		i++
-		i = encodeVarintNet(dAtA, i, uint64(len(m.BodyNode)))
-		i += copy(dAtA[i:], m.BodyNode)
+func (m *SubscribeReply) Reset()         { *m = SubscribeReply{} }
+func (m *SubscribeReply) String() string { return proto.CompactTextString(m) }
+func (*SubscribeReply) ProtoMessage()    {}
+func (*SubscribeReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a5b10ce944527a32, []int{19}
+}
+func (m *SubscribeReply) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *SubscribeReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_SubscribeReply.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
 	}
-	return i, nil
+}
+func (m *SubscribeReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SubscribeReply.Merge(m, src)
+}
+func (m *SubscribeReply) XXX_Size() int {
+	return m.Size()
+}
+func (m *SubscribeReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_SubscribeReply.DiscardUnknown(m)
 }
 
-func (m *GetLogsRequest) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalTo(dAtA)
-	if err != nil {
-		return nil, err
+var xxx_messageInfo_SubscribeReply proto.InternalMessageInfo
+
+func (m *SubscribeReply) GetRecord() *Log_Record {
+	if m != nil {
+		return m.Record
 	}
-	return dAtA[:n], nil
+	return nil
 }
 
-func (m *GetLogsRequest) MarshalTo(dAtA []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	if m.Header != nil {
-		dAtA[i] = 0xa
-		i++
-		i = encodeVarintNet(dAtA, i, uint64(m.Header.Size()))
-		n5, err := m.Header.MarshalTo(dAtA[i:])
+// PingRequest is used to check that a connection to a peer is alive.
+type PingRequest struct {
+}
+
+func (m *PingRequest) Reset()         { *m = PingRequest{} }
+func (m *PingRequest) String() string { return proto.CompactTextString(m) }
+func (*PingRequest) ProtoMessage()    {}
+func (*PingRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a5b10ce944527a32, []int{20}
+}
+func (m *PingRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *PingRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_PingRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
 		if err != nil {
-			return 0, err
+			return nil, err
 		}
-		i += n5
+		return b[:n], nil
 	}
-	if m.Body != nil {
-		dAtA[i] = 0x12
-		i++
-		i = encodeVarintNet(dAtA, i, uint64(m.Body.Size()))
-		n6, err := m.Body.MarshalTo(dAtA[i:])
+}
+func (m *PingRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PingRequest.Merge(m, src)
+}
+func (m *PingRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *PingRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_PingRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PingRequest proto.InternalMessageInfo
+
+type PingReply struct {
+}
+
+func (m *PingReply) Reset()         { *m = PingReply{} }
+func (m *PingReply) String() string { return proto.CompactTextString(m) }
+func (*PingReply) ProtoMessage()    {}
+func (*PingReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a5b10ce944527a32, []int{21}
+}
+func (m *PingReply) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *PingReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_PingReply.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
 		if err != nil {
-			return 0, err
+			return nil, err
 		}
-		i += n6
+		return b[:n], nil
 	}
-	return i, nil
+}
+func (m *PingReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PingReply.Merge(m, src)
+}
+func (m *PingReply) XXX_Size() int {
+	return m.Size()
+}
+func (m *PingReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_PingReply.DiscardUnknown(m)
 }
 
-func (m *GetLogsRequest_Body) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalTo(dAtA)
+var xxx_messageInfo_PingReply proto.InternalMessageInfo
+
+func init() {
+	proto.RegisterType((*Header)(nil), "net.pb.Header")
+	proto.RegisterType((*Log)(nil), "net.pb.Log")
+	proto.RegisterType((*Log_Record)(nil), "net.pb.Log.Record")
+	proto.RegisterType((*GetLogsRequest)(nil), "net.pb.GetLogsRequest")
+	proto.RegisterType((*GetLogsRequest_Body)(nil), "net.pb.GetLogsRequest.Body")
+	proto.RegisterType((*GetLogsReply)(nil), "net.pb.GetLogsReply")
+	proto.RegisterType((*GetLogsBatchRequest)(nil), "net.pb.GetLogsBatchRequest")
+	proto.RegisterType((*GetLogsBatchRequest_Body)(nil), "net.pb.GetLogsBatchRequest.Body")
+	proto.RegisterType((*GetLogsBatchReply)(nil), "net.pb.GetLogsBatchReply")
+	proto.RegisterType((*GetLogsBatchReply_ThreadEntry)(nil), "net.pb.GetLogsBatchReply.ThreadEntry")
+	proto.RegisterType((*GetHeadsRequest)(nil), "net.pb.GetHeadsRequest")
+	proto.RegisterType((*GetHeadsRequest_Body)(nil), "net.pb.GetHeadsRequest.Body")
+	proto.RegisterType((*GetHeadsReply)(nil), "net.pb.GetHeadsReply")
+	proto.RegisterType((*PushLogRequest)(nil), "net.pb.PushLogRequest")
+	proto.RegisterType((*PushLogRequest_Body)(nil), "net.pb.PushLogRequest.Body")
+	proto.RegisterType((*PushLogRequest_KeyChange)(nil), "net.pb.PushLogRequest.KeyChange")
+	proto.RegisterType((*PushLogReply)(nil), "net.pb.PushLogReply")
+	proto.RegisterType((*GetRecordsRequest)(nil), "net.pb.GetRecordsRequest")
+	proto.RegisterType((*GetRecordsRequest_Body)(nil), "net.pb.GetRecordsRequest.Body")
+	proto.RegisterType((*GetRecordsRequest_Body_LogEntry)(nil), "net.pb.GetRecordsRequest.Body.LogEntry")
+	proto.RegisterType((*GetRecordsReply)(nil), "net.pb.GetRecordsReply")
+	proto.RegisterType((*GetRecordsReply_LogEntry)(nil), "net.pb.GetRecordsReply.LogEntry")
+	proto.RegisterType((*GetRecordsStreamReply)(nil), "net.pb.GetRecordsStreamReply")
+	proto.RegisterType((*Manifest)(nil), "net.pb.Manifest")
+	proto.RegisterType((*Manifest_Body)(nil), "net.pb.Manifest.Body")
+	proto.RegisterType((*PushRecordRequest)(nil), "net.pb.PushRecordRequest")
+	proto.RegisterType((*PushRecordRequest_Body)(nil), "net.pb.PushRecordRequest.Body")
+	proto.RegisterType((*PushRecordReply)(nil), "net.pb.PushRecordReply")
+	proto.RegisterType((*PushRecordsRequest)(nil), "net.pb.PushRecordsRequest")
+	proto.RegisterType((*PushRecordsRequest_Body)(nil), "net.pb.PushRecordsRequest.Body")
+	proto.RegisterType((*PushRecordsReply)(nil), "net.pb.PushRecordsReply")
+	proto.RegisterType((*PushRecordsReply_Result)(nil), "net.pb.PushRecordsReply.Result")
+	proto.RegisterType((*SubscribeRequest)(nil), "net.pb.SubscribeRequest")
+	proto.RegisterType((*SubscribeRequest_Body)(nil), "net.pb.SubscribeRequest.Body")
+	proto.RegisterType((*SubscribeReply)(nil), "net.pb.SubscribeReply")
+	proto.RegisterType((*PingRequest)(nil), "net.pb.PingRequest")
+	proto.RegisterType((*PingReply)(nil), "net.pb.PingReply")
+}
+
+func init() { proto.RegisterFile("net.proto", fileDescriptor_a5b10ce944527a32) }
+
+var fileDescriptor_a5b10ce944527a32 = []byte{
+	// 1382 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xbc, 0x58, 0x4b, 0x8f, 0x1b, 0x45,
+	0x10, 0x76, 0x7b, 0x6c, 0xef, 0xb8, 0xbc, 0xcf, 0xce, 0x6b, 0x32, 0x49, 0x6c, 0x6b, 0x20, 0x89,
+	0x13, 0x25, 0xde, 0xb0, 0x09, 0x48, 0x11, 0x2f, 0xe1, 0x6c, 0x94, 0xac, 0x12, 0x90, 0xd5, 0xcb,
+	0x1f, 0x18, 0x7b, 0x7a, 0xc7, 0x56, 0x6c, 0xb7, 0x99, 0x19, 0xaf, 0x64, 0x71, 0x43, 0x42, 0x42,
+	0x70, 0x41, 0x88, 0x13, 0x1c, 0x38, 0x72, 0xe7, 0xc0, 0x81, 0x13, 0x07, 0x0e, 0xc0, 0x01, 0x45,
+	0x70, 0x41, 0x7b, 0x58, 0xc1, 0xe6, 0xc6, 0x2f, 0x88, 0x38, 0x20, 0xd4, 0xdd, 0xf3, 0xf4, 0x6b,
+	0xd7, 0x51, 0xb2, 0xb7, 0xe9, 0xaa, 0xea, 0xea, 0xaa, 0xaf, 0x1e, 0x5d, 0x3d, 0x90, 0xef, 0x51,
+	0xaf, 0xda, 0x77, 0x98, 0xc7, 0x70, 0x4e, 0x7c, 0x36, 0xf4, 0xeb, 0x76, 0xdb, 0x6b, 0x0d, 0x1a,
+	0xd5, 0x26, 0xeb, 0xae, 0xdb, 0xcc, 0x66, 0xeb, 0x82, 0xdd, 0x18, 0xec, 0x88, 0x95, 0x58, 0x88,
+	0x2f, 0xb9, 0xcd, 0x68, 0x43, 0xee, 0x3e, 0x35, 0x2d, 0xea, 0xe0, 0xcb, 0x90, 0xeb, 0x0f, 0x1a,
+	0x0f, 0xe8, 0x50, 0x43, 0x65, 0x54, 0x59, 0xac, 0xad, 0xec, 0xed, 0x97, 0x0a, 0x75, 0x2e, 0x54,
+	0x17, 0x64, 0xe2, 0xb3, 0xf1, 0x79, 0xc8, 0xbb, 0x6d, 0xbb, 0x67, 0x7a, 0x03, 0x87, 0x6a, 0x69,
+	0x2e, 0x4b, 0x22, 0x02, 0xd6, 0x60, 0xa1, 0xc9, 0xba, 0x7d, 0xb3, 0xe9, 0x69, 0x4a, 0x19, 0x55,
+	0x54, 0x12, 0x2c, 0x8d, 0xaf, 0xd3, 0xa0, 0x3c, 0x64, 0x36, 0x2e, 0x41, 0x7a, 0x6b, 0x73, 0xfc,
+	0x10, 0x4a, 0x9d, 0xad, 0x4d, 0x92, 0xde, 0xda, 0x8c, 0x59, 0x92, 0x9e, 0x6d, 0xc9, 0x4b, 0x90,
+	0x35, 0x2d, 0xcb, 0x71, 0x35, 0xa5, 0xac, 0x54, 0x16, 0x6b, 0x4b, 0x7b, 0xfb, 0xa5, 0xbc, 0x90,
+	0x7b, 0xc7, 0xb2, 0x1c, 0x22, 0x79, 0xb8, 0x0c, 0x99, 0x16, 0x35, 0x2d, 0x2d, 0x23, 0x74, 0x2d,
+	0xee, 0xed, 0x97, 0x54, 0x21, 0x73, 0xa7, 0x6d, 0x11, 0xc1, 0xd1, 0x3f, 0x42, 0x90, 0x23, 0xb4,
+	0xc9, 0x1c, 0x0b, 0x17, 0x01, 0x1c, 0xf1, 0xf5, 0x1e, 0xb3, 0xa8, 0xb4, 0x91, 0xc4, 0x28, 0xdc,
+	0x77, 0xba, 0x4b, 0x7b, 0x9e, 0x60, 0xfb, 0xbe, 0x87, 0x04, 0xbe, 0xbb, 0x25, 0xc0, 0x14, 0x6c,
+	0x45, 0xee, 0x8e, 0x28, 0x58, 0x07, 0xb5, 0xc1, 0xac, 0xa1, 0xe0, 0x0a, 0x73, 0x48, 0xb8, 0x36,
+	0x7e, 0x43, 0xb0, 0x7c, 0x8f, 0x7a, 0x0f, 0x99, 0xed, 0x12, 0xfa, 0xc1, 0x80, 0xba, 0x1e, 0xbe,
+	0x04, 0x39, 0xb9, 0x59, 0x18, 0x52, 0xd8, 0x58, 0xae, 0xca, 0x18, 0x57, 0x65, 0xc4, 0x88, 0xcf,
+	0xc5, 0xeb, 0x90, 0xe1, 0x6a, 0x84, 0x3d, 0x85, 0x8d, 0x73, 0x81, 0x54, 0x52, 0x5b, 0xb5, 0xc6,
+	0xac, 0x21, 0x11, 0x82, 0x7a, 0x13, 0x32, 0x7c, 0x85, 0xaf, 0x83, 0xea, 0xb5, 0x1c, 0x6a, 0x5a,
+	0x61, 0x3c, 0xd6, 0xf6, 0xf6, 0x4b, 0x4b, 0x02, 0x9e, 0xf7, 0x7d, 0x06, 0x09, 0x45, 0xf0, 0x35,
+	0x00, 0x97, 0x3a, 0xbb, 0xed, 0x26, 0x8d, 0x62, 0x13, 0xe1, 0xc9, 0x03, 0x13, 0xe3, 0x1b, 0xeb,
+	0xb0, 0x18, 0x5a, 0xd0, 0xef, 0x0c, 0x71, 0x09, 0x32, 0x1d, 0x66, 0xbb, 0x1a, 0x2a, 0x2b, 0x95,
+	0xc2, 0x46, 0x21, 0xb0, 0xf2, 0x21, 0xb3, 0x89, 0x60, 0x18, 0xdf, 0x21, 0x38, 0xe1, 0xef, 0xa8,
+	0x99, 0x5e, 0xb3, 0x35, 0x2f, 0x0c, 0xb7, 0x12, 0x30, 0x94, 0x47, 0x60, 0x88, 0xab, 0x8c, 0x63,
+	0xf1, 0xa6, 0x8f, 0xc5, 0xab, 0xb0, 0x20, 0x1d, 0x0d, 0x2c, 0x9c, 0x89, 0x63, 0x20, 0x6b, 0xfc,
+	0x82, 0x60, 0x2d, 0x79, 0x02, 0xf7, 0xf5, 0xed, 0x51, 0x65, 0x17, 0x27, 0x5b, 0xd3, 0xef, 0x0c,
+	0xab, 0x12, 0xe8, 0xbb, 0x3d, 0xcf, 0x89, 0xd4, 0xea, 0x2e, 0x14, 0x62, 0xf4, 0x79, 0x03, 0x15,
+	0x40, 0x9d, 0x9e, 0x02, 0x35, 0x3e, 0x09, 0x59, 0xea, 0x38, 0xcc, 0x11, 0x39, 0x9a, 0x27, 0x72,
+	0x61, 0x3c, 0x45, 0xb0, 0x72, 0x8f, 0x7a, 0x1c, 0xd6, 0xb9, 0x73, 0xf0, 0x46, 0x02, 0xfc, 0xf3,
+	0x31, 0x77, 0xe3, 0xea, 0xe2, 0xc0, 0x7f, 0x8a, 0x8e, 0x21, 0x0b, 0xf1, 0x45, 0xc8, 0x76, 0x98,
+	0xbd, 0xb5, 0xa9, 0x29, 0xa3, 0xad, 0x44, 0xf6, 0x1b, 0xc9, 0x35, 0x6e, 0xc2, 0x52, 0x64, 0x2a,
+	0x8f, 0xa0, 0x01, 0xd9, 0x56, 0x18, 0xbf, 0xd1, 0xb6, 0x21, 0x59, 0xc6, 0x1f, 0x0a, 0x2c, 0xd7,
+	0x07, 0x6e, 0x8b, 0xe3, 0xfa, 0x7c, 0x4a, 0x36, 0xa9, 0x2d, 0x8e, 0xd6, 0x3f, 0xc7, 0x82, 0xd6,
+	0x25, 0x58, 0xe0, 0xfb, 0xb8, 0xa8, 0x32, 0x41, 0x34, 0x60, 0xe2, 0x0b, 0xa0, 0x74, 0x98, 0x2d,
+	0x7a, 0xd8, 0x48, 0x7e, 0x71, 0x3a, 0x7e, 0x0b, 0xf2, 0x8f, 0xe8, 0xf0, 0x4e, 0xcb, 0xec, 0xd9,
+	0x54, 0xcb, 0x26, 0xcb, 0x71, 0xc4, 0xc5, 0x07, 0x81, 0x1c, 0x89, 0xb6, 0xe8, 0x1f, 0x42, 0x3e,
+	0xa4, 0x47, 0x11, 0x44, 0xb3, 0x22, 0x78, 0xc8, 0xad, 0x74, 0x85, 0x3b, 0xb6, 0xcb, 0x1e, 0x51,
+	0xcb, 0xbf, 0x2b, 0xc6, 0xd4, 0x04, 0x7c, 0x63, 0x19, 0x16, 0x43, 0x1b, 0xfb, 0x9d, 0xa1, 0xf1,
+	0xbd, 0x22, 0x2a, 0x5c, 0x5e, 0x10, 0x73, 0xd7, 0xc5, 0x46, 0x22, 0xd0, 0xc5, 0x58, 0x5d, 0x24,
+	0x15, 0xc6, 0x63, 0xfd, 0x53, 0xfa, 0x38, 0x62, 0xfd, 0xba, 0xdf, 0x24, 0x14, 0xd1, 0x24, 0x2e,
+	0xcf, 0xb6, 0x8c, 0xc7, 0x56, 0xb6, 0xa8, 0xb0, 0x81, 0xf4, 0x1d, 0xc6, 0x76, 0x44, 0x0a, 0xa8,
+	0x44, 0x2e, 0xf4, 0xcf, 0x10, 0xa8, 0x81, 0xe0, 0x51, 0xe3, 0xf6, 0x32, 0xe4, 0xd8, 0xce, 0x8e,
+	0x4b, 0xbd, 0x31, 0x83, 0x79, 0xa5, 0xf9, 0x3c, 0x7e, 0x5e, 0xa7, 0xdd, 0x6d, 0xcb, 0x99, 0x22,
+	0x4b, 0xe4, 0x02, 0x9f, 0x87, 0xb4, 0xc7, 0x26, 0x5e, 0xec, 0x69, 0x8f, 0x19, 0x5f, 0xa6, 0x61,
+	0x25, 0xee, 0x0d, 0x2f, 0xeb, 0x5b, 0x89, 0x4b, 0xa8, 0x3c, 0xc9, 0x69, 0xde, 0x93, 0x93, 0xde,
+	0xea, 0xbf, 0x3e, 0x83, 0x5f, 0xd7, 0x78, 0xc6, 0x09, 0x95, 0x7e, 0x1b, 0xc6, 0xb1, 0x32, 0xa9,
+	0xca, 0xd3, 0x48, 0x20, 0x12, 0x14, 0x94, 0x32, 0xa5, 0xa0, 0xae, 0x81, 0xda, 0x35, 0x7b, 0xed,
+	0x1d, 0xea, 0x7a, 0x7e, 0xd1, 0xad, 0x06, 0x32, 0xef, 0xfa, 0x74, 0x12, 0x4a, 0xf0, 0x52, 0xf0,
+	0x9c, 0x41, 0xaf, 0x69, 0x7a, 0xd4, 0x12, 0xe5, 0xa7, 0x92, 0x88, 0x60, 0x7c, 0x8b, 0xe0, 0x54,
+	0xe4, 0xef, 0xb6, 0xe7, 0x50, 0xb3, 0x2b, 0xc1, 0x39, 0xa2, 0x67, 0x57, 0x21, 0x27, 0xcd, 0xf6,
+	0x93, 0x7a, 0x92, 0x63, 0xbe, 0xc4, 0x61, 0x7e, 0xcd, 0xb6, 0xf4, 0x2b, 0x05, 0xd4, 0xc0, 0xbd,
+	0x23, 0x17, 0xdc, 0x95, 0x44, 0xc1, 0x9d, 0x1a, 0x85, 0x29, 0x5e, 0x67, 0x3f, 0x3c, 0x63, 0x9d,
+	0x85, 0x38, 0xa5, 0x8f, 0x98, 0xd9, 0xca, 0x8c, 0xcc, 0xbe, 0x14, 0xe5, 0x49, 0x66, 0xc2, 0x55,
+	0x13, 0x66, 0xc8, 0x55, 0xc8, 0x73, 0x0f, 0x6b, 0x1d, 0xd6, 0x7c, 0x24, 0xa0, 0x1a, 0x95, 0x8c,
+	0xd8, 0xb8, 0x02, 0x2a, 0x5f, 0xd4, 0x1d, 0xba, 0xab, 0xe5, 0x26, 0x88, 0x86, 0x5c, 0x3e, 0xad,
+	0xf3, 0xef, 0xed, 0xb6, 0xad, 0x2d, 0x70, 0x41, 0x12, 0x2c, 0x83, 0x59, 0x56, 0x4e, 0xdc, 0x9a,
+	0x1a, 0xcd, 0xb2, 0x92, 0x62, 0xfc, 0x8b, 0x60, 0x8d, 0xf7, 0x49, 0x3f, 0xe0, 0xcf, 0xa7, 0x2d,
+	0x8e, 0x29, 0x8c, 0x87, 0xeb, 0x13, 0xf4, 0x42, 0xc3, 0x15, 0xa5, 0xb5, 0x72, 0x58, 0x5a, 0x1b,
+	0x6b, 0xb0, 0x12, 0x37, 0x95, 0x5f, 0x13, 0xff, 0x21, 0xc0, 0x11, 0x6d, 0xee, 0x7b, 0xe2, 0x66,
+	0x02, 0x90, 0xd2, 0x38, 0x20, 0xcf, 0x73, 0x84, 0x3a, 0x22, 0x22, 0xb1, 0x16, 0xa6, 0x1c, 0xda,
+	0xc2, 0x8c, 0x8f, 0x11, 0xac, 0x26, 0xcc, 0xe5, 0x2d, 0xe5, 0x36, 0x57, 0xe1, 0x0e, 0x3a, 0x5e,
+	0xd0, 0x72, 0x27, 0x7b, 0xc6, 0x7b, 0x2e, 0x11, 0x72, 0x24, 0x90, 0xd7, 0x5f, 0xe3, 0x8f, 0x32,
+	0xfe, 0x89, 0x31, 0x64, 0x9a, 0xc1, 0x73, 0x2c, 0x4b, 0xc4, 0x37, 0x4f, 0xdc, 0x2e, 0x75, 0x5d,
+	0xd3, 0x96, 0x97, 0x7d, 0x9e, 0x04, 0x4b, 0xe3, 0x77, 0x04, 0xab, 0xdb, 0x83, 0x86, 0xdb, 0x74,
+	0xda, 0x0d, 0x3a, 0x6f, 0x18, 0x5e, 0x49, 0x84, 0xe1, 0x42, 0x20, 0x35, 0xaa, 0xef, 0xd8, 0x1f,
+	0x53, 0x5f, 0x20, 0x58, 0x8e, 0x19, 0xd1, 0xef, 0xcc, 0x7d, 0xde, 0x0b, 0xa8, 0x82, 0x25, 0x28,
+	0xd4, 0xdb, 0xbd, 0x60, 0x94, 0x33, 0x0a, 0x90, 0x97, 0xcb, 0x7e, 0x67, 0xb8, 0xf1, 0x4d, 0x16,
+	0x16, 0xb6, 0xa5, 0xfd, 0x3c, 0x09, 0xfc, 0x67, 0x0f, 0x3e, 0x3d, 0xf9, 0x51, 0xa5, 0x9f, 0x1c,
+	0xa3, 0xf3, 0x9a, 0x4a, 0xe1, 0xfb, 0xb0, 0x18, 0x7f, 0x31, 0xe1, 0x73, 0x33, 0x5e, 0x75, 0xfa,
+	0xd9, 0xa9, 0x8f, 0x2c, 0x23, 0x85, 0xdf, 0x00, 0x35, 0x98, 0xf0, 0xf1, 0x99, 0x29, 0xcf, 0x13,
+	0xfd, 0xd4, 0x38, 0x43, 0xee, 0xbe, 0x0d, 0x0b, 0xfe, 0x50, 0x18, 0xb9, 0x90, 0x9c, 0x64, 0xf5,
+	0x93, 0x63, 0x74, 0xb9, 0xb5, 0x06, 0x10, 0x5d, 0xb7, 0xf8, 0xec, 0xd4, 0x39, 0x4b, 0x3f, 0x33,
+	0x65, 0x1a, 0x31, 0x52, 0xb8, 0x0e, 0xab, 0xa3, 0x57, 0xf6, 0x2c, 0x4d, 0x17, 0xc6, 0x59, 0xb1,
+	0x7b, 0xde, 0x48, 0xdd, 0x40, 0xdc, 0xaa, 0xa8, 0x02, 0x23, 0x5d, 0x63, 0x0d, 0x58, 0x3f, 0x33,
+	0x89, 0x25, 0xad, 0xba, 0x0b, 0x85, 0x88, 0xe8, 0x62, 0x7d, 0x7a, 0xd3, 0xd2, 0xb5, 0x69, 0x65,
+	0x6f, 0xa4, 0xf0, 0x1d, 0xc8, 0x87, 0xa9, 0x8d, 0xb5, 0x69, 0x25, 0xa7, 0x9f, 0x9e, 0xc0, 0x11,
+	0x0a, 0x2a, 0xe8, 0x06, 0xe2, 0xef, 0x4f, 0x9e, 0x7c, 0xf8, 0x44, 0x78, 0x50, 0x94, 0x99, 0xfa,
+	0x5a, 0x92, 0x28, 0x76, 0xd5, 0xca, 0x4f, 0xff, 0x2e, 0xa2, 0x1f, 0x0f, 0x8a, 0xe8, 0xe7, 0x83,
+	0x22, 0x7a, 0x7c, 0x50, 0x44, 0x7f, 0x1d, 0x14, 0xd1, 0xe7, 0x4f, 0x8a, 0xa9, 0xc7, 0x4f, 0x8a,
+	0xa9, 0x3f, 0x9f, 0x14, 0x53, 0x8d, 0x9c, 0xf8, 0x45, 0x76, 0xf3, 0xff, 0x01, 0x00, 0xd0, 0xbe,
+	0xd2, 0x2d, 0x66, 0x13, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// ServiceClient is the client API for Service service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type ServiceClient interface {
+	// GetLogs from a peer.
+	GetLogs(ctx context.Context, in *GetLogsRequest, opts ...grpc.CallOption) (*GetLogsReply, error)
+	// GetLogsBatch from a peer for multiple threads.
+	GetLogsBatch(ctx context.Context, in *GetLogsBatchRequest, opts ...grpc.CallOption) (*GetLogsBatchReply, error)
+	// GetHeads of a log from a peer.
+	GetHeads(ctx context.Context, in *GetHeadsRequest, opts ...grpc.CallOption) (*GetHeadsReply, error)
+	// PushLog to a peer.
+	PushLog(ctx context.Context, in *PushLogRequest, opts ...grpc.CallOption) (*PushLogReply, error)
+	// GetRecords from a peer.
+	GetRecords(ctx context.Context, in *GetRecordsRequest, opts ...grpc.CallOption) (*GetRecordsReply, error)
+	// GetRecordsStream from a peer, one record per message.
+	GetRecordsStream(ctx context.Context, in *GetRecordsRequest, opts ...grpc.CallOption) (Service_GetRecordsStreamClient, error)
+	// PushRecord to a peer.
+	PushRecord(ctx context.Context, in *PushRecordRequest, opts ...grpc.CallOption) (*PushRecordReply, error)
+	// PushRecords to a peer in a single request.
+	PushRecords(ctx context.Context, in *PushRecordsRequest, opts ...grpc.CallOption) (*PushRecordsReply, error)
+	// Subscribe to new records in threads, which are sent as they're stored.
+	Subscribe(ctx context.Context, opts ...grpc.CallOption) (Service_SubscribeClient, error)
+	// Ping a peer to check the connection to it.
+	Ping(ctx context.Context, in *PingRequest, opts ...grpc.CallOption) (*PingReply, error)
+}
+
+type serviceClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewServiceClient(cc *grpc.ClientConn) ServiceClient {
+	return &serviceClient{cc}
+}
+
+func (c *serviceClient) GetLogs(ctx context.Context, in *GetLogsRequest, opts ...grpc.CallOption) (*GetLogsReply, error) {
+	out := new(GetLogsReply)
+	err := c.cc.Invoke(ctx, "/net.pb.Service/GetLogs", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
-	return dAtA[:n], nil
+	return out, nil
 }
 
-func (m *GetLogsRequest_Body) MarshalTo(dAtA []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	if m.ThreadID != nil {
-		dAtA[i] = 0xa
-		i++
-		i = encodeVarintNet(dAtA, i, uint64(m.ThreadID.Size()))
-		n7, err := m.ThreadID.MarshalTo(dAtA[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += n7
-	}
-	if m.ServiceKey != nil {
-		dAtA[i] = 0x12
-		i++
-		i = encodeVarintNet(dAtA, i, uint64(m.ServiceKey.Size()))
-		n8, err := m.ServiceKey.MarshalTo(dAtA[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += n8
+func (c *serviceClient) GetLogsBatch(ctx context.Context, in *GetLogsBatchRequest, opts ...grpc.CallOption) (*GetLogsBatchReply, error) {
+	out := new(GetLogsBatchReply)
+	err := c.cc.Invoke(ctx, "/net.pb.Service/GetLogsBatch", in, out, opts...)
+	if err != nil {
+		return nil, err
 	}
-	return i, nil
+	return out, nil
 }
 
-func (m *GetLogsReply) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalTo(dAtA)
+func (c *serviceClient) GetHeads(ctx context.Context, in *GetHeadsRequest, opts ...grpc.CallOption) (*GetHeadsReply, error) {
+	out := new(GetHeadsReply)
+	err := c.cc.Invoke(ctx, "/net.pb.Service/GetHeads", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
-	return dAtA[:n], nil
+	return out, nil
 }
 
-func (m *GetLogsReply) MarshalTo(dAtA []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	if len(m.Logs) > 0 {
-		for _, msg := range m.Logs {
-			dAtA[i] = 0xa
-			i++
-			i = encodeVarintNet(dAtA, i, uint64(msg.Size()))
-			n, err := msg.MarshalTo(dAtA[i:])
-			if err != nil {
-				return 0, err
-			}
-			i += n
-		}
+func (c *serviceClient) PushLog(ctx context.Context, in *PushLogRequest, opts ...grpc.CallOption) (*PushLogReply, error) {
+	out := new(PushLogReply)
+	err := c.cc.Invoke(ctx, "/net.pb.Service/PushLog", in, out, opts...)
+	if err != nil {
+		return nil, err
 	}
-	return i, nil
+	return out, nil
 }
 
-func (m *PushLogRequest) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalTo(dAtA)
+func (c *serviceClient) GetRecords(ctx context.Context, in *GetRecordsRequest, opts ...grpc.CallOption) (*GetRecordsReply, error) {
+	out := new(GetRecordsReply)
+	err := c.cc.Invoke(ctx, "/net.pb.Service/GetRecords", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
-	return dAtA[:n], nil
+	return out, nil
 }
 
-func (m *PushLogRequest) MarshalTo(dAtA []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	if m.Header != nil {
-		dAtA[i] = 0xa
-		i++
-		i = encodeVarintNet(dAtA, i, uint64(m.Header.Size()))
-		n9, err := m.Header.MarshalTo(dAtA[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += n9
+func (c *serviceClient) GetRecordsStream(ctx context.Context, in *GetRecordsRequest, opts ...grpc.CallOption) (Service_GetRecordsStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Service_serviceDesc.Streams[0], "/net.pb.Service/GetRecordsStream", opts...)
+	if err != nil {
+		return nil, err
 	}
-	if m.Body != nil {
-		dAtA[i] = 0x12
-		i++
-		i = encodeVarintNet(dAtA, i, uint64(m.Body.Size()))
-		n10, err := m.Body.MarshalTo(dAtA[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += n10
+	x := &serviceGetRecordsStreamClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
 	}
-	return i, nil
-}
-
-func (m *PushLogRequest_Body) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalTo(dAtA)
-	if err != nil {
+	if err := x.ClientStream.CloseSend(); err != nil {
 		return nil, err
 	}
-	return dAtA[:n], nil
+	return x, nil
 }
 
-func (m *PushLogRequest_Body) MarshalTo(dAtA []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	if m.ThreadID != nil {
-		dAtA[i] = 0xa
-		i++
-		i = encodeVarintNet(dAtA, i, uint64(m.ThreadID.Size()))
-		n11, err := m.ThreadID.MarshalTo(dAtA[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += n11
-	}
-	if m.ServiceKey != nil {
-		dAtA[i] = 0x12
-		i++
-		i = encodeVarintNet(dAtA, i, uint64(m.ServiceKey.Size()))
-		n12, err := m.ServiceKey.MarshalTo(dAtA[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += n12
-	}
-	if m.ReadKey != nil {
-		dAtA[i] = 0x1a
-		i++
-		i = encodeVarintNet(dAtA, i, uint64(m.ReadKey.Size()))
-		n13, err := m.ReadKey.MarshalTo(dAtA[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += n13
-	}
-	if m.Log != nil {
-		dAtA[i] = 0x22
-		i++
-		i = encodeVarintNet(dAtA, i, uint64(m.Log.Size()))
-		n14, err := m.Log.MarshalTo(dAtA[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += n14
+type Service_GetRecordsStreamClient interface {
+	Recv() (*GetRecordsStreamReply, error)
+	grpc.ClientStream
+}
+
+type serviceGetRecordsStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *serviceGetRecordsStreamClient) Recv() (*GetRecordsStreamReply, error) {
+	m := new(GetRecordsStreamReply)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
 	}
-	return i, nil
+	return m, nil
 }
 
-func (m *PushLogReply) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalTo(dAtA)
+func (c *serviceClient) PushRecord(ctx context.Context, in *PushRecordRequest, opts ...grpc.CallOption) (*PushRecordReply, error) {
+	out := new(PushRecordReply)
+	err := c.cc.Invoke(ctx, "/net.pb.Service/PushRecord", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
-	return dAtA[:n], nil
+	return out, nil
 }
 
-func (m *PushLogReply) MarshalTo(dAtA []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	return i, nil
+func (c *serviceClient) PushRecords(ctx context.Context, in *PushRecordsRequest, opts ...grpc.CallOption) (*PushRecordsReply, error) {
+	out := new(PushRecordsReply)
+	err := c.cc.Invoke(ctx, "/net.pb.Service/PushRecords", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-func (m *GetRecordsRequest) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalTo(dAtA)
+func (c *serviceClient) Subscribe(ctx context.Context, opts ...grpc.CallOption) (Service_SubscribeClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Service_serviceDesc.Streams[1], "/net.pb.Service/Subscribe", opts...)
 	if err != nil {
 		return nil, err
 	}
-	return dAtA[:n], nil
+	x := &serviceSubscribeClient{stream}
+	return x, nil
 }
 
-func (m *GetRecordsRequest) MarshalTo(dAtA []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	if m.Header != nil {
-		dAtA[i] = 0xa
-		i++
-		i = encodeVarintNet(dAtA, i, uint64(m.Header.Size()))
-		n15, err := m.Header.MarshalTo(dAtA[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += n15
-	}
-	if m.Body != nil {
-		dAtA[i] = 0x12
-		i++
-		i = encodeVarintNet(dAtA, i, uint64(m.Body.Size()))
-		n16, err := m.Body.MarshalTo(dAtA[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += n16
+type Service_SubscribeClient interface {
+	Send(*SubscribeRequest) error
+	Recv() (*SubscribeReply, error)
+	grpc.ClientStream
+}
+
+type serviceSubscribeClient struct {
+	grpc.ClientStream
+}
+
+func (x *serviceSubscribeClient) Send(m *SubscribeRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *serviceSubscribeClient) Recv() (*SubscribeReply, error) {
+	m := new(SubscribeReply)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
 	}
-	return i, nil
+	return m, nil
 }
 
-func (m *GetRecordsRequest_Body) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalTo(dAtA)
+func (c *serviceClient) Ping(ctx context.Context, in *PingRequest, opts ...grpc.CallOption) (*PingReply, error) {
+	out := new(PingReply)
+	err := c.cc.Invoke(ctx, "/net.pb.Service/Ping", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
-	return dAtA[:n], nil
+	return out, nil
 }
 
-func (m *GetRecordsRequest_Body) MarshalTo(dAtA []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	if m.ThreadID != nil {
-		dAtA[i] = 0xa
-		i++
-		i = encodeVarintNet(dAtA, i, uint64(m.ThreadID.Size()))
-		n17, err := m.ThreadID.MarshalTo(dAtA[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += n17
+// ServiceServer is the server API for Service service.
+type ServiceServer interface {
+	// GetLogs from a peer.
+	GetLogs(context.Context, *GetLogsRequest) (*GetLogsReply, error)
+	// GetLogsBatch from a peer for multiple threads.
+	GetLogsBatch(context.Context, *GetLogsBatchRequest) (*GetLogsBatchReply, error)
+	// GetHeads of a log from a peer.
+	GetHeads(context.Context, *GetHeadsRequest) (*GetHeadsReply, error)
+	// PushLog to a peer.
+	PushLog(context.Context, *PushLogRequest) (*PushLogReply, error)
+	// GetRecords from a peer.
+	GetRecords(context.Context, *GetRecordsRequest) (*GetRecordsReply, error)
+	// GetRecordsStream from a peer, one record per message.
+	GetRecordsStream(*GetRecordsRequest, Service_GetRecordsStreamServer) error
+	// PushRecord to a peer.
+	PushRecord(context.Context, *PushRecordRequest) (*PushRecordReply, error)
+	// PushRecords to a peer in a single request.
+	PushRecords(context.Context, *PushRecordsRequest) (*PushRecordsReply, error)
+	// Subscribe to new records in threads, which are sent as they're stored.
+	Subscribe(Service_SubscribeServer) error
+	// Ping a peer to check the connection to it.
+	Ping(context.Context, *PingRequest) (*PingReply, error)
+}
+
+func RegisterServiceServer(s *grpc.Server, srv ServiceServer) {
+	s.RegisterService(&_Service_serviceDesc, srv)
+}
+
+func _Service_GetLogs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetLogsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
 	}
-	if m.ServiceKey != nil {
-		dAtA[i] = 0x12
-		i++
-		i = encodeVarintNet(dAtA, i, uint64(m.ServiceKey.Size()))
-		n18, err := m.ServiceKey.MarshalTo(dAtA[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += n18
+	if interceptor == nil {
+		return srv.(ServiceServer).GetLogs(ctx, in)
 	}
-	if len(m.Logs) > 0 {
-		for _, msg := range m.Logs {
-			dAtA[i] = 0x1a
-			i++
-			i = encodeVarintNet(dAtA, i, uint64(msg.Size()))
-			n, err := msg.MarshalTo(dAtA[i:])
-			if err != nil {
-				return 0, err
-			}
-			i += n
-		}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/net.pb.Service/GetLogs",
 	}
-	return i, nil
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).GetLogs(ctx, req.(*GetLogsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func (m *GetRecordsRequest_Body_LogEntry) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalTo(dAtA)
-	if err != nil {
+func _Service_GetLogsBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetLogsBatchRequest)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return dAtA[:n], nil
+	if interceptor == nil {
+		return srv.(ServiceServer).GetLogsBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/net.pb.Service/GetLogsBatch",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).GetLogsBatch(ctx, req.(*GetLogsBatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func (m *GetRecordsRequest_Body_LogEntry) MarshalTo(dAtA []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	if m.LogID != nil {
-		dAtA[i] = 0xa
-		i++
-		i = encodeVarintNet(dAtA, i, uint64(m.LogID.Size()))
-		n19, err := m.LogID.MarshalTo(dAtA[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += n19
+func _Service_GetHeads_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetHeadsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
 	}
-	if m.Offset != nil {
-		dAtA[i] = 0x12
-		i++
-		i = encodeVarintNet(dAtA, i, uint64(m.Offset.Size()))
-		n20, err := m.Offset.MarshalTo(dAtA[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += n20
+	if interceptor == nil {
+		return srv.(ServiceServer).GetHeads(ctx, in)
 	}
-	if m.Limit != 0 {
-		dAtA[i] = 0x18
-		i++
-		i = encodeVarintNet(dAtA, i, uint64(m.Limit))
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/net.pb.Service/GetHeads",
 	}
-	return i, nil
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).GetHeads(ctx, req.(*GetHeadsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func (m *GetRecordsReply) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalTo(dAtA)
-	if err != nil {
+func _Service_PushLog_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PushLogRequest)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return dAtA[:n], nil
+	if interceptor == nil {
+		return srv.(ServiceServer).PushLog(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/net.pb.Service/PushLog",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).PushLog(ctx, req.(*PushLogRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func (m *GetRecordsReply) MarshalTo(dAtA []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	if len(m.Logs) > 0 {
-		for _, msg := range m.Logs {
-			dAtA[i] = 0xa
-			i++
-			i = encodeVarintNet(dAtA, i, uint64(msg.Size()))
-			n, err := msg.MarshalTo(dAtA[i:])
-			if err != nil {
-				return 0, err
-			}
-			i += n
-		}
+func _Service_GetRecords_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRecordsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
 	}
-	return i, nil
+	if interceptor == nil {
+		return srv.(ServiceServer).GetRecords(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/net.pb.Service/GetRecords",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).GetRecords(ctx, req.(*GetRecordsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func (m *GetRecordsReply_LogEntry) Marshal() (dAtA []byte, err error) {
+func _Service_GetRecordsStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetRecordsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ServiceServer).GetRecordsStream(m, &serviceGetRecordsStreamServer{stream})
+}
+
+type Service_GetRecordsStreamServer interface {
+	Send(*GetRecordsStreamReply) error
+	grpc.ServerStream
+}
+
+type serviceGetRecordsStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *serviceGetRecordsStreamServer) Send(m *GetRecordsStreamReply) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Service_PushRecord_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PushRecordRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).PushRecord(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/net.pb.Service/PushRecord",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).PushRecord(ctx, req.(*PushRecordRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_PushRecords_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PushRecordsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).PushRecords(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/net.pb.Service/PushRecords",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).PushRecords(ctx, req.(*PushRecordsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_Subscribe_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ServiceServer).Subscribe(&serviceSubscribeServer{stream})
+}
+
+type Service_SubscribeServer interface {
+	Send(*SubscribeReply) error
+	Recv() (*SubscribeRequest, error)
+	grpc.ServerStream
+}
+
+type serviceSubscribeServer struct {
+	grpc.ServerStream
+}
+
+func (x *serviceSubscribeServer) Send(m *SubscribeReply) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *serviceSubscribeServer) Recv() (*SubscribeRequest, error) {
+	m := new(SubscribeRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _Service_Ping_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).Ping(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/net.pb.Service/Ping",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).Ping(ctx, req.(*PingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Service_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "net.pb.Service",
+	HandlerType: (*ServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetLogs",
+			Handler:    _Service_GetLogs_Handler,
+		},
+		{
+			MethodName: "GetLogsBatch",
+			Handler:    _Service_GetLogsBatch_Handler,
+		},
+		{
+			MethodName: "GetHeads",
+			Handler:    _Service_GetHeads_Handler,
+		},
+		{
+			MethodName: "PushLog",
+			Handler:    _Service_PushLog_Handler,
+		},
+		{
+			MethodName: "GetRecords",
+			Handler:    _Service_GetRecords_Handler,
+		},
+		{
+			MethodName: "PushRecord",
+			Handler:    _Service_PushRecord_Handler,
+		},
+		{
+			MethodName: "PushRecords",
+			Handler:    _Service_PushRecords_Handler,
+		},
+		{
+			MethodName: "Ping",
+			Handler:    _Service_Ping_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "GetRecordsStream",
+			Handler:       _Service_GetRecordsStream_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Subscribe",
+			Handler:       _Service_Subscribe_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "net.proto",
+}
+
+func (m *Header) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
 	n, err := m.MarshalTo(dAtA)
@@ -1652,47 +2517,41 @@ func (m *GetRecordsReply_LogEntry) Marshal() (dAtA []byte, err error) {
 	return dAtA[:n], nil
 }
 
-func (m *GetRecordsReply_LogEntry) MarshalTo(dAtA []byte) (int, error) {
+func (m *Header) MarshalTo(dAtA []byte) (int, error) {
 	var i int
 	_ = i
 	var l int
 	_ = l
-	if m.LogID != nil {
+	if m.PubKey != nil {
 		dAtA[i] = 0xa
 		i++
-		i = encodeVarintNet(dAtA, i, uint64(m.LogID.Size()))
-		n21, err := m.LogID.MarshalTo(dAtA[i:])
+		i = encodeVarintNet(dAtA, i, uint64(m.PubKey.Size()))
+		n1, err := m.PubKey.MarshalTo(dAtA[i:])
 		if err != nil {
 			return 0, err
 		}
-		i += n21
+		i += n1
 	}
-	if len(m.Records) > 0 {
-		for _, msg := range m.Records {
-			dAtA[i] = 0x12
-			i++
-			i = encodeVarintNet(dAtA, i, uint64(msg.Size()))
-			n, err := msg.MarshalTo(dAtA[i:])
-			if err != nil {
-				return 0, err
-			}
-			i += n
-		}
+	if len(m.Signature) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintNet(dAtA, i, uint64(len(m.Signature)))
+		i += copy(dAtA[i:], m.Signature)
 	}
-	if m.Log != nil {
-		dAtA[i] = 0x1a
+	if m.Compact {
+		dAtA[i] = 0x18
 		i++
-		i = encodeVarintNet(dAtA, i, uint64(m.Log.Size()))
-		n22, err := m.Log.MarshalTo(dAtA[i:])
-		if err != nil {
-			return 0, err
+		if m.Compact {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
 		}
-		i += n22
+		i++
 	}
 	return i, nil
 }
 
-func (m *PushRecordRequest) Marshal() (dAtA []byte, err error) {
+func (m *Log) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
 	n, err := m.MarshalTo(dAtA)
@@ -1702,35 +2561,57 @@ func (m *PushRecordRequest) Marshal() (dAtA []byte, err error) {
 	return dAtA[:n], nil
 }
 
-func (m *PushRecordRequest) MarshalTo(dAtA []byte) (int, error) {
+func (m *Log) MarshalTo(dAtA []byte) (int, error) {
 	var i int
 	_ = i
 	var l int
 	_ = l
-	if m.Header != nil {
+	if m.ID != nil {
 		dAtA[i] = 0xa
 		i++
-		i = encodeVarintNet(dAtA, i, uint64(m.Header.Size()))
-		n23, err := m.Header.MarshalTo(dAtA[i:])
+		i = encodeVarintNet(dAtA, i, uint64(m.ID.Size()))
+		n2, err := m.ID.MarshalTo(dAtA[i:])
 		if err != nil {
 			return 0, err
 		}
-		i += n23
+		i += n2
 	}
-	if m.Body != nil {
+	if m.PubKey != nil {
 		dAtA[i] = 0x12
 		i++
-		i = encodeVarintNet(dAtA, i, uint64(m.Body.Size()))
-		n24, err := m.Body.MarshalTo(dAtA[i:])
+		i = encodeVarintNet(dAtA, i, uint64(m.PubKey.Size()))
+		n3, err := m.PubKey.MarshalTo(dAtA[i:])
 		if err != nil {
 			return 0, err
 		}
-		i += n24
+		i += n3
+	}
+	if len(m.Addrs) > 0 {
+		for _, msg := range m.Addrs {
+			dAtA[i] = 0x1a
+			i++
+			i = encodeVarintNet(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	if m.Head != nil {
+		dAtA[i] = 0x22
+		i++
+		i = encodeVarintNet(dAtA, i, uint64(m.Head.Size()))
+		n4, err := m.Head.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n4
 	}
 	return i, nil
 }
 
-func (m *PushRecordRequest_Body) Marshal() (dAtA []byte, err error) {
+func (m *Log_Record) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
 	n, err := m.MarshalTo(dAtA)
@@ -1740,45 +2621,39 @@ func (m *PushRecordRequest_Body) Marshal() (dAtA []byte, err error) {
 	return dAtA[:n], nil
 }
 
-func (m *PushRecordRequest_Body) MarshalTo(dAtA []byte) (int, error) {
+func (m *Log_Record) MarshalTo(dAtA []byte) (int, error) {
 	var i int
 	_ = i
 	var l int
 	_ = l
-	if m.ThreadID != nil {
+	if len(m.RecordNode) > 0 {
 		dAtA[i] = 0xa
 		i++
-		i = encodeVarintNet(dAtA, i, uint64(m.ThreadID.Size()))
-		n25, err := m.ThreadID.MarshalTo(dAtA[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += n25
+		i = encodeVarintNet(dAtA, i, uint64(len(m.RecordNode)))
+		i += copy(dAtA[i:], m.RecordNode)
 	}
-	if m.LogID != nil {
+	if len(m.EventNode) > 0 {
 		dAtA[i] = 0x12
 		i++
-		i = encodeVarintNet(dAtA, i, uint64(m.LogID.Size()))
-		n26, err := m.LogID.MarshalTo(dAtA[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += n26
+		i = encodeVarintNet(dAtA, i, uint64(len(m.EventNode)))
+		i += copy(dAtA[i:], m.EventNode)
 	}
-	if m.Record != nil {
+	if len(m.HeaderNode) > 0 {
 		dAtA[i] = 0x1a
 		i++
-		i = encodeVarintNet(dAtA, i, uint64(m.Record.Size()))
-		n27, err := m.Record.MarshalTo(dAtA[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += n27
+		i = encodeVarintNet(dAtA, i, uint64(len(m.HeaderNode)))
+		i += copy(dAtA[i:], m.HeaderNode)
+	}
+	if len(m.BodyNode) > 0 {
+		dAtA[i] = 0x22
+		i++
+		i = encodeVarintNet(dAtA, i, uint64(len(m.BodyNode)))
+		i += copy(dAtA[i:], m.BodyNode)
 	}
 	return i, nil
 }
 
-func (m *PushRecordReply) Marshal() (dAtA []byte, err error) {
+func (m *GetLogsRequest) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
 	n, err := m.MarshalTo(dAtA)
@@ -1788,654 +2663,5248 @@ func (m *PushRecordReply) Marshal() (dAtA []byte, err error) {
 	return dAtA[:n], nil
 }
 
-func (m *PushRecordReply) MarshalTo(dAtA []byte) (int, error) {
+func (m *GetLogsRequest) MarshalTo(dAtA []byte) (int, error) {
 	var i int
 	_ = i
 	var l int
 	_ = l
-	return i, nil
-}
-
-func encodeVarintNet(dAtA []byte, offset int, v uint64) int {
-	for v >= 1<<7 {
-		dAtA[offset] = uint8(v&0x7f | 0x80)
-		v >>= 7
-		offset++
-	}
-	dAtA[offset] = uint8(v)
-	return offset + 1
-}
-func NewPopulatedHeader(r randyNet, easy bool) *Header {
-	this := &Header{}
-	this.PubKey = NewPopulatedProtoPubKey(r)
-	v1 := r.Intn(100)
-	this.Signature = make([]byte, v1)
-	for i := 0; i < v1; i++ {
-		this.Signature[i] = byte(r.Intn(256))
+	if m.Header != nil {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintNet(dAtA, i, uint64(m.Header.Size()))
+		n5, err := m.Header.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n5
 	}
-	if !easy && r.Intn(10) != 0 {
+	if m.Body != nil {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintNet(dAtA, i, uint64(m.Body.Size()))
+		n6, err := m.Body.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n6
 	}
-	return this
+	return i, nil
 }
 
-func NewPopulatedLog(r randyNet, easy bool) *Log {
-	this := &Log{}
-	this.ID = NewPopulatedProtoPeerID(r)
-	this.PubKey = NewPopulatedProtoPubKey(r)
-	v2 := r.Intn(10)
-	this.Addrs = make([]ProtoAddr, v2)
-	for i := 0; i < v2; i++ {
-		v3 := NewPopulatedProtoAddr(r)
-		this.Addrs[i] = *v3
-	}
-	this.Head = NewPopulatedProtoCid(r)
-	if !easy && r.Intn(10) != 0 {
+func (m *GetLogsRequest_Body) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
 	}
-	return this
+	return dAtA[:n], nil
 }
 
-func NewPopulatedLog_Record(r randyNet, easy bool) *Log_Record {
-	this := &Log_Record{}
-	v4 := r.Intn(100)
-	this.RecordNode = make([]byte, v4)
-	for i := 0; i < v4; i++ {
-		this.RecordNode[i] = byte(r.Intn(256))
-	}
-	v5 := r.Intn(100)
-	this.EventNode = make([]byte, v5)
-	for i := 0; i < v5; i++ {
-		this.EventNode[i] = byte(r.Intn(256))
-	}
-	v6 := r.Intn(100)
-	this.HeaderNode = make([]byte, v6)
-	for i := 0; i < v6; i++ {
-		this.HeaderNode[i] = byte(r.Intn(256))
-	}
-	v7 := r.Intn(100)
-	this.BodyNode = make([]byte, v7)
-	for i := 0; i < v7; i++ {
-		this.BodyNode[i] = byte(r.Intn(256))
+func (m *GetLogsRequest_Body) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.ThreadID != nil {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintNet(dAtA, i, uint64(m.ThreadID.Size()))
+		n7, err := m.ThreadID.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n7
 	}
-	if !easy && r.Intn(10) != 0 {
+	if m.ServiceKey != nil {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintNet(dAtA, i, uint64(m.ServiceKey.Size()))
+		n8, err := m.ServiceKey.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n8
 	}
-	return this
+	return i, nil
 }
 
-func NewPopulatedGetLogsRequest(r randyNet, easy bool) *GetLogsRequest {
-	this := &GetLogsRequest{}
-	if r.Intn(10) != 0 {
-		this.Header = NewPopulatedHeader(r, easy)
-	}
-	if r.Intn(10) != 0 {
-		this.Body = NewPopulatedGetLogsRequest_Body(r, easy)
+func (m *GetLogsReply) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
 	}
-	if !easy && r.Intn(10) != 0 {
+	return dAtA[:n], nil
+}
+
+func (m *GetLogsReply) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Logs) > 0 {
+		for _, msg := range m.Logs {
+			dAtA[i] = 0xa
+			i++
+			i = encodeVarintNet(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
 	}
-	return this
+	return i, nil
 }
 
-func NewPopulatedGetLogsRequest_Body(r randyNet, easy bool) *GetLogsRequest_Body {
-	this := &GetLogsRequest_Body{}
-	this.ThreadID = NewPopulatedProtoThreadID(r)
-	this.ServiceKey = NewPopulatedProtoKey(r)
-	if !easy && r.Intn(10) != 0 {
+func (m *GetLogsBatchRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
 	}
-	return this
+	return dAtA[:n], nil
 }
 
-func NewPopulatedGetLogsReply(r randyNet, easy bool) *GetLogsReply {
-	this := &GetLogsReply{}
-	if r.Intn(10) != 0 {
-		v8 := r.Intn(5)
-		this.Logs = make([]*Log, v8)
-		for i := 0; i < v8; i++ {
-			this.Logs[i] = NewPopulatedLog(r, easy)
+func (m *GetLogsBatchRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.Header != nil {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintNet(dAtA, i, uint64(m.Header.Size()))
+		n9, err := m.Header.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
 		}
+		i += n9
 	}
-	if !easy && r.Intn(10) != 0 {
+	if m.Body != nil {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintNet(dAtA, i, uint64(m.Body.Size()))
+		n10, err := m.Body.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n10
 	}
-	return this
+	return i, nil
 }
 
-func NewPopulatedPushLogRequest(r randyNet, easy bool) *PushLogRequest {
-	this := &PushLogRequest{}
-	if r.Intn(10) != 0 {
-		this.Header = NewPopulatedHeader(r, easy)
-	}
-	if r.Intn(10) != 0 {
-		this.Body = NewPopulatedPushLogRequest_Body(r, easy)
-	}
-	if !easy && r.Intn(10) != 0 {
+func (m *GetLogsBatchRequest_Body) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
 	}
-	return this
+	return dAtA[:n], nil
 }
 
-func NewPopulatedPushLogRequest_Body(r randyNet, easy bool) *PushLogRequest_Body {
-	this := &PushLogRequest_Body{}
-	this.ThreadID = NewPopulatedProtoThreadID(r)
-	this.ServiceKey = NewPopulatedProtoKey(r)
-	this.ReadKey = NewPopulatedProtoKey(r)
-	if r.Intn(10) != 0 {
-		this.Log = NewPopulatedLog(r, easy)
-	}
-	if !easy && r.Intn(10) != 0 {
+func (m *GetLogsBatchRequest_Body) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Threads) > 0 {
+		for _, msg := range m.Threads {
+			dAtA[i] = 0xa
+			i++
+			i = encodeVarintNet(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
 	}
-	return this
+	return i, nil
 }
 
-func NewPopulatedPushLogReply(r randyNet, easy bool) *PushLogReply {
-	this := &PushLogReply{}
-	if !easy && r.Intn(10) != 0 {
+func (m *GetLogsBatchReply) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
 	}
-	return this
+	return dAtA[:n], nil
 }
 
-func NewPopulatedGetRecordsRequest(r randyNet, easy bool) *GetRecordsRequest {
-	this := &GetRecordsRequest{}
-	if r.Intn(10) != 0 {
-		this.Header = NewPopulatedHeader(r, easy)
-	}
-	if r.Intn(10) != 0 {
-		this.Body = NewPopulatedGetRecordsRequest_Body(r, easy)
-	}
-	if !easy && r.Intn(10) != 0 {
+func (m *GetLogsBatchReply) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Threads) > 0 {
+		for _, msg := range m.Threads {
+			dAtA[i] = 0xa
+			i++
+			i = encodeVarintNet(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
 	}
-	return this
+	return i, nil
 }
 
-func NewPopulatedGetRecordsRequest_Body(r randyNet, easy bool) *GetRecordsRequest_Body {
-	this := &GetRecordsRequest_Body{}
-	this.ThreadID = NewPopulatedProtoThreadID(r)
-	this.ServiceKey = NewPopulatedProtoKey(r)
-	if r.Intn(10) != 0 {
-		v9 := r.Intn(5)
-		this.Logs = make([]*GetRecordsRequest_Body_LogEntry, v9)
-		for i := 0; i < v9; i++ {
-			this.Logs[i] = NewPopulatedGetRecordsRequest_Body_LogEntry(r, easy)
-		}
-	}
-	if !easy && r.Intn(10) != 0 {
+func (m *GetLogsBatchReply_ThreadEntry) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
 	}
-	return this
+	return dAtA[:n], nil
 }
 
-func NewPopulatedGetRecordsRequest_Body_LogEntry(r randyNet, easy bool) *GetRecordsRequest_Body_LogEntry {
-	this := &GetRecordsRequest_Body_LogEntry{}
-	this.LogID = NewPopulatedProtoPeerID(r)
-	this.Offset = NewPopulatedProtoCid(r)
-	this.Limit = int32(r.Int31())
-	if r.Intn(2) == 0 {
-		this.Limit *= -1
+func (m *GetLogsBatchReply_ThreadEntry) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.ThreadID != nil {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintNet(dAtA, i, uint64(m.ThreadID.Size()))
+		n11, err := m.ThreadID.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n11
 	}
-	if !easy && r.Intn(10) != 0 {
+	if len(m.Logs) > 0 {
+		for _, msg := range m.Logs {
+			dAtA[i] = 0x12
+			i++
+			i = encodeVarintNet(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
 	}
-	return this
+	if len(m.Error) > 0 {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintNet(dAtA, i, uint64(len(m.Error)))
+		i += copy(dAtA[i:], m.Error)
+	}
+	return i, nil
 }
 
-func NewPopulatedGetRecordsReply(r randyNet, easy bool) *GetRecordsReply {
-	this := &GetRecordsReply{}
-	if r.Intn(10) != 0 {
-		v10 := r.Intn(5)
-		this.Logs = make([]*GetRecordsReply_LogEntry, v10)
-		for i := 0; i < v10; i++ {
-			this.Logs[i] = NewPopulatedGetRecordsReply_LogEntry(r, easy)
-		}
-	}
-	if !easy && r.Intn(10) != 0 {
+func (m *GetHeadsRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
 	}
-	return this
+	return dAtA[:n], nil
 }
 
-func NewPopulatedGetRecordsReply_LogEntry(r randyNet, easy bool) *GetRecordsReply_LogEntry {
-	this := &GetRecordsReply_LogEntry{}
-	this.LogID = NewPopulatedProtoPeerID(r)
-	if r.Intn(10) != 0 {
-		v11 := r.Intn(5)
-		this.Records = make([]*Log_Record, v11)
-		for i := 0; i < v11; i++ {
-			this.Records[i] = NewPopulatedLog_Record(r, easy)
+func (m *GetHeadsRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.Header != nil {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintNet(dAtA, i, uint64(m.Header.Size()))
+		n12, err := m.Header.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
 		}
+		i += n12
 	}
-	if r.Intn(10) != 0 {
-		this.Log = NewPopulatedLog(r, easy)
+	if m.Body != nil {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintNet(dAtA, i, uint64(m.Body.Size()))
+		n13, err := m.Body.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n13
 	}
-	if !easy && r.Intn(10) != 0 {
+	return i, nil
+}
+
+func (m *GetHeadsRequest_Body) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
 	}
-	return this
+	return dAtA[:n], nil
 }
 
-func NewPopulatedPushRecordRequest(r randyNet, easy bool) *PushRecordRequest {
-	this := &PushRecordRequest{}
-	if r.Intn(10) != 0 {
-		this.Header = NewPopulatedHeader(r, easy)
+func (m *GetHeadsRequest_Body) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.ThreadID != nil {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintNet(dAtA, i, uint64(m.ThreadID.Size()))
+		n14, err := m.ThreadID.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n14
 	}
-	if r.Intn(10) != 0 {
-		this.Body = NewPopulatedPushRecordRequest_Body(r, easy)
+	if m.ServiceKey != nil {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintNet(dAtA, i, uint64(m.ServiceKey.Size()))
+		n15, err := m.ServiceKey.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n15
 	}
-	if !easy && r.Intn(10) != 0 {
+	if m.LogID != nil {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintNet(dAtA, i, uint64(m.LogID.Size()))
+		n16, err := m.LogID.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n16
 	}
-	return this
+	return i, nil
 }
 
-func NewPopulatedPushRecordRequest_Body(r randyNet, easy bool) *PushRecordRequest_Body {
-	this := &PushRecordRequest_Body{}
-	this.ThreadID = NewPopulatedProtoThreadID(r)
-	this.LogID = NewPopulatedProtoPeerID(r)
-	if r.Intn(10) != 0 {
-		this.Record = NewPopulatedLog_Record(r, easy)
-	}
-	if !easy && r.Intn(10) != 0 {
+func (m *GetHeadsReply) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
 	}
-	return this
+	return dAtA[:n], nil
 }
 
-func NewPopulatedPushRecordReply(r randyNet, easy bool) *PushRecordReply {
-	this := &PushRecordReply{}
-	if !easy && r.Intn(10) != 0 {
+func (m *GetHeadsReply) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Heads) > 0 {
+		for _, msg := range m.Heads {
+			dAtA[i] = 0xa
+			i++
+			i = encodeVarintNet(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
 	}
-	return this
+	return i, nil
 }
 
-type randyNet interface {
-	Float32() float32
-	Float64() float64
-	Int63() int64
-	Int31() int32
-	Uint32() uint32
-	Intn(n int) int
+func (m *PushLogRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
 }
 
-func randUTF8RuneNet(r randyNet) rune {
-	ru := r.Intn(62)
-	if ru < 10 {
-		return rune(ru + 48)
-	} else if ru < 36 {
-		return rune(ru + 55)
+func (m *PushLogRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.Header != nil {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintNet(dAtA, i, uint64(m.Header.Size()))
+		n17, err := m.Header.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n17
 	}
-	return rune(ru + 61)
+	if m.Body != nil {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintNet(dAtA, i, uint64(m.Body.Size()))
+		n18, err := m.Body.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n18
+	}
+	return i, nil
 }
-func randStringNet(r randyNet) string {
-	v12 := r.Intn(100)
-	tmps := make([]rune, v12)
-	for i := 0; i < v12; i++ {
-		tmps[i] = randUTF8RuneNet(r)
+
+func (m *PushLogRequest_Body) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
 	}
-	return string(tmps)
+	return dAtA[:n], nil
 }
-func randUnrecognizedNet(r randyNet, maxFieldNumber int) (dAtA []byte) {
-	l := r.Intn(5)
-	for i := 0; i < l; i++ {
-		wire := r.Intn(4)
-		if wire == 3 {
-			wire = 5
+
+func (m *PushLogRequest_Body) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.ThreadID != nil {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintNet(dAtA, i, uint64(m.ThreadID.Size()))
+		n19, err := m.ThreadID.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
 		}
-		fieldNumber := maxFieldNumber + r.Intn(100)
-		dAtA = randFieldNet(dAtA, r, fieldNumber, wire)
+		i += n19
 	}
-	return dAtA
-}
-func randFieldNet(dAtA []byte, r randyNet, fieldNumber int, wire int) []byte {
-	key := uint32(fieldNumber)<<3 | uint32(wire)
-	switch wire {
-	case 0:
-		dAtA = encodeVarintPopulateNet(dAtA, uint64(key))
-		v13 := r.Int63()
-		if r.Intn(2) == 0 {
-			v13 *= -1
-		}
-		dAtA = encodeVarintPopulateNet(dAtA, uint64(v13))
-	case 1:
-		dAtA = encodeVarintPopulateNet(dAtA, uint64(key))
-		dAtA = append(dAtA, byte(r.Intn(256)), byte(r.Intn(256)), byte(r.Intn(256)), byte(r.Intn(256)), byte(r.Intn(256)), byte(r.Intn(256)), byte(r.Intn(256)), byte(r.Intn(256)))
-	case 2:
-		dAtA = encodeVarintPopulateNet(dAtA, uint64(key))
-		ll := r.Intn(100)
-		dAtA = encodeVarintPopulateNet(dAtA, uint64(ll))
-		for j := 0; j < ll; j++ {
-			dAtA = append(dAtA, byte(r.Intn(256)))
+	if m.ServiceKey != nil {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintNet(dAtA, i, uint64(m.ServiceKey.Size()))
+		n20, err := m.ServiceKey.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
 		}
-	default:
-		dAtA = encodeVarintPopulateNet(dAtA, uint64(key))
-		dAtA = append(dAtA, byte(r.Intn(256)), byte(r.Intn(256)), byte(r.Intn(256)), byte(r.Intn(256)))
-	}
-	return dAtA
-}
-func encodeVarintPopulateNet(dAtA []byte, v uint64) []byte {
-	for v >= 1<<7 {
-		dAtA = append(dAtA, uint8(uint64(v)&0x7f|0x80))
-		v >>= 7
+		i += n20
 	}
-	dAtA = append(dAtA, uint8(v))
-	return dAtA
-}
-func (m *Header) Size() (n int) {
-	if m == nil {
-		return 0
+	if m.ReadKey != nil {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintNet(dAtA, i, uint64(m.ReadKey.Size()))
+		n21, err := m.ReadKey.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n21
 	}
-	var l int
-	_ = l
-	if m.PubKey != nil {
-		l = m.PubKey.Size()
-		n += 1 + l + sovNet(uint64(l))
+	if m.Log != nil {
+		dAtA[i] = 0x22
+		i++
+		i = encodeVarintNet(dAtA, i, uint64(m.Log.Size()))
+		n22, err := m.Log.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n22
 	}
-	l = len(m.Signature)
-	if l > 0 {
-		n += 1 + l + sovNet(uint64(l))
+	if m.KeyChange != nil {
+		dAtA[i] = 0x2a
+		i++
+		i = encodeVarintNet(dAtA, i, uint64(m.KeyChange.Size()))
+		n23, err := m.KeyChange.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n23
 	}
-	return n
+	return i, nil
 }
 
-func (m *Log) Size() (n int) {
-	if m == nil {
-		return 0
+func (m *PushLogRequest_KeyChange) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
 	}
+	return dAtA[:n], nil
+}
+
+func (m *PushLogRequest_KeyChange) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
 	var l int
 	_ = l
-	if m.ID != nil {
-		l = m.ID.Size()
-		n += 1 + l + sovNet(uint64(l))
+	if m.LogID != nil {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintNet(dAtA, i, uint64(m.LogID.Size()))
+		n24, err := m.LogID.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n24
 	}
-	if m.PubKey != nil {
-		l = m.PubKey.Size()
-		n += 1 + l + sovNet(uint64(l))
+	if len(m.Signature) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintNet(dAtA, i, uint64(len(m.Signature)))
+		i += copy(dAtA[i:], m.Signature)
 	}
-	if len(m.Addrs) > 0 {
-		for _, e := range m.Addrs {
-			l = e.Size()
-			n += 1 + l + sovNet(uint64(l))
+	if len(m.Revoked) > 0 {
+		for _, msg := range m.Revoked {
+			dAtA[i] = 0x1a
+			i++
+			i = encodeVarintNet(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
 		}
 	}
-	if m.Head != nil {
-		l = m.Head.Size()
-		n += 1 + l + sovNet(uint64(l))
-	}
-	return n
+	return i, nil
 }
 
-func (m *Log_Record) Size() (n int) {
-	if m == nil {
-		return 0
+func (m *PushLogReply) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
 	}
+	return dAtA[:n], nil
+}
+
+func (m *PushLogReply) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
 	var l int
 	_ = l
-	l = len(m.RecordNode)
-	if l > 0 {
-		n += 1 + l + sovNet(uint64(l))
-	}
-	l = len(m.EventNode)
-	if l > 0 {
-		n += 1 + l + sovNet(uint64(l))
-	}
-	l = len(m.HeaderNode)
-	if l > 0 {
-		n += 1 + l + sovNet(uint64(l))
-	}
-	l = len(m.BodyNode)
-	if l > 0 {
-		n += 1 + l + sovNet(uint64(l))
-	}
-	return n
+	return i, nil
 }
 
-func (m *GetLogsRequest) Size() (n int) {
-	if m == nil {
-		return 0
+func (m *GetRecordsRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
 	}
+	return dAtA[:n], nil
+}
+
+func (m *GetRecordsRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
 	var l int
 	_ = l
 	if m.Header != nil {
-		l = m.Header.Size()
-		n += 1 + l + sovNet(uint64(l))
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintNet(dAtA, i, uint64(m.Header.Size()))
+		n25, err := m.Header.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n25
 	}
 	if m.Body != nil {
-		l = m.Body.Size()
-		n += 1 + l + sovNet(uint64(l))
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintNet(dAtA, i, uint64(m.Body.Size()))
+		n26, err := m.Body.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n26
 	}
-	return n
+	return i, nil
 }
 
-func (m *GetLogsRequest_Body) Size() (n int) {
-	if m == nil {
-		return 0
+func (m *GetRecordsRequest_Body) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
 	}
+	return dAtA[:n], nil
+}
+
+func (m *GetRecordsRequest_Body) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
 	var l int
 	_ = l
 	if m.ThreadID != nil {
-		l = m.ThreadID.Size()
-		n += 1 + l + sovNet(uint64(l))
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintNet(dAtA, i, uint64(m.ThreadID.Size()))
+		n27, err := m.ThreadID.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n27
 	}
 	if m.ServiceKey != nil {
-		l = m.ServiceKey.Size()
-		n += 1 + l + sovNet(uint64(l))
-	}
-	return n
-}
-
-func (m *GetLogsReply) Size() (n int) {
-	if m == nil {
-		return 0
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintNet(dAtA, i, uint64(m.ServiceKey.Size()))
+		n28, err := m.ServiceKey.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n28
 	}
-	var l int
-	_ = l
 	if len(m.Logs) > 0 {
-		for _, e := range m.Logs {
-			l = e.Size()
-			n += 1 + l + sovNet(uint64(l))
+		for _, msg := range m.Logs {
+			dAtA[i] = 0x1a
+			i++
+			i = encodeVarintNet(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
 		}
 	}
-	return n
+	if m.Proof {
+		dAtA[i] = 0x20
+		i++
+		if m.Proof {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	return i, nil
 }
 
-func (m *PushLogRequest) Size() (n int) {
-	if m == nil {
-		return 0
+func (m *GetRecordsRequest_Body_LogEntry) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
 	}
-	var l int
-	_ = l
-	if m.Header != nil {
-		l = m.Header.Size()
-		n += 1 + l + sovNet(uint64(l))
-	}
-	if m.Body != nil {
-		l = m.Body.Size()
-		n += 1 + l + sovNet(uint64(l))
-	}
-	return n
+	return dAtA[:n], nil
 }
 
-func (m *PushLogRequest_Body) Size() (n int) {
-	if m == nil {
-		return 0
-	}
+func (m *GetRecordsRequest_Body_LogEntry) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
 	var l int
 	_ = l
-	if m.ThreadID != nil {
-		l = m.ThreadID.Size()
-		n += 1 + l + sovNet(uint64(l))
-	}
-	if m.ServiceKey != nil {
-		l = m.ServiceKey.Size()
-		n += 1 + l + sovNet(uint64(l))
+	if m.LogID != nil {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintNet(dAtA, i, uint64(m.LogID.Size()))
+		n29, err := m.LogID.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n29
 	}
-	if m.ReadKey != nil {
-		l = m.ReadKey.Size()
-		n += 1 + l + sovNet(uint64(l))
+	if m.Offset != nil {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintNet(dAtA, i, uint64(m.Offset.Size()))
+		n30, err := m.Offset.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n30
 	}
-	if m.Log != nil {
-		l = m.Log.Size()
-		n += 1 + l + sovNet(uint64(l))
+	if m.Limit != 0 {
+		dAtA[i] = 0x18
+		i++
+		i = encodeVarintNet(dAtA, i, uint64(m.Limit))
 	}
-	return n
-}
-
-func (m *PushLogReply) Size() (n int) {
-	if m == nil {
-		return 0
+	if m.To != nil {
+		dAtA[i] = 0x22
+		i++
+		i = encodeVarintNet(dAtA, i, uint64(m.To.Size()))
+		n31, err := m.To.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n31
 	}
-	var l int
-	_ = l
-	return n
+	return i, nil
 }
 
-func (m *GetRecordsRequest) Size() (n int) {
-	if m == nil {
-		return 0
-	}
-	var l int
-	_ = l
-	if m.Header != nil {
-		l = m.Header.Size()
-		n += 1 + l + sovNet(uint64(l))
-	}
-	if m.Body != nil {
-		l = m.Body.Size()
-		n += 1 + l + sovNet(uint64(l))
+func (m *GetRecordsReply) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
 	}
-	return n
+	return dAtA[:n], nil
 }
 
-func (m *GetRecordsRequest_Body) Size() (n int) {
-	if m == nil {
-		return 0
-	}
+func (m *GetRecordsReply) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
 	var l int
 	_ = l
-	if m.ThreadID != nil {
-		l = m.ThreadID.Size()
-		n += 1 + l + sovNet(uint64(l))
-	}
-	if m.ServiceKey != nil {
-		l = m.ServiceKey.Size()
-		n += 1 + l + sovNet(uint64(l))
-	}
 	if len(m.Logs) > 0 {
-		for _, e := range m.Logs {
-			l = e.Size()
-			n += 1 + l + sovNet(uint64(l))
+		for _, msg := range m.Logs {
+			dAtA[i] = 0xa
+			i++
+			i = encodeVarintNet(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
 		}
 	}
-	return n
+	return i, nil
 }
 
-func (m *GetRecordsRequest_Body_LogEntry) Size() (n int) {
-	if m == nil {
-		return 0
+func (m *GetRecordsReply_LogEntry) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
 	}
+	return dAtA[:n], nil
+}
+
+func (m *GetRecordsReply_LogEntry) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
 	var l int
 	_ = l
 	if m.LogID != nil {
-		l = m.LogID.Size()
-		n += 1 + l + sovNet(uint64(l))
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintNet(dAtA, i, uint64(m.LogID.Size()))
+		n32, err := m.LogID.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n32
 	}
-	if m.Offset != nil {
-		l = m.Offset.Size()
-		n += 1 + l + sovNet(uint64(l))
+	if len(m.Records) > 0 {
+		for _, msg := range m.Records {
+			dAtA[i] = 0x12
+			i++
+			i = encodeVarintNet(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
 	}
-	if m.Limit != 0 {
-		n += 1 + sovNet(uint64(m.Limit))
+	if m.Log != nil {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintNet(dAtA, i, uint64(m.Log.Size()))
+		n33, err := m.Log.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n33
 	}
-	return n
-}
-
-func (m *GetRecordsReply) Size() (n int) {
-	if m == nil {
-		return 0
+	if m.Manifest != nil {
+		dAtA[i] = 0x22
+		i++
+		i = encodeVarintNet(dAtA, i, uint64(m.Manifest.Size()))
+		n34, err := m.Manifest.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n34
 	}
-	var l int
-	_ = l
-	if len(m.Logs) > 0 {
-		for _, e := range m.Logs {
-			l = e.Size()
-			n += 1 + l + sovNet(uint64(l))
+	if m.Truncated {
+		dAtA[i] = 0x28
+		i++
+		if m.Truncated {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
 		}
+		i++
 	}
-	return n
+	return i, nil
 }
 
-func (m *GetRecordsReply_LogEntry) Size() (n int) {
-	if m == nil {
-		return 0
+func (m *GetRecordsStreamReply) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
 	}
+	return dAtA[:n], nil
+}
+
+func (m *GetRecordsStreamReply) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
 	var l int
 	_ = l
 	if m.LogID != nil {
-		l = m.LogID.Size()
-		n += 1 + l + sovNet(uint64(l))
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintNet(dAtA, i, uint64(m.LogID.Size()))
+		n35, err := m.LogID.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n35
 	}
-	if len(m.Records) > 0 {
-		for _, e := range m.Records {
-			l = e.Size()
-			n += 1 + l + sovNet(uint64(l))
+	if m.Record != nil {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintNet(dAtA, i, uint64(m.Record.Size()))
+		n36, err := m.Record.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
 		}
+		i += n36
 	}
 	if m.Log != nil {
-		l = m.Log.Size()
-		n += 1 + l + sovNet(uint64(l))
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintNet(dAtA, i, uint64(m.Log.Size()))
+		n37, err := m.Log.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n37
 	}
-	return n
+	if m.Truncated {
+		dAtA[i] = 0x28
+		i++
+		if m.Truncated {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	return i, nil
 }
 
-func (m *PushRecordRequest) Size() (n int) {
-	if m == nil {
-		return 0
+func (m *Manifest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
 	}
+	return dAtA[:n], nil
+}
+
+func (m *Manifest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
 	var l int
 	_ = l
 	if m.Header != nil {
-		l = m.Header.Size()
-		n += 1 + l + sovNet(uint64(l))
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintNet(dAtA, i, uint64(m.Header.Size()))
+		n38, err := m.Header.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n38
 	}
 	if m.Body != nil {
-		l = m.Body.Size()
-		n += 1 + l + sovNet(uint64(l))
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintNet(dAtA, i, uint64(m.Body.Size()))
+		n39, err := m.Body.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n39
 	}
-	return n
+	return i, nil
 }
 
-func (m *PushRecordRequest_Body) Size() (n int) {
-	if m == nil {
-		return 0
+func (m *Manifest_Body) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
 	}
+	return dAtA[:n], nil
+}
+
+func (m *Manifest_Body) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
 	var l int
 	_ = l
 	if m.ThreadID != nil {
-		l = m.ThreadID.Size()
-		n += 1 + l + sovNet(uint64(l))
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintNet(dAtA, i, uint64(m.ThreadID.Size()))
+		n40, err := m.ThreadID.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n40
 	}
 	if m.LogID != nil {
-		l = m.LogID.Size()
-		n += 1 + l + sovNet(uint64(l))
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintNet(dAtA, i, uint64(m.LogID.Size()))
+		n41, err := m.LogID.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n41
+	}
+	if m.Offset != nil {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintNet(dAtA, i, uint64(m.Offset.Size()))
+		n42, err := m.Offset.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n42
+	}
+	if len(m.Records) > 0 {
+		for _, msg := range m.Records {
+			dAtA[i] = 0x22
+			i++
+			i = encodeVarintNet(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	if m.HeadBlock != nil {
+		dAtA[i] = 0x2a
+		i++
+		i = encodeVarintNet(dAtA, i, uint64(m.HeadBlock.Size()))
+		n43, err := m.HeadBlock.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n43
+	}
+	if m.HeadPrev != nil {
+		dAtA[i] = 0x32
+		i++
+		i = encodeVarintNet(dAtA, i, uint64(m.HeadPrev.Size()))
+		n44, err := m.HeadPrev.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n44
+	}
+	if len(m.HeadSig) > 0 {
+		dAtA[i] = 0x3a
+		i++
+		i = encodeVarintNet(dAtA, i, uint64(len(m.HeadSig)))
+		i += copy(dAtA[i:], m.HeadSig)
+	}
+	if len(m.HeadPubKey) > 0 {
+		dAtA[i] = 0x42
+		i++
+		i = encodeVarintNet(dAtA, i, uint64(len(m.HeadPubKey)))
+		i += copy(dAtA[i:], m.HeadPubKey)
+	}
+	return i, nil
+}
+
+func (m *PushRecordRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *PushRecordRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.Header != nil {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintNet(dAtA, i, uint64(m.Header.Size()))
+		n45, err := m.Header.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n45
+	}
+	if m.Body != nil {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintNet(dAtA, i, uint64(m.Body.Size()))
+		n46, err := m.Body.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n46
+	}
+	return i, nil
+}
+
+func (m *PushRecordRequest_Body) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *PushRecordRequest_Body) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.ThreadID != nil {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintNet(dAtA, i, uint64(m.ThreadID.Size()))
+		n47, err := m.ThreadID.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n47
+	}
+	if m.LogID != nil {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintNet(dAtA, i, uint64(m.LogID.Size()))
+		n48, err := m.LogID.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n48
 	}
 	if m.Record != nil {
-		l = m.Record.Size()
-		n += 1 + l + sovNet(uint64(l))
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintNet(dAtA, i, uint64(m.Record.Size()))
+		n49, err := m.Record.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n49
 	}
-	return n
+	return i, nil
 }
 
-func (m *PushRecordReply) Size() (n int) {
-	if m == nil {
-		return 0
+func (m *PushRecordReply) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *PushRecordReply) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	return i, nil
+}
+
+func (m *PushRecordsRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *PushRecordsRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.Header != nil {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintNet(dAtA, i, uint64(m.Header.Size()))
+		n50, err := m.Header.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n50
+	}
+	if m.Body != nil {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintNet(dAtA, i, uint64(m.Body.Size()))
+		n51, err := m.Body.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n51
+	}
+	return i, nil
+}
+
+func (m *PushRecordsRequest_Body) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *PushRecordsRequest_Body) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.ThreadID != nil {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintNet(dAtA, i, uint64(m.ThreadID.Size()))
+		n52, err := m.ThreadID.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n52
+	}
+	if m.LogID != nil {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintNet(dAtA, i, uint64(m.LogID.Size()))
+		n53, err := m.LogID.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n53
+	}
+	if len(m.Records) > 0 {
+		for _, msg := range m.Records {
+			dAtA[i] = 0x1a
+			i++
+			i = encodeVarintNet(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	return i, nil
+}
+
+func (m *PushRecordsReply) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *PushRecordsReply) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Results) > 0 {
+		for _, msg := range m.Results {
+			dAtA[i] = 0xa
+			i++
+			i = encodeVarintNet(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	return i, nil
+}
+
+func (m *PushRecordsReply_Result) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *PushRecordsReply_Result) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.Code != 0 {
+		dAtA[i] = 0x8
+		i++
+		i = encodeVarintNet(dAtA, i, uint64(m.Code))
+	}
+	if len(m.Message) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintNet(dAtA, i, uint64(len(m.Message)))
+		i += copy(dAtA[i:], m.Message)
+	}
+	return i, nil
+}
+
+func (m *SubscribeRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *SubscribeRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.Header != nil {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintNet(dAtA, i, uint64(m.Header.Size()))
+		n54, err := m.Header.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n54
+	}
+	if m.Body != nil {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintNet(dAtA, i, uint64(m.Body.Size()))
+		n55, err := m.Body.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n55
+	}
+	return i, nil
+}
+
+func (m *SubscribeRequest_Body) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *SubscribeRequest_Body) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.ThreadID != nil {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintNet(dAtA, i, uint64(m.ThreadID.Size()))
+		n56, err := m.ThreadID.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n56
+	}
+	if m.ServiceKey != nil {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintNet(dAtA, i, uint64(m.ServiceKey.Size()))
+		n57, err := m.ServiceKey.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n57
+	}
+	return i, nil
+}
+
+func (m *SubscribeReply) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *SubscribeReply) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.ThreadID != nil {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintNet(dAtA, i, uint64(m.ThreadID.Size()))
+		n58, err := m.ThreadID.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n58
+	}
+	if m.LogID != nil {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintNet(dAtA, i, uint64(m.LogID.Size()))
+		n59, err := m.LogID.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n59
+	}
+	if m.Record != nil {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintNet(dAtA, i, uint64(m.Record.Size()))
+		n60, err := m.Record.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n60
+	}
+	return i, nil
+}
+
+func (m *PingRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *PingRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	return i, nil
+}
+
+func (m *PingReply) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *PingReply) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	return i, nil
+}
+
+func encodeVarintNet(dAtA []byte, offset int, v uint64) int {
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return offset + 1
+}
+func NewPopulatedHeader(r randyNet, easy bool) *Header {
+	this := &Header{}
+	this.PubKey = NewPopulatedProtoPubKey(r)
+	v1 := r.Intn(100)
+	this.Signature = make([]byte, v1)
+	for i := 0; i < v1; i++ {
+		this.Signature[i] = byte(r.Intn(256))
+	}
+	this.Compact = bool(bool(r.Intn(2) == 0))
+	if !easy && r.Intn(10) != 0 {
+	}
+	return this
+}
+
+func NewPopulatedLog(r randyNet, easy bool) *Log {
+	this := &Log{}
+	this.ID = NewPopulatedProtoPeerID(r)
+	this.PubKey = NewPopulatedProtoPubKey(r)
+	v2 := r.Intn(10)
+	this.Addrs = make([]ProtoAddr, v2)
+	for i := 0; i < v2; i++ {
+		v3 := NewPopulatedProtoAddr(r)
+		this.Addrs[i] = *v3
+	}
+	this.Head = NewPopulatedProtoCid(r)
+	if !easy && r.Intn(10) != 0 {
+	}
+	return this
+}
+
+func NewPopulatedLog_Record(r randyNet, easy bool) *Log_Record {
+	this := &Log_Record{}
+	v4 := r.Intn(100)
+	this.RecordNode = make([]byte, v4)
+	for i := 0; i < v4; i++ {
+		this.RecordNode[i] = byte(r.Intn(256))
+	}
+	v5 := r.Intn(100)
+	this.EventNode = make([]byte, v5)
+	for i := 0; i < v5; i++ {
+		this.EventNode[i] = byte(r.Intn(256))
+	}
+	v6 := r.Intn(100)
+	this.HeaderNode = make([]byte, v6)
+	for i := 0; i < v6; i++ {
+		this.HeaderNode[i] = byte(r.Intn(256))
+	}
+	v7 := r.Intn(100)
+	this.BodyNode = make([]byte, v7)
+	for i := 0; i < v7; i++ {
+		this.BodyNode[i] = byte(r.Intn(256))
+	}
+	if !easy && r.Intn(10) != 0 {
+	}
+	return this
+}
+
+func NewPopulatedGetLogsRequest(r randyNet, easy bool) *GetLogsRequest {
+	this := &GetLogsRequest{}
+	if r.Intn(10) != 0 {
+		this.Header = NewPopulatedHeader(r, easy)
+	}
+	if r.Intn(10) != 0 {
+		this.Body = NewPopulatedGetLogsRequest_Body(r, easy)
+	}
+	if !easy && r.Intn(10) != 0 {
+	}
+	return this
+}
+
+func NewPopulatedGetLogsRequest_Body(r randyNet, easy bool) *GetLogsRequest_Body {
+	this := &GetLogsRequest_Body{}
+	this.ThreadID = NewPopulatedProtoThreadID(r)
+	this.ServiceKey = NewPopulatedProtoKey(r)
+	if !easy && r.Intn(10) != 0 {
+	}
+	return this
+}
+
+func NewPopulatedGetLogsReply(r randyNet, easy bool) *GetLogsReply {
+	this := &GetLogsReply{}
+	if r.Intn(10) != 0 {
+		v8 := r.Intn(5)
+		this.Logs = make([]*Log, v8)
+		for i := 0; i < v8; i++ {
+			this.Logs[i] = NewPopulatedLog(r, easy)
+		}
+	}
+	if !easy && r.Intn(10) != 0 {
+	}
+	return this
+}
+
+func NewPopulatedGetLogsBatchRequest(r randyNet, easy bool) *GetLogsBatchRequest {
+	this := &GetLogsBatchRequest{}
+	if r.Intn(10) != 0 {
+		this.Header = NewPopulatedHeader(r, easy)
+	}
+	if r.Intn(10) != 0 {
+		this.Body = NewPopulatedGetLogsBatchRequest_Body(r, easy)
+	}
+	if !easy && r.Intn(10) != 0 {
+	}
+	return this
+}
+
+func NewPopulatedGetLogsBatchRequest_Body(r randyNet, easy bool) *GetLogsBatchRequest_Body {
+	this := &GetLogsBatchRequest_Body{}
+	if r.Intn(10) != 0 {
+		v9 := r.Intn(5)
+		this.Threads = make([]*GetLogsRequest_Body, v9)
+		for i := 0; i < v9; i++ {
+			this.Threads[i] = NewPopulatedGetLogsRequest_Body(r, easy)
+		}
+	}
+	if !easy && r.Intn(10) != 0 {
+	}
+	return this
+}
+
+func NewPopulatedGetLogsBatchReply(r randyNet, easy bool) *GetLogsBatchReply {
+	this := &GetLogsBatchReply{}
+	if r.Intn(10) != 0 {
+		v10 := r.Intn(5)
+		this.Threads = make([]*GetLogsBatchReply_ThreadEntry, v10)
+		for i := 0; i < v10; i++ {
+			this.Threads[i] = NewPopulatedGetLogsBatchReply_ThreadEntry(r, easy)
+		}
+	}
+	if !easy && r.Intn(10) != 0 {
+	}
+	return this
+}
+
+func NewPopulatedGetLogsBatchReply_ThreadEntry(r randyNet, easy bool) *GetLogsBatchReply_ThreadEntry {
+	this := &GetLogsBatchReply_ThreadEntry{}
+	this.ThreadID = NewPopulatedProtoThreadID(r)
+	if r.Intn(10) != 0 {
+		v11 := r.Intn(5)
+		this.Logs = make([]*Log, v11)
+		for i := 0; i < v11; i++ {
+			this.Logs[i] = NewPopulatedLog(r, easy)
+		}
+	}
+	this.Error = string(randStringNet(r))
+	if !easy && r.Intn(10) != 0 {
+	}
+	return this
+}
+
+func NewPopulatedGetHeadsRequest(r randyNet, easy bool) *GetHeadsRequest {
+	this := &GetHeadsRequest{}
+	if r.Intn(10) != 0 {
+		this.Header = NewPopulatedHeader(r, easy)
+	}
+	if r.Intn(10) != 0 {
+		this.Body = NewPopulatedGetHeadsRequest_Body(r, easy)
+	}
+	if !easy && r.Intn(10) != 0 {
+	}
+	return this
+}
+
+func NewPopulatedGetHeadsRequest_Body(r randyNet, easy bool) *GetHeadsRequest_Body {
+	this := &GetHeadsRequest_Body{}
+	this.ThreadID = NewPopulatedProtoThreadID(r)
+	this.ServiceKey = NewPopulatedProtoKey(r)
+	this.LogID = NewPopulatedProtoPeerID(r)
+	if !easy && r.Intn(10) != 0 {
+	}
+	return this
+}
+
+func NewPopulatedGetHeadsReply(r randyNet, easy bool) *GetHeadsReply {
+	this := &GetHeadsReply{}
+	v12 := r.Intn(10)
+	this.Heads = make([]ProtoCid, v12)
+	for i := 0; i < v12; i++ {
+		v13 := NewPopulatedProtoCid(r)
+		this.Heads[i] = *v13
+	}
+	if !easy && r.Intn(10) != 0 {
+	}
+	return this
+}
+
+func NewPopulatedPushLogRequest(r randyNet, easy bool) *PushLogRequest {
+	this := &PushLogRequest{}
+	if r.Intn(10) != 0 {
+		this.Header = NewPopulatedHeader(r, easy)
+	}
+	if r.Intn(10) != 0 {
+		this.Body = NewPopulatedPushLogRequest_Body(r, easy)
+	}
+	if !easy && r.Intn(10) != 0 {
+	}
+	return this
+}
+
+func NewPopulatedPushLogRequest_Body(r randyNet, easy bool) *PushLogRequest_Body {
+	this := &PushLogRequest_Body{}
+	this.ThreadID = NewPopulatedProtoThreadID(r)
+	this.ServiceKey = NewPopulatedProtoKey(r)
+	this.ReadKey = NewPopulatedProtoKey(r)
+	if r.Intn(10) != 0 {
+		this.Log = NewPopulatedLog(r, easy)
+	}
+	if r.Intn(10) != 0 {
+		this.KeyChange = NewPopulatedPushLogRequest_KeyChange(r, easy)
+	}
+	if !easy && r.Intn(10) != 0 {
+	}
+	return this
+}
+
+func NewPopulatedPushLogRequest_KeyChange(r randyNet, easy bool) *PushLogRequest_KeyChange {
+	this := &PushLogRequest_KeyChange{}
+	this.LogID = NewPopulatedProtoPeerID(r)
+	v14 := r.Intn(100)
+	this.Signature = make([]byte, v14)
+	for i := 0; i < v14; i++ {
+		this.Signature[i] = byte(r.Intn(256))
+	}
+	v15 := r.Intn(10)
+	this.Revoked = make([]ProtoPeerID, v15)
+	for i := 0; i < v15; i++ {
+		v16 := NewPopulatedProtoPeerID(r)
+		this.Revoked[i] = *v16
+	}
+	if !easy && r.Intn(10) != 0 {
+	}
+	return this
+}
+
+func NewPopulatedPushLogReply(r randyNet, easy bool) *PushLogReply {
+	this := &PushLogReply{}
+	if !easy && r.Intn(10) != 0 {
+	}
+	return this
+}
+
+func NewPopulatedGetRecordsRequest(r randyNet, easy bool) *GetRecordsRequest {
+	this := &GetRecordsRequest{}
+	if r.Intn(10) != 0 {
+		this.Header = NewPopulatedHeader(r, easy)
+	}
+	if r.Intn(10) != 0 {
+		this.Body = NewPopulatedGetRecordsRequest_Body(r, easy)
+	}
+	if !easy && r.Intn(10) != 0 {
+	}
+	return this
+}
+
+func NewPopulatedGetRecordsRequest_Body(r randyNet, easy bool) *GetRecordsRequest_Body {
+	this := &GetRecordsRequest_Body{}
+	this.ThreadID = NewPopulatedProtoThreadID(r)
+	this.ServiceKey = NewPopulatedProtoKey(r)
+	if r.Intn(10) != 0 {
+		v17 := r.Intn(5)
+		this.Logs = make([]*GetRecordsRequest_Body_LogEntry, v17)
+		for i := 0; i < v17; i++ {
+			this.Logs[i] = NewPopulatedGetRecordsRequest_Body_LogEntry(r, easy)
+		}
+	}
+	this.Proof = bool(bool(r.Intn(2) == 0))
+	if !easy && r.Intn(10) != 0 {
+	}
+	return this
+}
+
+func NewPopulatedGetRecordsRequest_Body_LogEntry(r randyNet, easy bool) *GetRecordsRequest_Body_LogEntry {
+	this := &GetRecordsRequest_Body_LogEntry{}
+	this.LogID = NewPopulatedProtoPeerID(r)
+	this.Offset = NewPopulatedProtoCid(r)
+	this.Limit = int32(r.Int31())
+	if r.Intn(2) == 0 {
+		this.Limit *= -1
+	}
+	this.To = NewPopulatedProtoCid(r)
+	if !easy && r.Intn(10) != 0 {
+	}
+	return this
+}
+
+func NewPopulatedGetRecordsReply(r randyNet, easy bool) *GetRecordsReply {
+	this := &GetRecordsReply{}
+	if r.Intn(10) != 0 {
+		v18 := r.Intn(5)
+		this.Logs = make([]*GetRecordsReply_LogEntry, v18)
+		for i := 0; i < v18; i++ {
+			this.Logs[i] = NewPopulatedGetRecordsReply_LogEntry(r, easy)
+		}
+	}
+	if !easy && r.Intn(10) != 0 {
+	}
+	return this
+}
+
+func NewPopulatedGetRecordsReply_LogEntry(r randyNet, easy bool) *GetRecordsReply_LogEntry {
+	this := &GetRecordsReply_LogEntry{}
+	this.LogID = NewPopulatedProtoPeerID(r)
+	if r.Intn(10) != 0 {
+		v19 := r.Intn(5)
+		this.Records = make([]*Log_Record, v19)
+		for i := 0; i < v19; i++ {
+			this.Records[i] = NewPopulatedLog_Record(r, easy)
+		}
+	}
+	if r.Intn(10) != 0 {
+		this.Log = NewPopulatedLog(r, easy)
+	}
+	if r.Intn(10) != 0 {
+		this.Manifest = NewPopulatedManifest(r, easy)
+	}
+	this.Truncated = bool(bool(r.Intn(2) == 0))
+	if !easy && r.Intn(10) != 0 {
+	}
+	return this
+}
+
+func NewPopulatedGetRecordsStreamReply(r randyNet, easy bool) *GetRecordsStreamReply {
+	this := &GetRecordsStreamReply{}
+	this.LogID = NewPopulatedProtoPeerID(r)
+	if r.Intn(10) != 0 {
+		this.Record = NewPopulatedLog_Record(r, easy)
+	}
+	if r.Intn(10) != 0 {
+		this.Log = NewPopulatedLog(r, easy)
+	}
+	this.Truncated = bool(bool(r.Intn(2) == 0))
+	if !easy && r.Intn(10) != 0 {
+	}
+	return this
+}
+
+func NewPopulatedManifest(r randyNet, easy bool) *Manifest {
+	this := &Manifest{}
+	if r.Intn(10) != 0 {
+		this.Header = NewPopulatedHeader(r, easy)
+	}
+	if r.Intn(10) != 0 {
+		this.Body = NewPopulatedManifest_Body(r, easy)
+	}
+	if !easy && r.Intn(10) != 0 {
+	}
+	return this
+}
+
+func NewPopulatedManifest_Body(r randyNet, easy bool) *Manifest_Body {
+	this := &Manifest_Body{}
+	this.ThreadID = NewPopulatedProtoThreadID(r)
+	this.LogID = NewPopulatedProtoPeerID(r)
+	this.Offset = NewPopulatedProtoCid(r)
+	v20 := r.Intn(10)
+	this.Records = make([]ProtoCid, v20)
+	for i := 0; i < v20; i++ {
+		v21 := NewPopulatedProtoCid(r)
+		this.Records[i] = *v21
+	}
+	this.HeadBlock = NewPopulatedProtoCid(r)
+	this.HeadPrev = NewPopulatedProtoCid(r)
+	v22 := r.Intn(100)
+	this.HeadSig = make([]byte, v22)
+	for i := 0; i < v22; i++ {
+		this.HeadSig[i] = byte(r.Intn(256))
+	}
+	v23 := r.Intn(100)
+	this.HeadPubKey = make([]byte, v23)
+	for i := 0; i < v23; i++ {
+		this.HeadPubKey[i] = byte(r.Intn(256))
+	}
+	if !easy && r.Intn(10) != 0 {
+	}
+	return this
+}
+
+func NewPopulatedPushRecordRequest(r randyNet, easy bool) *PushRecordRequest {
+	this := &PushRecordRequest{}
+	if r.Intn(10) != 0 {
+		this.Header = NewPopulatedHeader(r, easy)
+	}
+	if r.Intn(10) != 0 {
+		this.Body = NewPopulatedPushRecordRequest_Body(r, easy)
+	}
+	if !easy && r.Intn(10) != 0 {
+	}
+	return this
+}
+
+func NewPopulatedPushRecordRequest_Body(r randyNet, easy bool) *PushRecordRequest_Body {
+	this := &PushRecordRequest_Body{}
+	this.ThreadID = NewPopulatedProtoThreadID(r)
+	this.LogID = NewPopulatedProtoPeerID(r)
+	if r.Intn(10) != 0 {
+		this.Record = NewPopulatedLog_Record(r, easy)
+	}
+	if !easy && r.Intn(10) != 0 {
+	}
+	return this
+}
+
+func NewPopulatedPushRecordReply(r randyNet, easy bool) *PushRecordReply {
+	this := &PushRecordReply{}
+	if !easy && r.Intn(10) != 0 {
+	}
+	return this
+}
+
+func NewPopulatedPushRecordsRequest(r randyNet, easy bool) *PushRecordsRequest {
+	this := &PushRecordsRequest{}
+	if r.Intn(10) != 0 {
+		this.Header = NewPopulatedHeader(r, easy)
+	}
+	if r.Intn(10) != 0 {
+		this.Body = NewPopulatedPushRecordsRequest_Body(r, easy)
+	}
+	if !easy && r.Intn(10) != 0 {
+	}
+	return this
+}
+
+func NewPopulatedPushRecordsRequest_Body(r randyNet, easy bool) *PushRecordsRequest_Body {
+	this := &PushRecordsRequest_Body{}
+	this.ThreadID = NewPopulatedProtoThreadID(r)
+	this.LogID = NewPopulatedProtoPeerID(r)
+	if r.Intn(10) != 0 {
+		v24 := r.Intn(5)
+		this.Records = make([]*Log_Record, v24)
+		for i := 0; i < v24; i++ {
+			this.Records[i] = NewPopulatedLog_Record(r, easy)
+		}
+	}
+	if !easy && r.Intn(10) != 0 {
+	}
+	return this
+}
+
+func NewPopulatedPushRecordsReply(r randyNet, easy bool) *PushRecordsReply {
+	this := &PushRecordsReply{}
+	if r.Intn(10) != 0 {
+		v25 := r.Intn(5)
+		this.Results = make([]*PushRecordsReply_Result, v25)
+		for i := 0; i < v25; i++ {
+			this.Results[i] = NewPopulatedPushRecordsReply_Result(r, easy)
+		}
+	}
+	if !easy && r.Intn(10) != 0 {
+	}
+	return this
+}
+
+func NewPopulatedPushRecordsReply_Result(r randyNet, easy bool) *PushRecordsReply_Result {
+	this := &PushRecordsReply_Result{}
+	this.Code = int32(r.Int31())
+	if r.Intn(2) == 0 {
+		this.Code *= -1
+	}
+	this.Message = string(randStringNet(r))
+	if !easy && r.Intn(10) != 0 {
+	}
+	return this
+}
+
+func NewPopulatedSubscribeRequest(r randyNet, easy bool) *SubscribeRequest {
+	this := &SubscribeRequest{}
+	if r.Intn(10) != 0 {
+		this.Header = NewPopulatedHeader(r, easy)
+	}
+	if r.Intn(10) != 0 {
+		this.Body = NewPopulatedSubscribeRequest_Body(r, easy)
+	}
+	if !easy && r.Intn(10) != 0 {
+	}
+	return this
+}
+
+func NewPopulatedSubscribeRequest_Body(r randyNet, easy bool) *SubscribeRequest_Body {
+	this := &SubscribeRequest_Body{}
+	this.ThreadID = NewPopulatedProtoThreadID(r)
+	this.ServiceKey = NewPopulatedProtoKey(r)
+	if !easy && r.Intn(10) != 0 {
+	}
+	return this
+}
+
+func NewPopulatedSubscribeReply(r randyNet, easy bool) *SubscribeReply {
+	this := &SubscribeReply{}
+	this.ThreadID = NewPopulatedProtoThreadID(r)
+	this.LogID = NewPopulatedProtoPeerID(r)
+	if r.Intn(10) != 0 {
+		this.Record = NewPopulatedLog_Record(r, easy)
+	}
+	if !easy && r.Intn(10) != 0 {
+	}
+	return this
+}
+
+func NewPopulatedPingRequest(r randyNet, easy bool) *PingRequest {
+	this := &PingRequest{}
+	if !easy && r.Intn(10) != 0 {
+	}
+	return this
+}
+
+func NewPopulatedPingReply(r randyNet, easy bool) *PingReply {
+	this := &PingReply{}
+	if !easy && r.Intn(10) != 0 {
+	}
+	return this
+}
+
+type randyNet interface {
+	Float32() float32
+	Float64() float64
+	Int63() int64
+	Int31() int32
+	Uint32() uint32
+	Intn(n int) int
+}
+
+func randUTF8RuneNet(r randyNet) rune {
+	ru := r.Intn(62)
+	if ru < 10 {
+		return rune(ru + 48)
+	} else if ru < 36 {
+		return rune(ru + 55)
+	}
+	return rune(ru + 61)
+}
+func randStringNet(r randyNet) string {
+	v26 := r.Intn(100)
+	tmps := make([]rune, v26)
+	for i := 0; i < v26; i++ {
+		tmps[i] = randUTF8RuneNet(r)
+	}
+	return string(tmps)
+}
+func randUnrecognizedNet(r randyNet, maxFieldNumber int) (dAtA []byte) {
+	l := r.Intn(5)
+	for i := 0; i < l; i++ {
+		wire := r.Intn(4)
+		if wire == 3 {
+			wire = 5
+		}
+		fieldNumber := maxFieldNumber + r.Intn(100)
+		dAtA = randFieldNet(dAtA, r, fieldNumber, wire)
+	}
+	return dAtA
+}
+func randFieldNet(dAtA []byte, r randyNet, fieldNumber int, wire int) []byte {
+	key := uint32(fieldNumber)<<3 | uint32(wire)
+	switch wire {
+	case 0:
+		dAtA = encodeVarintPopulateNet(dAtA, uint64(key))
+		v27 := r.Int63()
+		if r.Intn(2) == 0 {
+			v27 *= -1
+		}
+		dAtA = encodeVarintPopulateNet(dAtA, uint64(v27))
+	case 1:
+		dAtA = encodeVarintPopulateNet(dAtA, uint64(key))
+		dAtA = append(dAtA, byte(r.Intn(256)), byte(r.Intn(256)), byte(r.Intn(256)), byte(r.Intn(256)), byte(r.Intn(256)), byte(r.Intn(256)), byte(r.Intn(256)), byte(r.Intn(256)))
+	case 2:
+		dAtA = encodeVarintPopulateNet(dAtA, uint64(key))
+		ll := r.Intn(100)
+		dAtA = encodeVarintPopulateNet(dAtA, uint64(ll))
+		for j := 0; j < ll; j++ {
+			dAtA = append(dAtA, byte(r.Intn(256)))
+		}
+	default:
+		dAtA = encodeVarintPopulateNet(dAtA, uint64(key))
+		dAtA = append(dAtA, byte(r.Intn(256)), byte(r.Intn(256)), byte(r.Intn(256)), byte(r.Intn(256)))
+	}
+	return dAtA
+}
+func encodeVarintPopulateNet(dAtA []byte, v uint64) []byte {
+	for v >= 1<<7 {
+		dAtA = append(dAtA, uint8(uint64(v)&0x7f|0x80))
+		v >>= 7
+	}
+	dAtA = append(dAtA, uint8(v))
+	return dAtA
+}
+func (m *Header) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.PubKey != nil {
+		l = m.PubKey.Size()
+		n += 1 + l + sovNet(uint64(l))
+	}
+	l = len(m.Signature)
+	if l > 0 {
+		n += 1 + l + sovNet(uint64(l))
+	}
+	if m.Compact {
+		n += 2
+	}
+	return n
+}
+
+func (m *Log) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.ID != nil {
+		l = m.ID.Size()
+		n += 1 + l + sovNet(uint64(l))
+	}
+	if m.PubKey != nil {
+		l = m.PubKey.Size()
+		n += 1 + l + sovNet(uint64(l))
+	}
+	if len(m.Addrs) > 0 {
+		for _, e := range m.Addrs {
+			l = e.Size()
+			n += 1 + l + sovNet(uint64(l))
+		}
+	}
+	if m.Head != nil {
+		l = m.Head.Size()
+		n += 1 + l + sovNet(uint64(l))
+	}
+	return n
+}
+
+func (m *Log_Record) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.RecordNode)
+	if l > 0 {
+		n += 1 + l + sovNet(uint64(l))
+	}
+	l = len(m.EventNode)
+	if l > 0 {
+		n += 1 + l + sovNet(uint64(l))
+	}
+	l = len(m.HeaderNode)
+	if l > 0 {
+		n += 1 + l + sovNet(uint64(l))
+	}
+	l = len(m.BodyNode)
+	if l > 0 {
+		n += 1 + l + sovNet(uint64(l))
+	}
+	return n
+}
+
+func (m *GetLogsRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Header != nil {
+		l = m.Header.Size()
+		n += 1 + l + sovNet(uint64(l))
+	}
+	if m.Body != nil {
+		l = m.Body.Size()
+		n += 1 + l + sovNet(uint64(l))
+	}
+	return n
+}
+
+func (m *GetLogsRequest_Body) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.ThreadID != nil {
+		l = m.ThreadID.Size()
+		n += 1 + l + sovNet(uint64(l))
+	}
+	if m.ServiceKey != nil {
+		l = m.ServiceKey.Size()
+		n += 1 + l + sovNet(uint64(l))
+	}
+	return n
+}
+
+func (m *GetLogsReply) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Logs) > 0 {
+		for _, e := range m.Logs {
+			l = e.Size()
+			n += 1 + l + sovNet(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *GetLogsBatchRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Header != nil {
+		l = m.Header.Size()
+		n += 1 + l + sovNet(uint64(l))
+	}
+	if m.Body != nil {
+		l = m.Body.Size()
+		n += 1 + l + sovNet(uint64(l))
+	}
+	return n
+}
+
+func (m *GetLogsBatchRequest_Body) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Threads) > 0 {
+		for _, e := range m.Threads {
+			l = e.Size()
+			n += 1 + l + sovNet(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *GetLogsBatchReply) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Threads) > 0 {
+		for _, e := range m.Threads {
+			l = e.Size()
+			n += 1 + l + sovNet(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *GetLogsBatchReply_ThreadEntry) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.ThreadID != nil {
+		l = m.ThreadID.Size()
+		n += 1 + l + sovNet(uint64(l))
+	}
+	if len(m.Logs) > 0 {
+		for _, e := range m.Logs {
+			l = e.Size()
+			n += 1 + l + sovNet(uint64(l))
+		}
+	}
+	l = len(m.Error)
+	if l > 0 {
+		n += 1 + l + sovNet(uint64(l))
+	}
+	return n
+}
+
+func (m *GetHeadsRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Header != nil {
+		l = m.Header.Size()
+		n += 1 + l + sovNet(uint64(l))
+	}
+	if m.Body != nil {
+		l = m.Body.Size()
+		n += 1 + l + sovNet(uint64(l))
+	}
+	return n
+}
+
+func (m *GetHeadsRequest_Body) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.ThreadID != nil {
+		l = m.ThreadID.Size()
+		n += 1 + l + sovNet(uint64(l))
+	}
+	if m.ServiceKey != nil {
+		l = m.ServiceKey.Size()
+		n += 1 + l + sovNet(uint64(l))
+	}
+	if m.LogID != nil {
+		l = m.LogID.Size()
+		n += 1 + l + sovNet(uint64(l))
+	}
+	return n
+}
+
+func (m *GetHeadsReply) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Heads) > 0 {
+		for _, e := range m.Heads {
+			l = e.Size()
+			n += 1 + l + sovNet(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *PushLogRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Header != nil {
+		l = m.Header.Size()
+		n += 1 + l + sovNet(uint64(l))
+	}
+	if m.Body != nil {
+		l = m.Body.Size()
+		n += 1 + l + sovNet(uint64(l))
+	}
+	return n
+}
+
+func (m *PushLogRequest_Body) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.ThreadID != nil {
+		l = m.ThreadID.Size()
+		n += 1 + l + sovNet(uint64(l))
+	}
+	if m.ServiceKey != nil {
+		l = m.ServiceKey.Size()
+		n += 1 + l + sovNet(uint64(l))
+	}
+	if m.ReadKey != nil {
+		l = m.ReadKey.Size()
+		n += 1 + l + sovNet(uint64(l))
+	}
+	if m.Log != nil {
+		l = m.Log.Size()
+		n += 1 + l + sovNet(uint64(l))
+	}
+	if m.KeyChange != nil {
+		l = m.KeyChange.Size()
+		n += 1 + l + sovNet(uint64(l))
+	}
+	return n
+}
+
+func (m *PushLogRequest_KeyChange) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.LogID != nil {
+		l = m.LogID.Size()
+		n += 1 + l + sovNet(uint64(l))
+	}
+	l = len(m.Signature)
+	if l > 0 {
+		n += 1 + l + sovNet(uint64(l))
+	}
+	if len(m.Revoked) > 0 {
+		for _, e := range m.Revoked {
+			l = e.Size()
+			n += 1 + l + sovNet(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *PushLogReply) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	return n
+}
+
+func (m *GetRecordsRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Header != nil {
+		l = m.Header.Size()
+		n += 1 + l + sovNet(uint64(l))
+	}
+	if m.Body != nil {
+		l = m.Body.Size()
+		n += 1 + l + sovNet(uint64(l))
+	}
+	return n
+}
+
+func (m *GetRecordsRequest_Body) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.ThreadID != nil {
+		l = m.ThreadID.Size()
+		n += 1 + l + sovNet(uint64(l))
+	}
+	if m.ServiceKey != nil {
+		l = m.ServiceKey.Size()
+		n += 1 + l + sovNet(uint64(l))
+	}
+	if len(m.Logs) > 0 {
+		for _, e := range m.Logs {
+			l = e.Size()
+			n += 1 + l + sovNet(uint64(l))
+		}
+	}
+	if m.Proof {
+		n += 2
+	}
+	return n
+}
+
+func (m *GetRecordsRequest_Body_LogEntry) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.LogID != nil {
+		l = m.LogID.Size()
+		n += 1 + l + sovNet(uint64(l))
+	}
+	if m.Offset != nil {
+		l = m.Offset.Size()
+		n += 1 + l + sovNet(uint64(l))
+	}
+	if m.Limit != 0 {
+		n += 1 + sovNet(uint64(m.Limit))
+	}
+	if m.To != nil {
+		l = m.To.Size()
+		n += 1 + l + sovNet(uint64(l))
+	}
+	return n
+}
+
+func (m *GetRecordsReply) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Logs) > 0 {
+		for _, e := range m.Logs {
+			l = e.Size()
+			n += 1 + l + sovNet(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *GetRecordsReply_LogEntry) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.LogID != nil {
+		l = m.LogID.Size()
+		n += 1 + l + sovNet(uint64(l))
+	}
+	if len(m.Records) > 0 {
+		for _, e := range m.Records {
+			l = e.Size()
+			n += 1 + l + sovNet(uint64(l))
+		}
+	}
+	if m.Log != nil {
+		l = m.Log.Size()
+		n += 1 + l + sovNet(uint64(l))
+	}
+	if m.Manifest != nil {
+		l = m.Manifest.Size()
+		n += 1 + l + sovNet(uint64(l))
+	}
+	if m.Truncated {
+		n += 2
+	}
+	return n
+}
+
+func (m *GetRecordsStreamReply) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.LogID != nil {
+		l = m.LogID.Size()
+		n += 1 + l + sovNet(uint64(l))
+	}
+	if m.Record != nil {
+		l = m.Record.Size()
+		n += 1 + l + sovNet(uint64(l))
+	}
+	if m.Log != nil {
+		l = m.Log.Size()
+		n += 1 + l + sovNet(uint64(l))
+	}
+	if m.Truncated {
+		n += 2
+	}
+	return n
+}
+
+func (m *Manifest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Header != nil {
+		l = m.Header.Size()
+		n += 1 + l + sovNet(uint64(l))
+	}
+	if m.Body != nil {
+		l = m.Body.Size()
+		n += 1 + l + sovNet(uint64(l))
+	}
+	return n
+}
+
+func (m *Manifest_Body) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.ThreadID != nil {
+		l = m.ThreadID.Size()
+		n += 1 + l + sovNet(uint64(l))
+	}
+	if m.LogID != nil {
+		l = m.LogID.Size()
+		n += 1 + l + sovNet(uint64(l))
+	}
+	if m.Offset != nil {
+		l = m.Offset.Size()
+		n += 1 + l + sovNet(uint64(l))
+	}
+	if len(m.Records) > 0 {
+		for _, e := range m.Records {
+			l = e.Size()
+			n += 1 + l + sovNet(uint64(l))
+		}
+	}
+	if m.HeadBlock != nil {
+		l = m.HeadBlock.Size()
+		n += 1 + l + sovNet(uint64(l))
+	}
+	if m.HeadPrev != nil {
+		l = m.HeadPrev.Size()
+		n += 1 + l + sovNet(uint64(l))
+	}
+	l = len(m.HeadSig)
+	if l > 0 {
+		n += 1 + l + sovNet(uint64(l))
+	}
+	l = len(m.HeadPubKey)
+	if l > 0 {
+		n += 1 + l + sovNet(uint64(l))
+	}
+	return n
+}
+
+func (m *PushRecordRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Header != nil {
+		l = m.Header.Size()
+		n += 1 + l + sovNet(uint64(l))
+	}
+	if m.Body != nil {
+		l = m.Body.Size()
+		n += 1 + l + sovNet(uint64(l))
+	}
+	return n
+}
+
+func (m *PushRecordRequest_Body) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.ThreadID != nil {
+		l = m.ThreadID.Size()
+		n += 1 + l + sovNet(uint64(l))
+	}
+	if m.LogID != nil {
+		l = m.LogID.Size()
+		n += 1 + l + sovNet(uint64(l))
+	}
+	if m.Record != nil {
+		l = m.Record.Size()
+		n += 1 + l + sovNet(uint64(l))
+	}
+	return n
+}
+
+func (m *PushRecordReply) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	return n
+}
+
+func (m *PushRecordsRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Header != nil {
+		l = m.Header.Size()
+		n += 1 + l + sovNet(uint64(l))
+	}
+	if m.Body != nil {
+		l = m.Body.Size()
+		n += 1 + l + sovNet(uint64(l))
+	}
+	return n
+}
+
+func (m *PushRecordsRequest_Body) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.ThreadID != nil {
+		l = m.ThreadID.Size()
+		n += 1 + l + sovNet(uint64(l))
+	}
+	if m.LogID != nil {
+		l = m.LogID.Size()
+		n += 1 + l + sovNet(uint64(l))
+	}
+	if len(m.Records) > 0 {
+		for _, e := range m.Records {
+			l = e.Size()
+			n += 1 + l + sovNet(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *PushRecordsReply) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Results) > 0 {
+		for _, e := range m.Results {
+			l = e.Size()
+			n += 1 + l + sovNet(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *PushRecordsReply_Result) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Code != 0 {
+		n += 1 + sovNet(uint64(m.Code))
+	}
+	l = len(m.Message)
+	if l > 0 {
+		n += 1 + l + sovNet(uint64(l))
+	}
+	return n
+}
+
+func (m *SubscribeRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Header != nil {
+		l = m.Header.Size()
+		n += 1 + l + sovNet(uint64(l))
+	}
+	if m.Body != nil {
+		l = m.Body.Size()
+		n += 1 + l + sovNet(uint64(l))
+	}
+	return n
+}
+
+func (m *SubscribeRequest_Body) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.ThreadID != nil {
+		l = m.ThreadID.Size()
+		n += 1 + l + sovNet(uint64(l))
+	}
+	if m.ServiceKey != nil {
+		l = m.ServiceKey.Size()
+		n += 1 + l + sovNet(uint64(l))
+	}
+	return n
+}
+
+func (m *SubscribeReply) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.ThreadID != nil {
+		l = m.ThreadID.Size()
+		n += 1 + l + sovNet(uint64(l))
+	}
+	if m.LogID != nil {
+		l = m.LogID.Size()
+		n += 1 + l + sovNet(uint64(l))
+	}
+	if m.Record != nil {
+		l = m.Record.Size()
+		n += 1 + l + sovNet(uint64(l))
+	}
+	return n
+}
+
+func (m *PingRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	return n
+}
+
+func (m *PingReply) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	return n
+}
+
+func sovNet(x uint64) (n int) {
+	for {
+		n++
+		x >>= 7
+		if x == 0 {
+			break
+		}
+	}
+	return n
+}
+func sozNet(x uint64) (n int) {
+	return sovNet(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (m *Header) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowNet
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Header: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Header: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field PubKey", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowNet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthNet
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthNet
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			var v ProtoPubKey
+			m.PubKey = &v
+			if err := m.PubKey.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Signature", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowNet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthNet
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthNet
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Signature = append(m.Signature[:0], dAtA[iNdEx:postIndex]...)
+			if m.Signature == nil {
+				m.Signature = []byte{}
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Compact", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowNet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Compact = bool(v != 0)
+		default:
+			iNdEx = preIndex
+			skippy, err := skipNet(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthNet
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthNet
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *Log) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowNet
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Log: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Log: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ID", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowNet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthNet
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthNet
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			var v ProtoPeerID
+			m.ID = &v
+			if err := m.ID.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field PubKey", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowNet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthNet
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthNet
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			var v ProtoPubKey
+			m.PubKey = &v
+			if err := m.PubKey.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Addrs", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowNet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthNet
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthNet
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			var v ProtoAddr
+			m.Addrs = append(m.Addrs, v)
+			if err := m.Addrs[len(m.Addrs)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Head", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowNet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthNet
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthNet
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			var v ProtoCid
+			m.Head = &v
+			if err := m.Head.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipNet(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthNet
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthNet
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *Log_Record) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowNet
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Record: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Record: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RecordNode", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowNet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthNet
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthNet
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.RecordNode = append(m.RecordNode[:0], dAtA[iNdEx:postIndex]...)
+			if m.RecordNode == nil {
+				m.RecordNode = []byte{}
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field EventNode", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowNet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthNet
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthNet
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.EventNode = append(m.EventNode[:0], dAtA[iNdEx:postIndex]...)
+			if m.EventNode == nil {
+				m.EventNode = []byte{}
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field HeaderNode", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowNet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthNet
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthNet
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.HeaderNode = append(m.HeaderNode[:0], dAtA[iNdEx:postIndex]...)
+			if m.HeaderNode == nil {
+				m.HeaderNode = []byte{}
+			}
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field BodyNode", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowNet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthNet
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthNet
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.BodyNode = append(m.BodyNode[:0], dAtA[iNdEx:postIndex]...)
+			if m.BodyNode == nil {
+				m.BodyNode = []byte{}
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipNet(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthNet
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthNet
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *GetLogsRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowNet
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: GetLogsRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: GetLogsRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Header", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowNet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthNet
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthNet
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Header == nil {
+				m.Header = &Header{}
+			}
+			if err := m.Header.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Body", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowNet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthNet
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthNet
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Body == nil {
+				m.Body = &GetLogsRequest_Body{}
+			}
+			if err := m.Body.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipNet(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthNet
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthNet
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *GetLogsRequest_Body) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowNet
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Body: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Body: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ThreadID", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowNet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthNet
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthNet
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			var v ProtoThreadID
+			m.ThreadID = &v
+			if err := m.ThreadID.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ServiceKey", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowNet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthNet
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthNet
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			var v ProtoKey
+			m.ServiceKey = &v
+			if err := m.ServiceKey.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipNet(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthNet
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthNet
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *GetLogsReply) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowNet
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: GetLogsReply: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: GetLogsReply: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Logs", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowNet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthNet
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthNet
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Logs = append(m.Logs, &Log{})
+			if err := m.Logs[len(m.Logs)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipNet(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthNet
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthNet
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *GetLogsBatchRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowNet
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: GetLogsBatchRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: GetLogsBatchRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Header", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowNet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthNet
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthNet
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Header == nil {
+				m.Header = &Header{}
+			}
+			if err := m.Header.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Body", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowNet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthNet
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthNet
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Body == nil {
+				m.Body = &GetLogsBatchRequest_Body{}
+			}
+			if err := m.Body.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipNet(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthNet
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthNet
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *GetLogsBatchRequest_Body) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowNet
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Body: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Body: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Threads", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowNet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthNet
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthNet
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Threads = append(m.Threads, &GetLogsRequest_Body{})
+			if err := m.Threads[len(m.Threads)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipNet(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthNet
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthNet
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *GetLogsBatchReply) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowNet
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: GetLogsBatchReply: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: GetLogsBatchReply: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Threads", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowNet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthNet
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthNet
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Threads = append(m.Threads, &GetLogsBatchReply_ThreadEntry{})
+			if err := m.Threads[len(m.Threads)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipNet(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthNet
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthNet
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *GetLogsBatchReply_ThreadEntry) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowNet
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ThreadEntry: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ThreadEntry: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ThreadID", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowNet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthNet
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthNet
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			var v ProtoThreadID
+			m.ThreadID = &v
+			if err := m.ThreadID.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Logs", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowNet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthNet
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthNet
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Logs = append(m.Logs, &Log{})
+			if err := m.Logs[len(m.Logs)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Error", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowNet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthNet
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthNet
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Error = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipNet(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthNet
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthNet
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *GetHeadsRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowNet
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: GetHeadsRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: GetHeadsRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Header", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowNet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthNet
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthNet
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Header == nil {
+				m.Header = &Header{}
+			}
+			if err := m.Header.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Body", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowNet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthNet
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthNet
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Body == nil {
+				m.Body = &GetHeadsRequest_Body{}
+			}
+			if err := m.Body.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipNet(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthNet
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthNet
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *GetHeadsRequest_Body) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowNet
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Body: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Body: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ThreadID", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowNet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthNet
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthNet
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			var v ProtoThreadID
+			m.ThreadID = &v
+			if err := m.ThreadID.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ServiceKey", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowNet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthNet
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthNet
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			var v ProtoKey
+			m.ServiceKey = &v
+			if err := m.ServiceKey.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field LogID", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowNet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthNet
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthNet
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			var v ProtoPeerID
+			m.LogID = &v
+			if err := m.LogID.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipNet(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthNet
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthNet
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *GetHeadsReply) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowNet
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: GetHeadsReply: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: GetHeadsReply: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Heads", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowNet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthNet
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthNet
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			var v ProtoCid
+			m.Heads = append(m.Heads, v)
+			if err := m.Heads[len(m.Heads)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipNet(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthNet
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthNet
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *PushLogRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowNet
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: PushLogRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: PushLogRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Header", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowNet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthNet
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthNet
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Header == nil {
+				m.Header = &Header{}
+			}
+			if err := m.Header.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Body", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowNet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthNet
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthNet
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Body == nil {
+				m.Body = &PushLogRequest_Body{}
+			}
+			if err := m.Body.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipNet(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthNet
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthNet
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *PushLogRequest_Body) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowNet
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Body: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Body: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ThreadID", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowNet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthNet
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthNet
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			var v ProtoThreadID
+			m.ThreadID = &v
+			if err := m.ThreadID.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ServiceKey", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowNet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthNet
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthNet
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			var v ProtoKey
+			m.ServiceKey = &v
+			if err := m.ServiceKey.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ReadKey", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowNet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthNet
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthNet
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			var v ProtoKey
+			m.ReadKey = &v
+			if err := m.ReadKey.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Log", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowNet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthNet
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthNet
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Log == nil {
+				m.Log = &Log{}
+			}
+			if err := m.Log.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field KeyChange", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowNet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthNet
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthNet
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.KeyChange == nil {
+				m.KeyChange = &PushLogRequest_KeyChange{}
+			}
+			if err := m.KeyChange.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipNet(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthNet
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthNet
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *PushLogRequest_KeyChange) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowNet
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: KeyChange: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: KeyChange: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field LogID", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowNet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthNet
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthNet
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			var v ProtoPeerID
+			m.LogID = &v
+			if err := m.LogID.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Signature", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowNet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthNet
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthNet
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Signature = append(m.Signature[:0], dAtA[iNdEx:postIndex]...)
+			if m.Signature == nil {
+				m.Signature = []byte{}
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Revoked", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowNet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthNet
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthNet
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			var v ProtoPeerID
+			m.Revoked = append(m.Revoked, v)
+			if err := m.Revoked[len(m.Revoked)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipNet(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthNet
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthNet
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *PushLogReply) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowNet
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: PushLogReply: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: PushLogReply: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		default:
+			iNdEx = preIndex
+			skippy, err := skipNet(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthNet
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthNet
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *GetRecordsRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowNet
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: GetRecordsRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: GetRecordsRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Header", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowNet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthNet
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthNet
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Header == nil {
+				m.Header = &Header{}
+			}
+			if err := m.Header.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Body", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowNet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthNet
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthNet
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Body == nil {
+				m.Body = &GetRecordsRequest_Body{}
+			}
+			if err := m.Body.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipNet(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthNet
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthNet
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *GetRecordsRequest_Body) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowNet
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Body: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Body: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ThreadID", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowNet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthNet
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthNet
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			var v ProtoThreadID
+			m.ThreadID = &v
+			if err := m.ThreadID.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ServiceKey", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowNet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthNet
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthNet
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			var v ProtoKey
+			m.ServiceKey = &v
+			if err := m.ServiceKey.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Logs", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowNet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthNet
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthNet
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Logs = append(m.Logs, &GetRecordsRequest_Body_LogEntry{})
+			if err := m.Logs[len(m.Logs)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Proof", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowNet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Proof = bool(v != 0)
+		default:
+			iNdEx = preIndex
+			skippy, err := skipNet(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthNet
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthNet
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
 	}
-	var l int
-	_ = l
-	return n
-}
 
-func sovNet(x uint64) (n int) {
-	for {
-		n++
-		x >>= 7
-		if x == 0 {
-			break
-		}
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
 	}
-	return n
-}
-func sozNet(x uint64) (n int) {
-	return sovNet(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+	return nil
 }
-func (m *Header) Unmarshal(dAtA []byte) error {
+func (m *GetRecordsRequest_Body_LogEntry) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -2458,15 +7927,15 @@ func (m *Header) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: Header: wiretype end group for non-group")
+			return fmt.Errorf("proto: LogEntry: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: Header: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: LogEntry: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field PubKey", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field LogID", wireType)
 			}
 			var byteLen int
 			for shift := uint(0); ; shift += 7 {
@@ -2493,15 +7962,15 @@ func (m *Header) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			var v ProtoPubKey
-			m.PubKey = &v
-			if err := m.PubKey.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+			var v ProtoPeerID
+			m.LogID = &v
+			if err := m.LogID.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
 		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Signature", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Offset", wireType)
 			}
 			var byteLen int
 			for shift := uint(0); ; shift += 7 {
@@ -2528,9 +7997,64 @@ func (m *Header) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Signature = append(m.Signature[:0], dAtA[iNdEx:postIndex]...)
-			if m.Signature == nil {
-				m.Signature = []byte{}
+			var v ProtoCid
+			m.Offset = &v
+			if err := m.Offset.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Limit", wireType)
+			}
+			m.Limit = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowNet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Limit |= int32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field To", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowNet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthNet
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthNet
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			var v ProtoCid
+			m.To = &v
+			if err := m.To.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
 			}
 			iNdEx = postIndex
 		default:
@@ -2557,7 +8081,7 @@ func (m *Header) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *Log) Unmarshal(dAtA []byte) error {
+func (m *GetRecordsReply) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -2580,15 +8104,102 @@ func (m *Log) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: Log: wiretype end group for non-group")
+			return fmt.Errorf("proto: GetRecordsReply: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: Log: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: GetRecordsReply: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ID", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Logs", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowNet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthNet
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthNet
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Logs = append(m.Logs, &GetRecordsReply_LogEntry{})
+			if err := m.Logs[len(m.Logs)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipNet(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthNet
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthNet
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *GetRecordsReply_LogEntry) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowNet
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: LogEntry: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: LogEntry: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field LogID", wireType)
 			}
 			var byteLen int
 			for shift := uint(0); ; shift += 7 {
@@ -2616,16 +8227,16 @@ func (m *Log) Unmarshal(dAtA []byte) error {
 				return io.ErrUnexpectedEOF
 			}
 			var v ProtoPeerID
-			m.ID = &v
-			if err := m.ID.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+			m.LogID = &v
+			if err := m.LogID.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
 		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field PubKey", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Records", wireType)
 			}
-			var byteLen int
+			var msglen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowNet
@@ -2635,32 +8246,31 @@ func (m *Log) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				byteLen |= int(b&0x7F) << shift
+				msglen |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if byteLen < 0 {
+			if msglen < 0 {
 				return ErrInvalidLengthNet
 			}
-			postIndex := iNdEx + byteLen
+			postIndex := iNdEx + msglen
 			if postIndex < 0 {
 				return ErrInvalidLengthNet
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			var v ProtoPubKey
-			m.PubKey = &v
-			if err := m.PubKey.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+			m.Records = append(m.Records, &Log_Record{})
+			if err := m.Records[len(m.Records)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
 		case 3:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Addrs", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Log", wireType)
 			}
-			var byteLen int
+			var msglen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowNet
@@ -2670,32 +8280,33 @@ func (m *Log) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				byteLen |= int(b&0x7F) << shift
+				msglen |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if byteLen < 0 {
+			if msglen < 0 {
 				return ErrInvalidLengthNet
 			}
-			postIndex := iNdEx + byteLen
+			postIndex := iNdEx + msglen
 			if postIndex < 0 {
 				return ErrInvalidLengthNet
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			var v ProtoAddr
-			m.Addrs = append(m.Addrs, v)
-			if err := m.Addrs[len(m.Addrs)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+			if m.Log == nil {
+				m.Log = &Log{}
+			}
+			if err := m.Log.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
 		case 4:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Head", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Manifest", wireType)
 			}
-			var byteLen int
+			var msglen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowNet
@@ -2705,27 +8316,48 @@ func (m *Log) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				byteLen |= int(b&0x7F) << shift
+				msglen |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if byteLen < 0 {
+			if msglen < 0 {
 				return ErrInvalidLengthNet
 			}
-			postIndex := iNdEx + byteLen
+			postIndex := iNdEx + msglen
 			if postIndex < 0 {
 				return ErrInvalidLengthNet
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			var v ProtoCid
-			m.Head = &v
-			if err := m.Head.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+			if m.Manifest == nil {
+				m.Manifest = &Manifest{}
+			}
+			if err := m.Manifest.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Truncated", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowNet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Truncated = bool(v != 0)
 		default:
 			iNdEx = preIndex
 			skippy, err := skipNet(dAtA[iNdEx:])
@@ -2750,7 +8382,7 @@ func (m *Log) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *Log_Record) Unmarshal(dAtA []byte) error {
+func (m *GetRecordsStreamReply) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -2773,15 +8405,15 @@ func (m *Log_Record) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: Record: wiretype end group for non-group")
+			return fmt.Errorf("proto: GetRecordsStreamReply: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: Record: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: GetRecordsStreamReply: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field RecordNode", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field LogID", wireType)
 			}
 			var byteLen int
 			for shift := uint(0); ; shift += 7 {
@@ -2808,16 +8440,17 @@ func (m *Log_Record) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.RecordNode = append(m.RecordNode[:0], dAtA[iNdEx:postIndex]...)
-			if m.RecordNode == nil {
-				m.RecordNode = []byte{}
+			var v ProtoPeerID
+			m.LogID = &v
+			if err := m.LogID.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
 			}
 			iNdEx = postIndex
 		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field EventNode", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Record", wireType)
 			}
-			var byteLen int
+			var msglen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowNet
@@ -2827,31 +8460,33 @@ func (m *Log_Record) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				byteLen |= int(b&0x7F) << shift
+				msglen |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if byteLen < 0 {
+			if msglen < 0 {
 				return ErrInvalidLengthNet
 			}
-			postIndex := iNdEx + byteLen
+			postIndex := iNdEx + msglen
 			if postIndex < 0 {
 				return ErrInvalidLengthNet
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.EventNode = append(m.EventNode[:0], dAtA[iNdEx:postIndex]...)
-			if m.EventNode == nil {
-				m.EventNode = []byte{}
+			if m.Record == nil {
+				m.Record = &Log_Record{}
+			}
+			if err := m.Record.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
 			}
 			iNdEx = postIndex
 		case 3:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field HeaderNode", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Log", wireType)
 			}
-			var byteLen int
+			var msglen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowNet
@@ -2861,31 +8496,33 @@ func (m *Log_Record) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				byteLen |= int(b&0x7F) << shift
+				msglen |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if byteLen < 0 {
+			if msglen < 0 {
 				return ErrInvalidLengthNet
 			}
-			postIndex := iNdEx + byteLen
+			postIndex := iNdEx + msglen
 			if postIndex < 0 {
 				return ErrInvalidLengthNet
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.HeaderNode = append(m.HeaderNode[:0], dAtA[iNdEx:postIndex]...)
-			if m.HeaderNode == nil {
-				m.HeaderNode = []byte{}
+			if m.Log == nil {
+				m.Log = &Log{}
+			}
+			if err := m.Log.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
 			}
 			iNdEx = postIndex
-		case 4:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field BodyNode", wireType)
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Truncated", wireType)
 			}
-			var byteLen int
+			var v int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowNet
@@ -2895,26 +8532,12 @@ func (m *Log_Record) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				byteLen |= int(b&0x7F) << shift
+				v |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if byteLen < 0 {
-				return ErrInvalidLengthNet
-			}
-			postIndex := iNdEx + byteLen
-			if postIndex < 0 {
-				return ErrInvalidLengthNet
-			}
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
-			}
-			m.BodyNode = append(m.BodyNode[:0], dAtA[iNdEx:postIndex]...)
-			if m.BodyNode == nil {
-				m.BodyNode = []byte{}
-			}
-			iNdEx = postIndex
+			m.Truncated = bool(v != 0)
 		default:
 			iNdEx = preIndex
 			skippy, err := skipNet(dAtA[iNdEx:])
@@ -2939,7 +8562,7 @@ func (m *Log_Record) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *GetLogsRequest) Unmarshal(dAtA []byte) error {
+func (m *Manifest) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -2962,10 +8585,10 @@ func (m *GetLogsRequest) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: GetLogsRequest: wiretype end group for non-group")
+			return fmt.Errorf("proto: Manifest: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: GetLogsRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: Manifest: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
@@ -3034,7 +8657,7 @@ func (m *GetLogsRequest) Unmarshal(dAtA []byte) error {
 				return io.ErrUnexpectedEOF
 			}
 			if m.Body == nil {
-				m.Body = &GetLogsRequest_Body{}
+				m.Body = &Manifest_Body{}
 			}
 			if err := m.Body.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
@@ -3064,7 +8687,7 @@ func (m *GetLogsRequest) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *GetLogsRequest_Body) Unmarshal(dAtA []byte) error {
+func (m *Manifest_Body) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -3130,7 +8753,7 @@ func (m *GetLogsRequest_Body) Unmarshal(dAtA []byte) error {
 			iNdEx = postIndex
 		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ServiceKey", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field LogID", wireType)
 			}
 			var byteLen int
 			for shift := uint(0); ; shift += 7 {
@@ -3157,70 +8780,191 @@ func (m *GetLogsRequest_Body) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			var v ProtoKey
-			m.ServiceKey = &v
-			if err := m.ServiceKey.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+			var v ProtoPeerID
+			m.LogID = &v
+			if err := m.LogID.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
-		default:
-			iNdEx = preIndex
-			skippy, err := skipNet(dAtA[iNdEx:])
-			if err != nil {
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Offset", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowNet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthNet
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthNet
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			var v ProtoCid
+			m.Offset = &v
+			if err := m.Offset.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
-			if skippy < 0 {
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Records", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowNet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
 				return ErrInvalidLengthNet
 			}
-			if (iNdEx + skippy) < 0 {
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
 				return ErrInvalidLengthNet
 			}
-			if (iNdEx + skippy) > l {
+			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			iNdEx += skippy
-		}
-	}
-
-	if iNdEx > l {
-		return io.ErrUnexpectedEOF
-	}
-	return nil
-}
-func (m *GetLogsReply) Unmarshal(dAtA []byte) error {
-	l := len(dAtA)
-	iNdEx := 0
-	for iNdEx < l {
-		preIndex := iNdEx
-		var wire uint64
-		for shift := uint(0); ; shift += 7 {
-			if shift >= 64 {
-				return ErrIntOverflowNet
+			var v ProtoCid
+			m.Records = append(m.Records, v)
+			if err := m.Records[len(m.Records)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
 			}
-			if iNdEx >= l {
+			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field HeadBlock", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowNet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthNet
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthNet
+			}
+			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			b := dAtA[iNdEx]
-			iNdEx++
-			wire |= uint64(b&0x7F) << shift
-			if b < 0x80 {
-				break
+			var v ProtoCid
+			m.HeadBlock = &v
+			if err := m.HeadBlock.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field HeadPrev", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowNet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthNet
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthNet
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			var v ProtoCid
+			m.HeadPrev = &v
+			if err := m.HeadPrev.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 7:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field HeadSig", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowNet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthNet
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthNet
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
 			}
-		}
-		fieldNum := int32(wire >> 3)
-		wireType := int(wire & 0x7)
-		if wireType == 4 {
-			return fmt.Errorf("proto: GetLogsReply: wiretype end group for non-group")
-		}
-		if fieldNum <= 0 {
-			return fmt.Errorf("proto: GetLogsReply: illegal tag %d (wire type %d)", fieldNum, wire)
-		}
-		switch fieldNum {
-		case 1:
+			m.HeadSig = append(m.HeadSig[:0], dAtA[iNdEx:postIndex]...)
+			if m.HeadSig == nil {
+				m.HeadSig = []byte{}
+			}
+			iNdEx = postIndex
+		case 8:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Logs", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field HeadPubKey", wireType)
 			}
-			var msglen int
+			var byteLen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowNet
@@ -3230,24 +8974,24 @@ func (m *GetLogsReply) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				msglen |= int(b&0x7F) << shift
+				byteLen |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
+			if byteLen < 0 {
 				return ErrInvalidLengthNet
 			}
-			postIndex := iNdEx + msglen
+			postIndex := iNdEx + byteLen
 			if postIndex < 0 {
 				return ErrInvalidLengthNet
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Logs = append(m.Logs, &Log{})
-			if err := m.Logs[len(m.Logs)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
+			m.HeadPubKey = append(m.HeadPubKey[:0], dAtA[iNdEx:postIndex]...)
+			if m.HeadPubKey == nil {
+				m.HeadPubKey = []byte{}
 			}
 			iNdEx = postIndex
 		default:
@@ -3274,7 +9018,7 @@ func (m *GetLogsReply) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *PushLogRequest) Unmarshal(dAtA []byte) error {
+func (m *PushRecordRequest) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -3297,10 +9041,10 @@ func (m *PushLogRequest) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: PushLogRequest: wiretype end group for non-group")
+			return fmt.Errorf("proto: PushRecordRequest: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: PushLogRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: PushRecordRequest: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
@@ -3369,7 +9113,7 @@ func (m *PushLogRequest) Unmarshal(dAtA []byte) error {
 				return io.ErrUnexpectedEOF
 			}
 			if m.Body == nil {
-				m.Body = &PushLogRequest_Body{}
+				m.Body = &PushRecordRequest_Body{}
 			}
 			if err := m.Body.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
@@ -3399,7 +9143,7 @@ func (m *PushLogRequest) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *PushLogRequest_Body) Unmarshal(dAtA []byte) error {
+func (m *PushRecordRequest_Body) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -3465,7 +9209,7 @@ func (m *PushLogRequest_Body) Unmarshal(dAtA []byte) error {
 			iNdEx = postIndex
 		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ServiceKey", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field LogID", wireType)
 			}
 			var byteLen int
 			for shift := uint(0); ; shift += 7 {
@@ -3492,50 +9236,15 @@ func (m *PushLogRequest_Body) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			var v ProtoKey
-			m.ServiceKey = &v
-			if err := m.ServiceKey.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+			var v ProtoPeerID
+			m.LogID = &v
+			if err := m.LogID.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
 		case 3:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ReadKey", wireType)
-			}
-			var byteLen int
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowNet
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				byteLen |= int(b&0x7F) << shift
-				if b < 0x80 {
-					break
-				}
-			}
-			if byteLen < 0 {
-				return ErrInvalidLengthNet
-			}
-			postIndex := iNdEx + byteLen
-			if postIndex < 0 {
-				return ErrInvalidLengthNet
-			}
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
-			}
-			var v ProtoKey
-			m.ReadKey = &v
-			if err := m.ReadKey.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
-			}
-			iNdEx = postIndex
-		case 4:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Log", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Record", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -3562,10 +9271,10 @@ func (m *PushLogRequest_Body) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if m.Log == nil {
-				m.Log = &Log{}
+			if m.Record == nil {
+				m.Record = &Log_Record{}
 			}
-			if err := m.Log.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+			if err := m.Record.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
@@ -3593,7 +9302,7 @@ func (m *PushLogRequest_Body) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *PushLogReply) Unmarshal(dAtA []byte) error {
+func (m *PushRecordReply) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -3616,10 +9325,10 @@ func (m *PushLogReply) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: PushLogReply: wiretype end group for non-group")
+			return fmt.Errorf("proto: PushRecordReply: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: PushLogReply: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: PushRecordReply: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		default:
@@ -3646,7 +9355,7 @@ func (m *PushLogReply) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *GetRecordsRequest) Unmarshal(dAtA []byte) error {
+func (m *PushRecordsRequest) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -3669,10 +9378,10 @@ func (m *GetRecordsRequest) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: GetRecordsRequest: wiretype end group for non-group")
+			return fmt.Errorf("proto: PushRecordsRequest: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: GetRecordsRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: PushRecordsRequest: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
@@ -3704,175 +9413,16 @@ func (m *GetRecordsRequest) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if m.Header == nil {
-				m.Header = &Header{}
-			}
-			if err := m.Header.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
-			}
-			iNdEx = postIndex
-		case 2:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Body", wireType)
-			}
-			var msglen int
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowNet
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				msglen |= int(b&0x7F) << shift
-				if b < 0x80 {
-					break
-				}
-			}
-			if msglen < 0 {
-				return ErrInvalidLengthNet
-			}
-			postIndex := iNdEx + msglen
-			if postIndex < 0 {
-				return ErrInvalidLengthNet
-			}
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
-			}
-			if m.Body == nil {
-				m.Body = &GetRecordsRequest_Body{}
-			}
-			if err := m.Body.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
-			}
-			iNdEx = postIndex
-		default:
-			iNdEx = preIndex
-			skippy, err := skipNet(dAtA[iNdEx:])
-			if err != nil {
-				return err
-			}
-			if skippy < 0 {
-				return ErrInvalidLengthNet
-			}
-			if (iNdEx + skippy) < 0 {
-				return ErrInvalidLengthNet
-			}
-			if (iNdEx + skippy) > l {
-				return io.ErrUnexpectedEOF
-			}
-			iNdEx += skippy
-		}
-	}
-
-	if iNdEx > l {
-		return io.ErrUnexpectedEOF
-	}
-	return nil
-}
-func (m *GetRecordsRequest_Body) Unmarshal(dAtA []byte) error {
-	l := len(dAtA)
-	iNdEx := 0
-	for iNdEx < l {
-		preIndex := iNdEx
-		var wire uint64
-		for shift := uint(0); ; shift += 7 {
-			if shift >= 64 {
-				return ErrIntOverflowNet
-			}
-			if iNdEx >= l {
-				return io.ErrUnexpectedEOF
-			}
-			b := dAtA[iNdEx]
-			iNdEx++
-			wire |= uint64(b&0x7F) << shift
-			if b < 0x80 {
-				break
-			}
-		}
-		fieldNum := int32(wire >> 3)
-		wireType := int(wire & 0x7)
-		if wireType == 4 {
-			return fmt.Errorf("proto: Body: wiretype end group for non-group")
-		}
-		if fieldNum <= 0 {
-			return fmt.Errorf("proto: Body: illegal tag %d (wire type %d)", fieldNum, wire)
-		}
-		switch fieldNum {
-		case 1:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ThreadID", wireType)
-			}
-			var byteLen int
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowNet
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				byteLen |= int(b&0x7F) << shift
-				if b < 0x80 {
-					break
-				}
-			}
-			if byteLen < 0 {
-				return ErrInvalidLengthNet
-			}
-			postIndex := iNdEx + byteLen
-			if postIndex < 0 {
-				return ErrInvalidLengthNet
-			}
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
-			}
-			var v ProtoThreadID
-			m.ThreadID = &v
-			if err := m.ThreadID.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
-			}
-			iNdEx = postIndex
-		case 2:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ServiceKey", wireType)
-			}
-			var byteLen int
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowNet
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				byteLen |= int(b&0x7F) << shift
-				if b < 0x80 {
-					break
-				}
-			}
-			if byteLen < 0 {
-				return ErrInvalidLengthNet
-			}
-			postIndex := iNdEx + byteLen
-			if postIndex < 0 {
-				return ErrInvalidLengthNet
-			}
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
+			if m.Header == nil {
+				m.Header = &Header{}
 			}
-			var v ProtoKey
-			m.ServiceKey = &v
-			if err := m.ServiceKey.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+			if err := m.Header.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
-		case 3:
+		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Logs", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Body", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -3899,8 +9449,10 @@ func (m *GetRecordsRequest_Body) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Logs = append(m.Logs, &GetRecordsRequest_Body_LogEntry{})
-			if err := m.Logs[len(m.Logs)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+			if m.Body == nil {
+				m.Body = &PushRecordsRequest_Body{}
+			}
+			if err := m.Body.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
@@ -3928,7 +9480,7 @@ func (m *GetRecordsRequest_Body) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *GetRecordsRequest_Body_LogEntry) Unmarshal(dAtA []byte) error {
+func (m *PushRecordsRequest_Body) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -3951,15 +9503,15 @@ func (m *GetRecordsRequest_Body_LogEntry) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: LogEntry: wiretype end group for non-group")
+			return fmt.Errorf("proto: Body: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: LogEntry: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: Body: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field LogID", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field ThreadID", wireType)
 			}
 			var byteLen int
 			for shift := uint(0); ; shift += 7 {
@@ -3986,15 +9538,15 @@ func (m *GetRecordsRequest_Body_LogEntry) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			var v ProtoPeerID
-			m.LogID = &v
-			if err := m.LogID.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+			var v ProtoThreadID
+			m.ThreadID = &v
+			if err := m.ThreadID.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
 		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Offset", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field LogID", wireType)
 			}
 			var byteLen int
 			for shift := uint(0); ; shift += 7 {
@@ -4021,17 +9573,17 @@ func (m *GetRecordsRequest_Body_LogEntry) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			var v ProtoCid
-			m.Offset = &v
-			if err := m.Offset.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+			var v ProtoPeerID
+			m.LogID = &v
+			if err := m.LogID.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
 		case 3:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Limit", wireType)
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Records", wireType)
 			}
-			m.Limit = 0
+			var msglen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowNet
@@ -4041,11 +9593,26 @@ func (m *GetRecordsRequest_Body_LogEntry) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.Limit |= int32(b&0x7F) << shift
+				msglen |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
+			if msglen < 0 {
+				return ErrInvalidLengthNet
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthNet
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Records = append(m.Records, &Log_Record{})
+			if err := m.Records[len(m.Records)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipNet(dAtA[iNdEx:])
@@ -4070,7 +9637,7 @@ func (m *GetRecordsRequest_Body_LogEntry) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *GetRecordsReply) Unmarshal(dAtA []byte) error {
+func (m *PushRecordsReply) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -4093,15 +9660,15 @@ func (m *GetRecordsReply) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: GetRecordsReply: wiretype end group for non-group")
+			return fmt.Errorf("proto: PushRecordsReply: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: GetRecordsReply: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: PushRecordsReply: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Logs", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Results", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -4128,8 +9695,8 @@ func (m *GetRecordsReply) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Logs = append(m.Logs, &GetRecordsReply_LogEntry{})
-			if err := m.Logs[len(m.Logs)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+			m.Results = append(m.Results, &PushRecordsReply_Result{})
+			if err := m.Results[len(m.Results)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
@@ -4157,7 +9724,7 @@ func (m *GetRecordsReply) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *GetRecordsReply_LogEntry) Unmarshal(dAtA []byte) error {
+func (m *PushRecordsReply_Result) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -4180,17 +9747,36 @@ func (m *GetRecordsReply_LogEntry) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: LogEntry: wiretype end group for non-group")
+			return fmt.Errorf("proto: Result: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: LogEntry: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: Result: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Code", wireType)
+			}
+			m.Code = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowNet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Code |= int32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field LogID", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Message", wireType)
 			}
-			var byteLen int
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowNet
@@ -4200,30 +9786,80 @@ func (m *GetRecordsReply_LogEntry) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				byteLen |= int(b&0x7F) << shift
+				stringLen |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if byteLen < 0 {
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
 				return ErrInvalidLengthNet
 			}
-			postIndex := iNdEx + byteLen
+			postIndex := iNdEx + intStringLen
 			if postIndex < 0 {
 				return ErrInvalidLengthNet
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			var v ProtoPeerID
-			m.LogID = &v
-			if err := m.LogID.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+			m.Message = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipNet(dAtA[iNdEx:])
+			if err != nil {
 				return err
 			}
-			iNdEx = postIndex
-		case 2:
+			if skippy < 0 {
+				return ErrInvalidLengthNet
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthNet
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *SubscribeRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowNet
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: SubscribeRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: SubscribeRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Records", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Header", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -4250,14 +9886,16 @@ func (m *GetRecordsReply_LogEntry) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Records = append(m.Records, &Log_Record{})
-			if err := m.Records[len(m.Records)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+			if m.Header == nil {
+				m.Header = &Header{}
+			}
+			if err := m.Header.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
-		case 3:
+		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Log", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Body", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -4284,10 +9922,10 @@ func (m *GetRecordsReply_LogEntry) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if m.Log == nil {
-				m.Log = &Log{}
+			if m.Body == nil {
+				m.Body = &SubscribeRequest_Body{}
 			}
-			if err := m.Log.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+			if err := m.Body.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
@@ -4315,7 +9953,7 @@ func (m *GetRecordsReply_LogEntry) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *PushRecordRequest) Unmarshal(dAtA []byte) error {
+func (m *SubscribeRequest_Body) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -4338,17 +9976,17 @@ func (m *PushRecordRequest) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: PushRecordRequest: wiretype end group for non-group")
+			return fmt.Errorf("proto: Body: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: PushRecordRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: Body: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Header", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field ThreadID", wireType)
 			}
-			var msglen int
+			var byteLen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowNet
@@ -4358,33 +9996,32 @@ func (m *PushRecordRequest) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				msglen |= int(b&0x7F) << shift
+				byteLen |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
+			if byteLen < 0 {
 				return ErrInvalidLengthNet
 			}
-			postIndex := iNdEx + msglen
+			postIndex := iNdEx + byteLen
 			if postIndex < 0 {
 				return ErrInvalidLengthNet
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if m.Header == nil {
-				m.Header = &Header{}
-			}
-			if err := m.Header.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+			var v ProtoThreadID
+			m.ThreadID = &v
+			if err := m.ThreadID.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
 		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Body", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field ServiceKey", wireType)
 			}
-			var msglen int
+			var byteLen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowNet
@@ -4394,25 +10031,24 @@ func (m *PushRecordRequest) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				msglen |= int(b&0x7F) << shift
+				byteLen |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
+			if byteLen < 0 {
 				return ErrInvalidLengthNet
 			}
-			postIndex := iNdEx + msglen
+			postIndex := iNdEx + byteLen
 			if postIndex < 0 {
 				return ErrInvalidLengthNet
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if m.Body == nil {
-				m.Body = &PushRecordRequest_Body{}
-			}
-			if err := m.Body.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+			var v ProtoKey
+			m.ServiceKey = &v
+			if err := m.ServiceKey.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
@@ -4440,7 +10076,7 @@ func (m *PushRecordRequest) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *PushRecordRequest_Body) Unmarshal(dAtA []byte) error {
+func (m *SubscribeReply) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -4463,10 +10099,10 @@ func (m *PushRecordRequest_Body) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: Body: wiretype end group for non-group")
+			return fmt.Errorf("proto: SubscribeReply: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: Body: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: SubscribeReply: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
@@ -4599,7 +10235,7 @@ func (m *PushRecordRequest_Body) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *PushRecordReply) Unmarshal(dAtA []byte) error {
+func (m *PingRequest) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -4622,10 +10258,63 @@ func (m *PushRecordReply) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: PushRecordReply: wiretype end group for non-group")
+			return fmt.Errorf("proto: PingRequest: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: PushRecordReply: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: PingRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		default:
+			iNdEx = preIndex
+			skippy, err := skipNet(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthNet
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthNet
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *PingReply) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowNet
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: PingReply: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: PingReply: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		default:
@@ -0,0 +1,137 @@
+package net
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gogo/status"
+	"github.com/libp2p/go-libp2p-core/protocol"
+	"github.com/textileio/go-threads/core/thread"
+	pb "github.com/textileio/go-threads/net/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/keepalive"
+)
+
+var (
+	// KeepaliveInterval is how long a connection to a peer may be idle before
+	// gRPC pings it to check that it's alive. Peers close connections that
+	// are pinged more than twice as often, so it should be the same on all
+	// peers. Only peers on keepaliveVersion or newer are pinged.
+	KeepaliveInterval = time.Second * 30
+
+	// KeepaliveTimeout is how long to wait for a reply to a keepalive ping or
+	// a Ping request before the connection is considered dead.
+	KeepaliveTimeout = time.Second * 10
+
+	// ConnCheckInterval is how often cached connections to peers are checked
+	// with a Ping request. Connections that fail the check are evicted, so
+	// that the next request to the peer dials a new connection instead of
+	// waiting on a dead one.
+	ConnCheckInterval = time.Minute
+)
+
+// keepaliveVersion is the first thread protocol version whose hosts accept
+// the pings of keepaliveDialOption. Older hosts close connections that are
+// pinged more than once every five minutes.
+const keepaliveVersion = "0.0.2"
+
+// supportsKeepalive returns whether or not a peer that negotiated the given
+// thread protocol version accepts keepalive pings.
+func supportsKeepalive(p protocol.ID) bool {
+	v := strings.TrimPrefix(string(p), "/"+thread.Name+"/")
+	if v == string(p) {
+		return false
+	}
+	have, want := strings.Split(v, "."), strings.Split(keepaliveVersion, ".")
+	for i := range want {
+		if i >= len(have) {
+			return false
+		}
+		h, err := strconv.Atoi(have[i])
+		if err != nil {
+			return false
+		}
+		w, _ := strconv.Atoi(want[i])
+		if h != w {
+			return h > w
+		}
+	}
+	return true
+}
+
+// keepaliveDialOption returns the keepalive parameters of connections to
+// peers. Idle connections are pinged too, since they're cached for reuse.
+func keepaliveDialOption() grpc.DialOption {
+	return grpc.WithKeepaliveParams(keepalive.ClientParameters{
+		Time:                KeepaliveInterval,
+		Timeout:             KeepaliveTimeout,
+		PermitWithoutStream: true,
+	})
+}
+
+// keepaliveServerOptions returns the options that let peers ping
+// connections as configured by keepaliveDialOption. Pings are allowed at half
+// the interval, so that pings that arrive early, e.g., from peers that ping
+// right after reading from an idle connection, don't close it.
+func keepaliveServerOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             KeepaliveInterval / 2,
+			PermitWithoutStream: true,
+		}),
+	}
+}
+
+// Ping replies to a peer checking its connection to the host.
+func (s *server) Ping(context.Context, *pb.PingRequest) (*pb.PingReply, error) {
+	return &pb.PingReply{}, nil
+}
+
+// startCheckingConns periodically checks cached connections until the
+// network is closed.
+func (s *server) startCheckingConns() {
+	tick := time.NewTicker(ConnCheckInterval)
+	defer tick.Stop()
+	for {
+		select {
+		case <-tick.C:
+			s.checkConns(s.net.ctx)
+		case <-s.net.ctx.Done():
+			return
+		}
+	}
+}
+
+// checkConns pings the peer of each cached connection, evicting the
+// connections that don't reply.
+func (s *server) checkConns(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, cc := range s.conns.all() {
+		wg.Add(1)
+		go func(cc cachedConn) {
+			defer wg.Done()
+			cctx, cancel := context.WithTimeout(ctx, KeepaliveTimeout)
+			defer cancel()
+			if _, err := pb.NewServiceClient(cc.conn).Ping(cctx, &pb.PingRequest{}); err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				if status.Code(err) == codes.Unimplemented {
+					// Peers on older versions reply, but without Ping
+					s.health.success(cc.pid)
+					return
+				}
+				s.log.Debugf("evicting connection to %s after a failed ping: %s", cc.pid, err)
+				s.conns.remove(cc.pid, cc.conn)
+				s.health.failure(cc.pid)
+				return
+			}
+			s.health.success(cc.pid)
+		}(cc)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,51 @@
+package net
+
+import (
+	"context"
+
+	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+)
+
+// DefaultPressurePushConcurrency is the default of Config.PressurePushConcurrency.
+const DefaultPressurePushConcurrency = 4
+
+// underPressure returns whether or not the host has reached the configured
+// connection high water mark.
+func (n *net) underPressure() bool {
+	if n.conf.ConnHighWater <= 0 {
+		return false
+	}
+	return len(n.host.Network().Conns()) >= n.conf.ConnHighWater
+}
+
+// shedPush returns whether a direct push to a peer should be deferred to the
+// outbound queue because the host is under connection pressure, and the peer
+// is not connected. Critical pushes, i.e., ones a caller waits on, are not shed.
+func (n *net) shedPush(pid peer.ID, critical bool) bool {
+	return !critical && n.underPressure() && n.host.Network().Connectedness(pid) != network.Connected
+}
+
+// pressurePushConcurrency returns the max number of direct pushes run at once
+// under connection pressure.
+func (n *net) pressurePushConcurrency() int {
+	if n.conf.PressurePushConcurrency > 0 {
+		return n.conf.PressurePushConcurrency
+	}
+	return DefaultPressurePushConcurrency
+}
+
+// acquirePushSlot waits for a slot to run a direct push if the host is under
+// connection pressure, returning a func that releases it. Without pressure,
+// pushes run at once.
+func (s *server) acquirePushSlot(ctx context.Context) (func(), error) {
+	if !s.net.underPressure() {
+		return func() {}, nil
+	}
+	select {
+	case s.pushSlots <- struct{}{}:
+		return func() { <-s.pushSlots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
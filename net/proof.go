@@ -0,0 +1,117 @@
+package net
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/ipfs/go-cid"
+	"github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/textileio/go-threads/cbor"
+	core "github.com/textileio/go-threads/core/net"
+	"github.com/textileio/go-threads/core/thread"
+	tcrypto "github.com/textileio/go-threads/crypto"
+	pb "github.com/textileio/go-threads/net/pb"
+)
+
+// ErrInvalidManifest indicates that records don't match their manifest.
+var ErrInvalidManifest = fmt.Errorf("invalid manifest")
+
+// manifest returns a manifest of records served from a log, signed by the host.
+func (s *server) manifest(id thread.ID, lid peer.ID, offset cid.Cid, recs []core.Record) (*pb.Manifest, error) {
+	body := &pb.Manifest_Body{
+		ThreadID: &pb.ProtoThreadID{ID: id},
+		LogID:    &pb.ProtoPeerID{ID: lid},
+		Offset:   &pb.ProtoCid{Cid: offset},
+		Records:  make([]pb.ProtoCid, len(recs)),
+	}
+	for i, r := range recs {
+		body.Records[i] = pb.ProtoCid{Cid: r.Cid()}
+	}
+	if len(recs) > 0 {
+		head := recs[len(recs)-1]
+		body.HeadBlock = &pb.ProtoCid{Cid: head.BlockID()}
+		body.HeadPrev = &pb.ProtoCid{Cid: head.PrevID()}
+		body.HeadSig = head.Sig()
+		if !head.PrevID().Defined() {
+			body.HeadPubKey = head.PubKey()
+		}
+	}
+	header, err := SignRequest(s.net.getPrivKey(), body)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.Manifest{Header: header, Body: body}, nil
+}
+
+// VerifyManifest checks records returned for a log against their manifest.
+// The manifest must be signed by the serving peer and the records must hash
+// to the listed cids. Each record is decoded with the thread's service key
+// and must be signed by the log key and link to the record before it, the
+// first one to the manifest's offset. The last record must be the signed head
+// of the manifest. The ID of the serving peer is returned.
+func VerifyManifest(m *pb.Manifest, logKey crypto.PubKey, sk tcrypto.DecryptionKey, recs []*pb.Log_Record) (peer.ID, error) {
+	if m == nil || m.Body == nil {
+		return "", fmt.Errorf("%w: missing body", ErrInvalidManifest)
+	}
+	pid, err := verifyRequest(m.Header, m.Body)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrInvalidManifest, err)
+	}
+	lid, err := peer.IDFromPublicKey(logKey)
+	if err != nil {
+		return "", err
+	}
+	if m.Body.LogID == nil || m.Body.LogID.ID != lid {
+		return "", fmt.Errorf("%w: log key does not match log %s", ErrInvalidManifest, m.Body.LogID)
+	}
+
+	listed := m.Body.Records
+	if len(recs) != len(listed) {
+		return "", fmt.Errorf("%w: expected %d records, got %d", ErrInvalidManifest, len(listed), len(recs))
+	}
+	prev := cid.Undef
+	if m.Body.Offset != nil {
+		prev = m.Body.Offset.Cid
+	}
+	var head core.Record
+	for i, r := range recs {
+		c, err := listed[i].Prefix().Sum(r.RecordNode)
+		if err != nil {
+			return "", err
+		}
+		if !c.Equals(listed[i].Cid) {
+			return "", fmt.Errorf("%w: record %d does not match %s", ErrInvalidManifest, i, listed[i].Cid)
+		}
+		rec, err := cbor.RecordFromProto(r, sk)
+		if err != nil {
+			return "", fmt.Errorf("%w: decoding record %s: %s", ErrInvalidManifest, c, err)
+		}
+		if !rec.PrevID().Equals(prev) {
+			return "", fmt.Errorf("%w: record %s does not link to %s", ErrInvalidManifest, c, prev)
+		}
+		if err = rec.Verify(logKey); err != nil {
+			return "", fmt.Errorf("%w: bad signature on record %s", ErrInvalidManifest, c)
+		}
+		prev = c
+		head = rec
+	}
+	if head == nil {
+		return pid, nil
+	}
+
+	if m.Body.HeadBlock == nil || m.Body.HeadPrev == nil {
+		return "", fmt.Errorf("%w: missing head", ErrInvalidManifest)
+	}
+	if !head.BlockID().Equals(m.Body.HeadBlock.Cid) ||
+		!head.PrevID().Equals(m.Body.HeadPrev.Cid) ||
+		!bytes.Equal(head.Sig(), m.Body.HeadSig) {
+		return "", fmt.Errorf("%w: last record %s is not the head", ErrInvalidManifest, prev)
+	}
+	payload := cbor.RecordPayload(m.Body.HeadBlock.Cid, m.Body.HeadPrev.Cid, m.Body.HeadPubKey)
+	ok, err := logKey.Verify(payload, m.Body.HeadSig)
+	if !ok || err != nil {
+		return "", fmt.Errorf("%w: bad head signature", ErrInvalidManifest)
+	}
+	return pid, nil
+}
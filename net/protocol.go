@@ -0,0 +1,50 @@
+package net
+
+import (
+	nnet "net"
+
+	"github.com/libp2p/go-libp2p-core/helpers"
+	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/protocol"
+	gostream "github.com/libp2p/go-libp2p-gostream"
+	"github.com/textileio/go-threads/core/thread"
+)
+
+// protocols returns the thread protocol versions the host speaks, newest first.
+func (n *net) protocols() []protocol.ID {
+	if len(n.conf.Protocols) > 0 {
+		return n.conf.Protocols
+	}
+	return thread.Protocols
+}
+
+// streamConn is a net.Conn over a libp2p stream, as used by gostream, for
+// streams opened with a negotiated protocol.
+type streamConn struct {
+	network.Stream
+}
+
+func (c *streamConn) Close() error {
+	if err := c.Stream.Close(); err != nil {
+		_ = c.Stream.Reset()
+		return err
+	}
+	go func() { _ = helpers.AwaitEOF(c.Stream) }()
+	return nil
+}
+
+func (c *streamConn) LocalAddr() nnet.Addr {
+	return streamAddr(c.Conn().LocalPeer())
+}
+
+func (c *streamConn) RemoteAddr() nnet.Addr {
+	return streamAddr(c.Conn().RemotePeer())
+}
+
+// streamAddr is the address of a peer at either end of a streamConn.
+type streamAddr peer.ID
+
+func (a streamAddr) Network() string { return gostream.Network }
+
+func (a streamAddr) String() string { return peer.ID(a).Pretty() }
@@ -1,21 +1,97 @@
 package net
 
 import (
+	"container/list"
 	"context"
 	"fmt"
+	"hash/fnv"
 	"sync"
+	"time"
 
 	"github.com/gogo/protobuf/proto"
+	"github.com/libp2p/go-libp2p-core/host"
 	"github.com/libp2p/go-libp2p-core/peer"
 	pubsub "github.com/libp2p/go-libp2p-pubsub"
 	"github.com/textileio/go-threads/core/thread"
 	pb "github.com/textileio/go-threads/net/pb"
 )
 
+const (
+	// DefaultSubscribeWorkers is the default number of workers handling the
+	// records received on each thread topic.
+	DefaultSubscribeWorkers = 4
+
+	// DefaultSubscribeQueueSize is the default number of received records
+	// each worker buffers.
+	DefaultSubscribeQueueSize = 32
+
+	// DefaultPublishTimeout is the default max time to wait for a record to be
+	// published to a thread topic.
+	DefaultPublishTimeout = time.Second * 10
+
+	// DefaultSubscribeRetryInterval is the default delay before retrying to
+	// join a thread topic that could not be joined.
+	DefaultSubscribeRetryInterval = time.Second * 10
+
+	// DefaultMaxSubscribeRetryInterval is the default max delay between
+	// attempts to join a thread topic.
+	DefaultMaxSubscribeRetryInterval = time.Minute * 10
+
+	// DefaultSubscribeErrorLimit is the default number of consecutive errors
+	// reading a thread topic after which the subscription is replaced.
+	DefaultSubscribeErrorLimit = 3
+
+	// DefaultResubscribeInterval is the default delay before replacing a
+	// closed subscription.
+	DefaultResubscribeInterval = time.Second
+
+	// DefaultMaxPubSubMessageSize is the default max size in bytes of a record
+	// push published to a thread topic. It leaves room for the other message
+	// fields within the 1 MiB frames read by pubsub peers.
+	DefaultMaxPubSubMessageSize = 1<<20 - 4<<10
+)
+
+// PubSubRouter selects the router of the pubsub instance started for thread
+// topics. See Config.PubSubRouter.
+type PubSubRouter int
+
+const (
+	// GossipSubRouter routes messages over a mesh of topic peers with GossipSub.
+	GossipSubRouter PubSubRouter = iota
+	// FloodSubRouter sends messages to all topic peers with FloodSub, which is
+	// simpler and more predictable in small, fully-connected networks.
+	FloodSubRouter
+)
+
+// newPubSubRouter starts a pubsub instance on h with router r.
+func newPubSubRouter(ctx context.Context, h host.Host, r PubSubRouter) (*pubsub.PubSub, error) {
+	opts := []pubsub.Option{
+		pubsub.WithMessageSigning(false),
+		pubsub.WithStrictSignatureVerification(false),
+	}
+	switch r {
+	case GossipSubRouter:
+		return pubsub.NewGossipSub(ctx, h, opts...)
+	case FloodSubRouter:
+		return pubsub.NewFloodSub(ctx, h, opts...)
+	default:
+		return nil, fmt.Errorf("unknown pubsub router %d", r)
+	}
+}
+
+// maxPubSubMessageSize returns the max size of a published record push.
+func (n *net) maxPubSubMessageSize() int {
+	if n.conf.MaxPubSubMessageSize > 0 {
+		return n.conf.MaxPubSubMessageSize
+	}
+	return DefaultMaxPubSubMessageSize
+}
+
 // Handler receives all pushed thread records.
 type Handler func(context.Context, *pb.PushRecordRequest)
 
 // PubSub manages thread pubsub topics.
+// A nil PubSub, as used in local-only mode, ignores all topic operations.
 type PubSub struct {
 	sync.RWMutex
 
@@ -24,68 +100,276 @@ type PubSub struct {
 	ps      *pubsub.PubSub
 	handler Handler
 	m       map[thread.ID]*topic
+
+	// max is the max number of subscribed topics. Zero means no limit.
+	max int
+	// known holds all added threads, including those whose topics have been
+	// evicted. Evicted topics are joined again when a record is published.
+	known  map[thread.ID]struct{}
+	recent *recentTopics
+	// failed holds the topics that could not be joined, which are retried.
+	failed map[thread.ID]*joinRetry
+
+	workers             int
+	queueSize           int
+	publishTimeout      time.Duration
+	retryInterval       time.Duration
+	maxRetryInterval    time.Duration
+	errorLimit          int
+	resubscribeInterval time.Duration
+	log                 Logger
+}
+
+// joinRetry tracks the attempts to join a topic.
+type joinRetry struct {
+	attempts int
+	next     time.Time
 }
 
 type topic struct {
 	t *pubsub.Topic
+	p publisher
 	h *pubsub.TopicEventHandler
-	s *pubsub.Subscription
+	s subscription
 
 	cancel context.CancelFunc
 }
 
-// NewPubSub returns a new thread topic manager.
-func NewPubSub(ctx context.Context, host peer.ID, ps *pubsub.PubSub, handler Handler) *PubSub {
-	return &PubSub{
+// publisher is the part of a topic used to publish records.
+type publisher interface {
+	Publish(ctx context.Context, data []byte, opts ...pubsub.PubOpt) error
+}
+
+// subscription is the part of a topic subscription read by the subscribe loop.
+type subscription interface {
+	Next(ctx context.Context) (*pubsub.Message, error)
+	Cancel()
+}
+
+// NewPubSub returns a new thread topic manager. Topics are tuned by the
+// pubsub fields of conf, where zero values use the package defaults.
+func NewPubSub(ctx context.Context, host peer.ID, ps *pubsub.PubSub, handler Handler, conf Config) *PubSub {
+	s := &PubSub{
 		ctx:     ctx,
 		host:    host,
 		ps:      ps,
 		handler: handler,
 		m:       make(map[thread.ID]*topic),
+		known:   make(map[thread.ID]struct{}),
+		recent:  newRecentTopics(),
+		failed:  make(map[thread.ID]*joinRetry),
+
+		workers:             conf.SubscribeWorkers,
+		queueSize:           conf.SubscribeQueueSize,
+		publishTimeout:      conf.PublishTimeout,
+		retryInterval:       conf.SubscribeRetryInterval,
+		maxRetryInterval:    conf.MaxSubscribeRetryInterval,
+		errorLimit:          conf.SubscribeErrorLimit,
+		resubscribeInterval: conf.ResubscribeInterval,
+		log:                 conf.Logger,
+	}
+	if s.log == nil {
+		s.log = defaultLogger()
+	}
+	if s.workers <= 0 {
+		s.workers = DefaultSubscribeWorkers
+	}
+	if s.queueSize <= 0 {
+		s.queueSize = DefaultSubscribeQueueSize
+	}
+	if s.publishTimeout == 0 {
+		s.publishTimeout = DefaultPublishTimeout
+	}
+	if s.retryInterval <= 0 {
+		s.retryInterval = DefaultSubscribeRetryInterval
 	}
+	if s.maxRetryInterval <= 0 {
+		s.maxRetryInterval = DefaultMaxSubscribeRetryInterval
+	}
+	if s.errorLimit <= 0 {
+		s.errorLimit = DefaultSubscribeErrorLimit
+	}
+	if s.resubscribeInterval <= 0 {
+		s.resubscribeInterval = DefaultResubscribeInterval
+	}
+	go s.startRetryingJoins()
+	return s
+}
+
+// SetMaxTopics sets the max number of thread topics subscribed at once.
+// When the max is reached, the topic of the least recently active thread is
+// left. It is joined again the next time a record is published to it.
+// Zero means no limit.
+func (s *PubSub) SetMaxTopics(max int) error {
+	if s == nil {
+		return nil
+	}
+	s.Lock()
+	defer s.Unlock()
+	s.max = max
+	return s.evict(thread.Undef)
 }
 
 // Add a new thread topic. This may be called repeatedly for the same thread.
+// If the topic cannot be joined, joining is retried in the background.
 func (s *PubSub) Add(id thread.ID) error {
+	if s == nil {
+		return nil
+	}
 	s.Lock()
 	defer s.Unlock()
-	if _, ok := s.m[id]; ok {
-		return nil
+	s.known[id] = struct{}{}
+	_, err := s.join(id)
+	return err
+}
+
+// join a thread topic, evicting others if the max is exceeded.
+// This method should be guarded.
+func (s *PubSub) join(id thread.ID) (*topic, error) {
+	if t, ok := s.m[id]; ok {
+		s.recent.touch(id)
+		return t, nil
 	}
 
 	pt, err := s.ps.Join(id.String())
 	if err != nil {
-		return err
+		return nil, s.joinFailed(id, err)
 	}
 	h, err := pt.EventHandler()
 	if err != nil {
-		return err
+		_ = pt.Close()
+		return nil, s.joinFailed(id, err)
 	}
 	if err = s.ps.RegisterTopicValidator(id.String(), s.topicValidator); err != nil {
-		return err
+		h.Cancel()
+		_ = pt.Close()
+		return nil, s.joinFailed(id, err)
 	}
+	delete(s.failed, id)
 
 	ctx, cancel := context.WithCancel(s.ctx)
 	topic := &topic{
 		t:      pt,
+		p:      pt,
 		h:      h,
 		cancel: cancel,
 	}
 	s.m[id] = topic
+	s.recent.touch(id)
 	go s.watch(ctx, id, topic)
 	go s.subscribe(ctx, id, topic)
+	return topic, s.evict(id)
+}
+
+// joinFailed schedules another attempt to join a topic and returns err.
+// This method should be guarded.
+func (s *PubSub) joinFailed(id thread.ID, err error) error {
+	r, ok := s.failed[id]
+	if !ok {
+		r = &joinRetry{}
+		s.failed[id] = r
+	}
+	delay := s.retryInterval << uint(r.attempts)
+	if delay <= 0 || delay > s.maxRetryInterval {
+		delay = s.maxRetryInterval
+	}
+	r.attempts++
+	r.next = time.Now().Add(delay)
+	s.log.Warnf("error joining topic of thread %s, retrying in %s: %s", id, delay, err)
+	return err
+}
+
+// retryJoins attempts to join each failed topic that is due for a retry.
+func (s *PubSub) retryJoins() {
+	s.Lock()
+	defer s.Unlock()
+	now := time.Now()
+	for id, r := range s.failed {
+		_, known := s.known[id]
+		_, joined := s.m[id]
+		if !known || joined {
+			delete(s.failed, id)
+			continue
+		}
+		if now.Before(r.next) {
+			continue
+		}
+		_, _ = s.join(id)
+	}
+}
+
+// startRetryingJoins periodically retries failed joins until ctx is done.
+func (s *PubSub) startRetryingJoins() {
+	tick := time.NewTicker(s.retryInterval)
+	defer tick.Stop()
+	for {
+		select {
+		case <-tick.C:
+			s.retryJoins()
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
+
+// evict leaves the least recently active topics, other than keep, until the
+// max is no longer exceeded. This method should be guarded.
+func (s *PubSub) evict(keep thread.ID) error {
+	for s.max > 0 && len(s.m) > s.max {
+		id, ok := s.recent.oldest(keep)
+		if !ok {
+			return nil
+		}
+		s.log.Debugf("leaving topic of inactive thread %s", id)
+		if err := s.leave(id); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
 // Remove a thread topic. This may be called repeatedly for the same thread.
 func (s *PubSub) Remove(id thread.ID) error {
+	if s == nil {
+		return nil
+	}
+	s.Lock()
+	defer s.Unlock()
+	delete(s.known, id)
+	delete(s.failed, id)
+	return s.leave(id)
+}
+
+// Close leaves all thread topics, so that a pubsub instance given by the
+// caller is left as it was found, e.g., for another network on the host.
+func (s *PubSub) Close() error {
+	if s == nil {
+		return nil
+	}
 	s.Lock()
 	defer s.Unlock()
+	for id := range s.m {
+		if err := s.leave(id); err != nil {
+			return err
+		}
+	}
+	s.known = make(map[thread.ID]struct{})
+	s.failed = make(map[thread.ID]*joinRetry)
+	return nil
+}
+
+// leave a thread topic. This method should be guarded.
+func (s *PubSub) leave(id thread.ID) error {
+	s.recent.remove(id)
 	topic, ok := s.m[id]
 	if !ok {
 		return nil
 	}
-	topic.s.Cancel()
+	// Stop the subscribe loop before closing its subscription
+	topic.cancel()
+	if topic.s != nil {
+		topic.s.Cancel()
+	}
 	topic.h.Cancel()
 	if err := s.ps.UnregisterTopicValidator(id.String()); err != nil {
 		return err
@@ -93,30 +377,103 @@ func (s *PubSub) Remove(id thread.ID) error {
 	if err := topic.t.Close(); err != nil {
 		return err
 	}
-	topic.cancel()
 	delete(s.m, id)
 	return nil
 }
 
-func (s *PubSub) topicValidator(context.Context, peer.ID, *pubsub.Message) bool {
-	// @todo: determine if this is needed (related to host signatures)
+// topicValidator accepts only push record requests that are signed by the
+// sender. Rejected messages are dropped before reaching subscribers and are
+// not propagated to other peers.
+func (s *PubSub) topicValidator(_ context.Context, _ peer.ID, m *pubsub.Message) bool {
+	from, err := peer.IDFromBytes(m.From)
+	if err != nil {
+		return false
+	}
+	req := new(pb.PushRecordRequest)
+	if err = proto.Unmarshal(m.Data, req); err != nil {
+		s.log.Debugf("rejecting malformed multicast request from %s: %s", from, err)
+		return false
+	}
+	if req.Header == nil || req.Header.PubKey == nil || req.Header.PubKey.PubKey == nil || req.Body == nil {
+		s.log.Debugf("rejecting incomplete multicast request from %s", from)
+		return false
+	}
+	pid, err := verifyRequest(req.Header, req.Body)
+	if err != nil {
+		s.log.Debugf("rejecting multicast request from %s: %s", from, err)
+		return false
+	}
+	if pid != from {
+		s.log.Debugf("rejecting multicast request from %s signed by %s", from, pid)
+		return false
+	}
 	return true
 }
 
-// Publish a record request to a thread.
+// Publish a record request to a thread. It returns once the topic takes the
+// record, or when ctx is done or the publish timeout expires, whichever comes
+// first. A negative publish timeout only leaves ctx.
 func (s *PubSub) Publish(ctx context.Context, id thread.ID, req *pb.PushRecordRequest) error {
-	s.RLock()
-	defer s.RUnlock()
-	topic, ok := s.m[id]
-	if !ok {
-		return fmt.Errorf("thread topic not found")
+	if s == nil {
+		return nil
+	}
+	topic, err := s.activeTopic(id)
+	if err != nil {
+		return err
 	}
 
 	data, err := req.Marshal()
 	if err != nil {
 		return err
 	}
-	return topic.t.Publish(ctx, data)
+	if s.publishTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.publishTimeout)
+		defer cancel()
+	}
+	// The topic may not honor ctx, so the wait is bounded here
+	errc := make(chan error, 1)
+	go func() { errc <- topic.p.Publish(ctx, data) }()
+	select {
+	case err = <-errc:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// activeTopic returns a thread topic, joining it again if it was evicted.
+func (s *PubSub) activeTopic(id thread.ID) (*topic, error) {
+	s.RLock()
+	t, ok := s.m[id]
+	s.RUnlock()
+	if ok {
+		s.recent.touch(id)
+		return t, nil
+	}
+	s.Lock()
+	defer s.Unlock()
+	if _, ok := s.known[id]; !ok {
+		return nil, fmt.Errorf("thread topic not found")
+	}
+	return s.join(id)
+}
+
+// Peers returns the peers known to be subscribed to a thread topic.
+// No peers are returned for a thread whose topic has been evicted.
+func (s *PubSub) Peers(id thread.ID) ([]peer.ID, error) {
+	if s == nil {
+		return nil, nil
+	}
+	s.RLock()
+	defer s.RUnlock()
+	if _, ok := s.m[id]; !ok {
+		if _, ok := s.known[id]; ok {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("thread topic not found")
+	}
+	return s.ps.ListPeers(id.String()), nil
 }
 
 // watch peer events from a pubsub topic.
@@ -133,37 +490,140 @@ func (s *PubSub) watch(ctx context.Context, id thread.ID, topic *topic) {
 		case pubsub.PeerLeave:
 			msg = "LEFT"
 		}
-		log.Infof("pubsub peer event: %s %s %s", pe.Peer, msg, id)
+		s.log.Infof("pubsub peer event: %s %s %s", pe.Peer, msg, id)
 	}
 }
 
-// subscribe to a topic for thread updates.
+// subscribe to a topic for thread updates, unless already subscribed.
+// Records are handed off to a pool of workers so that a slow record doesn't
+// stall the records of other logs.
 func (s *PubSub) subscribe(ctx context.Context, id thread.ID, topic *topic) {
-	var err error
 	s.Lock()
-	topic.s, err = topic.t.Subscribe()
+	sub := topic.s
+	var err error
+	if sub == nil && ctx.Err() == nil {
+		if sub, err = topic.t.Subscribe(); err == nil {
+			topic.s = sub
+		}
+	}
 	s.Unlock()
 	if err != nil {
-		log.Errorf("error subscribing to topic %s: %s", id, err)
+		s.log.Warnf("error subscribing to topic of thread %s: %s", id, err)
+		sub = s.resubscribe(ctx, id, topic)
+	}
+	if sub == nil {
 		return
 	}
 
+	workers := s.workers
+	queues := make([]chan *pb.PushRecordRequest, workers)
+	var wg sync.WaitGroup
+	for i := range queues {
+		queues[i] = make(chan *pb.PushRecordRequest, s.queueSize)
+		wg.Add(1)
+		go func(q chan *pb.PushRecordRequest) {
+			defer wg.Done()
+			for req := range q {
+				s.handler(ctx, req)
+			}
+		}(queues[i])
+	}
+	defer func() {
+		for _, q := range queues {
+			close(q)
+		}
+		wg.Wait()
+	}()
+
+	var errs int
 	for {
-		msg, err := topic.s.Next(ctx)
+		msg, err := sub.Next(ctx)
+		if ctx.Err() != nil {
+			return
+		}
 		if err != nil {
-			break
+			errs++
+			s.log.Warnf("error reading topic of thread %s: %s", id, err)
+			if errs < s.errorLimit {
+				continue
+			}
+			if sub = s.resubscribe(ctx, id, topic); sub == nil {
+				return
+			}
+			errs = 0
+			continue
 		}
+		errs = 0
 		from, req, err := s.handleMsg(msg)
 		if err != nil {
-			log.Errorf("error handling multicast request: %s", err)
+			s.log.Errorf("error handling multicast request: %s", err)
 			continue
 		} else if req == nil {
 			continue
 		}
-		log.Debugf("received multicast record from %s", from)
+		s.log.Debugf("received multicast record from %s", from)
+		s.recent.touch(id)
+
+		select {
+		case queues[workerIndex(req, workers)] <- req:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// resubscribe cancels the failed subscription of a topic, if any, and
+// replaces it, retrying with backoff until it succeeds. A nil subscription is
+// returned if ctx is done, i.e., the topic was left.
+func (s *PubSub) resubscribe(ctx context.Context, id thread.ID, topic *topic) subscription {
+	s.Lock()
+	if topic.s != nil {
+		topic.s.Cancel()
+		topic.s = nil
+	}
+	s.Unlock()
+
+	delay := s.resubscribeInterval
+	for {
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		}
+
+		s.Lock()
+		if ctx.Err() != nil {
+			s.Unlock()
+			return nil
+		}
+		sub, err := topic.t.Subscribe()
+		if err == nil {
+			topic.s = sub
+		}
+		s.Unlock()
+		if err == nil {
+			s.log.Infof("resubscribed to topic of thread %s", id)
+			return sub
+		}
+
+		delay *= 2
+		if delay <= 0 || delay > s.maxRetryInterval {
+			delay = s.maxRetryInterval
+		}
+		s.log.Warnf("error resubscribing to topic of thread %s, retrying in %s: %s", id, delay, err)
+	}
+}
 
-		s.handler(ctx, req)
+// workerIndex returns the worker that handles records in the log of req.
+func workerIndex(req *pb.PushRecordRequest, workers int) int {
+	if req.Body == nil || req.Body.LogID == nil {
+		return 0
 	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(req.Body.LogID.ID))
+	return int(h.Sum32() % uint32(workers))
 }
 
 func (s *PubSub) handleMsg(m *pubsub.Message) (from peer.ID, rec *pb.PushRecordRequest, err error) {
@@ -184,8 +644,54 @@ func (s *PubSub) handleMsg(m *pubsub.Message) (from peer.ID, rec *pb.PushRecordR
 		return
 	}
 	if from.String() != pid.String() {
-		log.Warnf("multicast sender does not match record header (%s != %s)", from, pid)
+		s.log.Warnf("multicast sender does not match record header (%s != %s)", from, pid)
 		return
 	}
 	return from, req, nil
 }
+
+// recentTopics orders subscribed threads by their last activity.
+type recentTopics struct {
+	sync.Mutex
+	l *list.List
+	e map[thread.ID]*list.Element
+}
+
+func newRecentTopics() *recentTopics {
+	return &recentTopics{
+		l: list.New(),
+		e: make(map[thread.ID]*list.Element),
+	}
+}
+
+// touch marks a thread as the most recently active.
+func (r *recentTopics) touch(id thread.ID) {
+	r.Lock()
+	defer r.Unlock()
+	if e, ok := r.e[id]; ok {
+		r.l.MoveToFront(e)
+		return
+	}
+	r.e[id] = r.l.PushFront(id)
+}
+
+func (r *recentTopics) remove(id thread.ID) {
+	r.Lock()
+	defer r.Unlock()
+	if e, ok := r.e[id]; ok {
+		r.l.Remove(e)
+		delete(r.e, id)
+	}
+}
+
+// oldest returns the least recently active thread other than keep.
+func (r *recentTopics) oldest(keep thread.ID) (thread.ID, bool) {
+	r.Lock()
+	defer r.Unlock()
+	for e := r.l.Back(); e != nil; e = e.Prev() {
+		if id := e.Value.(thread.ID); id != keep {
+			return id, true
+		}
+	}
+	return thread.Undef, false
+}
@@ -0,0 +1,171 @@
+package net
+
+import (
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/textileio/go-threads/core/thread"
+)
+
+// LogRateLimit bounds the number of new records a log may receive by push
+// per interval. A zero value disables the limit.
+type LogRateLimit struct {
+	Records  int
+	Interval time.Duration
+}
+
+func (l LogRateLimit) enabled() bool {
+	return l.Records > 0 && l.Interval > 0
+}
+
+// maxRateWindows is the number of tracked logs above which expired windows are pruned.
+const maxRateWindows = 1024
+
+type logKey struct {
+	id  thread.ID
+	lid peer.ID
+}
+
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+// logLimiter tracks pushed records per log in fixed windows.
+type logLimiter struct {
+	sync.Mutex
+	windows map[logKey]*rateWindow
+}
+
+func newLogLimiter() *logLimiter {
+	return &logLimiter{windows: make(map[logKey]*rateWindow)}
+}
+
+// allow counts a record pushed to a log and returns false if the log is over limit.
+func (l *logLimiter) allow(id thread.ID, lid peer.ID, limit LogRateLimit, now time.Time) bool {
+	if !limit.enabled() {
+		return true
+	}
+	l.Lock()
+	defer l.Unlock()
+	k := logKey{id: id, lid: lid}
+	w, ok := l.windows[k]
+	if !ok || now.Sub(w.start) >= limit.Interval {
+		if !ok && len(l.windows) >= maxRateWindows {
+			l.prune(limit.Interval, now)
+		}
+		w = &rateWindow{start: now}
+		l.windows[k] = w
+	}
+	if w.count >= limit.Records {
+		return false
+	}
+	w.count++
+	return true
+}
+
+// prune removes windows that ended before now.
+func (l *logLimiter) prune(interval time.Duration, now time.Time) {
+	for k, w := range l.windows {
+		if now.Sub(w.start) >= interval {
+			delete(l.windows, k)
+		}
+	}
+}
+
+// PeerRateLimit bounds the rate of records pushed by each peer, directly or
+// over pubsub, with a token bucket. Each record costs a token, so batches cost
+// as much as pushing their records one by one. A zero Rate disables the limit.
+type PeerRateLimit struct {
+	// Rate is the number of records per second a peer may sustain.
+	Rate float64
+	// Burst is the number of records a peer may push at once. Values below
+	// one mean one. A batch larger than Burst is accepted from a full bucket,
+	// and the peer waits for the tokens it overdrew.
+	Burst int
+	// Trusted lists peers that are not limited, e.g., replicas syncing large
+	// volumes of records.
+	Trusted []peer.ID
+}
+
+func (l PeerRateLimit) enabled() bool {
+	return l.Rate > 0
+}
+
+func (l PeerRateLimit) burst() float64 {
+	if l.Burst < 1 {
+		return 1
+	}
+	return float64(l.Burst)
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// peerLimiter tracks pushed records per peer in token buckets.
+type peerLimiter struct {
+	sync.Mutex
+	limit   PeerRateLimit
+	trusted map[peer.ID]struct{}
+	buckets map[peer.ID]*tokenBucket
+}
+
+func newPeerLimiter(limit PeerRateLimit) *peerLimiter {
+	l := &peerLimiter{
+		limit:   limit,
+		trusted: make(map[peer.ID]struct{}, len(limit.Trusted)),
+		buckets: make(map[peer.ID]*tokenBucket),
+	}
+	for _, p := range limit.Trusted {
+		l.trusted[p] = struct{}{}
+	}
+	return l
+}
+
+// allow takes n tokens from a peer's bucket and returns false if it holds
+// fewer, or isn't full for batches larger than the burst.
+func (l *peerLimiter) allow(pid peer.ID, n int, now time.Time) bool {
+	if !l.limit.enabled() {
+		return true
+	}
+	if _, ok := l.trusted[pid]; ok {
+		return true
+	}
+	l.Lock()
+	defer l.Unlock()
+	b, ok := l.buckets[pid]
+	if !ok {
+		if len(l.buckets) >= maxRateWindows {
+			l.prune(now)
+		}
+		b = &tokenBucket{tokens: l.limit.burst(), last: now}
+		l.buckets[pid] = b
+	} else {
+		b.tokens += now.Sub(b.last).Seconds() * l.limit.Rate
+		if max := l.limit.burst(); b.tokens > max {
+			b.tokens = max
+		}
+		b.last = now
+	}
+	need := float64(n)
+	if max := l.limit.burst(); need > max {
+		need = max
+	}
+	if b.tokens < need {
+		return false
+	}
+	b.tokens -= float64(n)
+	return true
+}
+
+// prune removes buckets that have refilled by now.
+func (l *peerLimiter) prune(now time.Time) {
+	for p, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.limit.Rate >= l.limit.burst() {
+			delete(l.buckets, p)
+		}
+	}
+}
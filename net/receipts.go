@@ -0,0 +1,116 @@
+package net
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ipfs/go-cid"
+	cbornode "github.com/ipfs/go-ipld-cbor"
+	"github.com/libp2p/go-libp2p-core/peer"
+	mh "github.com/multiformats/go-multihash"
+	"github.com/textileio/go-threads/cbor"
+	core "github.com/textileio/go-threads/core/net"
+	"github.com/textileio/go-threads/core/thread"
+)
+
+func init() {
+	cbornode.RegisterCborType(receiptBody{})
+}
+
+// receiptBody is the body of a receipt record.
+type receiptBody struct {
+	ThreadsReceipt cid.Cid
+}
+
+// receiptKey returns the thread metadata key of a log's receipt for a record.
+func receiptKey(rid cid.Cid, lid peer.ID) string {
+	return "receipt/" + rid.String() + "/" + lid.String()
+}
+
+// SendReceipt acknowledges a record by adding a receipt record to the host's
+// log, which is pushed to other members like any other record. Receipts are
+// not sent to record listeners. They can be queried with Receipts by members
+// with the thread's read key.
+func (n *net) SendReceipt(ctx context.Context, id thread.ID, rid cid.Cid, opts ...core.ThreadOption) (r core.ThreadRecord, err error) {
+	args := &core.ThreadOptions{}
+	for _, opt := range opts {
+		opt(args)
+	}
+	pk, err := args.Token.Validate(n.getPrivKey())
+	if err != nil {
+		return
+	}
+	has, err := n.hasBlock(id, rid)
+	if err != nil {
+		return
+	}
+	if !has {
+		return nil, fmt.Errorf("record %s not found", rid)
+	}
+
+	body, err := cbornode.WrapObject(&receiptBody{ThreadsReceipt: rid}, mh.SHA2_256, -1)
+	if err != nil {
+		return
+	}
+	rec, lg, err := n.appendOwnRecord(ctx, id, body, pk, true)
+	if err != nil {
+		return
+	}
+	if err = n.store.PutString(id, receiptKey(rid, lg.ID), rec.Cid().String()); err != nil {
+		return
+	}
+	if err = n.pushRecord(ctx, id, lg.ID, rec, args); err != nil {
+		return
+	}
+	return NewRecord(rec, id, lg.ID), nil
+}
+
+// Receipts returns the logs that have acknowledged a record, mapped to the
+// cid of their receipt record.
+func (n *net) Receipts(id thread.ID, rid cid.Cid) (map[peer.ID]cid.Cid, error) {
+	info, err := n.store.GetThread(id)
+	if err != nil {
+		return nil, err
+	}
+	receipts := make(map[peer.ID]cid.Cid)
+	for _, lg := range info.Logs {
+		v, err := n.store.GetString(id, receiptKey(rid, lg.ID))
+		if err != nil {
+			return nil, err
+		}
+		if v == nil {
+			continue
+		}
+		if receipts[lg.ID], err = cid.Decode(*v); err != nil {
+			return nil, err
+		}
+	}
+	return receipts, nil
+}
+
+// putReceipt stores the receipt if a record is one, and returns whether or not
+// it was. Only records whose event header marks them as receipts are
+// considered, so they must be readable with the thread's read key.
+func (n *net) putReceipt(ctx context.Context, id thread.ID, lid peer.ID, rec core.Record, event *cbor.Event) (bool, error) {
+	rk, err := n.store.ReadKey(id)
+	if err != nil || rk == nil {
+		return false, err
+	}
+	ds := n.threadDAG(id)
+	header, err := event.GetHeader(ctx, ds, rk)
+	if err != nil {
+		return false, nil
+	}
+	if receipt, err := header.Receipt(); err != nil || !receipt {
+		return false, nil
+	}
+	body, err := event.GetBody(ctx, ds, rk)
+	if err != nil {
+		return false, nil
+	}
+	var rb receiptBody
+	if err = cbornode.DecodeInto(body.RawData(), &rb); err != nil || !rb.ThreadsReceipt.Defined() {
+		return false, nil
+	}
+	return true, n.store.PutString(id, receiptKey(rb.ThreadsReceipt, lid), rec.Cid().String())
+}
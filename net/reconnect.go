@@ -0,0 +1,79 @@
+package net
+
+import (
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+)
+
+// ReconnectSyncInterval is the min time between syncs triggered by
+// connections to the same peer.
+var ReconnectSyncInterval = time.Second * 30
+
+// reconnects triggers thread syncs when members connect to the host.
+type reconnects struct {
+	sync.Mutex
+	last     map[peer.ID]time.Time
+	notifiee network.Notifiee
+}
+
+// startReconnectSync pulls the threads shared with a peer whenever it connects,
+// so that records written while the peers were apart are exchanged right away.
+// The peer does the same on its end, pulling the host's new records.
+// Pushes queued for the peer are retried as well.
+func (n *net) startReconnectSync() {
+	n.reconnects = &reconnects{last: make(map[peer.ID]time.Time)}
+	n.reconnects.notifiee = &network.NotifyBundle{
+		ConnectedF: func(_ network.Network, c network.Conn) {
+			go n.syncWithPeer(c.RemotePeer())
+			go n.server.retryPushesTo(c.RemotePeer())
+		},
+	}
+	n.host.Network().Notify(n.reconnects.notifiee)
+}
+
+// stopReconnectSync stops listening for connections.
+func (n *net) stopReconnectSync() {
+	if n.reconnects != nil {
+		n.host.Network().StopNotify(n.reconnects.notifiee)
+	}
+}
+
+// syncWithPeer pulls the threads in which a peer addresses a log.
+func (n *net) syncWithPeer(pid peer.ID) {
+	if n.ctx.Err() != nil {
+		return
+	}
+	n.reconnects.Lock()
+	if last, ok := n.reconnects.last[pid]; ok && time.Since(last) < ReconnectSyncInterval {
+		n.reconnects.Unlock()
+		return
+	}
+	n.reconnects.last[pid] = time.Now()
+	n.reconnects.Unlock()
+
+	ts, err := n.store.Threads()
+	if err != nil {
+		n.log.Errorf("error listing threads: %s", err)
+		return
+	}
+	for _, id := range ts {
+		ok, err := n.isMember(id, pid)
+		if err != nil {
+			n.log.Errorf("error checking membership of %s in thread %s: %s", pid, id, err)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		id := id
+		n.spawn(id, func() {
+			n.log.Debugf("syncing thread %s with reconnected peer %s", id, pid)
+			if err := n.pullThread(n.ctx, id); err != nil {
+				n.log.Errorf("error pulling thread %s: %s", id, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,436 @@
+package net
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	"github.com/libp2p/go-libp2p-core/peer"
+	ma "github.com/multiformats/go-multiaddr"
+	"github.com/textileio/go-threads/cbor"
+	core "github.com/textileio/go-threads/core/net"
+	"github.com/textileio/go-threads/core/thread"
+	sym "github.com/textileio/go-threads/crypto/symmetric"
+	pb "github.com/textileio/go-threads/net/pb"
+)
+
+// rekeyedAtKey is the thread metadata key of the time the thread key was last replaced.
+const rekeyedAtKey = "rekeyedAt"
+
+// RekeyThread replaces the service and read keys of a thread with new random keys.
+// The new keys are pushed to all known members along with the host's own log.
+// Members that only appear as replicators of the host's log receive the service key.
+// Records created before the call remain readable with the retained old keys.
+// Records created after the call use the new keys, i.e., the host's log head at
+// the time of the call is the cutover point.
+func (n *net) RekeyThread(ctx context.Context, id thread.ID) (key thread.Key, err error) {
+	tsph := n.getThreadSemaphore(id)
+	tsph <- struct{}{}
+	defer func() { <-tsph }()
+
+	info, err := n.store.GetThread(id)
+	if err != nil {
+		return
+	}
+	if !info.Key.CanRead() {
+		return key, fmt.Errorf("a read-key is required to rekey a thread")
+	}
+	ownlg, err := n.getOwnLog(id)
+	if err != nil {
+		return
+	}
+	if ownlg.PubKey == nil {
+		return key, fmt.Errorf("an own log is required to rekey a thread")
+	}
+
+	key = thread.NewRandomKey()
+	readerkc, err := signKeyChange(id, ownlg, info.Key, key.Service(), key.Read(), nil)
+	if err != nil {
+		return
+	}
+	replicatorkc, err := signKeyChange(id, ownlg, info.Key, key.Service(), nil, nil)
+	if err != nil {
+		return
+	}
+	if err = n.rotateKey(id, info.Key, key); err != nil {
+		return
+	}
+	n.log.Debugf("rekeyed thread %s at cutover %s", id, ownlg.Head)
+
+	readers, replicators := n.threadMembers(info, ownlg.ID)
+	for pid := range readers {
+		if err := n.server.pushLog(ctx, id, ownlg, pid, key.Service(), key.Read(), readerkc); err != nil {
+			n.log.Errorf("error pushing new keys to %s: %s", pid, err)
+		}
+	}
+	for pid := range replicators {
+		if err := n.server.pushLog(ctx, id, ownlg, pid, key.Service(), nil, replicatorkc); err != nil {
+			n.log.Errorf("error pushing new service-key to %s: %s", pid, err)
+		}
+	}
+	return key, nil
+}
+
+// RotateReadKey replaces the read key of a thread with a new random key,
+// keeping the service key. The new key is pushed to the members addressed by
+// logs other than the host's own, except the revoked peers. The logs of
+// revoked peers are tombstoned, here and by the members that accept the new
+// key, so revoked peers can no longer push records or key changes. Records
+// they already hold stay readable. Until members receive the new key, the
+// records they create still use the old key, which remains readable by
+// revoked peers.
+func (n *net) RotateReadKey(ctx context.Context, id thread.ID, revoked ...peer.ID) (key thread.Key, err error) {
+	tsph := n.getThreadSemaphore(id)
+	tsph <- struct{}{}
+	defer func() { <-tsph }()
+
+	info, err := n.store.GetThread(id)
+	if err != nil {
+		return
+	}
+	if !info.Key.CanRead() {
+		return key, fmt.Errorf("a read-key is required to rotate it")
+	}
+	ownlg, err := n.getOwnLog(id)
+	if err != nil {
+		return
+	}
+	if ownlg.PubKey == nil {
+		return key, fmt.Errorf("an own log is required to rotate a read-key")
+	}
+
+	rk, err := sym.NewRandom()
+	if err != nil {
+		return
+	}
+	key = thread.NewKey(info.Key.Service(), rk)
+	revokedLogs := n.revokedLogs(info, ownlg.ID, revoked)
+	kc, err := signKeyChange(id, ownlg, info.Key, key.Service(), key.Read(), revokedLogs)
+	if err != nil {
+		return
+	}
+	if err = n.rotateKey(id, info.Key, key); err != nil {
+		return
+	}
+	if err = n.revokeLogs(id, ownlg.ID, revokedLogs); err != nil {
+		return
+	}
+	n.log.Debugf("rotated read-key of thread %s at cutover %s", id, ownlg.Head)
+
+	readers, _ := n.threadMembers(info, ownlg.ID)
+	for _, pid := range revoked {
+		delete(readers, pid)
+	}
+	for pid := range readers {
+		if err := n.server.pushLog(ctx, id, ownlg, pid, key.Service(), key.Read(), kc); err != nil {
+			n.log.Errorf("error pushing new read-key to %s: %s", pid, err)
+		}
+	}
+	return key, nil
+}
+
+// ErrKeyChangeDenied indicates that a thread key pushed by a peer was refused
+// because the change was not signed by a log in the thread.
+var ErrKeyChangeDenied = fmt.Errorf("key change not authorized")
+
+// revokedLogs returns the logs other than own that are addressed by a revoked
+// peer, in order of ID.
+func (n *net) revokedLogs(info thread.Info, own peer.ID, revoked []peer.ID) []peer.ID {
+	pids := make(map[peer.ID]struct{}, len(revoked))
+	for _, pid := range revoked {
+		pids[pid] = struct{}{}
+	}
+	var lids []peer.ID
+	for _, lg := range info.Logs {
+		if lg.ID == own {
+			continue
+		}
+		for _, addr := range lg.Addrs {
+			if pid, err := peerIDFromAddr(addr); err == nil {
+				if _, ok := pids[pid]; ok {
+					lids = append(lids, lg.ID)
+					break
+				}
+			}
+		}
+	}
+	sortPeers(lids)
+	return lids
+}
+
+// revokeLogs tombstones the logs revoked by a key change, except own.
+func (n *net) revokeLogs(id thread.ID, own peer.ID, lids []peer.ID) error {
+	for _, lid := range lids {
+		if lid == own {
+			continue
+		}
+		if err := n.tombstone(id, lid); err != nil {
+			return err
+		}
+		n.log.Debugf("revoked log %s in thread %s", lid, id)
+	}
+	return nil
+}
+
+// keyChangePayload returns the payload signed to authorize replacing the
+// thread key old with the service key sk and, if not nil, the read key rk.
+// The old read key is only covered along with a new one, so that members
+// without a read key can verify changes of the service key. Covering the
+// replaced key keeps a signed change from being replayed once the thread
+// moved on to other keys. The revoked logs, if any, are covered last, so that
+// changes without them are signed as before.
+func keyChangePayload(id thread.ID, old thread.Key, sk, rk *sym.Key, revoked []peer.ID) ([]byte, error) {
+	payload := append([]byte("threads/keychange/"), id.Bytes()...)
+	payload = append(payload, old.Service().Bytes()...)
+	payload = append(payload, sk.Bytes()...)
+	if rk != nil {
+		if !old.CanRead() {
+			return nil, fmt.Errorf("a read-key is required to change it")
+		}
+		payload = append(payload, old.Read().Bytes()...)
+		payload = append(payload, rk.Bytes()...)
+	}
+	for _, lid := range revoked {
+		payload = append(payload, []byte(lid)...)
+	}
+	return payload, nil
+}
+
+// signKeyChange signs a change of the thread key old to sk and rk, revoking
+// the given logs, with the private key of lg.
+func signKeyChange(id thread.ID, lg thread.LogInfo, old thread.Key, sk, rk *sym.Key, revoked []peer.ID) (*pb.PushLogRequest_KeyChange, error) {
+	if lg.PrivKey == nil {
+		return nil, fmt.Errorf("a log private-key is required to sign a key change")
+	}
+	payload, err := keyChangePayload(id, old, sk, rk, revoked)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := lg.PrivKey.Sign(payload)
+	if err != nil {
+		return nil, err
+	}
+	kc := &pb.PushLogRequest_KeyChange{
+		LogID:     &pb.ProtoPeerID{ID: lg.ID},
+		Signature: sig,
+	}
+	for _, lid := range revoked {
+		kc.Revoked = append(kc.Revoked, pb.ProtoPeerID{ID: lid})
+	}
+	return kc, nil
+}
+
+// verifyKeyChange returns ErrKeyChangeDenied unless the keys pushed with a
+// log are signed by the key of a log that is in the thread, and not
+// tombstoned. The logs revoked by the change are returned.
+func (n *net) verifyKeyChange(id thread.ID, cur thread.Key, body *pb.PushLogRequest_Body) ([]peer.ID, error) {
+	kc := body.KeyChange
+	if kc == nil || kc.LogID == nil || len(kc.Signature) == 0 {
+		return nil, fmt.Errorf("%w: missing signature", ErrKeyChangeDenied)
+	}
+	if removed, err := n.isTombstoned(id, kc.LogID.ID); err != nil {
+		return nil, err
+	} else if removed {
+		return nil, fmt.Errorf("%w: log %s was removed from the thread", ErrKeyChangeDenied, kc.LogID.ID)
+	}
+	lg, err := n.store.GetLog(id, kc.LogID.ID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: log %s is not in the thread", ErrKeyChangeDenied, kc.LogID.ID)
+	}
+	if lg.PubKey == nil {
+		return nil, fmt.Errorf("%w: log %s has no public key", ErrKeyChangeDenied, lg.ID)
+	}
+	var rk *sym.Key
+	if body.ReadKey != nil {
+		rk = body.ReadKey.Key
+	}
+	revoked := make([]peer.ID, len(kc.Revoked))
+	for i, lid := range kc.Revoked {
+		revoked[i] = lid.ID
+	}
+	payload, err := keyChangePayload(id, cur, body.ServiceKey.Key, rk, revoked)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrKeyChangeDenied, err)
+	}
+	if ok, err := lg.PubKey.Verify(payload, kc.Signature); !ok || err != nil {
+		return nil, fmt.Errorf("%w: bad signature", ErrKeyChangeDenied)
+	}
+	return revoked, nil
+}
+
+// threadMembers returns the peers other than the host that are addressed by
+// the thread's logs. Readers are addressed by logs other than own, while
+// replicators are only addressed by own.
+func (n *net) threadMembers(info thread.Info, own peer.ID) (readers, replicators map[peer.ID]struct{}) {
+	readers = make(map[peer.ID]struct{})
+	replicators = make(map[peer.ID]struct{})
+	for _, lg := range info.Logs {
+		for _, addr := range lg.Addrs {
+			pid, err := peerIDFromAddr(addr)
+			if err != nil {
+				n.log.Error(err)
+				continue
+			}
+			if pid == n.host.ID() {
+				continue
+			}
+			if lg.ID == own {
+				replicators[pid] = struct{}{}
+			} else {
+				readers[pid] = struct{}{}
+			}
+		}
+	}
+	for pid := range readers {
+		delete(replicators, pid)
+	}
+	return readers, replicators
+}
+
+// rotateKey retires the old thread key and stores the new one.
+// If the new key does not include a read key, the old read key is left in place.
+func (n *net) rotateKey(id thread.ID, old, key thread.Key) error {
+	retired, err := n.retiredKeys(id)
+	if err != nil {
+		return err
+	}
+	if err = n.store.SetRetiredKeys(id, append([]thread.Key{old}, retired...)); err != nil {
+		return err
+	}
+	if err = n.store.PutInt64(id, rekeyedAtKey, time.Now().UnixNano()); err != nil {
+		return err
+	}
+	if err = n.store.AddServiceKey(id, key.Service()); err != nil {
+		return err
+	}
+	if key.CanRead() {
+		return n.store.AddReadKey(id, key.Read())
+	}
+	return nil
+}
+
+// retiredKeys returns the keys previously used by a thread, newest first.
+// They are kept in the key book, so they are sealed like the current keys.
+func (n *net) retiredKeys(id thread.ID) ([]thread.Key, error) {
+	return n.store.RetiredKeys(id)
+}
+
+// serviceKeys returns the current and retired service keys of a thread, newest first.
+func (n *net) serviceKeys(id thread.ID) ([]*sym.Key, error) {
+	sk, err := n.store.ServiceKey(id)
+	if err != nil {
+		return nil, err
+	}
+	if sk == nil {
+		return nil, fmt.Errorf("a service-key is required to decode records")
+	}
+	retired, err := n.retiredKeys(id)
+	if err != nil {
+		return nil, err
+	}
+	keys := []*sym.Key{sk}
+	for _, k := range retired {
+		keys = append(keys, k.Service())
+	}
+	return keys, nil
+}
+
+// getRecordWithKeys returns the record at rid, trying all known service keys.
+func (n *net) getRecordWithKeys(ctx context.Context, id thread.ID, rid cid.Cid) (rec core.Record, err error) {
+	keys, err := n.serviceKeys(id)
+	if err != nil {
+		return
+	}
+	ds := n.threadDAG(id)
+	for _, sk := range keys {
+		if rec, err = cbor.GetRecord(ctx, ds, rid, sk); err == nil {
+			n.preloadRecord(ctx, ds, rec)
+			return rec, nil
+		}
+	}
+	return
+}
+
+// inboundServiceKeys returns the service keys used to decode records received
+// from peers. Retired keys are only included until the rekey cutover ends.
+func (n *net) inboundServiceKeys(id thread.ID) ([]*sym.Key, error) {
+	keys, err := n.serviceKeys(id)
+	if err != nil || n.conf.RekeyCutover <= 0 || len(keys) == 1 {
+		return keys, err
+	}
+	at, err := n.store.GetInt64(id, rekeyedAtKey)
+	if err != nil {
+		return nil, err
+	}
+	if at != nil && time.Since(time.Unix(0, *at)) > n.conf.RekeyCutover {
+		return keys[:1], nil
+	}
+	return keys, nil
+}
+
+// recordFromProto decodes a proto record received from a peer, trying the
+// service keys returned by inboundServiceKeys.
+func (n *net) recordFromProto(id thread.ID, pbrec *pb.Log_Record) (rec core.Record, err error) {
+	keys, err := n.inboundServiceKeys(id)
+	if err != nil {
+		return
+	}
+	for i, sk := range keys {
+		if rec, err = cbor.RecordFromProtoWithLimits(pbrec, sk, n.conf.DecodeLimits); err == nil {
+			if i > 0 {
+				n.log.Debugf("decoded record %s in thread %s with retired service-key %d", rec.Cid(), id, i)
+			}
+			if err = n.checkRecordSize(id, rec); err != nil {
+				return nil, err
+			}
+			return rec, nil
+		}
+	}
+	n.log.Warnf("record in thread %s could not be decoded with %d service-keys", id, len(keys))
+	return
+}
+
+// isMember returns whether or not a peer is addressed by any log in the thread.
+func (n *net) isMember(id thread.ID, pid peer.ID) (bool, error) {
+	info, err := n.store.GetThread(id)
+	if err != nil {
+		return false, err
+	}
+	for _, lg := range info.Logs {
+		for _, addr := range lg.Addrs {
+			p, err := peerIDFromAddr(addr)
+			if err != nil {
+				continue
+			}
+			if p == pid {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// peerIDFromAddr returns the ID of the peer a multiaddress points to. For a
+// relayed address, this is the last peer ID component, not the relay's.
+func peerIDFromAddr(addr ma.Multiaddr) (peer.ID, error) {
+	var p string
+	ma.ForEach(addr, func(c ma.Component) bool {
+		if c.Protocol().Code == ma.P_P2P {
+			p = c.Value()
+		}
+		return true
+	})
+	if p == "" {
+		return "", ma.ErrProtocolNotFound
+	}
+	return peer.Decode(p)
+}
+
+func keysToStrings(keys []thread.Key) []string {
+	strs := make([]string, len(keys))
+	for i, k := range keys {
+		strs[i] = k.String()
+	}
+	return strs
+}
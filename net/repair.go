@@ -0,0 +1,104 @@
+package net
+
+import (
+	"context"
+
+	"github.com/gogo/status"
+	"github.com/ipfs/go-cid"
+	"github.com/libp2p/go-libp2p-core/peer"
+	core "github.com/textileio/go-threads/core/net"
+	"github.com/textileio/go-threads/core/thread"
+	"google.golang.org/grpc/codes"
+)
+
+// replies tracks the records each peer returned per log during a pull.
+type replies map[peer.ID]map[peer.ID][]core.Record
+
+// readRepair pushes records to the peers that were found to be missing them during a pull.
+// offsets are the log offsets used in the pull request, and merged are the records
+// received from all peers.
+func (s *server) readRepair(ctx context.Context, id thread.ID, offsets map[peer.ID]cid.Cid, got replies, merged map[peer.ID][]core.Record) {
+	for pid, logs := range got {
+		for lid, served := range logs {
+			missing, err := s.missingRecords(ctx, id, lid, offsets[lid], served, merged[lid])
+			if err != nil {
+				s.log.Errorf("error finding records missing from %s: %s", pid, err)
+				continue
+			}
+			if len(missing) == 0 {
+				continue
+			}
+			s.log.Debugf("repairing %d records in log %s on %s", len(missing), lid, pid)
+			if err = s.pushRecordsToPeer(ctx, id, lid, missing, pid); err != nil {
+				s.log.Warnf("read-repair of %s failed: %s", pid, err)
+			}
+		}
+	}
+}
+
+// missingRecords returns the records a peer does not have based on what it
+// served after offset, oldest first and at most one pull page. A peer that
+// served nothing is at offset. A peer whose records don't build on offset is
+// either behind it, and so is missing local records too, or on another branch
+// of the log, which is not repaired.
+func (s *server) missingRecords(ctx context.Context, id thread.ID, lid peer.ID, offset cid.Cid, served, merged []core.Record) ([]core.Record, error) {
+	limit := s.pullLimit()
+	tip := offset
+	var missing []core.Record
+	if len(served) > 0 {
+		tip = served[len(served)-1].Cid()
+		if served[0].PrevID() != offset {
+			local, _, err := s.net.getServableRecordRange(ctx, id, lid, tip, offset, limit)
+			if err != nil {
+				return nil, err
+			}
+			if len(local) == 0 || local[0].PrevID() != tip {
+				// The peer's head is not in the local log
+				return nil, nil
+			}
+			missing = local
+			tip = local[len(local)-1].Cid()
+		}
+	}
+
+	// Follow the merged records that build on what the peer will have
+	for _, r := range merged {
+		if len(missing) >= limit {
+			break
+		}
+		if r.PrevID() == tip {
+			missing = append(missing, r)
+			tip = r.Cid()
+		}
+	}
+	return missing, nil
+}
+
+// pushRecordsToPeer pushes records to a single peer, oldest first.
+func (s *server) pushRecordsToPeer(ctx context.Context, id thread.ID, lid peer.ID, recs []core.Record, pid peer.ID) error {
+	client, err := s.dial(pid)
+	if err != nil {
+		return err
+	}
+	for _, rec := range recs {
+		req, err := s.newPushRecordRequest(ctx, id, lid, rec)
+		if err != nil {
+			return err
+		}
+		cctx, cancel := s.requestContext(ctx)
+		_, err = client.PushRecord(cctx, req)
+		cancel()
+		if err != nil {
+			if status.Convert(err).Code() == codes.NotFound {
+				// The peer doesn't know the log, nothing to repair
+				return nil
+			}
+			if isHeld(err) {
+				// The peer fetches the missing ancestors itself
+				continue
+			}
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package net
+
+import (
+	"context"
+
+	"github.com/ipfs/go-cid"
+	format "github.com/ipfs/go-ipld-format"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/textileio/go-threads/cbor"
+	core "github.com/textileio/go-threads/core/net"
+	"github.com/textileio/go-threads/core/thread"
+)
+
+// ReplicationFilter decides whether or not the body of a record received from
+// another peer is persisted locally. Skipped records still advance the log head
+// and are announced to subscribers; only their body block is left out of the
+// local store. Records in logs owned by the host are always persisted.
+// A log is only served to peers up to its first record with a skipped body,
+// since the record can't be sent without it. Peers get the rest of the log
+// from other members, e.g., the log's owner.
+type ReplicationFilter func(RecordMeta) bool
+
+// RecordMeta describes a record that is about to be persisted.
+type RecordMeta struct {
+	// ThreadID is the record's thread.
+	ThreadID thread.ID
+	// LogID is the record's log.
+	LogID peer.ID
+	// Record is the record itself.
+	Record core.Record
+	// BodySize is the size of the encrypted record body in bytes.
+	BodySize int
+	// Body is the decrypted record body, or nil if the host does not hold the thread read key.
+	Body format.Node
+}
+
+// shouldReplicate returns whether or not the body of rec should be persisted.
+func (n *net) shouldReplicate(ctx context.Context, id thread.ID, lg thread.LogInfo, rec core.Record, event *cbor.Event, body format.Node) bool {
+	if n.conf.ReplicationFilter == nil || lg.PrivKey != nil {
+		return true
+	}
+	meta := RecordMeta{
+		ThreadID: id,
+		LogID:    lg.ID,
+		Record:   rec,
+		BodySize: len(body.RawData()),
+	}
+	rk, err := n.store.ReadKey(id)
+	if err != nil {
+		n.log.Errorf("error getting read-key for thread %s: %s", id, err)
+	} else if rk != nil {
+		if meta.Body, err = event.GetBody(ctx, n.threadDAG(id), rk); err != nil {
+			n.log.Errorf("error decrypting body of record %s: %s", rec.Cid(), err)
+		}
+	}
+	return n.conf.ReplicationFilter(meta)
+}
+
+// skippedBodyKey returns the thread metadata key of the size of a record body
+// that was not persisted.
+func skippedBodyKey(rid cid.Cid) string {
+	return "skippedBody/" + rid.String()
+}
+
+// skipBody marks the body of a record as not persisted.
+func (n *net) skipBody(id thread.ID, rid cid.Cid, size int) error {
+	return n.store.PutInt64(id, skippedBodyKey(rid), int64(size))
+}
+
+// servableRecords returns the leading records, oldest first, that can be sent
+// to peers, i.e., up to the first record whose body was not persisted, and
+// whether or not any records were left out.
+func (n *net) servableRecords(id thread.ID, recs []core.Record) ([]core.Record, bool, error) {
+	if n.conf.ReplicationFilter == nil {
+		return recs, false, nil
+	}
+	for i, r := range recs {
+		v, err := n.store.GetInt64(id, skippedBodyKey(r.Cid()))
+		if err != nil {
+			return nil, false, err
+		}
+		if v != nil {
+			return recs[:i], true, nil
+		}
+	}
+	return recs, false, nil
+}
+
+// getServableRecordRange is like getLocalRecordRange, but leaves out the
+// records that can't be sent to peers. See servableRecords. A range that was
+// cut short is not reported as truncated, since no more records can be sent.
+func (n *net) getServableRecordRange(ctx context.Context, id thread.ID, lid peer.ID, offset, to cid.Cid, limit int) ([]core.Record, bool, error) {
+	recs, truncated, err := n.getLocalRecordRange(ctx, id, lid, offset, to, limit)
+	if err != nil {
+		return nil, false, err
+	}
+	recs, cut, err := n.servableRecords(id, recs)
+	if err != nil {
+		return nil, false, err
+	}
+	return recs, truncated && !cut, nil
+}
@@ -0,0 +1,66 @@
+package net
+
+import (
+	"sync"
+
+	"github.com/textileio/go-threads/core/thread"
+)
+
+// routines counts the goroutines running on behalf of each thread.
+type routines struct {
+	sync.Mutex
+	m map[thread.ID]int
+}
+
+func newRoutines() *routines {
+	return &routines{m: make(map[thread.ID]int)}
+}
+
+// enter accounts a goroutine to a thread. If limit is greater than zero and
+// the thread already has limit goroutines, enter returns false.
+func (r *routines) enter(id thread.ID, limit int) bool {
+	r.Lock()
+	defer r.Unlock()
+	if limit > 0 && r.m[id] >= limit {
+		return false
+	}
+	r.m[id]++
+	return true
+}
+
+// exit releases a goroutine accounted to a thread.
+func (r *routines) exit(id thread.ID) {
+	r.Lock()
+	defer r.Unlock()
+	if r.m[id] <= 1 {
+		delete(r.m, id)
+	} else {
+		r.m[id]--
+	}
+}
+
+// spawn runs f in a background goroutine accounted to a thread.
+// Nothing is run if the thread has reached the configured goroutine cap.
+func (n *net) spawn(id thread.ID, f func()) bool {
+	if !n.routines.enter(id, n.settings.maxThreadGoroutines()) {
+		n.log.Warnf("thread %s reached its goroutine cap, skipping background work", id)
+		return false
+	}
+	go func() {
+		defer n.routines.exit(id)
+		f()
+	}()
+	return true
+}
+
+// ThreadGoroutines returns the number of background goroutines currently
+// running on behalf of each thread.
+func (n *net) ThreadGoroutines() map[thread.ID]int {
+	n.routines.Lock()
+	defer n.routines.Unlock()
+	res := make(map[thread.ID]int, len(n.routines.m))
+	for id, c := range n.routines.m {
+		res[id] = c
+	}
+	return res
+}
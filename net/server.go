@@ -4,66 +4,112 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
+	"reflect"
 	"sync"
+	"time"
 
 	"github.com/gogo/protobuf/proto"
 	"github.com/gogo/status"
 	"github.com/ipfs/go-cid"
+	"github.com/libp2p/go-libp2p-core/crypto"
 	"github.com/libp2p/go-libp2p-core/peer"
-	pubsub "github.com/libp2p/go-libp2p-pubsub"
 	ma "github.com/multiformats/go-multiaddr"
 	"github.com/textileio/go-threads/cbor"
 	lstore "github.com/textileio/go-threads/core/logstore"
+	core "github.com/textileio/go-threads/core/net"
 	"github.com/textileio/go-threads/core/thread"
 	pb "github.com/textileio/go-threads/net/pb"
-	"google.golang.org/grpc"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc/codes"
 )
 
 // server implements the net gRPC server.
 type server struct {
 	sync.Mutex
-	net   *net
-	ps    *PubSub
-	conns map[peer.ID]*grpc.ClientConn
+	net        *net
+	ps         *PubSub
+	conns      *connCache
+	health     *peerHealth
+	outbound   *outbound
+	inflight   *inflight
+	gaps       *gapFills
+	deliveries *deliveries
+	limiter    *peerLimiter
+	followers  *followers
+	// logFetches are the unknown logs being fetched from the sender of a
+	// pushed record.
+	logFetches map[logKey]struct{}
+	// pushSlots bounds direct pushes run at once under connection pressure.
+	pushSlots chan struct{}
+
+	introducers map[peer.ID]struct{}
+
+	log Logger
 }
 
 // newServer creates a new network server.
 func newServer(n *net) (*server, error) {
-	s := &server{
-		net:   n,
-		conns: make(map[peer.ID]*grpc.ClientConn),
-	}
-	ps, err := pubsub.NewGossipSub(
-		n.ctx,
-		n.host,
-		pubsub.WithMessageSigning(false),
-		pubsub.WithStrictSignatureVerification(false))
+	queue, err := newOutbound(n.conf.PushRetryTTL, n.conf.OutboundQueueLimit, n.conf.OutboundStore, n.log)
 	if err != nil {
 		return nil, err
 	}
-	s.ps = NewPubSub(n.ctx, n.host.ID(), ps, s.pubsubHandler)
+	s := &server{
+		net:        n,
+		conns:      newConnCache(MaxConns, n.log),
+		health:     newPeerHealth(n.conf.UnreachableAfter, n.conf.UnreachableBackoff, n.log),
+		outbound:   queue,
+		inflight:   newInflight(),
+		gaps:       newGapFills(),
+		deliveries: newDeliveries(),
+		limiter:    newPeerLimiter(n.conf.PeerRateLimit),
+		followers:  newFollowers(),
+		logFetches: make(map[logKey]struct{}),
+		pushSlots:  make(chan struct{}, n.pressurePushConcurrency()),
+		log:        n.log,
+	}
+	if len(n.conf.ThreadIntroducers) > 0 {
+		s.introducers = make(map[peer.ID]struct{})
+		for _, p := range n.conf.ThreadIntroducers {
+			s.introducers[p] = struct{}{}
+		}
+	}
+	if n.conf.LocalOnly {
+		return s, nil
+	}
+	ps := n.conf.PubSub
+	if ps == nil {
+		var err error
+		ps, err = newPubSubRouter(n.ctx, n.host, n.conf.PubSubRouter)
+		if err != nil {
+			return nil, err
+		}
+	}
+	s.ps = NewPubSub(n.ctx, n.host.ID(), ps, s.pubsubHandler, n.conf)
+	if err := s.ps.SetMaxTopics(n.conf.MaxSubscriptions); err != nil {
+		return nil, err
+	}
 
 	ts, err := n.store.Threads()
 	if err != nil {
 		return nil, err
 	}
 	for _, id := range ts {
-		if err := s.ps.Add(id); err != nil {
-			return nil, err
-		}
+		// Failed topics are retried in the background
+		_ = s.ps.Add(id)
 	}
 	return s, nil
 }
 
-// pubsubHandler receives records over pubsub.
+// pubsubHandler receives records over pubsub. They count towards the rate
+// limit of the peer that signed them, like records pushed directly.
 func (s *server) pubsubHandler(ctx context.Context, req *pb.PushRecordRequest) {
 	if _, err := s.PushRecord(ctx, req); err != nil {
 		// This error will be "log not found" if the record sent over pubsub
 		// beat the log, which has to be sent directly via the normal API.
 		// In this case, the record will arrive directly after the log via
 		// the normal API.
-		log.Debugf("error handling pubsub record: %s", err)
+		s.log.Debugf("error handling pubsub record: %s", err)
 	}
 }
 
@@ -73,26 +119,106 @@ func (s *server) GetLogs(_ context.Context, req *pb.GetLogsRequest) (*pb.GetLogs
 	if err != nil {
 		return nil, err
 	}
-	log.Debugf("received get logs request from %s", pid)
+	s.log.Debugf("received get logs request from %s", pid)
 
 	pblgs := &pb.GetLogsReply{}
-	if err := s.checkServiceKey(req.Body.ThreadID.ID, req.Body.ServiceKey); err != nil {
+	if pblgs.Logs, err = s.threadLogs(req.Body); err != nil {
 		return pblgs, err
 	}
 
-	info, err := s.net.store.GetThread(req.Body.ThreadID.ID) // Safe since putRecord will change head when fully-available
+	s.log.Debugf("sending %d logs to %s", len(pblgs.Logs), pid)
+
+	return pblgs, nil
+}
+
+// GetLogsBatch receives a batch get logs request. Each thread is authorized
+// with its own service key. Threads that fail are reported in their entry
+// without failing the whole request.
+func (s *server) GetLogsBatch(_ context.Context, req *pb.GetLogsBatchRequest) (*pb.GetLogsBatchReply, error) {
+	if req.Body == nil {
+		return nil, status.Error(codes.InvalidArgument, "a batch is required")
+	}
+	pid, err := verifyRequest(req.Header, req.Body)
+	if err != nil {
+		return nil, err
+	}
+	s.log.Debugf("received batch get logs request for %d threads from %s", len(req.Body.Threads), pid)
+
+	if len(req.Body.Threads) > MaxGetLogsBatchSize {
+		return nil, status.Errorf(codes.InvalidArgument, "batch exceeds %d threads", MaxGetLogsBatchSize)
+	}
+	reply := &pb.GetLogsBatchReply{
+		Threads: make([]*pb.GetLogsBatchReply_ThreadEntry, len(req.Body.Threads)),
+	}
+	for i, body := range req.Body.Threads {
+		entry := &pb.GetLogsBatchReply_ThreadEntry{}
+		if body == nil || body.ThreadID == nil {
+			entry.Error = "missing thread id"
+		} else {
+			entry.ThreadID = body.ThreadID
+			if entry.Logs, err = s.threadLogs(body); err != nil {
+				entry.Error = err.Error()
+			}
+		}
+		reply.Threads[i] = entry
+	}
+
+	s.log.Debugf("sending logs of %d threads to %s", len(reply.Threads), pid)
+
+	return reply, nil
+}
+
+// threadLogs returns the logs of the thread in a get logs request body,
+// after checking the service key.
+func (s *server) threadLogs(body *pb.GetLogsRequest_Body) ([]*pb.Log, error) {
+	if body.ThreadID == nil {
+		return nil, status.Error(codes.InvalidArgument, "a thread is required")
+	}
+	if err := s.checkServiceKey(body.ThreadID.ID, body.ServiceKey); err != nil {
+		return nil, err
+	}
+
+	info, err := s.net.store.GetThread(body.ThreadID.ID) // Safe since putRecord will change head when fully-available
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
-	pblgs.Logs = make([]*pb.Log, len(info.Logs))
+	pblgs := make([]*pb.Log, len(info.Logs))
 	for i, l := range info.Logs {
-		pblgs.Logs[i] = logToProto(l)
+		pblgs[i] = logToProto(s.net.advertisedLog(l))
 	}
+	return pblgs, nil
+}
 
-	log.Debugf("sending %d logs to %s", len(info.Logs), pid)
+// GetHeads receives a get heads request.
+func (s *server) GetHeads(_ context.Context, req *pb.GetHeadsRequest) (*pb.GetHeadsReply, error) {
+	if req.Body == nil || req.Body.ThreadID == nil || req.Body.LogID == nil {
+		return nil, status.Error(codes.InvalidArgument, "a thread and log are required")
+	}
+	pid, err := verifyRequest(req.Header, req.Body)
+	if err != nil {
+		return nil, err
+	}
+	s.log.Debugf("received get heads request from %s", pid)
 
-	return pblgs, nil
+	if err = s.checkServiceKey(req.Body.ThreadID.ID, req.Body.ServiceKey); err != nil {
+		return nil, err
+	}
+	if _, err = s.net.store.GetLog(req.Body.ThreadID.ID, req.Body.LogID.ID); err != nil {
+		if errors.Is(err, lstore.ErrLogNotFound) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	heads, err := s.net.store.Heads(req.Body.ThreadID.ID, req.Body.LogID.ID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	reply := &pb.GetHeadsReply{Heads: make([]pb.ProtoCid, len(heads))}
+	for i, h := range heads {
+		reply.Heads[i] = pb.ProtoCid{Cid: h}
+	}
+	return reply, nil
 }
 
 // PushLog receives a push log request.
@@ -102,7 +228,13 @@ func (s *server) PushLog(_ context.Context, req *pb.PushLogRequest) (*pb.PushLog
 	if err != nil {
 		return nil, err
 	}
-	log.Debugf("received push log request from %s", pid)
+	s.log.Debugf("received push log request from %s", pid)
+	lg := logFromProto(req.Body.Log)
+	if removed, err := s.net.isTombstoned(req.Body.ThreadID.ID, lg.ID); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	} else if removed {
+		return nil, status.Error(codes.PermissionDenied, "log was removed from the thread")
+	}
 
 	// Pick up missing keys
 	info, err := s.net.store.GetThread(req.Body.ThreadID.ID)
@@ -111,12 +243,35 @@ func (s *server) PushLog(_ context.Context, req *pb.PushLogRequest) (*pb.PushLog
 	}
 	if !info.Key.Defined() {
 		if req.Body.ServiceKey != nil && req.Body.ServiceKey.Key != nil {
+			if !s.canIntroduce(pid) {
+				return nil, status.Error(codes.PermissionDenied, "peer is not allowed to introduce threads")
+			}
 			if err = s.net.store.AddServiceKey(req.Body.ThreadID.ID, req.Body.ServiceKey.Key); err != nil {
 				return nil, status.Error(codes.Internal, err.Error())
 			}
 		} else {
 			return nil, status.Error(codes.NotFound, lstore.ErrThreadNotFound.Error())
 		}
+	} else if rekeyed(info.Key, req.Body.ServiceKey, req.Body.ReadKey) {
+		// The thread was rekeyed, or its read key rotated, by a member
+		revoked, err := s.net.verifyKeyChange(req.Body.ThreadID.ID, info.Key, req.Body)
+		if err != nil {
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		}
+		key := thread.NewServiceKey(req.Body.ServiceKey.Key)
+		if req.Body.ReadKey != nil && req.Body.ReadKey.Key != nil {
+			key = thread.NewKey(req.Body.ServiceKey.Key, req.Body.ReadKey.Key)
+		}
+		if err = s.net.rotateKey(req.Body.ThreadID.ID, info.Key, key); err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		own, err := s.net.getOwnLog(req.Body.ThreadID.ID)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		if err = s.net.revokeLogs(req.Body.ThreadID.ID, own.ID, revoked); err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
 	} else if !info.Key.CanRead() {
 		if req.Body.ReadKey != nil && req.Body.ReadKey.Key != nil {
 			if err = s.net.store.AddReadKey(req.Body.ThreadID.ID, req.Body.ReadKey.Key); err != nil {
@@ -125,115 +280,466 @@ func (s *server) PushLog(_ context.Context, req *pb.PushLogRequest) (*pb.PushLog
 		}
 	}
 
-	lg := logFromProto(req.Body.Log)
-	err = s.net.createExternalLogIfNotExist(req.Body.ThreadID.ID, lg.ID, lg.PubKey, lg.PrivKey, lg.Addrs)
+	err = s.net.addExternalLog(req.Body.ThreadID.ID, lg, AddrSourcePushed, pid)
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
+	if !info.Key.Defined() {
+		// The thread was introduced by this request
+		id := req.Body.ThreadID.ID
+		s.net.spawn(id, func() {
+			tinfo, err := s.net.getThreadWithAddrs(id)
+			if err == nil {
+				err = s.net.runCreatedHook(s.net.ctx, tinfo)
+			}
+			if err != nil {
+				s.log.Errorf("error running thread hook for %s: %s", id, err)
+			}
+		})
+	}
 
-	go s.net.updateRecordsFromLog(req.Body.ThreadID.ID, lg.ID)
+	s.net.logPulls.enqueue(req.Body.ThreadID.ID, lg.ID)
 	return &pb.PushLogReply{}, nil
 }
 
+// rekeyed returns whether the keys pushed with a log replace the current
+// thread key, i.e., the service key differs, or the read key differs from a
+// read key that is already held.
+func rekeyed(cur thread.Key, sk, rk *pb.ProtoKey) bool {
+	if sk == nil || sk.Key == nil {
+		return false
+	}
+	if !bytes.Equal(sk.Key.Bytes(), cur.Service().Bytes()) {
+		return true
+	}
+	return cur.CanRead() && rk != nil && rk.Key != nil &&
+		!bytes.Equal(rk.Key.Bytes(), cur.Read().Bytes())
+}
+
 // GetRecords receives a get records request.
-func (s *server) GetRecords(ctx context.Context, req *pb.GetRecordsRequest) (*pb.GetRecordsReply, error) {
+func (s *server) GetRecords(ctx context.Context, req *pb.GetRecordsRequest) (_ *pb.GetRecordsReply, err error) {
+	if req.Body == nil || req.Body.ThreadID == nil {
+		return nil, status.Error(codes.InvalidArgument, "a thread is required")
+	}
 	pid, err := verifyRequest(req.Header, req.Body)
 	if err != nil {
 		return nil, err
 	}
-	log.Debugf("received get records request from %s", pid)
+	ctx, span := s.net.tracer.start(ctx, "GetRecords", req.Body.ThreadID.ID, "", cid.Undef)
+	defer func() { s.net.tracer.end(span, err) }()
+	s.log.Debugf("received get records request from %s", pid)
 
 	pbrecs := &pb.GetRecordsReply{}
 	if err := s.checkServiceKey(req.Body.ThreadID.ID, req.Body.ServiceKey); err != nil {
 		return pbrecs, err
 	}
 
-	reqd := make(map[peer.ID]*pb.GetRecordsRequest_Body_LogEntry)
-	for _, l := range req.Body.Logs {
-		reqd[l.LogID.ID] = l
-	}
-	info, err := s.net.store.GetThread(req.Body.ThreadID.ID)
+	pulls, err := s.logPulls(req)
 	if err != nil {
 		return nil, err
 	}
-	pbrecs.Logs = make([]*pb.GetRecordsReply_LogEntry, len(info.Logs))
+	pbrecs.Logs = make([]*pb.GetRecordsReply_LogEntry, len(pulls))
 
-	for i, lg := range info.Logs {
-		var offset cid.Cid
-		var limit int
-		var pblg *pb.Log
-		if opts, ok := reqd[lg.ID]; ok {
-			offset = opts.Offset.Cid
-			limit = int(opts.Limit)
-		} else {
-			offset = cid.Undef
-			limit = MaxPullLimit
-			pblg = logToProto(lg)
+	for i, lp := range pulls {
+		if req.Body.Proof && lp.to.Defined() {
+			return nil, status.Error(codes.InvalidArgument, "proofs are not supported for bounded ranges")
 		}
-		recs, err := s.net.getLocalRecords(ctx, req.Body.ThreadID.ID, lg.ID, offset, limit)
+		recs, truncated, err := s.net.getServableRecordRange(ctx, req.Body.ThreadID.ID, lp.lg.ID, lp.offset, lp.to, lp.limit)
 		if err != nil {
 			return nil, status.Error(codes.Internal, err.Error())
 		}
 
 		entry := &pb.GetRecordsReply_LogEntry{
-			LogID:   &pb.ProtoPeerID{ID: lg.ID},
-			Records: make([]*pb.Log_Record, len(recs)),
-			Log:     pblg,
+			LogID:     &pb.ProtoPeerID{ID: lp.lg.ID},
+			Records:   make([]*pb.Log_Record, len(recs)),
+			Log:       lp.pblg,
+			Truncated: truncated,
 		}
 		for j, r := range recs {
-			entry.Records[j], err = cbor.RecordToProto(ctx, s.net, r)
+			entry.Records[j], err = s.replyRecord(ctx, req, r)
+			if err != nil {
+				return nil, status.Error(codes.Internal, err.Error())
+			}
+		}
+		if req.Body.Proof {
+			entry.Manifest, err = s.manifest(req.Body.ThreadID.ID, lp.lg.ID, lp.offset, recs)
 			if err != nil {
 				return nil, status.Error(codes.Internal, err.Error())
 			}
 		}
 		pbrecs.Logs[i] = entry
 
-		log.Debugf("sending %d records in log %s to %s", len(recs), lg.ID, pid)
+		s.log.Debugf("sending %d records in log %s to %s", len(recs), lp.lg.ID, pid)
 	}
 
 	return pbrecs, nil
 }
 
+// GetRecordsStream receives a get records request and replies with a
+// stream of records, one per message, so that large logs are not held in
+// a single reply. Proofs are not supported.
+func (s *server) GetRecordsStream(req *pb.GetRecordsRequest, stream pb.Service_GetRecordsStreamServer) error {
+	if req.Body == nil || req.Body.ThreadID == nil {
+		return status.Error(codes.InvalidArgument, "a thread is required")
+	}
+	pid, err := verifyRequest(req.Header, req.Body)
+	if err != nil {
+		return err
+	}
+	s.log.Debugf("received get records stream request from %s", pid)
+
+	if req.Body.Proof {
+		return status.Error(codes.InvalidArgument, "proofs are not supported by record streams")
+	}
+	if err := s.checkServiceKey(req.Body.ThreadID.ID, req.Body.ServiceKey); err != nil {
+		return err
+	}
+
+	pulls, err := s.logPulls(req)
+	if err != nil {
+		return err
+	}
+	ctx := stream.Context()
+
+	for _, lp := range pulls {
+		lid := &pb.ProtoPeerID{ID: lp.lg.ID}
+		if lp.pblg != nil {
+			if err = stream.Send(&pb.GetRecordsStreamReply{LogID: lid, Log: lp.pblg}); err != nil {
+				return err
+			}
+		}
+		recs, truncated, err := s.net.getServableRecordRange(ctx, req.Body.ThreadID.ID, lp.lg.ID, lp.offset, lp.to, lp.limit)
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+		for _, r := range recs {
+			pbrec, err := s.replyRecord(ctx, req, r)
+			if err != nil {
+				return status.Error(codes.Internal, err.Error())
+			}
+			if err = stream.Send(&pb.GetRecordsStreamReply{LogID: lid, Record: pbrec}); err != nil {
+				return err
+			}
+		}
+		if truncated {
+			if err = stream.Send(&pb.GetRecordsStreamReply{LogID: lid, Truncated: true}); err != nil {
+				return err
+			}
+		}
+
+		s.log.Debugf("streamed %d records in log %s to %s", len(recs), lp.lg.ID, pid)
+	}
+
+	return nil
+}
+
+// logPull is a log to send in reply to a get records request.
+type logPull struct {
+	lg     thread.LogInfo
+	offset cid.Cid
+	to     cid.Cid
+	limit  int
+	// pblg is set for logs missing from the request.
+	pblg *pb.Log
+}
+
+// logPulls returns the logs to send in reply to a get records request.
+// Logs missing from the request are sent in full along with their info.
+// Requested limits are capped by the pull limit.
+func (s *server) logPulls(req *pb.GetRecordsRequest) ([]logPull, error) {
+	max := s.pullLimit()
+	reqd := make(map[peer.ID]*pb.GetRecordsRequest_Body_LogEntry)
+	for _, l := range req.Body.Logs {
+		if l == nil || l.LogID == nil || l.Offset == nil {
+			return nil, status.Error(codes.InvalidArgument, "a log and offset are required")
+		}
+		reqd[l.LogID.ID] = l
+	}
+	info, err := s.net.store.GetThread(req.Body.ThreadID.ID)
+	if err != nil {
+		return nil, err
+	}
+	pulls := make([]logPull, len(info.Logs))
+	for i, lg := range info.Logs {
+		pulls[i].lg = lg
+		if opts, ok := reqd[lg.ID]; ok {
+			pulls[i].offset = opts.Offset.Cid
+			pulls[i].limit = int(opts.Limit)
+			if pulls[i].limit > max {
+				pulls[i].limit = max
+			}
+			if opts.To != nil {
+				pulls[i].to = opts.To.Cid
+			}
+		} else {
+			pulls[i].offset = cid.Undef
+			pulls[i].limit = max
+			pulls[i].pblg = logToProto(s.net.advertisedLog(lg))
+		}
+	}
+	return pulls, nil
+}
+
+// pullLimit returns the max number of records sent per log in reply to a get
+// records request.
+func (s *server) pullLimit() int {
+	if s.net.conf.MaxPullLimit > 0 {
+		return s.net.conf.MaxPullLimit
+	}
+	return MaxPullLimit
+}
+
+// replyRecord returns the proto record sent in reply to a get records request.
+func (s *server) replyRecord(ctx context.Context, req *pb.GetRecordsRequest, r core.Record) (*pb.Log_Record, error) {
+	pbrec, err := cbor.RecordToProto(ctx, s.net.threadDAG(req.Body.ThreadID.ID), r)
+	if err != nil {
+		return nil, err
+	}
+	if req.Header.Compact && s.net.conf.CompactRecords {
+		pbrec = cbor.CompactRecordProto(pbrec)
+	}
+	return pbrec, nil
+}
+
 // PushRecord receives a push record request.
-func (s *server) PushRecord(ctx context.Context, req *pb.PushRecordRequest) (*pb.PushRecordReply, error) {
+func (s *server) PushRecord(ctx context.Context, req *pb.PushRecordRequest) (_ *pb.PushRecordReply, err error) {
+	if req.Body == nil || req.Body.ThreadID == nil || req.Body.LogID == nil || req.Body.Record == nil {
+		return nil, status.Error(codes.InvalidArgument, "a thread, log and record are required")
+	}
 	pid, err := verifyRequest(req.Header, req.Body)
 	if err != nil {
 		return nil, err
 	}
-	log.Debugf("received push record request from %s", pid)
+	ctx, span := s.net.tracer.start(ctx, "PushRecord", req.Body.ThreadID.ID, req.Body.LogID.ID, cid.Undef)
+	defer func() { s.net.tracer.end(span, err) }()
+	s.log.Debugf("received push record request from %s", pid)
+	if !s.limiter.allow(pid, 1, time.Now()) {
+		return nil, status.Error(codes.ResourceExhausted, "peer rate limit exceeded")
+	}
 
-	// A log is required to accept new records
-	logpk, err := s.net.store.PubKey(req.Body.ThreadID.ID, req.Body.LogID.ID)
+	errs, err := s.acceptRecords(ctx, pid, req.Body.ThreadID.ID, req.Body.LogID.ID, []*pb.Log_Record{req.Body.Record})
 	if err != nil {
-		return nil, status.Error(codes.Internal, err.Error())
+		return nil, err
 	}
-	if logpk == nil {
-		return nil, status.Error(codes.NotFound, "log not found")
+	if errs[0] != nil {
+		return nil, errs[0]
 	}
+	return &pb.PushRecordReply{}, nil
+}
 
-	key, err := s.net.store.ServiceKey(req.Body.ThreadID.ID)
+// PushRecords receives a batch push records request. The records are
+// accepted in order, and the outcome of each is reported in the reply.
+// Records after the first one that is not accepted are skipped with
+// codes.Aborted, so the sender can resume from the failed record. Records
+// held until their ancestors arrive are reported with
+// codes.FailedPrecondition, and don't stop the batch.
+func (s *server) PushRecords(ctx context.Context, req *pb.PushRecordsRequest) (_ *pb.PushRecordsReply, err error) {
+	if req.Body == nil || req.Body.ThreadID == nil || req.Body.LogID == nil {
+		return nil, status.Error(codes.InvalidArgument, "a thread and log are required")
+	}
+	for _, pbrec := range req.Body.Records {
+		if pbrec == nil {
+			return nil, status.Error(codes.InvalidArgument, "records must not be empty")
+		}
+	}
+	pid, err := verifyRequest(req.Header, req.Body)
 	if err != nil {
-		return nil, status.Error(codes.Internal, err.Error())
+		return nil, err
+	}
+	ctx, span := s.net.tracer.start(ctx, "PushRecords", req.Body.ThreadID.ID, req.Body.LogID.ID, cid.Undef)
+	defer func() { s.net.tracer.end(span, err) }()
+	s.log.Debugf("received push records request for %d records from %s", len(req.Body.Records), pid)
+	if len(req.Body.Records) > MaxPushBatchSize {
+		return nil, status.Errorf(codes.InvalidArgument, "batch exceeds %d records", MaxPushBatchSize)
 	}
-	rec, err := cbor.RecordFromProto(req.Body.Record, key)
+	if !s.limiter.allow(pid, len(req.Body.Records), time.Now()) {
+		return nil, status.Error(codes.ResourceExhausted, "peer rate limit exceeded")
+	}
+	errs, err := s.acceptRecords(ctx, pid, req.Body.ThreadID.ID, req.Body.LogID.ID, req.Body.Records)
 	if err != nil {
+		return nil, err
+	}
+	reply := &pb.PushRecordsReply{Results: make([]*pb.PushRecordsReply_Result, len(errs))}
+	for i, err := range errs {
+		st := status.Convert(err)
+		reply.Results[i] = &pb.PushRecordsReply_Result{Code: int32(st.Code()), Message: st.Message()}
+	}
+	return reply, nil
+}
+
+// acceptRecords verifies and stores pushed records in a log, in order.
+// An error is returned for each record. Records after the first error are
+// not attempted. The returned error is set if no record can be accepted,
+// e.g., because the log is unknown.
+func (s *server) acceptRecords(ctx context.Context, pid peer.ID, id thread.ID, lid peer.ID, pbrecs []*pb.Log_Record) ([]error, error) {
+	if removed, err := s.net.isTombstoned(id, lid); err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
+	} else if removed {
+		return nil, status.Error(codes.NotFound, "log was removed from the thread")
 	}
-	knownRecord, err := s.net.bstore.Has(rec.Cid())
+	// A log is required to accept new records
+	logpk, err := s.net.store.PubKey(id, lid)
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
+	if logpk == nil && s.net.conf.FetchMissingLogs {
+		logpk, err = s.fetchLog(ctx, id, lid, pid)
+		if err != nil {
+			s.log.Warnf("error fetching log %s from %s: %s", lid, pid, err)
+		}
+	}
+	if logpk == nil {
+		return nil, status.Error(codes.NotFound, "log not found")
+	}
+
+	errs := make([]error, len(pbrecs))
+	for i, pbrec := range pbrecs {
+		if errs[i] = s.acceptRecord(ctx, pid, id, lid, logpk, pbrec); errs[i] != nil && !isHeld(errs[i]) {
+			for j := i + 1; j < len(pbrecs); j++ {
+				errs[j] = status.Error(codes.Aborted, "a previous record was not accepted")
+			}
+			break
+		}
+	}
+	return errs, nil
+}
+
+// acceptRecord verifies and stores a single record pushed by pid.
+func (s *server) acceptRecord(ctx context.Context, pid peer.ID, id thread.ID, lid peer.ID, logpk crypto.PubKey, pbrec *pb.Log_Record) error {
+	s.net.metrics.observeRecordSize(pbrec.Size())
+	rec, err := s.net.recordFromProto(id, pbrec)
+	if errors.Is(err, ErrRecordTooLarge) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	} else if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	s.net.tracer.annotate(trace.SpanFromContext(ctx), thread.Undef, "", rec.Cid())
+	knownRecord, err := s.net.hasBlock(id, rec.Cid())
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
 	if knownRecord {
-		return &pb.PushRecordReply{}, nil
+		return nil
 	}
 
-	if err = rec.Verify(logpk); err != nil {
-		return nil, status.Error(codes.Unauthenticated, err.Error())
+	if err = s.net.validateRecord(ctx, PushedRecord{
+		Thread: id,
+		Log:    lid,
+		LogKey: logpk,
+		From:   pid,
+		Record: rec,
+	}); err != nil {
+		return err
+	}
+	if err = s.net.PutRecord(ctx, id, lid, rec); err != nil {
+		if errors.Is(err, ErrEquivocation) {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		// The record may have arrived before its ancestors
+		if rec.PrevID().Defined() {
+			has, herr := s.net.hasBlock(id, rec.PrevID())
+			if herr == nil && !has && s.net.orphans.add(id, lid, rec) {
+				s.log.Debugf("holding record %s until %s arrives", rec.Cid(), rec.PrevID())
+				s.fillGap(id, lid, rec.PrevID(), pid)
+				return errRecordHeld
+			}
+		}
+		return status.Error(codes.Internal, err.Error())
 	}
-	if err = s.net.PutRecord(ctx, req.Body.ThreadID.ID, req.Body.LogID.ID, rec); err != nil {
-		return nil, status.Error(codes.Internal, err.Error())
+	return nil
+}
+
+// fetchLog requests the logs of a known thread from a peer and adds the log
+// with the given ID, returning its public key.
+// A nil key is returned if the peer doesn't have the log either, or if the
+// log is already being fetched, so that a burst of pushes to an unknown log
+// results in one request. The fetched log is only added if its key matches
+// its ID.
+func (s *server) fetchLog(ctx context.Context, id thread.ID, lid peer.ID, pid peer.ID) (crypto.PubKey, error) {
+	k := logKey{id: id, lid: lid}
+	s.Lock()
+	if _, ok := s.logFetches[k]; ok {
+		s.Unlock()
+		return nil, nil
+	}
+	s.logFetches[k] = struct{}{}
+	s.Unlock()
+	defer func() {
+		s.Lock()
+		delete(s.logFetches, k)
+		s.Unlock()
+	}()
+
+	lgs, err := s.getLogs(ctx, id, pid)
+	if err != nil {
+		return nil, err
 	}
-	return &pb.PushRecordReply{}, nil
+	for _, lg := range lgs {
+		if lg.ID != lid {
+			continue
+		}
+		if lg.PubKey == nil || !lid.MatchesPublicKey(lg.PubKey) {
+			return nil, fmt.Errorf("log %s has a public key that does not match its ID", lid)
+		}
+		lg.Head = cid.Undef
+		if err = s.net.addLog(id, lg, AddrSourcePulled, pid); err != nil {
+			return nil, err
+		}
+		return lg.PubKey, nil
+	}
+	return nil, nil
+}
+
+// fetchMissingRecords pulls the records of a log that are missing locally,
+// up to prev, from the peer that pushed a record building on them. The
+// records are validated like pushed records before they're put.
+func (s *server) fetchMissingRecords(ctx context.Context, id thread.ID, lid peer.ID, prev cid.Cid, pid peer.ID) error {
+	addr, err := ma.NewMultiaddr("/" + ma.ProtocolWithCode(ma.P_P2P).Name + "/" + pid.String())
+	if err != nil {
+		return err
+	}
+	lg, err := s.net.store.GetLog(id, lid)
+	if err != nil {
+		return err
+	}
+	offset := cid.Undef
+	if lg.Head.Defined() {
+		has, err := s.net.hasBlock(id, lg.Head)
+		if err != nil {
+			return err
+		}
+		if has {
+			offset = lg.Head
+		}
+	}
+	recs, err := s.getRecordRange(ctx, id, lid, offset, prev, []ma.Multiaddr{addr})
+	if err != nil {
+		return err
+	}
+	for _, r := range recs {
+		if err = s.net.validateRecord(ctx, PushedRecord{
+			Thread: id,
+			Log:    lid,
+			LogKey: lg.PubKey,
+			From:   pid,
+			Record: r,
+		}); err != nil {
+			return err
+		}
+		if err = s.net.PutRecord(ctx, id, lid, r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// canIntroduce returns whether or not a peer may push logs for threads unknown to the host.
+func (s *server) canIntroduce(pid peer.ID) bool {
+	if s.introducers == nil {
+		return true
+	}
+	_, ok := s.introducers[pid]
+	return ok
 }
 
 // checkServiceKey compares a key with the one stored under thread.
@@ -256,7 +762,7 @@ func (s *server) checkServiceKey(id thread.ID, k *pb.ProtoKey) error {
 
 // verifyRequest verifies that the signature associated with a request is valid.
 func verifyRequest(header *pb.Header, body proto.Marshaler) (pid peer.ID, err error) {
-	if header == nil || body == nil {
+	if header == nil || header.PubKey == nil || header.PubKey.PubKey == nil || isNilBody(body) {
 		err = status.Error(codes.InvalidArgument, "bad request")
 		return
 	}
@@ -278,6 +784,16 @@ func verifyRequest(header *pb.Header, body proto.Marshaler) (pid peer.ID, err er
 	return pid, nil
 }
 
+// isNilBody returns whether a request body is missing. Bodies are passed as
+// interfaces, so a missing body is usually a typed nil pointer.
+func isNilBody(body proto.Marshaler) bool {
+	if body == nil {
+		return true
+	}
+	v := reflect.ValueOf(body)
+	return v.Kind() == reflect.Ptr && v.IsNil()
+}
+
 // logToProto returns a proto log from a thread log.
 func logToProto(l thread.LogInfo) *pb.Log {
 	pbaddrs := make([]pb.ProtoAddr, len(l.Addrs))
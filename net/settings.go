@@ -0,0 +1,103 @@
+package net
+
+import (
+	"sync"
+	"time"
+)
+
+// settings holds the network parameters that may be changed while running.
+// Changes take effect on subsequent operations.
+type settings struct {
+	sync.RWMutex
+
+	timeout     time.Duration
+	maxRoutines int
+	maxPeers    int
+	autoPullOff bool
+	logRate     LogRateLimit
+}
+
+func newSettings(conf Config) *settings {
+	s := &settings{
+		timeout:     conf.RequestTimeout,
+		maxRoutines: conf.MaxThreadGoroutines,
+		maxPeers:    conf.MaxTopicPeers,
+		logRate:     conf.LogRateLimit,
+	}
+	if s.timeout <= 0 {
+		s.timeout = DialTimeout
+	}
+	return s
+}
+
+func (s *settings) requestTimeout() time.Duration {
+	s.RLock()
+	defer s.RUnlock()
+	return s.timeout
+}
+
+func (s *settings) maxThreadGoroutines() int {
+	s.RLock()
+	defer s.RUnlock()
+	return s.maxRoutines
+}
+
+func (s *settings) maxTopicPeers() int {
+	s.RLock()
+	defer s.RUnlock()
+	return s.maxPeers
+}
+
+func (s *settings) logRateLimit() LogRateLimit {
+	s.RLock()
+	defer s.RUnlock()
+	return s.logRate
+}
+
+func (s *settings) autoPull() bool {
+	s.RLock()
+	defer s.RUnlock()
+	return !s.autoPullOff
+}
+
+// SetRequestTimeout sets the max time to wait for a request to a peer.
+// A non-positive timeout restores the default of DialTimeout.
+func (n *net) SetRequestTimeout(timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = DialTimeout
+	}
+	n.settings.Lock()
+	defer n.settings.Unlock()
+	n.settings.timeout = timeout
+}
+
+// SetMaxThreadGoroutines sets the max number of background goroutines per thread.
+// Zero means no limit.
+func (n *net) SetMaxThreadGoroutines(max int) {
+	n.settings.Lock()
+	defer n.settings.Unlock()
+	n.settings.maxRoutines = max
+}
+
+// SetMaxTopicPeers sets the number of thread topic peers above which records
+// are only pushed directly. Zero means no limit.
+func (n *net) SetMaxTopicPeers(max int) {
+	n.settings.Lock()
+	defer n.settings.Unlock()
+	n.settings.maxPeers = max
+}
+
+// SetAutoPull enables or disables the periodic pulling of all threads.
+func (n *net) SetAutoPull(enabled bool) {
+	n.settings.Lock()
+	defer n.settings.Unlock()
+	n.settings.autoPullOff = !enabled
+}
+
+// SetLogRateLimit sets the max number of new records a log may receive by
+// push per interval. A zero records or interval disables the limit.
+func (n *net) SetLogRateLimit(records int, interval time.Duration) {
+	n.settings.Lock()
+	defer n.settings.Unlock()
+	n.settings.logRate = LogRateLimit{Records: records, Interval: interval}
+}
@@ -0,0 +1,33 @@
+package net
+
+import (
+	"github.com/gogo/protobuf/proto"
+	"github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/libp2p/go-libp2p-core/peer"
+	pb "github.com/textileio/go-threads/net/pb"
+)
+
+// SignRequest signs a request body with sk and returns the request header.
+// The signature covers the protobuf encoding of the body as returned by
+// body.Marshal(). Ed25519 keys produce deterministic signatures, so the
+// result can be compared byte-for-byte across implementations.
+func SignRequest(sk crypto.PrivKey, body proto.Marshaler) (*pb.Header, error) {
+	payload, err := body.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	sig, err := sk.Sign(payload)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.Header{
+		PubKey:    &pb.ProtoPubKey{PubKey: sk.GetPublic()},
+		Signature: sig,
+	}, nil
+}
+
+// VerifyRequest verifies a request header against its body and returns the
+// ID of the signing peer.
+func VerifyRequest(header *pb.Header, body proto.Marshaler) (peer.ID, error) {
+	return verifyRequest(header, body)
+}
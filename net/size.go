@@ -0,0 +1,74 @@
+package net
+
+import (
+	"context"
+	"fmt"
+
+	format "github.com/ipfs/go-ipld-format"
+	"github.com/textileio/go-threads/cbor"
+	core "github.com/textileio/go-threads/core/net"
+	"github.com/textileio/go-threads/core/thread"
+	"github.com/textileio/go-threads/crypto"
+)
+
+// ErrRecordTooLarge indicates a received record's event body exceeds
+// Config.MaxRecordSize.
+var ErrRecordTooLarge = fmt.Errorf("record body too large")
+
+// checkRecordSize returns ErrRecordTooLarge if the event body of a received
+// record exceeds the configured maximum. The body is measured decrypted with
+// the current or a retired read key. If none of them is known or decrypts the
+// body, the encrypted size is used, which is never smaller.
+func (n *net) checkRecordSize(id thread.ID, rec core.Record) error {
+	max := n.conf.MaxRecordSize
+	if max <= 0 {
+		return nil
+	}
+	// The event, header, and body of a received record are already loaded
+	event, err := cbor.EventFromRecord(context.Background(), nil, rec)
+	if err != nil {
+		return err
+	}
+	keys, err := n.readKeys(id)
+	if err != nil {
+		return err
+	}
+	var body format.Node
+	for _, rk := range keys {
+		if body, err = event.GetBody(context.Background(), nil, rk); err == nil {
+			break
+		}
+	}
+	if body == nil {
+		if body, err = event.GetBody(context.Background(), nil, nil); err != nil {
+			return err
+		}
+	}
+	if size := len(body.RawData()); size > max {
+		return fmt.Errorf("%w: %d bytes exceeds %d", ErrRecordTooLarge, size, max)
+	}
+	return nil
+}
+
+// readKeys returns the current and retired read keys of a thread that are
+// known, newest first.
+func (n *net) readKeys(id thread.ID) ([]crypto.DecryptionKey, error) {
+	var keys []crypto.DecryptionKey
+	rk, err := n.store.ReadKey(id)
+	if err != nil {
+		return nil, err
+	}
+	if rk != nil {
+		keys = append(keys, rk)
+	}
+	retired, err := n.retiredKeys(id)
+	if err != nil {
+		return nil, err
+	}
+	for _, k := range retired {
+		if k.CanRead() {
+			keys = append(keys, k.Read())
+		}
+	}
+	return keys, nil
+}
@@ -0,0 +1,97 @@
+package net
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ipfs/go-cid"
+	bs "github.com/ipfs/go-ipfs-blockstore"
+	format "github.com/ipfs/go-ipld-format"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/textileio/go-threads/cbor"
+	core "github.com/textileio/go-threads/core/net"
+	"github.com/textileio/go-threads/core/thread"
+	sym "github.com/textileio/go-threads/crypto/symmetric"
+)
+
+// ThreadStats returns stats about the records of a thread held locally.
+// Blocks are only read from the thread's blockstore, so records and blocks
+// that are not held are not fetched.
+func (n *net) ThreadStats(id thread.ID) (stats core.ThreadStats, err error) {
+	info, err := n.store.GetThread(id)
+	if err != nil {
+		return
+	}
+	keys, err := n.serviceKeys(id)
+	if err != nil {
+		return
+	}
+	ds, size := n.localDAG(id)
+
+	stats.Logs = make(map[peer.ID]core.LogStats, len(info.Logs))
+	seen := make(map[cid.Cid]struct{})
+	for _, lg := range info.Logs {
+		var ls core.LogStats
+		if ls.Heads, err = n.store.Heads(id, lg.ID); err != nil {
+			return
+		}
+		for _, head := range ls.Heads {
+			for cursor := head; cursor.Defined(); {
+				if _, ok := seen[cursor]; ok {
+					break
+				}
+				if has, err := n.hasBlock(id, cursor); err != nil {
+					return stats, err
+				} else if !has {
+					break
+				}
+				r, err := getLocalRecord(n.ctx, ds, cursor, keys)
+				if err != nil {
+					return stats, err
+				}
+				rsize, err := recordSize(n.ctx, ds, r, size)
+				if err != nil {
+					return stats, err
+				}
+				seen[cursor] = struct{}{}
+				ls.Records++
+				ls.Bytes += rsize
+				cursor = r.PrevID()
+			}
+		}
+		stats.Logs[lg.ID] = ls
+		stats.Records += ls.Records
+		stats.Bytes += ls.Bytes
+	}
+	return stats, nil
+}
+
+// getLocalRecord decodes the record at rid with the first service key that works.
+func getLocalRecord(ctx context.Context, ds format.DAGService, rid cid.Cid, keys []*sym.Key) (rec core.Record, err error) {
+	for _, sk := range keys {
+		if rec, err = cbor.GetRecord(ctx, ds, rid, sk); err == nil {
+			return rec, nil
+		}
+	}
+	return
+}
+
+// recordSize returns the size of the record, event, header, and body blocks
+// of a record that are held locally, as reported by size.
+func recordSize(ctx context.Context, ds format.DAGService, r core.Record, size func(cid.Cid) (int, error)) (int64, error) {
+	ids := []cid.Cid{r.Cid(), r.BlockID()}
+	if event, err := cbor.EventFromRecord(ctx, ds, r); err == nil {
+		ids = append(ids, event.HeaderID(), event.BodyID())
+	}
+	var total int64
+	for _, c := range ids {
+		s, err := size(c)
+		if errors.Is(err, bs.ErrNotFound) || errors.Is(err, format.ErrNotFound) {
+			continue
+		} else if err != nil {
+			return 0, err
+		}
+		total += int64(s)
+	}
+	return total, nil
+}
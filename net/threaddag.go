@@ -0,0 +1,77 @@
+package net
+
+import (
+	"context"
+	"errors"
+
+	bserv "github.com/ipfs/go-blockservice"
+	"github.com/ipfs/go-cid"
+	offline "github.com/ipfs/go-ipfs-exchange-offline"
+	format "github.com/ipfs/go-ipld-format"
+	dag "github.com/ipfs/go-merkledag"
+	"github.com/textileio/go-threads/cbor"
+	core "github.com/textileio/go-threads/core/net"
+	"github.com/textileio/go-threads/core/thread"
+)
+
+// DAGResolver returns the DAG service in which to keep a thread's records.
+// Returning nil selects the network's shared DAG service. Resolved DAG
+// services must not fetch blocks from the network, since they are also used
+// to check which records are held locally.
+type DAGResolver func(id thread.ID) format.DAGService
+
+// threadDAG returns the DAG service holding a thread's records.
+func (n *net) threadDAG(id thread.ID) format.DAGService {
+	if n.conf.DAGResolver != nil {
+		if ds := n.conf.DAGResolver(id); ds != nil {
+			return ds
+		}
+	}
+	return n.DAGService
+}
+
+// localDAG returns a DAG service that only reads a thread's blocks held
+// locally, along with a func returning the size of a block.
+func (n *net) localDAG(id thread.ID) (format.DAGService, func(cid.Cid) (int, error)) {
+	ds := n.threadDAG(id)
+	if ds != n.DAGService {
+		return ds, func(c cid.Cid) (int, error) {
+			nd, err := ds.Get(n.ctx, c)
+			if err != nil {
+				return 0, err
+			}
+			return len(nd.RawData()), nil
+		}
+	}
+	return dag.NewDAGService(bserv.New(n.bstore, offline.Exchange(n.bstore))), n.bstore.GetSize
+}
+
+// hasBlock returns whether or not a thread's block is held locally.
+func (n *net) hasBlock(id thread.ID, c cid.Cid) (bool, error) {
+	ds := n.threadDAG(id)
+	if ds == n.DAGService {
+		return n.bstore.Has(c)
+	}
+	if _, err := ds.Get(n.ctx, c); errors.Is(err, format.ErrNotFound) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// preloadRecord loads the blocks of a record from a thread's DAG service, so
+// that they're available to callers using the network's shared DAG service.
+func (n *net) preloadRecord(ctx context.Context, ds format.DAGService, rec core.Record) {
+	if ds == n.DAGService {
+		return
+	}
+	event, err := cbor.EventFromRecord(ctx, ds, rec)
+	if err != nil {
+		return
+	}
+	if _, err = event.GetHeader(ctx, ds, nil); err != nil {
+		return
+	}
+	_, _ = event.GetBody(ctx, ds, nil)
+}
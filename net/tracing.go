@@ -0,0 +1,159 @@
+package net
+
+import (
+	"context"
+
+	"github.com/ipfs/go-cid"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/textileio/go-threads/core/thread"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// tracerName is the instrumentation name of the network's tracer.
+const tracerName = "github.com/textileio/go-threads/net"
+
+// tracer creates spans for pushes and pulls, and propagates their context to
+// peers in gRPC metadata. Methods on a nil *tracer do nothing, so that
+// tracing costs nothing when no tracer provider is configured.
+type tracer struct {
+	t trace.Tracer
+	p propagation.TraceContext
+}
+
+func newTracer(tp trace.TracerProvider) *tracer {
+	if tp == nil {
+		return nil
+	}
+	return &tracer{t: tp.Tracer(tracerName)}
+}
+
+// start starts a span with the thread, log, and record as attributes.
+// Undefined IDs are omitted.
+func (t *tracer) start(ctx context.Context, name string, id thread.ID, lid peer.ID, rid cid.Cid) (context.Context, trace.Span) {
+	if t == nil {
+		return ctx, nil
+	}
+	ctx, span := t.t.Start(ctx, name)
+	t.annotate(span, id, lid, rid)
+	return ctx, span
+}
+
+// annotate adds the thread, log, and record to a span started with start.
+// Undefined IDs are omitted.
+func (t *tracer) annotate(span trace.Span, id thread.ID, lid peer.ID, rid cid.Cid) {
+	if t == nil || span == nil {
+		return
+	}
+	var kvs []label.KeyValue
+	if id.Defined() {
+		kvs = append(kvs, label.String("thread.id", id.String()))
+	}
+	if lid != "" {
+		kvs = append(kvs, label.String("log.id", lid.String()))
+	}
+	if rid.Defined() {
+		kvs = append(kvs, label.String("record.cid", rid.String()))
+	}
+	span.SetAttributes(kvs...)
+}
+
+// end ends a span started with start, recording err if it's not nil.
+func (t *tracer) end(span trace.Span, err error) {
+	if t == nil || span == nil {
+		return
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// detach returns a background context that carries the span of ctx, for
+// requests that may outlive ctx.
+func (t *tracer) detach(ctx context.Context) context.Context {
+	if t == nil {
+		return context.Background()
+	}
+	return trace.ContextWithSpan(context.Background(), trace.SpanFromContext(ctx))
+}
+
+// dialOptions returns the interceptors that send the span context of
+// requests to peers.
+func (t *tracer) dialOptions() []grpc.DialOption {
+	if t == nil {
+		return nil
+	}
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			return invoker(t.inject(ctx), method, req, reply, cc, opts...)
+		}),
+		grpc.WithChainStreamInterceptor(func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			return streamer(t.inject(ctx), desc, cc, method, opts...)
+		}),
+	}
+}
+
+// serverOptions returns the interceptors that continue the spans of peers
+// in the handling of their requests.
+func (t *tracer) serverOptions() []grpc.ServerOption {
+	if t == nil {
+		return nil
+	}
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			return handler(t.extract(ctx), req)
+		}),
+		grpc.ChainStreamInterceptor(func(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			return handler(srv, &tracedStream{ServerStream: ss, ctx: t.extract(ss.Context())})
+		}),
+	}
+}
+
+func (t *tracer) inject(ctx context.Context) context.Context {
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if ok {
+		md = md.Copy()
+	} else {
+		md = metadata.MD{}
+	}
+	t.p.Inject(ctx, mdCarrier(md))
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
+func (t *tracer) extract(ctx context.Context) context.Context {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ctx
+	}
+	return t.p.Extract(ctx, mdCarrier(md))
+}
+
+// mdCarrier adapts gRPC metadata to a propagation carrier.
+type mdCarrier metadata.MD
+
+func (c mdCarrier) Get(key string) string {
+	if v := metadata.MD(c).Get(key); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+func (c mdCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+// tracedStream is a server stream with a context carrying a peer's span.
+type tracedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *tracedStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,96 @@
+package net
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gogo/status"
+	"github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/libp2p/go-libp2p-core/peer"
+	core "github.com/textileio/go-threads/core/net"
+	"github.com/textileio/go-threads/core/thread"
+	"google.golang.org/grpc/codes"
+)
+
+// Verdict is the outcome of a record validator.
+type Verdict int
+
+const (
+	// VerdictAccept passes the record on to the next validator.
+	VerdictAccept Verdict = iota
+	// VerdictReject drops the record.
+	VerdictReject
+)
+
+// PushedRecord is a record received from a peer, either by a direct push or
+// over pubsub, that has not been stored yet.
+type PushedRecord struct {
+	// Thread is the ID of the record's thread.
+	Thread thread.ID
+	// Log is the ID of the record's log.
+	Log peer.ID
+	// LogKey is the public key of the record's log.
+	LogKey crypto.PubKey
+	// From is the peer that sent the record.
+	From peer.ID
+	// Record is the received record.
+	Record core.Record
+}
+
+// RecordValidator decides whether a received record may be stored.
+// A rejected record may come with an error describing why. An error with
+// VerdictAccept means the validator failed, and the record is dropped too.
+// gRPC status errors are returned to the sender as is.
+type RecordValidator func(ctx context.Context, r PushedRecord) (Verdict, error)
+
+// recordValidators returns the validation pipeline of received records.
+// Signatures are checked first, and the rate limit last, so that only
+// records that would otherwise be stored count against the limit.
+func (n *net) recordValidators() []RecordValidator {
+	vs := make([]RecordValidator, 0, len(n.conf.RecordValidators)+2)
+	vs = append(vs, validateSignature)
+	vs = append(vs, n.conf.RecordValidators...)
+	return append(vs, n.validateRateLimit)
+}
+
+// validateRecord runs a received record through the validation pipeline,
+// returning a status error if it's not accepted.
+func (n *net) validateRecord(ctx context.Context, r PushedRecord) error {
+	for _, v := range n.recordValidators() {
+		verdict, err := v(ctx, r)
+		switch {
+		case verdict == VerdictReject:
+			if err == nil {
+				err = fmt.Errorf("record %s rejected", r.Record.Cid())
+			}
+			return validationStatus(err, codes.PermissionDenied)
+		case err != nil:
+			return validationStatus(err, codes.Internal)
+		}
+	}
+	return nil
+}
+
+// validationStatus returns err as a status error, using code if err is not
+// a status error already.
+func validationStatus(err error, code codes.Code) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	return status.Error(code, err.Error())
+}
+
+func validateSignature(_ context.Context, r PushedRecord) (Verdict, error) {
+	if err := r.Record.Verify(r.LogKey); err != nil {
+		return VerdictReject, status.Error(codes.Unauthenticated, err.Error())
+	}
+	return VerdictAccept, nil
+}
+
+func (n *net) validateRateLimit(_ context.Context, r PushedRecord) (Verdict, error) {
+	if !n.limiter.allow(r.Thread, r.Log, n.settings.logRateLimit(), time.Now()) {
+		return VerdictReject, status.Error(codes.ResourceExhausted, "log rate limit exceeded")
+	}
+	return VerdictAccept, nil
+}
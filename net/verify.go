@@ -0,0 +1,192 @@
+package net
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/ipfs/go-cid"
+	"github.com/libp2p/go-libp2p-core/peer"
+	ma "github.com/multiformats/go-multiaddr"
+	core "github.com/textileio/go-threads/core/net"
+	"github.com/textileio/go-threads/core/thread"
+)
+
+// VerifyThread queries all known thread members for their log heads and
+// compares them with the local heads.
+// A member that doesn't report a log is not counted as behind, since members
+// may only track the logs they care about. Logs are reported in order of ID,
+// and the peers in each list in order of ID.
+func (n *net) VerifyThread(ctx context.Context, id thread.ID) (report core.VerifyReport, err error) {
+	info, err := n.store.GetThread(id)
+	if err != nil {
+		return
+	}
+	report.ThreadID = id
+
+	members := make(map[peer.ID]struct{})
+	for _, lg := range info.Logs {
+		for _, addr := range lg.Addrs {
+			pid, err := peerIDFromAddr(addr)
+			if err != nil {
+				n.log.Error(err)
+				continue
+			}
+			if pid != n.host.ID() {
+				members[pid] = struct{}{}
+			}
+		}
+	}
+
+	var lock sync.Mutex
+	heads := make(map[peer.ID]map[peer.ID]cid.Cid) // log -> member -> head
+	wg := sync.WaitGroup{}
+	for pid := range members {
+		wg.Add(1)
+		go func(pid peer.ID) {
+			defer wg.Done()
+			lgs, err := n.server.getLogs(ctx, id, pid)
+			lock.Lock()
+			defer lock.Unlock()
+			if err != nil {
+				report.Unreachable = append(report.Unreachable, pid)
+				return
+			}
+			for _, lg := range lgs {
+				if heads[lg.ID] == nil {
+					heads[lg.ID] = make(map[peer.ID]cid.Cid)
+				}
+				heads[lg.ID][pid] = lg.Head
+			}
+		}(pid)
+	}
+	wg.Wait()
+
+	local := make(map[peer.ID]cid.Cid)
+	for _, lg := range info.Logs {
+		local[lg.ID] = lg.Head
+		if _, ok := heads[lg.ID]; !ok {
+			heads[lg.ID] = make(map[peer.ID]cid.Cid)
+		}
+	}
+	for lid, hs := range heads {
+		lr := core.LogReport{
+			LogID:     lid,
+			LocalHead: local[lid],
+			Heads:     hs,
+		}
+		distinct := make(map[cid.Cid]struct{})
+		for pid, h := range hs {
+			distinct[h] = struct{}{}
+			if h == lr.LocalHead {
+				continue
+			}
+			if !h.Defined() {
+				lr.Behind = append(lr.Behind, pid)
+				continue
+			}
+			has, err := n.hasBlock(id, h)
+			if err != nil {
+				return report, err
+			}
+			if has {
+				lr.Behind = append(lr.Behind, pid)
+			} else {
+				lr.Ahead = append(lr.Ahead, pid)
+			}
+		}
+		lr.Divergent = len(distinct) > 1
+		sortPeers(lr.Behind)
+		sortPeers(lr.Ahead)
+		report.Logs = append(report.Logs, lr)
+	}
+	sort.Slice(report.Logs, func(i, j int) bool {
+		return report.Logs[i].LogID < report.Logs[j].LogID
+	})
+	sortPeers(report.Unreachable)
+	return report, nil
+}
+
+// sortPeers sorts peer IDs, so that reports don't depend on the order in
+// which members replied.
+func sortPeers(pids []peer.ID) {
+	sort.Slice(pids, func(i, j int) bool {
+		return pids[i] < pids[j]
+	})
+}
+
+// IsReplicatedTo queries a peer for its log heads and returns true if, for
+// every log with a local head, the peer's head is at least as advanced.
+// A head that is unknown locally is only taken to be ahead if the peer
+// returns the records linking it to the local head, see descends.
+func (n *net) IsReplicatedTo(ctx context.Context, id thread.ID, pid peer.ID) (bool, error) {
+	info, err := n.store.GetThread(id)
+	if err != nil {
+		return false, err
+	}
+	lgs, err := n.server.getLogs(ctx, id, pid)
+	if err != nil {
+		return false, err
+	}
+	heads := make(map[peer.ID]cid.Cid, len(lgs))
+	for _, lg := range lgs {
+		heads[lg.ID] = lg.Head
+	}
+	for _, lg := range info.Logs {
+		if !lg.Head.Defined() {
+			continue
+		}
+		h := heads[lg.ID]
+		if !h.Defined() {
+			return false, nil
+		}
+		if h.Equals(lg.Head) {
+			continue
+		}
+		has, err := n.hasBlock(id, h)
+		if err != nil {
+			return false, err
+		}
+		if !has {
+			ok, err := n.descends(ctx, id, lg, h, pid)
+			if err != nil || !ok {
+				return false, err
+			}
+			continue
+		}
+		ahead, err := n.isAhead(id, h, lg.Head)
+		if err != nil {
+			return false, err
+		}
+		if !ahead {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// descends returns whether or not head descends from the local head of a log,
+// by requesting the records in between from a peer. The records must be
+// signed by the log key and link head to the local head. Heads more than
+// MaxPullLimit records ahead are not followed.
+func (n *net) descends(ctx context.Context, id thread.ID, lg thread.LogInfo, head cid.Cid, pid peer.ID) (bool, error) {
+	if lg.PubKey == nil {
+		return false, nil
+	}
+	addr, err := ma.NewMultiaddr("/" + ma.ProtocolWithCode(ma.P_P2P).Name + "/" + pid.String())
+	if err != nil {
+		return false, err
+	}
+	recs, err := n.server.getRecordRange(ctx, id, lg.ID, lg.Head, head, []ma.Multiaddr{addr})
+	if err != nil {
+		return false, err
+	}
+	prev := lg.Head
+	for _, r := range recs {
+		if !r.PrevID().Equals(prev) || r.Verify(lg.PubKey) != nil {
+			return false, nil
+		}
+		prev = r.Cid()
+	}
+	return prev.Equals(head), nil
+}
@@ -20,6 +20,7 @@ var keyBookSuite = map[string]func(kb core.KeyBook) func(*testing.T){
 	"AddGetPubKey":            testKeyBookPubKey,
 	"AddGetReadKey":           testKeyBookReadKey,
 	"AddGetServiceKey":        testKeyBookServiceKey,
+	"SetGetRetiredKeys":       testKeyBookRetiredKeys,
 	"LogsWithKeys":            testKeyBookLogs,
 	"testKeyBookClearKeys":    testKeyBookClearKeys,
 	"testKeyBookClearLogKeys": testKeyBookClearLogKeys,
@@ -166,6 +167,41 @@ func testKeyBookServiceKey(kb core.KeyBook) func(t *testing.T) {
 	}
 }
 
+func testKeyBookRetiredKeys(kb core.KeyBook) func(t *testing.T) {
+	return func(t *testing.T) {
+		tid := thread.NewIDV1(thread.Raw, 24)
+
+		if keys, err := kb.RetiredKeys(tid); err != nil || len(keys) > 0 {
+			t.Error("expected retired keys to be empty on init without errors")
+		}
+
+		keys := []thread.Key{thread.NewRandomKey(), thread.NewRandomServiceKey()}
+		if err := kb.SetRetiredKeys(tid, keys); err != nil {
+			t.Fatal(err)
+		}
+
+		res, err := kb.RetiredKeys(tid)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res) != len(keys) {
+			t.Fatalf("expected %d retired keys, got %d", len(keys), len(res))
+		}
+		for i := range keys {
+			if res[i].String() != keys[i].String() {
+				t.Errorf("retrieved retired key %d did not match stored key", i)
+			}
+		}
+
+		if err = kb.ClearKeys(tid); err != nil {
+			t.Fatal(err)
+		}
+		if keys, err := kb.RetiredKeys(tid); err != nil || len(keys) > 0 {
+			t.Error("retired keys should have been deleted")
+		}
+	}
+}
+
 func testKeyBookClearKeys(kb core.KeyBook) func(t *testing.T) {
 	return func(t *testing.T) {
 		tid := thread.NewIDV1(thread.Raw, 24)
@@ -287,6 +323,18 @@ func testKeyBookClearLogKeys(kb core.KeyBook) func(t *testing.T) {
 			t.Error("missing public key")
 		}
 
+		_, otherPub, err := pt.RandTestKeyPair(crypto.RSA, crypto.MinRsaKeyBits)
+		if err != nil {
+			t.Fatal(err)
+		}
+		other, err := peer.IDFromPublicKey(otherPub)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = kb.AddPubKey(tid, other, otherPub); err != nil {
+			t.Fatal(err)
+		}
+
 		if err = kb.ClearLogKeys(tid, id); err != nil {
 			t.Fatal(err)
 		}
@@ -297,6 +345,9 @@ func testKeyBookClearLogKeys(kb core.KeyBook) func(t *testing.T) {
 		if res, err := kb.PubKey(tid, id); err != nil || res != nil {
 			t.Error("public key should have been deleted")
 		}
+		if res, err := kb.PubKey(tid, other); err != nil || res == nil {
+			t.Error("other log's public key should not have been deleted")
+		}
 	}
 }
 
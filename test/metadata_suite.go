@@ -21,6 +21,7 @@ var metadataBookSuite = map[string]func(mb core.ThreadMetadata) func(*testing.T)
 	"String":   testMetadataBookString,
 	"Byte":     testMetadataBookBytes,
 	"NotFound": testMetadataBookNotFound,
+	"Clear":    testMetadataBookClear,
 }
 
 type MetadataBookFactory func() (core.ThreadMetadata, func())
@@ -158,3 +159,33 @@ func testMetadataBookNotFound(mb core.ThreadMetadata) func(*testing.T) {
 		})
 	}
 }
+
+func testMetadataBookClear(mb core.ThreadMetadata) func(*testing.T) {
+	return func(t *testing.T) {
+		tid := thread.NewIDV1(thread.Raw, 24)
+		other := thread.NewIDV1(thread.Raw, 24)
+
+		key := "key1"
+		if err := mb.PutString(tid, key, "textile"); err != nil {
+			t.Fatalf(errStrPut, key, err)
+		}
+		if err := mb.PutInt64(tid, "key2", 42); err != nil {
+			t.Fatalf(errStrPut, "key2", err)
+		}
+		if err := mb.PutString(other, key, "textile"); err != nil {
+			t.Fatalf(errStrPut, key, err)
+		}
+		if err := mb.ClearMetadata(tid); err != nil {
+			t.Fatalf("clear failed: %v", err)
+		}
+		if v, err := mb.GetString(tid, key); v != nil || err != nil {
+			t.Fatalf(errStrNotFoundKey)
+		}
+		if v, err := mb.GetInt64(tid, "key2"); v != nil || err != nil {
+			t.Fatalf(errStrNotFoundKey)
+		}
+		if v, err := mb.GetString(other, key); v == nil || err != nil {
+			t.Fatalf(errStrValueShouldExist)
+		}
+	}
+}